@@ -17,25 +17,39 @@ import (
 
 	msg "main/internal/database/mongo"
 	psql "main/internal/database/postgres"
+	audit "main/internal/database/postgres/audit_repo"
 	auth "main/internal/database/postgres/auth_repo"
 	chat "main/internal/database/postgres/chat_repo"
+	export "main/internal/database/postgres/export_repo"
 	user "main/internal/database/postgres/user_repo"
 	rdb "main/internal/database/redis"
 	authRPC "main/internal/delivery/grpc/auth"
 	interceptor "main/internal/delivery/grpc/middleware"
 	httpHandler "main/internal/delivery/http"
+	AccountHandler "main/internal/delivery/http/account"
+	AuditHandler "main/internal/delivery/http/audit"
+	BotHandler "main/internal/delivery/http/bot"
 	ChatHandler "main/internal/delivery/http/chat"
 	MessageHandler "main/internal/delivery/http/message"
 	"main/internal/delivery/http/middleware/metrics"
+	"main/internal/delivery/http/middleware/requestinfo"
 	UserHandler "main/internal/delivery/http/user"
+	WellKnownHandler "main/internal/delivery/http/wellknown"
 	"main/internal/delivery/ws"
+	"main/internal/infrastructure/blob"
 	kafka "main/internal/infrastructure/kafka"
+	"main/internal/infrastructure/mailer"
+	srvAccount "main/internal/usecase/account"
+	srvAudit "main/internal/usecase/audit"
 	srvAuth "main/internal/usecase/auth"
+	srvBot "main/internal/usecase/bot"
 	srvChat "main/internal/usecase/chat"
 	eventHandler "main/internal/usecase/event"
 	srvMessage "main/internal/usecase/message"
 	srvUser "main/internal/usecase/user"
 	claims "main/pkg/jwt"
+	"main/pkg/oidc"
+	"main/pkg/password"
 	pb "main/pkg/proto/gen/auth/v1"
 
 	"github.com/go-chi/chi"
@@ -56,6 +70,7 @@ const (
 type CombinedTokenManager struct {
 	*claims.Manager
 	*rdb.Cache
+	*srvBot.BotService
 }
 
 func main() {
@@ -75,7 +90,25 @@ func main() {
 	metricsRouter.Handle("/metrics", promhttp.Handler())
 
 	//-----------------------JWT Claims---------------------------
-	jwtManager, err := claims.NewManager(secretKey)
+	var (
+		jwtManager *claims.Manager
+		err        error
+	)
+	if cfg.JWT.Algorithm == claims.AlgHS256 {
+		jwtManager, err = claims.NewManager(secretKey)
+	} else {
+		keyOpts := claims.KeyOptions{
+			Algorithm:        cfg.JWT.Algorithm,
+			KeysDir:          cfg.JWT.KeysDir,
+			Retention:        cfg.JWT.KeyRetention + cfg.Auth.TokenTTL,
+			RotationInterval: cfg.JWT.RotationInterval,
+		}
+		if cfg.JWT.AcceptLegacyTokens {
+			keyOpts.LegacySecret = secretKey
+			keyOpts.LegacyTTL = cfg.Auth.TokenTTL
+		}
+		jwtManager, err = claims.NewAsymmetricManager(keyOpts)
+	}
 	if err != nil {
 		logger.Error("failed to create JWT manager", slog.String("error", err.Error()))
 		return
@@ -116,10 +149,12 @@ func main() {
 	userRepo := user.NewUserRepository(postgres)
 	chatRepo := chat.NewChatRepository(postgres, logger)
 	msgRepo := msg.NewMessageRepository(mongoClient, logger)
-
-	tokenController := &CombinedTokenManager{
-		Manager: jwtManager,
-		Cache:   NewCache,
+	auditRepo := audit.NewAuditRepository(postgres)
+	exportRepo := export.NewExportRepository(postgres)
+	exportStore, err := blob.NewLocalStore(cfg.Account.ExportDir)
+	if err != nil {
+		logger.Error("failed to create export store", slog.String("error", err.Error()))
+		return
 	}
 
 	//-----------------------Kafka-------------------------------
@@ -147,24 +182,129 @@ func main() {
 		}
 	}()
 
+	//-----------------------Mailer-------------------------------
+	var mailSender srvAuth.Mailer
+	switch cfg.Mail.Driver {
+	case "smtp":
+		if cfg.Mail.Host == "" {
+			logger.Error("mail host is not set; set MAIL_HOST, or MAIL_DRIVER=file for local runs")
+			return
+		}
+		mailSender = mailer.NewSMTPMailer(cfg.Mail)
+	case "file":
+		logger.Warn("file mailer logs verification codes and reset links; use it for local runs only")
+		mailSender, err = mailer.NewFileMailer(cfg.Mail.Dir, cfg.Mail.From, logger)
+		if err != nil {
+			logger.Error("failed to create mailer", slog.String("error", err.Error()))
+			return
+		}
+	default:
+		logger.Error("unknown mail driver", slog.String("driver", cfg.Mail.Driver))
+		return
+	}
+
+	//-----------------------OIDC Providers-------------------------------
+	oidcProviders := make(map[string]srvAuth.OIDCProvider, len(cfg.OIDC))
+	for _, p := range cfg.OIDC {
+		oidcProviders[p.Name] = oidc.NewProvider(oidc.Config{
+			IssuerURL:    p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		}, nil)
+	}
+
+	//-----------------------Passwords-------------------------------
+	breached := password.CommonPasswords()
+	if cfg.Auth.Password.BreachedList != "" {
+		breached, err = password.LoadBreachedList(cfg.Auth.Password.BreachedList)
+		if err != nil {
+			logger.Error("failed to load breached password list", slog.String("error", err.Error()))
+			return
+		}
+		logger.Info("breached password list loaded", slog.Int("passwords", breached.Len()))
+	}
+	passwordPolicy := password.Policy{
+		MinLength:     cfg.Auth.Password.MinLength,
+		MaxLength:     cfg.Auth.Password.MaxLength,
+		RequireUpper:  cfg.Auth.Password.RequireUpper,
+		RequireLower:  cfg.Auth.Password.RequireLower,
+		RequireDigit:  cfg.Auth.Password.RequireDigit,
+		RequireSymbol: cfg.Auth.Password.RequireSymbol,
+		Breached:      breached,
+	}
+	passwordHasher := password.NewHasher(password.Params{
+		Memory:      cfg.Auth.Password.MemoryKiB,
+		Iterations:  cfg.Auth.Password.Iterations,
+		Parallelism: cfg.Auth.Password.Parallelism,
+	})
+
 	//-----------------------Services-------------------------------
-	userService := srvUser.NewUserService(userRepo, logger)
-	authService := srvAuth.NewAuthService(authRepo, userRepo, jwtManager, NewCache, cfg.Auth.TokenTTL)
-	chatService := srvChat.NewChatService(userRepo, chatRepo, logger)
-	messageService := srvMessage.NewMessageService(chatRepo, msgRepo, producer, logger)
+	auditService := srvAudit.NewAuditService(auditRepo, logger)
+	userService := srvUser.NewUserService(userRepo, auditService, logger)
+	emailCodeSecret := cfg.Auth.EmailCodeSecret
+	if emailCodeSecret == "" {
+		emailCodeSecret = config.DeriveSecret(secretKey, "email verification codes")
+	}
+	authService := srvAuth.NewAuthService(authRepo, userRepo, jwtManager, NewCache, NewCache, mailSender, logger, srvAuth.Options{
+		TokenTTL:   cfg.Auth.TokenTTL,
+		TOTPIssuer: cfg.Auth.TOTPIssuer,
+		CodeSecret: emailCodeSecret,
+		Lockout: srvAuth.LockoutPolicy{
+			MaxUserFailures: cfg.Auth.MaxFailuresPerUser,
+			MaxIPFailures:   cfg.Auth.MaxFailuresPerIP,
+			Window:          cfg.Auth.FailureWindow,
+			BaseDelay:       cfg.Auth.LockoutBase,
+			MaxDelay:        cfg.Auth.LockoutMax,
+		},
+		OIDCProviders:  oidcProviders,
+		PasswordHasher: passwordHasher,
+		PasswordPolicy: &passwordPolicy,
+		Audit:          auditService,
+	})
+	wsManager := ws.NewManager(logger)
+	membershipEvents := srvChat.MembershipPublishers{producer, ws.NewMembershipNotifier(wsManager, chatRepo, logger)}
+	chatService := srvChat.NewChatService(userRepo, chatRepo, msgRepo, membershipEvents, auditService, logger)
+	chatService.Messages = producer
+	inviteService := srvChat.NewInviteService(chatService, chatRepo, logger)
+	botService := srvBot.NewBotService(userRepo, logger)
+	messageService := srvMessage.NewMessageService(chatRepo, userRepo, msgRepo, producer, logger)
+	exportLinkSecret := cfg.Account.ExportLinkSecret
+	if exportLinkSecret == "" {
+		exportLinkSecret = config.DeriveSecret(secretKey, "export download links")
+	}
+	exportService := srvAccount.NewExportService(exportRepo, userRepo, chatRepo, msgRepo, exportStore, logger, srvAccount.ExportOptions{
+		LinkSecret: exportLinkSecret,
+		LinkTTL:    cfg.Account.ExportLinkTTL,
+		Retention:  cfg.Account.ExportRetention,
+		Audit:      auditService,
+	})
+	accountService := srvAccount.NewAccountService(userRepo, msgRepo, authService, producer, logger, srvAccount.Options{
+		DeletionGrace:   cfg.Account.DeletionGrace,
+		DeletedMessages: cfg.Account.DeletedMessages,
+		Exports:         exportService,
+		Audit:           auditService,
+	})
+
+	tokenController := &CombinedTokenManager{
+		Manager:    jwtManager,
+		Cache:      NewCache,
+		BotService: botService,
+	}
 
 	//-----------------------HTTP Server-------------------------------
 
-	wsManager := ws.NewManager(logger)
 	logger.Info("Connected to database successfully")
 
 	router.Use(middleware.RequestID)
+	router.Use(requestinfo.RequestInfo)
 	router.Use(metrics.PrometheusMiddleware)
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.URLFormat)
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"http.frontend.com", "http://localhost:8082"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-type", "X-CSRF-Token"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: true,
@@ -173,16 +313,25 @@ func main() {
 
 	//-----------------------Handlers-------------------------------
 	userHandler := UserHandler.NewUserHandler(userService, tokenController, logger)
-	chatHandler := ChatHandler.NewChatHandler(messageService, chatService, logger, tokenController)
+	chatHandler := ChatHandler.NewChatHandler(messageService, chatService, inviteService, logger, wsManager, tokenController)
 	messageHandler := MessageHandler.NewMessageHandler(messageService, chatService, logger, wsManager, tokenController)
+	wellKnownHandler := WellKnownHandler.NewWellKnownHandler(jwtManager, logger)
+	botHandler := BotHandler.NewBotHandler(botService, logger, tokenController)
+	auditHandler := AuditHandler.NewAuditHandler(auditService, logger, tokenController)
+	accountHandler := AccountHandler.NewAccountHandler(accountService, exportService, logger, tokenController)
 	authRpcHandler := authRPC.NewAuthHandler(authService, logger)
 
-	HTTP := httpHandler.NewHTTPHandler(userHandler, chatHandler, messageHandler, logger)
+	HTTP := httpHandler.NewHTTPHandler(userHandler, chatHandler, messageHandler, wellKnownHandler, botHandler, auditHandler, accountHandler, logger)
 	HTTP.RegisterRoutes(router)
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	go jwtManager.StartRotation(ctx, logger)
+	go accountService.StartPurge(ctx, cfg.Account.PurgeInterval)
+	go chatService.StartRestrictionSweeper(ctx, cfg.Chat.RestrictionSweepInterval)
+	go exportService.Start(ctx, cfg.Account.ExportPollInterval)
+
 	serverParams := &http.Server{
 		Addr:         addr,
 		Handler:      router,
@@ -194,10 +343,15 @@ func main() {
 		Addr:    net.JoinHostPort(cfg.Metrics.Host, strconv.Itoa(cfg.Metrics.Port)),
 		Handler: metricsRouter,
 	}
+	grpcAuth := interceptor.NewAuthenticator(jwtManager, NewCache, botService)
+	trustedProxies, err := interceptor.ParseTrustedProxies(cfg.Grpc.TrustedProxies)
+	if err != nil {
+		logger.Error("invalid trusted proxies", slog.String("error", err.Error()))
+		return
+	}
 	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(
-			interceptor.AuthInterceptor(jwtManager),
-		),
+		grpc.ChainUnaryInterceptor(interceptor.RequestInfoInterceptor(trustedProxies), grpcAuth.UnaryInterceptor()),
+		grpc.ChainStreamInterceptor(interceptor.RequestInfoStreamInterceptor(trustedProxies), grpcAuth.StreamInterceptor()),
 	)
 	pb.RegisterAuthServiceServer(grpcServer, authRpcHandler)
 
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/hex"
 	"flag"
 	"os"
 	"strconv"
@@ -18,6 +21,9 @@ type Metrics struct {
 type GrpcServer struct {
 	Host string `yaml:"host" env:"GRPC_HOST" env-default:"0.0.0.0"`
 	Port int    `yaml:"port" env:"GRPC_PORT" env-default:"50052"`
+	// TrustedProxies lists the CIDRs of proxies, such as the REST gateway,
+	// whose x-forwarded-for is used for the client IP.
+	TrustedProxies []string `yaml:"trusted_proxies" env:"GRPC_TRUSTED_PROXIES"`
 }
 
 type Server struct {
@@ -61,19 +67,113 @@ type Redis struct {
 }
 
 type Auth struct {
-	TokenTTL time.Duration `yaml:"token_ttl" env:"AUTH_TOKEN_TTL" env-default:"15m"`
+	TokenTTL   time.Duration `yaml:"token_ttl" env:"AUTH_TOKEN_TTL" env-default:"15m"`
+	TOTPIssuer string        `yaml:"totp_issuer" env:"AUTH_TOTP_ISSUER" env-default:"Chat"`
+	// EmailCodeSecret signs email verification codes. When unset it is
+	// derived from MY_SECRET_KEY.
+	EmailCodeSecret string `yaml:"-" env:"EMAIL_CODE_SECRET"`
+
+	// Failed login lockouts; a zero threshold disables the counter.
+	MaxFailuresPerUser int           `yaml:"max_failures_per_user" env:"AUTH_MAX_FAILURES_PER_USER" env-default:"5"`
+	MaxFailuresPerIP   int           `yaml:"max_failures_per_ip" env:"AUTH_MAX_FAILURES_PER_IP" env-default:"20"`
+	FailureWindow      time.Duration `yaml:"failure_window" env:"AUTH_FAILURE_WINDOW" env-default:"15m"`
+	LockoutBase        time.Duration `yaml:"lockout_base" env:"AUTH_LOCKOUT_BASE" env-default:"30s"`
+	LockoutMax         time.Duration `yaml:"lockout_max" env:"AUTH_LOCKOUT_MAX" env-default:"1h"`
+
+	Password Password `yaml:"password"`
+}
+
+// Password configures hashing and the rules for new passwords. Changing the
+// argon2id parameters upgrades stored hashes as users sign in.
+type Password struct {
+	MemoryKiB   uint32 `yaml:"memory_kib" env:"PASSWORD_MEMORY_KIB" env-default:"65536"`
+	Iterations  uint32 `yaml:"iterations" env:"PASSWORD_ITERATIONS" env-default:"3"`
+	Parallelism uint8  `yaml:"parallelism" env:"PASSWORD_PARALLELISM" env-default:"2"`
+
+	MinLength     int  `yaml:"min_length" env:"PASSWORD_MIN_LENGTH" env-default:"8"`
+	MaxLength     int  `yaml:"max_length" env:"PASSWORD_MAX_LENGTH" env-default:"128"`
+	RequireUpper  bool `yaml:"require_upper" env:"PASSWORD_REQUIRE_UPPER" env-default:"true"`
+	RequireLower  bool `yaml:"require_lower" env:"PASSWORD_REQUIRE_LOWER" env-default:"false"`
+	RequireDigit  bool `yaml:"require_digit" env:"PASSWORD_REQUIRE_DIGIT" env-default:"false"`
+	RequireSymbol bool `yaml:"require_symbol" env:"PASSWORD_REQUIRE_SYMBOL" env-default:"false"`
+	// BreachedList is a file of leaked passwords, one per line, checked in
+	// addition to the built-in list of common ones.
+	BreachedList string `yaml:"breached_list" env:"PASSWORD_BREACHED_LIST"`
+}
+
+// Account configures account deletion and personal data exports.
+// DeletedMessages is "anonymize" or "delete".
+type Account struct {
+	DeletionGrace   time.Duration `yaml:"deletion_grace" env:"ACCOUNT_DELETION_GRACE" env-default:"720h"`
+	PurgeInterval   time.Duration `yaml:"purge_interval" env:"ACCOUNT_PURGE_INTERVAL" env-default:"1h"`
+	DeletedMessages string        `yaml:"deleted_messages" env:"ACCOUNT_DELETED_MESSAGES" env-default:"anonymize"`
+
+	// ExportDir is where export archives are stored.
+	ExportDir          string        `yaml:"export_dir" env:"ACCOUNT_EXPORT_DIR" env-default:"./tmp/exports"`
+	ExportRetention    time.Duration `yaml:"export_retention" env:"ACCOUNT_EXPORT_RETENTION" env-default:"168h"`
+	ExportLinkTTL      time.Duration `yaml:"export_link_ttl" env:"ACCOUNT_EXPORT_LINK_TTL" env-default:"15m"`
+	ExportPollInterval time.Duration `yaml:"export_poll_interval" env:"ACCOUNT_EXPORT_POLL_INTERVAL" env-default:"1m"`
+	// ExportLinkSecret signs export download links. When unset it is
+	// derived from MY_SECRET_KEY.
+	ExportLinkSecret string `yaml:"-" env:"EXPORT_LINK_SECRET"`
+}
+
+// Chat configures chat moderation.
+type Chat struct {
+	// RestrictionSweepInterval is how often expired member restrictions are
+	// deleted. They stop applying at expiry either way.
+	RestrictionSweepInterval time.Duration `yaml:"restriction_sweep_interval" env:"CHAT_RESTRICTION_SWEEP_INTERVAL" env-default:"5m"`
+}
+
+type JWT struct {
+	Algorithm        string        `yaml:"algorithm" env:"JWT_ALGORITHM" env-default:"HS256"`
+	KeysDir          string        `yaml:"keys_dir" env:"JWT_KEYS_DIR"`
+	RotationInterval time.Duration `yaml:"rotation_interval" env:"JWT_ROTATION_INTERVAL" env-default:"720h"`
+	KeyRetention     time.Duration `yaml:"key_retention" env:"JWT_KEY_RETENTION" env-default:"1h"`
+	// AcceptLegacyTokens keeps HS256 tokens signed with the shared secret
+	// valid for one access token TTL after switching to asymmetric keys.
+	AcceptLegacyTokens bool `yaml:"accept_legacy_tokens" env:"JWT_ACCEPT_LEGACY_TOKENS"`
+}
+
+type Mail struct {
+	// Driver is "smtp" or "file". The smtp driver, the default, needs Host
+	// and the app does not start without it. The file driver logs messages,
+	// codes included, and writes them to Dir; it is meant for local runs only.
+	Driver   string `yaml:"driver" env:"MAIL_DRIVER" env-default:"smtp"`
+	Host     string `yaml:"host" env:"MAIL_HOST"`
+	Port     int    `yaml:"port" env:"MAIL_PORT" env-default:"587"`
+	Username string `yaml:"username" env:"MAIL_USERNAME"`
+	Password string `yaml:"password" env:"MAIL_PASSWORD"`
+	From     string `yaml:"from" env:"MAIL_FROM" env-default:"no-reply@localhost"`
+	Dir      string `yaml:"dir" env:"MAIL_DIR"`
+}
+
+// OIDCProvider is an OpenID provider users can sign in with. Name is the
+// value clients pass to select it.
+type OIDCProvider struct {
+	Name         string   `yaml:"name"`
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	Scopes       []string `yaml:"scopes"`
 }
 
 type Config struct {
-	Env      string     `yaml:"env" env:"ENV" env-default:"development"`
-	Server   Server     `yaml:"server"`
-	Postgres Postgres   `yaml:"postgres"`
-	MongoDB  MongoDB    `yaml:"mongodb"`
-	Redis    Redis      `yaml:"redis"`
-	Kafka    Kafka      `yaml:"kafka"`
-	Metrics  Metrics    `yaml:"metrics"`
-	Auth     Auth       `yaml:"auth"`
-	Grpc     GrpcServer `yaml:"grpc"`
+	Env      string         `yaml:"env" env:"ENV" env-default:"development"`
+	Server   Server         `yaml:"server"`
+	Postgres Postgres       `yaml:"postgres"`
+	MongoDB  MongoDB        `yaml:"mongodb"`
+	Redis    Redis          `yaml:"redis"`
+	Kafka    Kafka          `yaml:"kafka"`
+	Metrics  Metrics        `yaml:"metrics"`
+	Auth     Auth           `yaml:"auth"`
+	Account  Account        `yaml:"account"`
+	Chat     Chat           `yaml:"chat"`
+	JWT      JWT            `yaml:"jwt"`
+	Mail     Mail           `yaml:"mail"`
+	OIDC     []OIDCProvider `yaml:"oidc"`
+	Grpc     GrpcServer     `yaml:"grpc"`
 }
 
 type EnvConfig struct {
@@ -91,6 +191,16 @@ func (e *EnvConfig) MySecretKey() string {
 	return e.SecretKey
 }
 
+// DeriveSecret derives the key for one purpose from MY_SECRET_KEY, so that no
+// other purpose shares a key with the JWT signer.
+func DeriveSecret(secretKey, purpose string) string {
+	key, err := hkdf.Key(sha256.New, []byte(secretKey), nil, purpose, sha256.Size)
+	if err != nil {
+		panic("cannot derive secret: " + err.Error())
+	}
+	return hex.EncodeToString(key)
+}
+
 func MySecretKey() string {
 	envConfig := &EnvConfig{
 		SecretKey: "",
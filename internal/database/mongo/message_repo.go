@@ -89,6 +89,7 @@ func (r *MessageRepository) EditMessage(ctx context.Context, senderID int64, cha
 		"_id":       objID,
 		"sender_id": senderID,
 		"chat_id":   chatID,
+		"type":      bson.M{"$ne": dom.MessageTypeSystem},
 	}
 
 	update := bson.M{
@@ -121,6 +122,7 @@ func (r *MessageRepository) DeleteMessage(ctx context.Context, senderID, chatID
 		"_id":       bson.M{"$in": oids},
 		"sender_id": senderID,
 		"chat_id":   chatID,
+		"type":      bson.M{"$ne": dom.MessageTypeSystem},
 	}
 
 	res, err := r.coll.DeleteMany(ctx, filter)
@@ -190,3 +192,135 @@ func (r *MessageRepository) GetLatestMessage(ctx context.Context, chatID int64)
 
 	return msg, nil
 }
+
+// GetMessage returns the message msgID of the chat, or
+// ErrMessageDoesNotExists.
+func (r *MessageRepository) GetMessage(ctx context.Context, chatID int64, msgID string) (dom.Message, error) {
+	objID, err := primitive.ObjectIDFromHex(msgID)
+	if err != nil {
+		return dom.Message{}, customerrors.ErrMessageDoesNotExists
+	}
+
+	var msg dom.Message
+	err = r.coll.FindOne(ctx, bson.M{"_id": objID, "chat_id": chatID}).Decode(&msg)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return dom.Message{}, customerrors.ErrMessageDoesNotExists
+		}
+		return dom.Message{}, fmt.Errorf("failed to get message: %w", err)
+	}
+	return msg, nil
+}
+
+// CountUnread returns, per chat, how many messages from other senders came
+// after each read marker, or after the user joined if they have not read
+// anything yet. System messages about the user's own changes do not count.
+// Chats with nothing unread are left out.
+func (r *MessageRepository) CountUnread(ctx context.Context, userID int64, states []dom.ReadState) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(states))
+	if len(states) == 0 {
+		return counts, nil
+	}
+
+	unread := make([]bson.M, 0, len(states))
+	for _, state := range states {
+		cond := bson.M{"chat_id": state.ChatID}
+		if objID, err := primitive.ObjectIDFromHex(state.LastReadMessageID); err == nil {
+			cond["_id"] = bson.M{"$gt": objID}
+		} else {
+			cond["created_at"] = bson.M{"$gt": state.JoinedAt}
+		}
+		unread = append(unread, cond)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"sender_id": bson.M{"$ne": userID}, "actor_id": bson.M{"$ne": userID}, "$or": unread}}},
+		{{Key: "$group", Value: bson.M{"_id": "$chat_id", "count": bson.M{"$sum": 1}}}},
+	}
+	cursor, err := r.coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var row struct {
+			ChatID int64 `bson:"_id"`
+			Count  int64 `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, fmt.Errorf("decode error: %w", err)
+		}
+		counts[row.ChatID] = row.Count
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("mongo cursor error: %w", err)
+	}
+	return counts, nil
+}
+
+// AnonymizeSenderMessages detaches the messages of the given senders from
+// them: the sender ID is cleared and the name replaced with username.
+func (r *MessageRepository) AnonymizeSenderMessages(ctx context.Context, senderIDs []int64, username string) (int64, error) {
+	filter := bson.M{"sender_id": bson.M{"$in": senderIDs}}
+	update := bson.M{
+		"$set": bson.M{
+			"sender_id":       int64(0),
+			"sender_username": username,
+		},
+	}
+
+	res, err := r.coll.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to anonymize messages: %w", err)
+	}
+	return res.ModifiedCount, nil
+}
+
+// DeleteSenderMessages deletes the messages of the given senders and returns
+// the chats they were in.
+func (r *MessageRepository) DeleteSenderMessages(ctx context.Context, senderIDs []int64) ([]int64, error) {
+	filter := bson.M{"sender_id": bson.M{"$in": senderIDs}}
+
+	values, err := r.coll.Distinct(ctx, "chat_id", filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chats of messages: %w", err)
+	}
+	chatIDs := make([]int64, 0, len(values))
+	for _, v := range values {
+		if id, ok := v.(int64); ok {
+			chatIDs = append(chatIDs, id)
+		}
+	}
+
+	if _, err := r.coll.DeleteMany(ctx, filter); err != nil {
+		return nil, fmt.Errorf("failed to delete messages: %w", err)
+	}
+	return chatIDs, nil
+}
+
+// StreamSenderMessages calls fn with every message of the sender, oldest
+// first, stopping at the first error.
+func (r *MessageRepository) StreamSenderMessages(ctx context.Context, senderID int64, fn func(dom.Message) error) error {
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.coll.Find(ctx, bson.M{"sender_id": senderID}, findOptions)
+	if err != nil {
+		return fmt.Errorf("mongo find error: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var msg dom.Message
+		if err := cursor.Decode(&msg); err != nil {
+			return fmt.Errorf("decode error: %w", err)
+		}
+		if err := fn(msg); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("mongo cursor error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package audit_repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	dom "main/internal/domain/entity"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAuditRepository(pool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{
+		pool: pool,
+	}
+}
+
+func (r *AuditRepository) InsertEvent(ctx context.Context, event dom.AuditEvent) error {
+	metadata := []byte("{}")
+	if len(event.Metadata) > 0 {
+		var err error
+		metadata, err = json.Marshal(event.Metadata)
+		if err != nil {
+			return fmt.Errorf("repo: insert audit event: marshal metadata: %w", err)
+		}
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO audit_events (occurred_at, actor_id, action, target_type, target_id, ip, user_agent, request_id, metadata)
+		VALUES (COALESCE($1::timestamptz, NOW()), NULLIF($2::bigint, 0), $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9)`,
+		nullTime(event), event.ActorID, event.Action, event.TargetType, event.TargetID,
+		event.IP, event.UserAgent, event.RequestID, metadata)
+	if err != nil {
+		return fmt.Errorf("repo: insert audit event: %w", err)
+	}
+	return nil
+}
+
+// ListEvents returns up to filter.Limit events matching filter, newest first.
+func (r *AuditRepository) ListEvents(ctx context.Context, filter dom.AuditFilter) ([]dom.AuditEvent, error) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+	if filter.ActorID != 0 {
+		add("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		add("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		add("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		add("target_id = ?", filter.TargetID)
+	}
+	if !filter.From.IsZero() {
+		add("occurred_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("occurred_at < ?", filter.To)
+	}
+	if filter.BeforeID > 0 {
+		add("id < ?", filter.BeforeID)
+	}
+
+	query := `
+		SELECT id, occurred_at, COALESCE(actor_id, 0), action, COALESCE(target_type, ''), COALESCE(target_id, ''),
+		       COALESCE(ip, ''), COALESCE(user_agent, ''), COALESCE(request_id, ''), metadata
+		FROM audit_events`
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += " ORDER BY id DESC LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repo: list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []dom.AuditEvent{}
+	for rows.Next() {
+		var (
+			e        dom.AuditEvent
+			metadata []byte
+		)
+		if err := rows.Scan(&e.ID, &e.OccurredAt, &e.ActorID, &e.Action, &e.TargetType, &e.TargetID,
+			&e.IP, &e.UserAgent, &e.RequestID, &metadata); err != nil {
+			return nil, fmt.Errorf("repo: list audit events: scan: %w", err)
+		}
+		if err := json.Unmarshal(metadata, &e.Metadata); err != nil {
+			return nil, fmt.Errorf("repo: list audit events: metadata: %w", err)
+		}
+		if len(e.Metadata) == 0 {
+			e.Metadata = nil
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: list audit events: %w", err)
+	}
+	return events, nil
+}
+
+func nullTime(event dom.AuditEvent) interface{} {
+	if event.OccurredAt.IsZero() {
+		return nil
+	}
+	return event.OccurredAt
+}
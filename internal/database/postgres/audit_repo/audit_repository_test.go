@@ -0,0 +1,63 @@
+package audit_repo_test
+
+import (
+	"context"
+	"main/internal/database/postgres/audit_repo"
+	repositoryT "main/internal/database/postgres/repositoryTest"
+	dom "main/internal/domain/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditEvents(t *testing.T) {
+	pool, teardown := repositoryT.SetupTestDB(t)
+	defer teardown()
+
+	repo := audit_repo.NewAuditRepository(pool)
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, repo.InsertEvent(ctx, dom.AuditEvent{
+		OccurredAt: past,
+		ActorID:    1,
+		Action:     dom.AuditLogin,
+		IP:         "203.0.113.7",
+		Metadata:   map[string]interface{}{"method": "password"},
+	}))
+	require.NoError(t, repo.InsertEvent(ctx, dom.AuditEvent{Action: dom.AuditLoginFailed}))
+	require.NoError(t, repo.InsertEvent(ctx, dom.AuditEvent{
+		ActorID:    1,
+		Action:     dom.AuditChatDeleted,
+		TargetType: "chat",
+		TargetID:   "42",
+	}))
+
+	all, err := repo.ListEvents(ctx, dom.AuditFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, dom.AuditChatDeleted, all[0].Action, "newest first")
+	assert.Equal(t, int64(0), all[1].ActorID)
+	assert.Nil(t, all[1].Metadata)
+	assert.Equal(t, "password", all[2].Metadata["method"])
+	assert.True(t, all[2].OccurredAt.Equal(past))
+
+	byActor, err := repo.ListEvents(ctx, dom.AuditFilter{ActorID: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, byActor, 2)
+
+	byTarget, err := repo.ListEvents(ctx, dom.AuditFilter{TargetType: "chat", TargetID: "42", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, byTarget, 1)
+
+	recent, err := repo.ListEvents(ctx, dom.AuditFilter{From: past.Add(time.Minute), Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, recent, 2)
+
+	page, err := repo.ListEvents(ctx, dom.AuditFilter{BeforeID: all[0].ID, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, all[1].ID, page[0].ID)
+}
@@ -10,6 +10,7 @@ import (
 	dom "main/internal/domain/entity"
 	"main/pkg/customerrors"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -29,9 +30,9 @@ func NewAuthRepository(pool *pgxpool.Pool, logger *slog.Logger) *AuthRepository
 func (r *AuthRepository) GetCredentialsByUsername(ctx context.Context, username string) (dom.User, error) {
 	var user dom.User
 
-	query := "SELECT id, password_hash FROM users WHERE username=$1"
+	query := "SELECT id, password_hash, totp_enabled FROM users WHERE username=$1"
 
-	err := r.pool.QueryRow(ctx, query, username).Scan(&user.ID, &user.Password)
+	err := r.pool.QueryRow(ctx, query, username).Scan(&user.ID, &user.Password, &user.TOTPEnabled)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return dom.User{}, customerrors.ErrUserNotFound
@@ -43,11 +44,25 @@ func (r *AuthRepository) GetCredentialsByUsername(ctx context.Context, username
 	return user, nil
 }
 
+func (r *AuthRepository) GetUserRole(ctx context.Context, userID int64) (string, error) {
+	var role string
+	err := r.pool.QueryRow(ctx, "SELECT role FROM users WHERE id=$1", userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", customerrors.ErrUserNotFound
+		}
+		return "", fmt.Errorf("repo: get user role: %w", err)
+	}
+	return role, nil
+}
+
+// SaveRefreshToken stores a new refresh token. An empty FamilyID starts a new
+// token family, otherwise the token joins the given family.
 func (r *AuthRepository) SaveRefreshToken(ctx context.Context, rt dom.RefreshToken) error {
 	_, err := r.pool.Exec(ctx, `
-		INSERT INTO refresh_tokens (user_id, refresh_token, created_at, expires_at)
-		VALUES ($1, $2, $3, $4)`,
-		rt.UserID, rt.Token, rt.CreatedAt, rt.ExpiresAt)
+		INSERT INTO refresh_tokens (user_id, refresh_token, family_id, created_at, expires_at)
+		VALUES ($1, $2, COALESCE(NULLIF($3, '')::uuid, gen_random_uuid()), $4, $5)`,
+		rt.UserID, rt.Token, rt.FamilyID, rt.CreatedAt, rt.ExpiresAt)
 
 	if err != nil {
 		return fmt.Errorf("repo: save refresh token: %w", customerrors.ErrDatabase)
@@ -55,6 +70,210 @@ func (r *AuthRepository) SaveRefreshToken(ctx context.Context, rt dom.RefreshTok
 	return nil
 }
 
+func (r *AuthRepository) GetRefreshTokenByValue(ctx context.Context, token string) (dom.RefreshToken, error) {
+	var rt dom.RefreshToken
+
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, user_id, refresh_token, family_id::text, created_at, expires_at, used_at, revoked_at
+		FROM refresh_tokens WHERE refresh_token=$1`, token).
+		Scan(&rt.ID, &rt.UserID, &rt.Token, &rt.FamilyID, &rt.CreatedAt, &rt.ExpiresAt, &rt.UsedAt, &rt.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.RefreshToken{}, customerrors.ErrRefreshTokenNotFound
+		}
+		return dom.RefreshToken{}, fmt.Errorf("repo: get refresh token by value: %w", err)
+	}
+	return rt, nil
+}
+
+// RotateRefreshToken marks the token with usedID as used and stores next in a
+// single transaction. If the token was already used or revoked by a concurrent
+// request, nothing is stored and ErrRefreshTokenReused is returned.
+func (r *AuthRepository) RotateRefreshToken(ctx context.Context, usedID int64, next dom.RefreshToken) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repo: rotate refresh token: begin: %w", customerrors.ErrDatabase)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
+		"UPDATE refresh_tokens SET used_at=NOW() WHERE id=$1 AND used_at IS NULL AND revoked_at IS NULL", usedID)
+	if err != nil {
+		return fmt.Errorf("repo: rotate refresh token: mark used: %w", customerrors.ErrDatabase)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrRefreshTokenReused
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO refresh_tokens (user_id, refresh_token, family_id, created_at, expires_at)
+		VALUES ($1, $2, $3::uuid, $4, $5)`,
+		next.UserID, next.Token, next.FamilyID, next.CreatedAt, next.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("repo: rotate refresh token: insert: %w", customerrors.ErrDatabase)
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE sessions SET last_used_at=NOW() WHERE id=$1::uuid", next.FamilyID)
+	if err != nil {
+		return fmt.Errorf("repo: rotate refresh token: touch session: %w", customerrors.ErrDatabase)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repo: rotate refresh token: commit: %w", customerrors.ErrDatabase)
+	}
+	return nil
+}
+
+// RevokeTokenFamily revokes every refresh token of the family together with
+// the session the family belongs to.
+func (r *AuthRepository) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repo: revoke token family: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := revokeSessionTokensTX(ctx, tx, familyID); err != nil {
+		return fmt.Errorf("repo: revoke token family: %w", err)
+	}
+
+	_, err = tx.Exec(ctx,
+		"UPDATE sessions SET revoked_at=NOW() WHERE id=$1::uuid AND revoked_at IS NULL", familyID)
+	if err != nil {
+		return fmt.Errorf("repo: revoke token family: session: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repo: revoke token family: commit: %w", err)
+	}
+	return nil
+}
+
+func revokeSessionTokensTX(ctx context.Context, tx pgx.Tx, sessionID string) error {
+	_, err := tx.Exec(ctx,
+		"UPDATE refresh_tokens SET revoked_at=NOW() WHERE family_id=$1::uuid AND revoked_at IS NULL", sessionID)
+	return err
+}
+
+// CreateSession stores a new session and the first refresh token of its
+// family in a single transaction.
+func (r *AuthRepository) CreateSession(ctx context.Context, session dom.Session, rt dom.RefreshToken) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repo: create session: begin: %w", customerrors.ErrDatabase)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO sessions (id, user_id, device_name, user_agent, ip, created_at, last_used_at)
+		VALUES ($1::uuid, $2, $3, $4, $5, $6, $6)`,
+		session.ID, session.UserID, session.DeviceName, session.UserAgent, session.IP, session.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("repo: create session: %w", customerrors.ErrDatabase)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO refresh_tokens (user_id, refresh_token, family_id, created_at, expires_at)
+		VALUES ($1, $2, $3::uuid, $4, $5)`,
+		rt.UserID, rt.Token, session.ID, rt.CreatedAt, rt.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("repo: create session: save refresh token: %w", customerrors.ErrDatabase)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repo: create session: commit: %w", customerrors.ErrDatabase)
+	}
+	return nil
+}
+
+func (r *AuthRepository) ListSessions(ctx context.Context, userID int64) ([]dom.Session, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id::text, user_id, device_name, user_agent, ip, created_at, last_used_at
+		FROM sessions
+		WHERE user_id=$1 AND revoked_at IS NULL
+		ORDER BY last_used_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repo: list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []dom.Session{}
+	for rows.Next() {
+		var s dom.Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.DeviceName, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastUsedAt); err != nil {
+			return nil, fmt.Errorf("repo: list sessions: scan: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: list sessions: rows: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes a session owned by userID and all of its refresh
+// tokens. It returns ErrNotFound when the user has no such active session.
+func (r *AuthRepository) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	if err := uuid.Validate(sessionID); err != nil {
+		return customerrors.ErrNotFound
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repo: revoke session: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
+		"UPDATE sessions SET revoked_at=NOW() WHERE id=$1::uuid AND user_id=$2 AND revoked_at IS NULL", sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("repo: revoke session: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrNotFound
+	}
+
+	if err := revokeSessionTokensTX(ctx, tx, sessionID); err != nil {
+		return fmt.Errorf("repo: revoke session: tokens: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repo: revoke session: commit: %w", err)
+	}
+	return nil
+}
+
+// RevokeAllSessions revokes every active session of the user and returns the
+// IDs of the sessions it revoked.
+func (r *AuthRepository) RevokeAllSessions(ctx context.Context, userID int64) ([]string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repo: revoke all sessions: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx,
+		"UPDATE sessions SET revoked_at=NOW() WHERE user_id=$1 AND revoked_at IS NULL RETURNING id::text", userID)
+	if err != nil {
+		return nil, fmt.Errorf("repo: revoke all sessions: %w", err)
+	}
+	sessionIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("repo: revoke all sessions: scan: %w", err)
+	}
+
+	_, err = tx.Exec(ctx,
+		"UPDATE refresh_tokens SET revoked_at=NOW() WHERE user_id=$1 AND revoked_at IS NULL", userID)
+	if err != nil {
+		return nil, fmt.Errorf("repo: revoke all sessions: tokens: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("repo: revoke all sessions: commit: %w", err)
+	}
+	return sessionIDs, nil
+}
+
 func (r *AuthRepository) GetRefreshToken(ctx context.Context, userID int64) (string, error) {
 	var storedToken string
 	var expiry time.Time
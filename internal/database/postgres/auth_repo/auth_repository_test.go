@@ -6,6 +6,7 @@ import (
 	dbtest "main/internal/database/postgres/repositoryTest"
 	dom "main/internal/domain/entity"
 	"main/pkg/customerrors"
+	"strings"
 	"testing"
 	"time"
 
@@ -231,3 +232,190 @@ func TestDeleteRefreshToken(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 0, count)
 }
+
+func TestRotateRefreshToken(t *testing.T) {
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+
+	repo := auth.NewAuthRepository(pool, nil)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, "INSERT INTO users (id, username, email, password_hash) VALUES (1, 'u1', 'e1', 'p1')")
+	assert.NoError(t, err)
+
+	err = repo.SaveRefreshToken(ctx, dom.RefreshToken{
+		UserID:    1,
+		Token:     "first_token",
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+	})
+	assert.NoError(t, err)
+
+	first, err := repo.GetRefreshTokenByValue(ctx, "first_token")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, first.FamilyID)
+	assert.Nil(t, first.UsedAt)
+
+	next := dom.RefreshToken{
+		UserID:    1,
+		Token:     "second_token",
+		FamilyID:  first.FamilyID,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+	}
+	err = repo.RotateRefreshToken(ctx, first.ID, next)
+	assert.NoError(t, err)
+
+	first, err = repo.GetRefreshTokenByValue(ctx, "first_token")
+	assert.NoError(t, err)
+	assert.NotNil(t, first.UsedAt)
+
+	second, err := repo.GetRefreshTokenByValue(ctx, "second_token")
+	assert.NoError(t, err)
+	assert.Equal(t, first.FamilyID, second.FamilyID)
+
+	next.Token = "third_token"
+	err = repo.RotateRefreshToken(ctx, first.ID, next)
+	assert.ErrorIs(t, err, customerrors.ErrRefreshTokenReused)
+
+	err = repo.RevokeTokenFamily(ctx, first.FamilyID)
+	assert.NoError(t, err)
+
+	second, err = repo.GetRefreshTokenByValue(ctx, "second_token")
+	assert.NoError(t, err)
+	assert.NotNil(t, second.RevokedAt)
+}
+
+func TestTOTP(t *testing.T) {
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+
+	repo := auth.NewAuthRepository(pool, nil)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, "INSERT INTO users (id, username, email, password_hash) VALUES (1, 'u1', 'e1', 'p1')")
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.SetTOTPSecret(ctx, 1, "SECRET"))
+	assert.NoError(t, repo.EnableTOTP(ctx, 1, []string{"hash1", "hash2"}))
+	assert.ErrorIs(t, repo.SetTOTPSecret(ctx, 1, "OTHER"), customerrors.ErrTOTPAlreadyEnabled)
+
+	state, err := repo.GetTOTP(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, dom.TOTP{UserID: 1, Username: "u1", Secret: "SECRET", Enabled: true}, state)
+
+	user, err := repo.GetCredentialsByUsername(ctx, "u1")
+	assert.NoError(t, err)
+	assert.True(t, user.TOTPEnabled)
+
+	assert.NoError(t, repo.UseTOTPStep(ctx, 1, 100))
+	assert.ErrorIs(t, repo.UseTOTPStep(ctx, 1, 100), customerrors.ErrInvalidTOTPCode)
+
+	assert.NoError(t, repo.UseRecoveryCode(ctx, 1, "hash1"))
+	assert.ErrorIs(t, repo.UseRecoveryCode(ctx, 1, "hash1"), customerrors.ErrInvalidTOTPCode)
+
+	assert.NoError(t, repo.DisableTOTP(ctx, 1))
+	assert.ErrorIs(t, repo.UseRecoveryCode(ctx, 1, "hash2"), customerrors.ErrInvalidTOTPCode)
+}
+
+func TestConsumeEmailCode(t *testing.T) {
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+
+	repo := auth.NewAuthRepository(pool, nil)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, "INSERT INTO users (id, username, email, password_hash) VALUES (1, 'u1', 'e1', 'p1')")
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.SaveEmailCode(ctx, 1, "right", time.Now().Add(time.Hour)))
+
+	err = repo.ConsumeEmailCode(ctx, 1, "wrong", 2)
+	assert.ErrorIs(t, err, customerrors.ErrInvalidEmailCode)
+
+	assert.NoError(t, repo.ConsumeEmailCode(ctx, 1, "right", 2))
+
+	var verified bool
+	err = pool.QueryRow(ctx, "SELECT email_verified_at IS NOT NULL FROM users WHERE id=1").Scan(&verified)
+	assert.NoError(t, err)
+	assert.True(t, verified)
+
+	err = repo.ConsumeEmailCode(ctx, 1, "right", 2)
+	assert.ErrorIs(t, err, customerrors.ErrInvalidEmailCode, "code works only once")
+
+	assert.NoError(t, repo.SaveEmailCode(ctx, 1, "next", time.Now().Add(time.Hour)))
+	_ = repo.ConsumeEmailCode(ctx, 1, "wrong", 2)
+	_ = repo.ConsumeEmailCode(ctx, 1, "wrong", 2)
+	err = repo.ConsumeEmailCode(ctx, 1, "next", 2)
+	assert.ErrorIs(t, err, customerrors.ErrInvalidEmailCode, "too many attempts")
+}
+
+func TestResetPassword(t *testing.T) {
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+
+	repo := auth.NewAuthRepository(pool, nil)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, "INSERT INTO users (id, username, email, password_hash) VALUES (1, 'u1', 'e1', 'p1')")
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.SavePasswordResetToken(ctx, 1, "expired", time.Now().Add(-time.Minute)))
+	assert.NoError(t, repo.SavePasswordResetToken(ctx, 1, "first", time.Now().Add(time.Hour)))
+	assert.NoError(t, repo.SavePasswordResetToken(ctx, 1, "second", time.Now().Add(time.Hour)))
+
+	_, err = repo.ResetPassword(ctx, "expired", "p2")
+	assert.ErrorIs(t, err, customerrors.ErrInvalidResetToken)
+	_, err = repo.GetPasswordResetUserID(ctx, "expired")
+	assert.ErrorIs(t, err, customerrors.ErrInvalidResetToken)
+
+	userID, err := repo.GetPasswordResetUserID(ctx, "first")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), userID)
+
+	userID, err = repo.ResetPassword(ctx, "first", "p2")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), userID)
+
+	hash, err := repo.GetPasswordHash(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "p2", hash)
+
+	_, err = repo.ResetPassword(ctx, "second", "p3")
+	assert.ErrorIs(t, err, customerrors.ErrInvalidResetToken, "other tokens are invalidated")
+}
+
+func TestUserIdentities(t *testing.T) {
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+
+	repo := auth.NewAuthRepository(pool, nil)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, "INSERT INTO users (id, username, email, password_hash) VALUES (1, 'alice', 'a@example.com', 'p1')")
+	assert.NoError(t, err)
+
+	_, err = repo.GetUserByIdentity(ctx, "idp", "sub-1")
+	assert.ErrorIs(t, err, customerrors.ErrUserNotFound)
+
+	assert.NoError(t, repo.LinkIdentity(ctx, 1, dom.ExternalIdentity{Provider: "idp", Subject: "sub-1", Email: "a@example.com"}))
+	user, err := repo.GetUserByIdentity(ctx, "idp", "sub-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), user.ID)
+	assert.Equal(t, "alice", user.Username)
+
+	_, err = repo.CreateUserWithIdentity(ctx, "bob", dom.ExternalIdentity{Provider: "idp", Subject: "sub-2", Email: "a@example.com"})
+	assert.ErrorIs(t, err, customerrors.ErrEmailAlreadyExists)
+
+	created, err := repo.CreateUserWithIdentity(ctx, "alice", dom.ExternalIdentity{
+		Provider: "idp", Subject: "sub-3", Email: "b@example.com", EmailVerified: true,
+	})
+	assert.NoError(t, err)
+	assert.NotEqual(t, "alice", created.Username, "a taken username gets a suffix")
+	assert.True(t, strings.HasPrefix(created.Username, "alice"))
+
+	user, err = repo.GetUserByIdentity(ctx, "idp", "sub-3")
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, user.ID)
+	assert.True(t, user.EmailVerified)
+}
@@ -0,0 +1,63 @@
+package auth_repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"main/pkg/customerrors"
+)
+
+// SaveEmailCode stores the verification code of the user, replacing any code
+// sent before.
+func (r *AuthRepository) SaveEmailCode(ctx context.Context, userID int64, codeHash string, expiresAt time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO email_verification_codes (user_id, code_hash, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET code_hash=EXCLUDED.code_hash, attempts=0, created_at=NOW(), expires_at=EXCLUDED.expires_at`,
+		userID, codeHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("repo: save email code: %w", err)
+	}
+	return nil
+}
+
+// ConsumeEmailCode marks the email of the user as verified if codeHash
+// matches a live code. The code is deleted on success; a wrong guess counts
+// against maxAttempts, after which the code no longer matches at all.
+func (r *AuthRepository) ConsumeEmailCode(ctx context.Context, userID int64, codeHash string, maxAttempts int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repo: consume email code: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		DELETE FROM email_verification_codes
+		WHERE user_id=$1 AND code_hash=$2 AND expires_at>NOW() AND attempts<$3`,
+		userID, codeHash, maxAttempts)
+	if err != nil {
+		return fmt.Errorf("repo: consume email code: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		// Outside of tx, so the attempt is counted despite the rollback.
+		_, err := r.pool.Exec(ctx,
+			"UPDATE email_verification_codes SET attempts=attempts+1 WHERE user_id=$1", userID)
+		if err != nil {
+			return fmt.Errorf("repo: consume email code: count attempt: %w", err)
+		}
+		return customerrors.ErrInvalidEmailCode
+	}
+
+	_, err = tx.Exec(ctx,
+		"UPDATE users SET email_verified_at=NOW() WHERE id=$1 AND email_verified_at IS NULL", userID)
+	if err != nil {
+		return fmt.Errorf("repo: consume email code: verify: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repo: consume email code: commit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package auth_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// maxUsernameAttempts bounds how many suffixed usernames are tried when the
+// one suggested by the identity provider is taken.
+const maxUsernameAttempts = 5
+
+// GetUserByIdentity returns the user linked to the subject at provider and
+// records the login.
+func (r *AuthRepository) GetUserByIdentity(ctx context.Context, provider, subject string) (dom.User, error) {
+	var user dom.User
+	err := r.pool.QueryRow(ctx, `
+		UPDATE user_identities i SET last_login_at=NOW()
+		FROM users u
+		WHERE i.provider=$1 AND i.subject=$2 AND u.id=i.user_id
+		RETURNING u.id, u.username, u.email, u.email_verified_at IS NOT NULL`,
+		provider, subject).Scan(&user.ID, &user.Username, &user.Email, &user.EmailVerified)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.User{}, customerrors.ErrUserNotFound
+		}
+		return dom.User{}, fmt.Errorf("repo: get user by identity: %w", err)
+	}
+	return user, nil
+}
+
+func (r *AuthRepository) LinkIdentity(ctx context.Context, userID int64, identity dom.ExternalIdentity) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO user_identities (provider, subject, user_id, email)
+		VALUES ($1, $2, $3, NULLIF($4, ''))`,
+		identity.Provider, identity.Subject, userID, identity.Email)
+	if err != nil {
+		return fmt.Errorf("repo: link identity: %w", err)
+	}
+	return nil
+}
+
+// CreateUserWithIdentity creates a user without a password and links the
+// identity to it. If username is taken a random numeric suffix is appended,
+// keeping the result within the 20 characters of the column. The email counts as
+// verified when the provider says so.
+func (r *AuthRepository) CreateUserWithIdentity(ctx context.Context, username string, identity dom.ExternalIdentity) (dom.User, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return dom.User{}, fmt.Errorf("repo: create user with identity: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE email=$1)", identity.Email).Scan(&exists)
+	if err != nil {
+		return dom.User{}, fmt.Errorf("repo: create user with identity: check email: %w", err)
+	}
+	if exists {
+		return dom.User{}, customerrors.ErrEmailAlreadyExists
+	}
+
+	user := dom.User{Email: identity.Email, EmailVerified: identity.EmailVerified}
+	for attempt := 0; user.ID == 0; attempt++ {
+		if attempt == maxUsernameAttempts {
+			return dom.User{}, customerrors.ErrUsernameAlreadyExists
+		}
+		candidate := username
+		if attempt > 0 {
+			suffix := strconv.Itoa(1000 + rand.IntN(9000))
+			candidate = username[:min(len(username), 20-len(suffix))] + suffix
+		}
+		err = tx.QueryRow(ctx, `
+			INSERT INTO users (username, email, password_hash, email_verified_at)
+			VALUES ($1, $2, '', CASE WHEN $3 THEN NOW() END)
+			ON CONFLICT (username) DO NOTHING
+			RETURNING id`,
+			candidate, identity.Email, identity.EmailVerified).Scan(&user.ID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return dom.User{}, fmt.Errorf("repo: create user with identity: insert user: %w", err)
+		}
+		user.Username = candidate
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO user_identities (provider, subject, user_id, email)
+		VALUES ($1, $2, $3, NULLIF($4, ''))`,
+		identity.Provider, identity.Subject, user.ID, identity.Email)
+	if err != nil {
+		return dom.User{}, fmt.Errorf("repo: create user with identity: link: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return dom.User{}, fmt.Errorf("repo: create user with identity: commit: %w", err)
+	}
+	return user, nil
+}
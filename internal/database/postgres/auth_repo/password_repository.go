@@ -0,0 +1,100 @@
+package auth_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"main/pkg/customerrors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *AuthRepository) GetPasswordHash(ctx context.Context, userID int64) (string, error) {
+	var hash string
+	err := r.pool.QueryRow(ctx, "SELECT password_hash FROM users WHERE id=$1", userID).Scan(&hash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", customerrors.ErrUserNotFound
+		}
+		return "", fmt.Errorf("repo: get password hash: %w", err)
+	}
+	return hash, nil
+}
+
+func (r *AuthRepository) UpdatePassword(ctx context.Context, userID int64, passwordHash string) error {
+	tag, err := r.pool.Exec(ctx, "UPDATE users SET password_hash=$2 WHERE id=$1", userID, passwordHash)
+	if err != nil {
+		return fmt.Errorf("repo: update password: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *AuthRepository) SavePasswordResetToken(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		"INSERT INTO password_reset_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3)",
+		userID, tokenHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("repo: save password reset token: %w", err)
+	}
+	return nil
+}
+
+// GetPasswordResetUserID returns the owner of a live reset token without
+// consuming it, or ErrInvalidResetToken.
+func (r *AuthRepository) GetPasswordResetUserID(ctx context.Context, tokenHash string) (int64, error) {
+	var userID int64
+	err := r.pool.QueryRow(ctx, `
+		SELECT user_id FROM password_reset_tokens
+		WHERE token_hash=$1 AND used_at IS NULL AND expires_at>NOW()`, tokenHash).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, customerrors.ErrInvalidResetToken
+		}
+		return 0, fmt.Errorf("repo: get password reset token: %w", err)
+	}
+	return userID, nil
+}
+
+// ResetPassword consumes a live reset token and sets the new password of its
+// owner. Every other pending token of the owner is invalidated as well. It
+// returns the ID of the user whose password was changed.
+func (r *AuthRepository) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("repo: reset password: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID int64
+	err = tx.QueryRow(ctx, `
+		UPDATE password_reset_tokens SET used_at=NOW()
+		WHERE token_hash=$1 AND used_at IS NULL AND expires_at>NOW()
+		RETURNING user_id`, tokenHash).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, customerrors.ErrInvalidResetToken
+		}
+		return 0, fmt.Errorf("repo: reset password: consume token: %w", err)
+	}
+
+	_, err = tx.Exec(ctx,
+		"UPDATE password_reset_tokens SET used_at=NOW() WHERE user_id=$1 AND used_at IS NULL", userID)
+	if err != nil {
+		return 0, fmt.Errorf("repo: reset password: invalidate tokens: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE users SET password_hash=$2 WHERE id=$1", userID, passwordHash)
+	if err != nil {
+		return 0, fmt.Errorf("repo: reset password: update: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("repo: reset password: commit: %w", err)
+	}
+	return userID, nil
+}
@@ -0,0 +1,135 @@
+package auth_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *AuthRepository) GetTOTP(ctx context.Context, userID int64) (dom.TOTP, error) {
+	t := dom.TOTP{UserID: userID}
+
+	err := r.pool.QueryRow(ctx,
+		"SELECT username, totp_secret, totp_enabled FROM users WHERE id=$1", userID).
+		Scan(&t.Username, &t.Secret, &t.Enabled)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.TOTP{}, customerrors.ErrUserNotFound
+		}
+		return dom.TOTP{}, fmt.Errorf("repo: get totp: %w", err)
+	}
+	return t, nil
+}
+
+// SetTOTPSecret stores a pending secret. Re-enrolling replaces a pending
+// secret, but an enabled one can only be replaced after disabling it.
+func (r *AuthRepository) SetTOTPSecret(ctx context.Context, userID int64, secret string) error {
+	tag, err := r.pool.Exec(ctx,
+		"UPDATE users SET totp_secret=$2, totp_last_step=0 WHERE id=$1 AND NOT totp_enabled", userID, secret)
+	if err != nil {
+		return fmt.Errorf("repo: set totp secret: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrTOTPAlreadyEnabled
+	}
+	return nil
+}
+
+// EnableTOTP turns two-factor authentication on and replaces the recovery
+// codes of the user with codeHashes.
+func (r *AuthRepository) EnableTOTP(ctx context.Context, userID int64, codeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repo: enable totp: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
+		"UPDATE users SET totp_enabled=TRUE WHERE id=$1 AND totp_secret<>'' AND NOT totp_enabled", userID)
+	if err != nil {
+		return fmt.Errorf("repo: enable totp: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrTOTPAlreadyEnabled
+	}
+
+	if err := replaceRecoveryCodesTX(ctx, tx, userID, codeHashes); err != nil {
+		return fmt.Errorf("repo: enable totp: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repo: enable totp: commit: %w", err)
+	}
+	return nil
+}
+
+func (r *AuthRepository) DisableTOTP(ctx context.Context, userID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repo: disable totp: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		"UPDATE users SET totp_secret='', totp_enabled=FALSE, totp_last_step=0 WHERE id=$1", userID)
+	if err != nil {
+		return fmt.Errorf("repo: disable totp: %w", err)
+	}
+	if err := replaceRecoveryCodesTX(ctx, tx, userID, nil); err != nil {
+		return fmt.Errorf("repo: disable totp: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repo: disable totp: commit: %w", err)
+	}
+	return nil
+}
+
+func replaceRecoveryCodesTX(ctx context.Context, tx pgx.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, "DELETE FROM totp_recovery_codes WHERE user_id=$1", userID); err != nil {
+		return fmt.Errorf("delete recovery codes: %w", err)
+	}
+	for _, hash := range codeHashes {
+		_, err := tx.Exec(ctx,
+			"INSERT INTO totp_recovery_codes (user_id, code_hash) VALUES ($1, $2)", userID, hash)
+		if err != nil {
+			return fmt.Errorf("insert recovery code: %w", err)
+		}
+	}
+	return nil
+}
+
+// UseTOTPStep records that the code of the given time step was used. A step
+// that is not newer than the last used one is rejected, so every code can be
+// used only once.
+func (r *AuthRepository) UseTOTPStep(ctx context.Context, userID int64, step int64) error {
+	tag, err := r.pool.Exec(ctx,
+		"UPDATE users SET totp_last_step=$2 WHERE id=$1 AND totp_last_step<$2", userID, step)
+	if err != nil {
+		return fmt.Errorf("repo: use totp step: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrInvalidTOTPCode
+	}
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code as used. It returns
+// ErrInvalidTOTPCode when the user has no such unused code.
+func (r *AuthRepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) error {
+	tag, err := r.pool.Exec(ctx,
+		"UPDATE totp_recovery_codes SET used_at=NOW() WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL",
+		userID, codeHash)
+	if err != nil {
+		return fmt.Errorf("repo: use recovery code: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrInvalidTOTPCode
+	}
+	return nil
+}
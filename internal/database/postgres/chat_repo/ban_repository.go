@@ -0,0 +1,125 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// BanMember records the ban and removes the user from the chat if they are
+// a member; removed reports whether they were. Banning again replaces the
+// reason and expiry.
+func (c *ChatRepository) BanMember(ctx context.Context, ban dom.ChatBan) (removed bool, err error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("repository: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO chat_bans (chat_id, user_id, banned_by, reason, expires_at)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5)
+		ON CONFLICT (chat_id, user_id) DO UPDATE
+		SET banned_by = EXCLUDED.banned_by, reason = EXCLUDED.reason,
+		    expires_at = EXCLUDED.expires_at, created_at = NOW()`,
+		ban.ChatID, ban.UserID, ban.BannedBy, ban.Reason, ban.ExpiresAt)
+	if err != nil {
+		return false, fmt.Errorf("repository: failed to insert ban: %w", err)
+	}
+	tag, err := tx.Exec(ctx,
+		"DELETE FROM chat_members WHERE chat_id = $1 AND user_id = $2", ban.ChatID, ban.UserID)
+	if err != nil {
+		return false, fmt.Errorf("repository: failed to remove banned member: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("repository: failed to commit transaction: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetBan returns the ban of the user, expired or not, or ErrNotFound if
+// there is none.
+func (c *ChatRepository) GetBan(ctx context.Context, chatID, userID int64) (dom.ChatBan, error) {
+	var ban dom.ChatBan
+	err := c.pool.QueryRow(ctx, `
+		SELECT chat_id, user_id, COALESCE(banned_by, 0), reason, expires_at, created_at
+		FROM chat_bans
+		WHERE chat_id = $1 AND user_id = $2`, chatID, userID).
+		Scan(&ban.ChatID, &ban.UserID, &ban.BannedBy, &ban.Reason, &ban.ExpiresAt, &ban.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.ChatBan{}, customerrors.ErrNotFound
+		}
+		return dom.ChatBan{}, fmt.Errorf("repository: failed to get ban: %w", err)
+	}
+	return ban, nil
+}
+
+// UnbanMember lifts the ban, or returns ErrNotFound if there is none.
+func (c *ChatRepository) UnbanMember(ctx context.Context, chatID, userID int64) error {
+	tag, err := c.pool.Exec(ctx,
+		"DELETE FROM chat_bans WHERE chat_id = $1 AND user_id = $2", chatID, userID)
+	if err != nil {
+		return fmt.Errorf("repository: failed to delete ban: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
+// ListBans returns the bans of the chat that have not expired, newest first.
+func (c *ChatRepository) ListBans(ctx context.Context, chatID int64) ([]dom.ChatBan, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT chat_id, user_id, COALESCE(banned_by, 0), reason, expires_at, created_at
+		FROM chat_bans
+		WHERE chat_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
+		ORDER BY created_at DESC, user_id`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to list bans: %w", err)
+	}
+	defer rows.Close()
+
+	bans := []dom.ChatBan{}
+	for rows.Next() {
+		var ban dom.ChatBan
+		if err := rows.Scan(&ban.ChatID, &ban.UserID, &ban.BannedBy, &ban.Reason, &ban.ExpiresAt, &ban.CreatedAt); err != nil {
+			return nil, fmt.Errorf("repository: failed to scan ban: %w", err)
+		}
+		bans = append(bans, ban)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+	return bans, nil
+}
+
+// BannedUsers returns which of userIDs are currently banned from the chat.
+func (c *ChatRepository) BannedUsers(ctx context.Context, chatID int64, userIDs []int64) ([]int64, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT user_id FROM chat_bans
+		WHERE chat_id = $1 AND user_id = ANY($2)
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		ORDER BY user_id`, chatID, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to check bans: %w", err)
+	}
+	defer rows.Close()
+
+	banned := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("repository: failed to scan ban: %w", err)
+		}
+		banned = append(banned, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+	return banned, nil
+}
@@ -0,0 +1,68 @@
+package chat_repo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"main/internal/database/postgres/chat_repo"
+	dbtest "main/internal/database/postgres/repositoryTest"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatBans(t *testing.T) {
+	ctx := context.Background()
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+	_, err := pool.Exec(ctx, `
+		INSERT INTO users (id, username, email, password_hash) VALUES
+		(1, 'alice', 'alice@example.com', 'hash'),
+		(2, 'bob', 'bob@example.com', 'hash'),
+		(3, 'carol', 'carol@example.com', 'hash')`)
+	require.NoError(t, err)
+	repo := chat_repo.NewChatRepository(pool, nil)
+
+	chatID, err := repo.CreateChat(ctx, 1, "Bans", false, []int64{1, 2})
+	require.NoError(t, err)
+
+	removed, err := repo.BanMember(ctx, dom.ChatBan{ChatID: chatID, UserID: 2, BannedBy: 1, Reason: "spam"})
+	require.NoError(t, err)
+	assert.True(t, removed)
+	inChat, err := repo.CheckIsMemberOfChat(ctx, chatID, 2)
+	require.NoError(t, err)
+	assert.False(t, inChat)
+
+	// An expired ban no longer counts.
+	past := time.Now().Add(-time.Minute)
+	removed, err = repo.BanMember(ctx, dom.ChatBan{ChatID: chatID, UserID: 3, BannedBy: 1, ExpiresAt: &past})
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	banned, err := repo.BannedUsers(ctx, chatID, []int64{2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, banned)
+
+	bans, err := repo.ListBans(ctx, chatID)
+	require.NoError(t, err)
+	require.Len(t, bans, 1)
+	assert.Equal(t, "spam", bans[0].Reason)
+
+	ban, err := repo.GetBan(ctx, chatID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), ban.BannedBy)
+	ban, err = repo.GetBan(ctx, chatID, 3)
+	require.NoError(t, err)
+	assert.NotNil(t, ban.ExpiresAt)
+
+	require.NoError(t, repo.UnbanMember(ctx, chatID, 2))
+	assert.ErrorIs(t, repo.UnbanMember(ctx, chatID, 2), customerrors.ErrNotFound)
+	_, err = repo.GetBan(ctx, chatID, 2)
+	assert.ErrorIs(t, err, customerrors.ErrNotFound)
+	banned, err = repo.BannedUsers(ctx, chatID, []int64{2})
+	require.NoError(t, err)
+	assert.Empty(t, banned)
+}
@@ -0,0 +1,85 @@
+package chat_repo_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"main/internal/database/postgres/chat_repo"
+	dbtest "main/internal/database/postgres/repositoryTest"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListOfChatsPages(t *testing.T) {
+	ctx := context.Background()
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+	_, err := pool.Exec(ctx, `
+		INSERT INTO users (id, username, email, password_hash) VALUES
+		(1, 'alice', 'alice@example.com', 'hash'),
+		(2, 'bob', 'bob@example.com', 'hash')`)
+	require.NoError(t, err)
+	repo := chat_repo.NewChatRepository(pool, nil)
+
+	quiet, err := repo.CreateChat(ctx, 1, "Quiet", false, []int64{1})
+	require.NoError(t, err)
+	busy, err := repo.CreateChat(ctx, 1, "Busy", false, []int64{1, 2})
+	require.NoError(t, err)
+	older, err := repo.CreateChat(ctx, 1, "Older", false, []int64{1, 2})
+	require.NoError(t, err)
+
+	now := time.Now().Truncate(time.Microsecond)
+	require.NoError(t, repo.UpdateChatLastMessage(ctx, busy, 2, strings.Repeat("x", 300), now.Add(time.Hour)))
+	require.NoError(t, repo.UpdateChatLastMessage(ctx, older, 1, "hello", now.Add(-time.Hour)))
+	pinned := true
+	require.NoError(t, repo.UpdateMemberSettings(ctx, busy, 1, dom.ChatSettings{Pinned: &pinned}))
+	assert.ErrorIs(t, repo.UpdateMemberSettings(ctx, quiet, 2, dom.ChatSettings{Pinned: &pinned}), customerrors.ErrUserNotMemberOfChat)
+
+	page, err := repo.ListOfChats(ctx, 1, time.Time{}, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, busy, page[0].ID)
+	assert.Equal(t, "bob", page[0].LastMessageSender)
+	assert.Equal(t, int64(2), page[0].LastMessageSenderID)
+	assert.Len(t, page[0].LastMessagePreview, 200)
+	assert.Equal(t, 2, page[0].MembersCount)
+	assert.True(t, page[0].IsPinned)
+	assert.Equal(t, dom.ChatTypeGroup, page[0].Type)
+	// Without messages the chat ranks by creation time.
+	assert.Equal(t, quiet, page[1].ID)
+	assert.Nil(t, page[1].LastMessageAt)
+
+	page, err = repo.ListOfChats(ctx, 1, page[1].CreatedAt, page[1].ID, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, older, page[0].ID)
+	assert.Equal(t, "hello", page[0].LastMessagePreview)
+}
+
+func TestClearChatLastMessage(t *testing.T) {
+	ctx := context.Background()
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+	_, err := pool.Exec(ctx, `
+		INSERT INTO users (id, username, email, password_hash) VALUES
+		(1, 'alice', 'alice@example.com', 'hash')`)
+	require.NoError(t, err)
+	repo := chat_repo.NewChatRepository(pool, nil)
+
+	chatID, err := repo.CreateChat(ctx, 1, "Chat", false, []int64{1})
+	require.NoError(t, err)
+	require.NoError(t, repo.UpdateChatLastMessage(ctx, chatID, 1, "secret", time.Now().Add(time.Hour)))
+	require.NoError(t, repo.ClearChatLastMessage(ctx, chatID))
+
+	page, err := repo.ListOfChats(ctx, 1, time.Time{}, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Empty(t, page[0].LastMessagePreview)
+	assert.Zero(t, page[0].LastMessageSenderID)
+	assert.Nil(t, page[0].LastMessageAt)
+}
@@ -2,6 +2,7 @@ package chat_repo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	dom "main/internal/domain/entity"
@@ -12,6 +13,10 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// maxPreviewLen is the number of characters of the last message kept for
+// the chat list.
+const maxPreviewLen = 200
+
 type ChatRepository struct {
 	pool   *pgxpool.Pool
 	logger *slog.Logger
@@ -24,7 +29,10 @@ func NewChatRepository(pool *pgxpool.Pool, logger *slog.Logger) *ChatRepository
 	}
 }
 
+// CreateChat stores the chat with its members; ownerID, one of the members,
+// becomes the owner.
 func (c *ChatRepository) CreateChat(ctx context.Context,
+	ownerID int64,
 	title string,
 	isPrivate bool,
 	membersID []int64) (int64, error) {
@@ -59,7 +67,7 @@ func (c *ChatRepository) CreateChat(ctx context.Context,
 		return 0, fmt.Errorf("failed to insert chat: %w", customerrors.ErrDatabase)
 	}
 
-	err = c.addMembersTX(ctx, tx, chatId, membersID)
+	err = c.addMembersTX(ctx, tx, chatId, ownerID, membersID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to add members to chat: %w", customerrors.ErrDatabase)
 	}
@@ -71,10 +79,14 @@ func (c *ChatRepository) CreateChat(ctx context.Context,
 	return chatId, nil
 
 }
-func (c *ChatRepository) addMembersTX(ctx context.Context, tx pgx.Tx, chatID int64, members []int64) error {
+func (c *ChatRepository) addMembersTX(ctx context.Context, tx pgx.Tx, chatID, ownerID int64, members []int64) error {
 	for _, userID := range members {
+		role := dom.ChatRoleMember
+		if userID == ownerID {
+			role = dom.ChatRoleOwner
+		}
 		_, err := tx.Exec(ctx,
-			"INSERT INTO chat_members (chat_id, user_id) VALUES ($1, $2)", chatID, userID)
+			"INSERT INTO chat_members (chat_id, user_id, role) VALUES ($1, $2, $3)", chatID, userID, role)
 		if err != nil {
 			return fmt.Errorf("repository: failed to insert chat member: %w", err)
 		}
@@ -96,37 +108,111 @@ func (c *ChatRepository) CheckIfChatExists(ctx context.Context, chatID int64) (b
 	return exists, err
 }
 
-func (c *ChatRepository) ListOfChats(ctx context.Context, userID int64) ([]dom.Chat, error) {
-
-	var chats []dom.Chat
-	query := "SELECT title FROM chats where id IN (SELECT chat_id FROM chat_members WHERE user_id=$1) ORDER BY last_message_at DESC"
-
-	rows, err := c.pool.Query(ctx, query, userID)
+// ListOfChats returns a page of the user's chats, most recent activity
+// first: the last message, or creation for chats without messages. A
+// beforeID of 0 starts from the top; otherwise the page continues after the
+// chat (beforeID, beforeAt).
+func (c *ChatRepository) ListOfChats(ctx context.Context, userID int64, beforeAt time.Time, beforeID int64, limit int) ([]dom.Chat, error) {
+	// Direct chats are listed under the name of the other member.
+	query := `
+		WITH mine AS (
+			SELECT c.id, COALESCE(u.username, c.title) AS title, c.type, c.avatar_url,
+				COALESCE(c.is_private, FALSE) AS is_private, c.is_public,
+				COALESCE(c.last_message_preview, '') AS last_message_preview,
+				COALESCE(c.last_message_sender_id, 0) AS last_message_sender_id,
+				COALESCE(s.username, '') AS last_message_sender,
+				c.last_message_at,
+				COALESCE(c.created_at, to_timestamp(0)) AS created_at,
+				COALESCE(c.last_message_at, c.created_at, to_timestamp(0)) AS active_at,
+				cm.is_pinned, cm.is_muted
+			FROM chat_members cm
+			JOIN chats c ON c.id = cm.chat_id
+			LEFT JOIN direct_chats d ON d.chat_id = c.id
+			LEFT JOIN users u ON u.id = CASE WHEN d.user_low = $1 THEN d.user_high ELSE d.user_low END
+			LEFT JOIN users s ON s.id = c.last_message_sender_id
+			WHERE cm.user_id = $1
+		)
+		SELECT m.id, m.title, m.type, m.avatar_url, m.is_private, m.is_public,
+			(SELECT COUNT(*) FROM chat_members WHERE chat_id = m.id),
+			m.last_message_preview, m.last_message_sender_id, m.last_message_sender,
+			m.last_message_at, m.created_at, m.is_pinned, m.is_muted
+		FROM mine m
+		WHERE $3 = 0 OR (m.active_at, m.id) < ($2, $3)
+		ORDER BY m.active_at DESC, m.id DESC
+		LIMIT $4`
+
+	rows, err := c.pool.Query(ctx, query, userID, beforeAt, beforeID, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to select titles of chats: %w", err)
+		return nil, fmt.Errorf("repository: failed to list chats: %w", err)
 	}
 	defer rows.Close()
 
+	chats := []dom.Chat{}
 	for rows.Next() {
 		var chat dom.Chat
-		if err := rows.Scan(&chat.Title); err != nil {
-			return nil, fmt.Errorf("failed to scan rows: %w", err)
+		if err := rows.Scan(&chat.ID, &chat.Title, &chat.Type, &chat.AvatarURL, &chat.IsPrivate, &chat.IsPublic,
+			&chat.MembersCount, &chat.LastMessagePreview, &chat.LastMessageSenderID, &chat.LastMessageSender,
+			&chat.LastMessageAt, &chat.CreatedAt, &chat.IsPinned, &chat.IsMuted); err != nil {
+			return nil, fmt.Errorf("repository: failed to scan chat: %w", err)
 		}
 		chats = append(chats, chat)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows iteration error: %w", err)
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
 	}
 
 	return chats, nil
+}
+
+// UpdateMemberSettings changes the chat preferences of the member and
+// returns ErrUserNotMemberOfChat if there is no such member.
+func (c *ChatRepository) UpdateMemberSettings(ctx context.Context, chatID, userID int64, settings dom.ChatSettings) error {
+	tag, err := c.pool.Exec(ctx, `
+		UPDATE chat_members
+		SET is_pinned = COALESCE($3, is_pinned), is_muted = COALESCE($4, is_muted)
+		WHERE chat_id = $1 AND user_id = $2`, chatID, userID, settings.Pinned, settings.Muted)
+	if err != nil {
+		return fmt.Errorf("repository: failed to update chat settings: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrUserNotMemberOfChat
+	}
+	return nil
+}
+
+// ListMemberships returns the chats the user belongs to, oldest membership
+// first.
+func (c *ChatRepository) ListMemberships(ctx context.Context, userID int64) ([]dom.Membership, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT c.id, c.title, cm.joined_at
+		FROM chat_members cm JOIN chats c ON c.id = cm.chat_id
+		WHERE cm.user_id = $1
+		ORDER BY cm.joined_at, c.id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to list memberships: %w", err)
+	}
+	defer rows.Close()
 
+	memberships := []dom.Membership{}
+	for rows.Next() {
+		var m dom.Membership
+		if err := rows.Scan(&m.ChatID, &m.Title, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("repository: failed to scan membership: %w", err)
+		}
+		memberships = append(memberships, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+	return memberships, nil
 }
 
 func (c *ChatRepository) GetChatDetails(ctx context.Context, chatID int64) (dom.Chat, error) {
 
 	var chat dom.Chat
-	query := "SELECT id, title, is_private, created_at, members, members_usernames, members_count FROM chats WHERE id=$1"
+	var pinned *string
+	query := "SELECT id, title, is_private, created_at, members, members_usernames, members_count, pinned_message_id FROM chats WHERE id=$1"
 	err := c.pool.QueryRow(ctx, query, chatID).Scan(
 		&chat.ID,
 		&chat.Title,
@@ -134,7 +220,8 @@ func (c *ChatRepository) GetChatDetails(ctx context.Context, chatID int64) (dom.
 		&chat.CreatedAt,
 		&chat.MembersID,
 		&chat.MembersUsernames,
-		&chat.MembersCount)
+		&chat.MembersCount,
+		&pinned)
 	if err != nil {
 		return dom.Chat{}, fmt.Errorf("failed to select chat details: %w", err)
 	}
@@ -146,9 +233,17 @@ func (c *ChatRepository) GetChatDetails(ctx context.Context, chatID int64) (dom.
 		MembersID:        chat.MembersID,
 		MembersUsernames: chat.MembersUsernames,
 		MembersCount:     chat.MembersCount,
+		PinnedMessageID:  derefString(pinned),
 	}, nil
 }
 
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 // func (c *ChatRepository) OpenChat(ctx context.Context, chatID int64, userID int64) ([]dom.Message, error) {
 
 // 	rows, err := c.pool.Query(ctx,
@@ -214,6 +309,127 @@ func (c *ChatRepository) RemoveMember(ctx context.Context, chatID, userID int64)
 	return err
 }
 
+// GetMemberRole returns the role of the user in the chat, or
+// ErrUserNotMemberOfChat.
+func (c *ChatRepository) GetMemberRole(ctx context.Context, chatID, userID int64) (string, error) {
+	var role string
+	err := c.pool.QueryRow(ctx,
+		"SELECT role FROM chat_members WHERE chat_id=$1 AND user_id=$2", chatID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", customerrors.ErrUserNotMemberOfChat
+		}
+		return "", fmt.Errorf("repository: failed to select member role: %w", err)
+	}
+	return role, nil
+}
+
+// SetMemberRole changes the role of a member other than the owner; ownership
+// only moves with TransferOwnership.
+func (c *ChatRepository) SetMemberRole(ctx context.Context, chatID, userID int64, role string) error {
+	tag, err := c.pool.Exec(ctx,
+		"UPDATE chat_members SET role=$3 WHERE chat_id=$1 AND user_id=$2 AND role <> 'owner'", chatID, userID, role)
+	if err != nil {
+		return fmt.Errorf("repository: failed to update member role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrUserNotMemberOfChat
+	}
+	return nil
+}
+
+// TransferOwnership makes toID the owner and the previous owner fromID an
+// admin. The owner is demoted first so the one-owner index holds throughout.
+func (c *ChatRepository) TransferOwnership(ctx context.Context, chatID, fromID, toID int64) error {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
+		"UPDATE chat_members SET role='admin' WHERE chat_id=$1 AND user_id=$2 AND role='owner'", chatID, fromID)
+	if err != nil {
+		return fmt.Errorf("repository: failed to demote owner: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrChatPermissionDenied
+	}
+
+	tag, err = tx.Exec(ctx,
+		"UPDATE chat_members SET role='owner' WHERE chat_id=$1 AND user_id=$2", chatID, toID)
+	if err != nil {
+		return fmt.Errorf("repository: failed to promote owner: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrUserNotMemberOfChat
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository: failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// UpdateChat applies the non-nil fields of upd and returns the chat as
+// stored.
+func (c *ChatRepository) UpdateChat(ctx context.Context, chatID int64, upd dom.ChatUpdate) (dom.Chat, error) {
+	chat := dom.Chat{ID: chatID}
+	var handle *string
+	err := c.pool.QueryRow(ctx, `
+		UPDATE chats SET
+			title = COALESCE($2, title),
+			description = COALESCE($3, description),
+			avatar_url = COALESCE($4, avatar_url)
+		WHERE id = $1
+		RETURNING title, type, handle, description, avatar_url, is_private, is_public, created_at`,
+		chatID, upd.Title, upd.Description, upd.AvatarURL).
+		Scan(&chat.Title, &chat.Type, &handle, &chat.Description, &chat.AvatarURL, &chat.IsPrivate, &chat.IsPublic, &chat.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.Chat{}, customerrors.ErrNotFound
+		}
+		return dom.Chat{}, fmt.Errorf("repository: failed to update chat: %w", err)
+	}
+	chat.Handle = derefString(handle)
+	return chat, nil
+}
+
+// ListMemberIDs returns the ids of all members of the chat.
+func (c *ChatRepository) ListMemberIDs(ctx context.Context, chatID int64) ([]int64, error) {
+	rows, err := c.pool.Query(ctx, "SELECT user_id FROM chat_members WHERE chat_id = $1 ORDER BY user_id", chatID)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to list chat members: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("repository: failed to scan chat member: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+	return ids, nil
+}
+
+// SetPinnedMessage pins the message to the chat; an empty messageID unpins.
+func (c *ChatRepository) SetPinnedMessage(ctx context.Context, chatID int64, messageID string) error {
+	tag, err := c.pool.Exec(ctx,
+		"UPDATE chats SET pinned_message_id=NULLIF($1, '') WHERE id=$2", messageID, chatID)
+	if err != nil {
+		return fmt.Errorf("repository: failed to update pinned message: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
 func (c *ChatRepository) GetLastMessage(ctx context.Context, chatID int64) (int64, error) {
 	var id int64
 	query := "SELECT last_message_id from chats WHERE id=$1"
@@ -224,15 +440,38 @@ func (c *ChatRepository) GetLastMessage(ctx context.Context, chatID int64) (int6
 	return id, nil
 }
 
+// UpdateChatLastMessage stores the latest message of the chat for the chat
+// list. Long texts are cut down to a preview.
 func (c *ChatRepository) UpdateChatLastMessage(ctx context.Context,
 	chatID int64,
+	senderID int64,
 	messageText string,
 	createdAt time.Time) error {
 
-	_, err := c.pool.Exec(ctx,
-		"UPDATE chats SET last_message_text=$1, last_message_created_at=$2 WHERE id=$3", messageText, createdAt, chatID)
+	_, err := c.pool.Exec(ctx, `
+		UPDATE chats
+		SET last_message_preview = $1, last_message_at = $2, last_message_sender_id = NULLIF($3, 0)
+		WHERE id = $4`, previewText(messageText), createdAt, senderID, chatID)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// ClearChatLastMessage removes the last message of a chat that has no
+// messages left, so the chat list ranks it by creation time again.
+func (c *ChatRepository) ClearChatLastMessage(ctx context.Context, chatID int64) error {
+	_, err := c.pool.Exec(ctx, `
+		UPDATE chats
+		SET last_message_preview = NULL, last_message_at = NULL, last_message_sender_id = NULL
+		WHERE id = $1`, chatID)
+	return err
+}
+
+func previewText(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxPreviewLen {
+		return text
+	}
+	return string(runes[:maxPreviewLen])
+}
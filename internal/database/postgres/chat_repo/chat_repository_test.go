@@ -2,8 +2,10 @@ package chat_repo_test
 
 import (
 	"context"
+	"fmt"
 	"main/internal/database/postgres/chat_repo"
 	dbtest "main/internal/database/postgres/repositoryTest"
+	dom "main/internal/domain/entity"
 	"main/pkg/customerrors"
 	"testing"
 
@@ -117,7 +119,7 @@ func TestCreateChat(t *testing.T) {
 				t.Fatalf("failed to truncate chats table: %v", err)
 			}
 
-			_, err = chatRepo.CreateChat(ctx, tt.title, tt.isPrivate, tt.membersID)
+			_, err = chatRepo.CreateChat(ctx, tt.membersID[0], tt.title, tt.isPrivate, tt.membersID)
 			if tt.expectErr {
 				if err == nil {
 					t.Fatalf("expected error but got none")
@@ -135,6 +137,54 @@ func TestCreateChat(t *testing.T) {
 
 }
 
+func TestMemberRoles(t *testing.T) {
+	ctx := context.Background()
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+	for id := int64(1); id <= 3; id++ {
+		_, err := pool.Exec(ctx,
+			"INSERT INTO users (id, username, email, password_hash) VALUES ($1, $2, $3, $4)",
+			id, fmt.Sprintf("testuser%d", id), fmt.Sprintf("testuser%d@example.com", id), "hashedpassword")
+		if err != nil {
+			t.Fatalf("failed to insert test user: %v", err)
+		}
+	}
+	repo := chat_repo.NewChatRepository(pool, nil)
+
+	chatID, err := repo.CreateChat(ctx, 2, "Roles", false, []int64{1, 2})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.AddMembers(ctx, chatID, []int64{3}))
+
+	role, err := repo.GetMemberRole(ctx, chatID, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, dom.ChatRoleOwner, role)
+	role, err = repo.GetMemberRole(ctx, chatID, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, dom.ChatRoleMember, role)
+	_, err = repo.GetMemberRole(ctx, chatID, 999)
+	assert.ErrorIs(t, err, customerrors.ErrUserNotMemberOfChat)
+
+	assert.NoError(t, repo.SetMemberRole(ctx, chatID, 1, dom.ChatRoleAdmin))
+	assert.ErrorIs(t, repo.SetMemberRole(ctx, chatID, 2, dom.ChatRoleMember), customerrors.ErrUserNotMemberOfChat,
+		"the owner keeps its role")
+
+	assert.NoError(t, repo.TransferOwnership(ctx, chatID, 2, 3))
+	role, _ = repo.GetMemberRole(ctx, chatID, 3)
+	assert.Equal(t, dom.ChatRoleOwner, role)
+	role, _ = repo.GetMemberRole(ctx, chatID, 2)
+	assert.Equal(t, dom.ChatRoleAdmin, role)
+	assert.ErrorIs(t, repo.TransferOwnership(ctx, chatID, 2, 1), customerrors.ErrChatPermissionDenied,
+		"only the current owner can hand over")
+
+	assert.NoError(t, repo.SetPinnedMessage(ctx, chatID, "651eb1234567890abcdef123"))
+	var pinned *string
+	assert.NoError(t, pool.QueryRow(ctx, "SELECT pinned_message_id FROM chats WHERE id=$1", chatID).Scan(&pinned))
+	assert.Equal(t, "651eb1234567890abcdef123", *pinned)
+	assert.NoError(t, repo.SetPinnedMessage(ctx, chatID, ""))
+	assert.NoError(t, pool.QueryRow(ctx, "SELECT pinned_message_id FROM chats WHERE id=$1", chatID).Scan(&pinned))
+	assert.Nil(t, pinned)
+}
+
 // func TestOpenChat(t *testing.T) {
 // 	ctx := context.Background()
 // 	pool, teardown := dbtest.SetupTestDB(t)
@@ -223,3 +273,41 @@ func TestCreateChat(t *testing.T) {
 // 		})
 // 	}
 // }
+
+func TestUpdateChat(t *testing.T) {
+	ctx := context.Background()
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+	for id := int64(1); id <= 2; id++ {
+		_, err := pool.Exec(ctx,
+			"INSERT INTO users (id, username, email, password_hash) VALUES ($1, $2, $3, $4)",
+			id, fmt.Sprintf("testuser%d", id), fmt.Sprintf("testuser%d@example.com", id), "hashedpassword")
+		if err != nil {
+			t.Fatalf("failed to insert test user: %v", err)
+		}
+	}
+	repo := chat_repo.NewChatRepository(pool, nil)
+
+	chatID, err := repo.CreateChat(ctx, 1, "Old", false, []int64{1, 2})
+	assert.NoError(t, err)
+
+	title, avatar := "New", "https://cdn.example.com/a.png"
+	chat, err := repo.UpdateChat(ctx, chatID, dom.ChatUpdate{Title: &title, AvatarURL: &avatar})
+	assert.NoError(t, err)
+	assert.Equal(t, "New", chat.Title)
+	assert.Equal(t, avatar, chat.AvatarURL)
+	assert.Empty(t, chat.Description)
+
+	description := "About"
+	chat, err = repo.UpdateChat(ctx, chatID, dom.ChatUpdate{Description: &description})
+	assert.NoError(t, err)
+	assert.Equal(t, "New", chat.Title, "unset fields are kept")
+	assert.Equal(t, "About", chat.Description)
+
+	_, err = repo.UpdateChat(ctx, 999, dom.ChatUpdate{Title: &title})
+	assert.ErrorIs(t, err, customerrors.ErrNotFound)
+
+	members, err := repo.ListMemberIDs(ctx, chatID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, members)
+}
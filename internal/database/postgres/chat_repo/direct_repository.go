@@ -0,0 +1,130 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// directPair orders two user ids the way direct_chats stores them.
+func directPair(a, b int64) (int64, int64) {
+	if a < b {
+		return a, b
+	}
+	return b, a
+}
+
+// FindDirectChat returns the direct chat between the two users, titled with
+// the username of otherID, or ErrNotFound. A user who left the chat does not
+// find it until they open it again.
+func (c *ChatRepository) FindDirectChat(ctx context.Context, userID, otherID int64) (dom.Chat, error) {
+	return c.findDirectChat(ctx, c.pool, userID, otherID)
+}
+
+func (c *ChatRepository) findDirectChat(ctx context.Context, q rowQuerier, userID, otherID int64) (dom.Chat, error) {
+	low, high := directPair(userID, otherID)
+	chat := dom.Chat{Type: dom.ChatTypeDirect}
+	err := q.QueryRow(ctx, `
+		SELECT c.id, u.username, c.is_private, c.created_at
+		FROM direct_chats d
+		JOIN chats c ON c.id = d.chat_id
+		JOIN users u ON u.id = $3
+		WHERE d.user_low = $1 AND d.user_high = $2
+		  AND EXISTS (SELECT 1 FROM chat_members m WHERE m.chat_id = c.id AND m.user_id = $4)`, low, high, otherID, userID).
+		Scan(&chat.ID, &chat.Title, &chat.IsPrivate, &chat.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.Chat{}, customerrors.ErrNotFound
+		}
+		return dom.Chat{}, fmt.Errorf("repository: failed to select direct chat: %w", err)
+	}
+	chat.MembersID = []int64{low, high}
+	chat.MembersCount = 2
+	return chat, nil
+}
+
+// GetOrCreateDirectChat returns the direct chat between the two users,
+// creating it if there is none; created reports which happened. Either user
+// who left the chat is added back, as nobody else could. Concurrent calls for
+// the same pair end up with the same chat.
+func (c *ChatRepository) GetOrCreateDirectChat(ctx context.Context, userID, otherID int64) (chat dom.Chat, created bool, err error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return dom.Chat{}, false, fmt.Errorf("repository: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	low, high := directPair(userID, otherID)
+	_, err = tx.Exec(ctx, `
+		INSERT INTO chat_members (chat_id, user_id, role)
+		SELECT d.chat_id, u.id, $3
+		FROM direct_chats d CROSS JOIN (VALUES ($1::BIGINT), ($2::BIGINT)) AS u(id)
+		WHERE d.user_low = $1 AND d.user_high = $2
+		ON CONFLICT (chat_id, user_id) DO NOTHING`, low, high, dom.ChatRoleMember)
+	if err != nil {
+		return dom.Chat{}, false, fmt.Errorf("repository: failed to restore direct chat members: %w", err)
+	}
+
+	chat, err = c.findDirectChat(ctx, tx, userID, otherID)
+	if err == nil {
+		if err := tx.Commit(ctx); err != nil {
+			return dom.Chat{}, false, fmt.Errorf("repository: failed to commit transaction: %w", err)
+		}
+		return chat, false, nil
+	}
+	if !errors.Is(err, customerrors.ErrNotFound) {
+		return dom.Chat{}, false, err
+	}
+
+	var usernames [2]string
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE((SELECT username FROM users WHERE id = $1), ''),
+		       COALESCE((SELECT username FROM users WHERE id = $2), '')`,
+		userID, otherID).Scan(&usernames[0], &usernames[1])
+	if err != nil {
+		return dom.Chat{}, false, fmt.Errorf("repository: failed to select usernames: %w", err)
+	}
+	if usernames[0] == "" || usernames[1] == "" {
+		return dom.Chat{}, false, customerrors.ErrUserNotFound
+	}
+
+	// The stored title is only a fallback; readers see the other user's name.
+	chat = dom.Chat{Type: dom.ChatTypeDirect, IsPrivate: true, Title: usernames[1]}
+	err = tx.QueryRow(ctx,
+		"INSERT INTO chats (title, is_private, type) VALUES ($1, TRUE, $2) RETURNING id, created_at",
+		usernames[0]+", "+usernames[1], dom.ChatTypeDirect).Scan(&chat.ID, &chat.CreatedAt)
+	if err != nil {
+		return dom.Chat{}, false, fmt.Errorf("repository: failed to insert chat: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO direct_chats (chat_id, user_low, user_high) VALUES ($1, $2, $3)
+		ON CONFLICT (user_low, user_high) DO NOTHING`, chat.ID, low, high)
+	if err != nil {
+		return dom.Chat{}, false, fmt.Errorf("repository: failed to insert direct chat: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		// Another request created the chat first; drop ours and use that one.
+		_ = tx.Rollback(ctx)
+		chat, err = c.FindDirectChat(ctx, userID, otherID)
+		return chat, false, err
+	}
+
+	if err := c.addMembersTX(ctx, tx, chat.ID, 0, []int64{low, high}); err != nil {
+		return dom.Chat{}, false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return dom.Chat{}, false, fmt.Errorf("repository: failed to commit transaction: %w", err)
+	}
+	chat.MembersID = []int64{low, high}
+	chat.MembersCount = 2
+	return chat, true, nil
+}
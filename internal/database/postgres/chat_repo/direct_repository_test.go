@@ -0,0 +1,109 @@
+package chat_repo_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"main/internal/database/postgres/chat_repo"
+	dbtest "main/internal/database/postgres/repositoryTest"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectChats(t *testing.T) {
+	ctx := context.Background()
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+	_, err := pool.Exec(ctx, `
+		INSERT INTO users (id, username, email, password_hash) VALUES
+		(1, 'alice', 'alice@example.com', 'hash'),
+		(2, 'bob', 'bob@example.com', 'hash')`)
+	require.NoError(t, err)
+	repo := chat_repo.NewChatRepository(pool, nil)
+
+	_, err = repo.FindDirectChat(ctx, 1, 2)
+	assert.ErrorIs(t, err, customerrors.ErrNotFound)
+
+	// Concurrent first calls still create a single chat.
+	var wg sync.WaitGroup
+	ids := make([]int64, 4)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			chat, _, err := repo.GetOrCreateDirectChat(ctx, 1, 2)
+			assert.NoError(t, err)
+			ids[i] = chat.ID
+		}(i)
+	}
+	wg.Wait()
+	for _, id := range ids {
+		assert.Equal(t, ids[0], id)
+	}
+
+	chat, created, err := repo.GetOrCreateDirectChat(ctx, 2, 1)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, ids[0], chat.ID)
+	assert.Equal(t, "alice", chat.Title, "titled after the other member")
+	assert.Equal(t, dom.ChatTypeDirect, chat.Type)
+
+	chat, err = repo.FindDirectChat(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", chat.Title)
+
+	var chats int
+	require.NoError(t, pool.QueryRow(ctx, "SELECT COUNT(*) FROM chats WHERE type = 'direct'").Scan(&chats))
+	assert.Equal(t, 1, chats)
+
+	list, err := repo.ListOfChats(ctx, 2, time.Time{}, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "alice", list[0].Title)
+
+	_, _, err = repo.GetOrCreateDirectChat(ctx, 1, 99)
+	assert.ErrorIs(t, err, customerrors.ErrUserNotFound)
+}
+
+func TestDirectChatLeaveAndReopen(t *testing.T) {
+	ctx := context.Background()
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+	_, err := pool.Exec(ctx, `
+		INSERT INTO users (id, username, email, password_hash) VALUES
+		(1, 'alice', 'alice@example.com', 'hash'),
+		(2, 'bob', 'bob@example.com', 'hash')`)
+	require.NoError(t, err)
+	repo := chat_repo.NewChatRepository(pool, nil)
+
+	chat, created, err := repo.GetOrCreateDirectChat(ctx, 1, 2)
+	require.NoError(t, err)
+	require.True(t, created)
+
+	require.NoError(t, repo.RemoveMember(ctx, chat.ID, 1))
+
+	_, err = repo.FindDirectChat(ctx, 1, 2)
+	assert.ErrorIs(t, err, customerrors.ErrNotFound, "hidden from the user who left")
+	_, err = repo.FindDirectChat(ctx, 2, 1)
+	assert.NoError(t, err, "still there for the other user")
+
+	// Bob opening the chat again brings Alice back as well.
+	reopened, created, err := repo.GetOrCreateDirectChat(ctx, 2, 1)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, chat.ID, reopened.ID)
+
+	isMember, err := repo.CheckIsMemberOfChat(ctx, chat.ID, 1)
+	require.NoError(t, err)
+	assert.True(t, isMember)
+
+	var members int
+	require.NoError(t, pool.QueryRow(ctx,
+		"SELECT COUNT(*) FROM chat_members WHERE chat_id = $1 AND role = 'member'", chat.ID).Scan(&members))
+	assert.Equal(t, 2, members)
+}
@@ -0,0 +1,113 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const inviteColumns = `id, chat_id, code, COALESCE(created_by, 0), expires_at, max_uses, uses,
+	requires_approval, revoked_at, created_at`
+
+func scanInvite(row pgx.Row) (dom.ChatInvite, error) {
+	var inv dom.ChatInvite
+	err := row.Scan(&inv.ID, &inv.ChatID, &inv.Code, &inv.CreatedBy, &inv.ExpiresAt, &inv.MaxUses, &inv.Uses,
+		&inv.RequiresApproval, &inv.RevokedAt, &inv.CreatedAt)
+	return inv, err
+}
+
+func (c *ChatRepository) CreateInvite(ctx context.Context, invite dom.ChatInvite) (dom.ChatInvite, error) {
+	created, err := scanInvite(c.pool.QueryRow(ctx, `
+		INSERT INTO chat_invites (chat_id, code, created_by, expires_at, max_uses, requires_approval)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+inviteColumns,
+		invite.ChatID, invite.Code, invite.CreatedBy, invite.ExpiresAt, invite.MaxUses, invite.RequiresApproval))
+	if err != nil {
+		return dom.ChatInvite{}, fmt.Errorf("repository: failed to insert invite: %w", err)
+	}
+	return created, nil
+}
+
+// ListInvites returns the invites of the chat that are not revoked, newest
+// first.
+func (c *ChatRepository) ListInvites(ctx context.Context, chatID int64) ([]dom.ChatInvite, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT `+inviteColumns+`
+		FROM chat_invites
+		WHERE chat_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC, id DESC`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to list invites: %w", err)
+	}
+	defer rows.Close()
+
+	invites := []dom.ChatInvite{}
+	for rows.Next() {
+		inv, err := scanInvite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan invite: %w", err)
+		}
+		invites = append(invites, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+	return invites, nil
+}
+
+func (c *ChatRepository) GetInviteByCode(ctx context.Context, code string) (dom.ChatInvite, error) {
+	inv, err := scanInvite(c.pool.QueryRow(ctx,
+		"SELECT "+inviteColumns+" FROM chat_invites WHERE code = $1", code))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.ChatInvite{}, customerrors.ErrNotFound
+		}
+		return dom.ChatInvite{}, fmt.Errorf("repository: failed to select invite: %w", err)
+	}
+	return inv, nil
+}
+
+func (c *ChatRepository) RevokeInvite(ctx context.Context, chatID, inviteID int64) error {
+	tag, err := c.pool.Exec(ctx,
+		"UPDATE chat_invites SET revoked_at = NOW() WHERE id = $1 AND chat_id = $2 AND revoked_at IS NULL",
+		inviteID, chatID)
+	if err != nil {
+		return fmt.Errorf("repository: failed to revoke invite: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
+// ClaimInviteUse counts one use of the invite if it is still usable, so
+// concurrent redemptions cannot exceed max_uses.
+func (c *ChatRepository) ClaimInviteUse(ctx context.Context, inviteID int64) error {
+	tag, err := c.pool.Exec(ctx, `
+		UPDATE chat_invites SET uses = uses + 1
+		WHERE id = $1
+		  AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		  AND (max_uses IS NULL OR uses < max_uses)`, inviteID)
+	if err != nil {
+		return fmt.Errorf("repository: failed to claim invite use: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrInvalidInvite
+	}
+	return nil
+}
+
+// ReleaseInviteUse gives back a use claimed for a join that did not happen.
+func (c *ChatRepository) ReleaseInviteUse(ctx context.Context, inviteID int64) error {
+	_, err := c.pool.Exec(ctx,
+		"UPDATE chat_invites SET uses = uses - 1 WHERE id = $1 AND uses > 0", inviteID)
+	if err != nil {
+		return fmt.Errorf("repository: failed to release invite use: %w", err)
+	}
+	return nil
+}
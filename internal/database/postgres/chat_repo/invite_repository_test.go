@@ -0,0 +1,72 @@
+package chat_repo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"main/internal/database/postgres/chat_repo"
+	dbtest "main/internal/database/postgres/repositoryTest"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvites(t *testing.T) {
+	ctx := context.Background()
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+	for _, id := range []int64{1, 2, 3} {
+		_, err := pool.Exec(ctx,
+			"INSERT INTO users (id, username, email, password_hash) VALUES ($1, 'u' || $1::text, 'u' || $1::text || '@example.com', 'hash')", id)
+		require.NoError(t, err)
+	}
+	repo := chat_repo.NewChatRepository(pool, nil)
+
+	chatID, err := repo.CreateChat(ctx, 1, "Invites", false, []int64{1})
+	require.NoError(t, err)
+
+	one := 1
+	expires := time.Now().Add(time.Hour)
+	invite, err := repo.CreateInvite(ctx, dom.ChatInvite{ChatID: chatID, Code: "abc", CreatedBy: 1, ExpiresAt: &expires, MaxUses: &one})
+	require.NoError(t, err)
+	assert.Equal(t, 0, invite.Uses)
+	assert.Equal(t, &one, invite.MaxUses)
+
+	got, err := repo.GetInviteByCode(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, invite.ID, got.ID)
+	_, err = repo.GetInviteByCode(ctx, "missing")
+	assert.ErrorIs(t, err, customerrors.ErrNotFound)
+
+	require.NoError(t, repo.ClaimInviteUse(ctx, invite.ID))
+	assert.ErrorIs(t, repo.ClaimInviteUse(ctx, invite.ID), customerrors.ErrInvalidInvite, "max uses reached")
+	require.NoError(t, repo.ReleaseInviteUse(ctx, invite.ID))
+	require.NoError(t, repo.ClaimInviteUse(ctx, invite.ID))
+
+	approval, err := repo.CreateInvite(ctx, dom.ChatInvite{ChatID: chatID, Code: "def", CreatedBy: 1, RequiresApproval: true})
+	require.NoError(t, err)
+	req, err := repo.CreateJoinRequest(ctx, chatID, 2, approval.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, approval.ID, req.InviteID)
+	again, err := repo.CreateJoinRequest(ctx, chatID, 2, approval.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, req.ID, again.ID, "redeeming twice keeps one request")
+
+	requests, err := repo.ListJoinRequests(ctx, chatID)
+	require.NoError(t, err)
+	require.Len(t, requests, 1, "in the same queue as other join requests")
+	assert.Equal(t, int64(2), requests[0].UserID)
+	assert.Equal(t, approval.ID, requests[0].InviteID)
+
+	require.NoError(t, repo.RevokeInvite(ctx, chatID, approval.ID))
+	assert.ErrorIs(t, repo.RevokeInvite(ctx, chatID, approval.ID), customerrors.ErrNotFound)
+	assert.ErrorIs(t, repo.ClaimInviteUse(ctx, approval.ID), customerrors.ErrInvalidInvite)
+
+	invites, err := repo.ListInvites(ctx, chatID)
+	require.NoError(t, err)
+	require.Len(t, invites, 1, "revoked invites are not listed")
+	assert.Equal(t, "abc", invites[0].Code)
+}
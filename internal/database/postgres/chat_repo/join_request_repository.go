@@ -0,0 +1,138 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const joinRequestColumns = `id, chat_id, user_id, COALESCE(invite_id, 0), message, status, COALESCE(decided_by, 0), decided_at, created_at`
+
+func scanJoinRequest(row pgx.Row) (dom.JoinRequest, error) {
+	var req dom.JoinRequest
+	err := row.Scan(&req.ID, &req.ChatID, &req.UserID, &req.InviteID, &req.Message, &req.Status, &req.DecidedBy, &req.DecidedAt, &req.CreatedAt)
+	return req, err
+}
+
+// GetChatSummary returns the id, title, type and visibility of the chat, or
+// ErrNotFound.
+func (c *ChatRepository) GetChatSummary(ctx context.Context, chatID int64) (dom.Chat, error) {
+	chat := dom.Chat{ID: chatID}
+	err := c.pool.QueryRow(ctx,
+		"SELECT title, type, COALESCE(is_private, FALSE), is_public FROM chats WHERE id = $1", chatID).
+		Scan(&chat.Title, &chat.Type, &chat.IsPrivate, &chat.IsPublic)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.Chat{}, customerrors.ErrNotFound
+		}
+		return dom.Chat{}, fmt.Errorf("repository: failed to select chat: %w", err)
+	}
+	return chat, nil
+}
+
+// CreateJoinRequest stores a pending request, made through the invite unless
+// inviteID is 0. If the user already has one for the chat, that one is
+// returned instead.
+func (c *ChatRepository) CreateJoinRequest(ctx context.Context, chatID, userID, inviteID int64, message string) (dom.JoinRequest, error) {
+	req, err := scanJoinRequest(c.pool.QueryRow(ctx, `
+		WITH inserted AS (
+			INSERT INTO chat_join_requests (chat_id, user_id, message, invite_id)
+			VALUES ($1, $2, $3, NULLIF($4, 0))
+			ON CONFLICT (chat_id, user_id) WHERE status = 'pending' DO NOTHING
+			RETURNING `+joinRequestColumns+`
+		)
+		SELECT * FROM inserted
+		UNION ALL
+		SELECT `+joinRequestColumns+` FROM chat_join_requests
+		WHERE chat_id = $1 AND user_id = $2 AND status = 'pending'
+		LIMIT 1`, chatID, userID, message, inviteID))
+	if err != nil {
+		return dom.JoinRequest{}, fmt.Errorf("repository: failed to insert join request: %w", err)
+	}
+	return req, nil
+}
+
+// ListJoinRequests returns the pending requests of the chat, oldest first.
+func (c *ChatRepository) ListJoinRequests(ctx context.Context, chatID int64) ([]dom.JoinRequest, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT `+joinRequestColumns+`
+		FROM chat_join_requests
+		WHERE chat_id = $1 AND status = 'pending'
+		ORDER BY created_at, id`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to list join requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []dom.JoinRequest{}
+	for rows.Next() {
+		req, err := scanJoinRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan join request: %w", err)
+		}
+		requests = append(requests, req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+	return requests, nil
+}
+
+// DecideJoinRequest moves a pending request to status. It returns
+// ErrNotFound if there is no such pending request, so concurrent decisions
+// cannot both win.
+func (c *ChatRepository) DecideJoinRequest(ctx context.Context, chatID, requestID int64, status string, decidedBy int64) (dom.JoinRequest, error) {
+	req, err := scanJoinRequest(c.pool.QueryRow(ctx, `
+		UPDATE chat_join_requests
+		SET status = $3, decided_by = NULLIF($4, 0), decided_at = NOW()
+		WHERE id = $1 AND chat_id = $2 AND status = 'pending'
+		RETURNING `+joinRequestColumns, requestID, chatID, status, decidedBy))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.JoinRequest{}, customerrors.ErrNotFound
+		}
+		return dom.JoinRequest{}, fmt.Errorf("repository: failed to update join request: %w", err)
+	}
+	return req, nil
+}
+
+// ReopenJoinRequest puts back a request whose approval could not be carried
+// out.
+func (c *ChatRepository) ReopenJoinRequest(ctx context.Context, requestID int64) error {
+	_, err := c.pool.Exec(ctx, `
+		UPDATE chat_join_requests SET status = 'pending', decided_by = NULL, decided_at = NULL
+		WHERE id = $1`, requestID)
+	if err != nil {
+		return fmt.Errorf("repository: failed to reopen join request: %w", err)
+	}
+	return nil
+}
+
+// ListAdminIDs returns the owner and admins of the chat.
+func (c *ChatRepository) ListAdminIDs(ctx context.Context, chatID int64) ([]int64, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT user_id FROM chat_members
+		WHERE chat_id = $1 AND role IN ('owner', 'admin')
+		ORDER BY user_id`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to list chat admins: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("repository: failed to scan chat admin: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+	return ids, nil
+}
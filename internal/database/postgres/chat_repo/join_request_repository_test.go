@@ -0,0 +1,79 @@
+package chat_repo_test
+
+import (
+	"context"
+	"testing"
+
+	"main/internal/database/postgres/chat_repo"
+	dbtest "main/internal/database/postgres/repositoryTest"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinRequests(t *testing.T) {
+	ctx := context.Background()
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+	_, err := pool.Exec(ctx, `
+		INSERT INTO users (id, username, email, password_hash) VALUES
+		(1, 'alice', 'alice@example.com', 'hash'),
+		(2, 'bob', 'bob@example.com', 'hash'),
+		(3, 'carol', 'carol@example.com', 'hash')`)
+	require.NoError(t, err)
+	repo := chat_repo.NewChatRepository(pool, nil)
+
+	chatID, err := repo.CreateChat(ctx, 1, "Private", true, []int64{1})
+	require.NoError(t, err)
+
+	chat, err := repo.GetChatSummary(ctx, chatID)
+	require.NoError(t, err)
+	assert.True(t, chat.IsPrivate)
+	assert.Equal(t, dom.ChatTypeGroup, chat.Type)
+	_, err = repo.GetChatSummary(ctx, chatID+1)
+	assert.ErrorIs(t, err, customerrors.ErrNotFound)
+
+	req, err := repo.CreateJoinRequest(ctx, chatID, 2, 0, "hi")
+	require.NoError(t, err)
+	assert.Equal(t, dom.JoinRequestPending, req.Status)
+
+	// Asking again returns the pending request.
+	again, err := repo.CreateJoinRequest(ctx, chatID, 2, 0, "hello?")
+	require.NoError(t, err)
+	assert.Equal(t, req.ID, again.ID)
+	assert.Equal(t, "hi", again.Message)
+
+	other, err := repo.CreateJoinRequest(ctx, chatID, 3, 0, "")
+	require.NoError(t, err)
+
+	requests, err := repo.ListJoinRequests(ctx, chatID)
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, req.ID, requests[0].ID)
+
+	decided, err := repo.DecideJoinRequest(ctx, chatID, req.ID, dom.JoinRequestApproved, 1)
+	require.NoError(t, err)
+	assert.Equal(t, dom.JoinRequestApproved, decided.Status)
+	assert.Equal(t, int64(1), decided.DecidedBy)
+	assert.NotNil(t, decided.DecidedAt)
+	_, err = repo.DecideJoinRequest(ctx, chatID, req.ID, dom.JoinRequestRejected, 1)
+	assert.ErrorIs(t, err, customerrors.ErrNotFound)
+
+	require.NoError(t, repo.ReopenJoinRequest(ctx, req.ID))
+	requests, err = repo.ListJoinRequests(ctx, chatID)
+	require.NoError(t, err)
+	assert.Len(t, requests, 2)
+
+	_, err = repo.DecideJoinRequest(ctx, chatID, other.ID, dom.JoinRequestRejected, 1)
+	require.NoError(t, err)
+	// A rejected user may ask again.
+	retry, err := repo.CreateJoinRequest(ctx, chatID, 3, 0, "please")
+	require.NoError(t, err)
+	assert.NotEqual(t, other.ID, retry.ID)
+
+	admins, err := repo.ListAdminIDs(ctx, chatID)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, admins)
+}
@@ -0,0 +1,101 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const publicChatColumns = `c.id, c.title, c.handle, c.description, c.created_at,
+	(SELECT COUNT(*) FROM chat_members cm WHERE cm.chat_id = c.id)`
+
+func scanPublicChat(row pgx.Row) (dom.Chat, error) {
+	chat := dom.Chat{Type: dom.ChatTypeGroup, IsPublic: true}
+	err := row.Scan(&chat.ID, &chat.Title, &chat.Handle, &chat.Description, &chat.CreatedAt, &chat.MembersCount)
+	return chat, err
+}
+
+// CreatePublicChat stores a public chat with ownerID as its only member.
+func (c *ChatRepository) CreatePublicChat(ctx context.Context, ownerID int64, chat dom.Chat) (int64, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("repository: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var chatID int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO chats (title, is_private, is_public, handle, description)
+		VALUES ($1, FALSE, TRUE, $2, $3)
+		RETURNING id`, chat.Title, chat.Handle, chat.Description).Scan(&chatID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, customerrors.ErrHandleTaken
+		}
+		return 0, fmt.Errorf("repository: failed to insert chat: %w", err)
+	}
+	if err := c.addMembersTX(ctx, tx, chatID, ownerID, []int64{ownerID}); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("repository: failed to commit transaction: %w", err)
+	}
+	return chatID, nil
+}
+
+// GetPublicChat returns the chat if it is public, or ErrNotFound.
+func (c *ChatRepository) GetPublicChat(ctx context.Context, chatID int64) (dom.Chat, error) {
+	chat, err := scanPublicChat(c.pool.QueryRow(ctx,
+		"SELECT "+publicChatColumns+" FROM chats c WHERE c.id = $1 AND c.is_public", chatID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.Chat{}, customerrors.ErrNotFound
+		}
+		return dom.Chat{}, fmt.Errorf("repository: failed to select public chat: %w", err)
+	}
+	return chat, nil
+}
+
+// SearchPublicChats returns public chats whose title contains query or whose
+// handle starts with it, newest first. An empty query lists them all;
+// beforeID continues after the last chat of the previous page.
+func (c *ChatRepository) SearchPublicChats(ctx context.Context, query string, beforeID int64, limit int) ([]dom.Chat, error) {
+	pattern := escapeLike(strings.ToLower(query))
+	rows, err := c.pool.Query(ctx, `
+		SELECT `+publicChatColumns+`
+		FROM chats c
+		WHERE c.is_public
+		  AND ($1 = '' OR LOWER(c.title) LIKE '%' || $1 || '%' OR c.handle LIKE $1 || '%')
+		  AND ($2::bigint = 0 OR c.id < $2)
+		ORDER BY c.id DESC
+		LIMIT $3`, pattern, beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to search public chats: %w", err)
+	}
+	defer rows.Close()
+
+	chats := []dom.Chat{}
+	for rows.Next() {
+		chat, err := scanPublicChat(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan public chat: %w", err)
+		}
+		chats = append(chats, chat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+	return chats, nil
+}
+
+// escapeLike makes s match literally inside a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,69 @@
+package chat_repo_test
+
+import (
+	"context"
+	"testing"
+
+	"main/internal/database/postgres/chat_repo"
+	dbtest "main/internal/database/postgres/repositoryTest"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicChats(t *testing.T) {
+	ctx := context.Background()
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+	_, err := pool.Exec(ctx, `
+		INSERT INTO users (id, username, email, password_hash) VALUES
+		(1, 'alice', 'alice@example.com', 'hash')`)
+	require.NoError(t, err)
+	repo := chat_repo.NewChatRepository(pool, nil)
+
+	goID, err := repo.CreatePublicChat(ctx, 1, dom.Chat{Title: "Go news", Handle: "go_news", Description: "Releases"})
+	require.NoError(t, err)
+	rustID, err := repo.CreatePublicChat(ctx, 1, dom.Chat{Title: "Rust", Handle: "rustaceans"})
+	require.NoError(t, err)
+	_, err = repo.CreatePublicChat(ctx, 1, dom.Chat{Title: "Other", Handle: "go_news"})
+	assert.ErrorIs(t, err, customerrors.ErrHandleTaken)
+	privateID, err := repo.CreateChat(ctx, 1, "Go private", true, []int64{1})
+	require.NoError(t, err)
+
+	chat, err := repo.GetPublicChat(ctx, goID)
+	require.NoError(t, err)
+	assert.Equal(t, "go_news", chat.Handle)
+	assert.Equal(t, 1, chat.MembersCount)
+	role, err := repo.GetMemberRole(ctx, goID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, dom.ChatRoleOwner, role)
+
+	_, err = repo.GetPublicChat(ctx, privateID)
+	assert.ErrorIs(t, err, customerrors.ErrNotFound)
+
+	chats, err := repo.SearchPublicChats(ctx, "go", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, chats, 1)
+	assert.Equal(t, goID, chats[0].ID)
+
+	chats, err = repo.SearchPublicChats(ctx, "rust", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, chats, 1)
+	assert.Equal(t, rustID, chats[0].ID)
+
+	// "_" is matched literally, not as a wildcard.
+	chats, err = repo.SearchPublicChats(ctx, "go_", 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, chats, 1)
+
+	chats, err = repo.SearchPublicChats(ctx, "", 0, 1)
+	require.NoError(t, err)
+	require.Len(t, chats, 1)
+	assert.Equal(t, rustID, chats[0].ID)
+	chats, err = repo.SearchPublicChats(ctx, "", chats[0].ID, 1)
+	require.NoError(t, err)
+	require.Len(t, chats, 1)
+	assert.Equal(t, goID, chats[0].ID)
+}
@@ -0,0 +1,81 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const readStateColumns = `chat_id, user_id, COALESCE(last_read_message_id, ''), last_read_at, COALESCE(joined_at, to_timestamp(0))`
+
+func scanReadState(row pgx.Row) (dom.ReadState, error) {
+	var state dom.ReadState
+	err := row.Scan(&state.ChatID, &state.UserID, &state.LastReadMessageID, &state.LastReadAt, &state.JoinedAt)
+	return state, err
+}
+
+// MarkRead moves the read marker of the member up to messageID. The marker
+// never moves back: for an older message the current state is returned and
+// advanced is false. messageID must be a lowercase hex ObjectID, as made by
+// ObjectID.Hex: those sort like the IDs themselves, hence the byte-wise
+// comparison.
+func (c *ChatRepository) MarkRead(ctx context.Context, chatID, userID int64, messageID string) (dom.ReadState, bool, error) {
+	state, err := scanReadState(c.pool.QueryRow(ctx, `
+		UPDATE chat_members
+		SET last_read_message_id = $3, last_read_at = NOW()
+		WHERE chat_id = $1 AND user_id = $2
+		  AND (last_read_message_id IS NULL OR last_read_message_id COLLATE "C" < $3)
+		RETURNING `+readStateColumns, chatID, userID, messageID))
+	if err == nil {
+		return state, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return dom.ReadState{}, false, fmt.Errorf("repository: failed to update read marker: %w", err)
+	}
+
+	state, err = c.GetReadState(ctx, chatID, userID)
+	return state, false, err
+}
+
+// GetReadState returns the read marker of the member, or
+// ErrUserNotMemberOfChat.
+func (c *ChatRepository) GetReadState(ctx context.Context, chatID, userID int64) (dom.ReadState, error) {
+	state, err := scanReadState(c.pool.QueryRow(ctx, `
+		SELECT `+readStateColumns+`
+		FROM chat_members WHERE chat_id = $1 AND user_id = $2`, chatID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.ReadState{}, customerrors.ErrUserNotMemberOfChat
+		}
+		return dom.ReadState{}, fmt.Errorf("repository: failed to select read marker: %w", err)
+	}
+	return state, nil
+}
+
+// ListReadStates returns the read markers of the user in all their chats.
+func (c *ChatRepository) ListReadStates(ctx context.Context, userID int64) ([]dom.ReadState, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT `+readStateColumns+`
+		FROM chat_members WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to list read markers: %w", err)
+	}
+	defer rows.Close()
+
+	states := []dom.ReadState{}
+	for rows.Next() {
+		state, err := scanReadState(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository: failed to scan read marker: %w", err)
+		}
+		states = append(states, state)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+	return states, nil
+}
@@ -0,0 +1,63 @@
+package chat_repo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"main/internal/database/postgres/chat_repo"
+	dbtest "main/internal/database/postgres/repositoryTest"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMarkers(t *testing.T) {
+	ctx := context.Background()
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+	_, err := pool.Exec(ctx, `
+		INSERT INTO users (id, username, email, password_hash) VALUES
+		(1, 'alice', 'alice@example.com', 'hash'),
+		(2, 'bob', 'bob@example.com', 'hash')`)
+	require.NoError(t, err)
+	repo := chat_repo.NewChatRepository(pool, nil)
+
+	chatID, err := repo.CreateChat(ctx, 1, "Reads", false, []int64{1, 2})
+	require.NoError(t, err)
+
+	state, err := repo.GetReadState(ctx, chatID, 2)
+	require.NoError(t, err)
+	assert.Empty(t, state.LastReadMessageID)
+	assert.Nil(t, state.LastReadAt)
+
+	state, advanced, err := repo.MarkRead(ctx, chatID, 2, "651eb1234567890abcdef123")
+	require.NoError(t, err)
+	assert.True(t, advanced)
+	assert.Equal(t, "651eb1234567890abcdef123", state.LastReadMessageID)
+	assert.NotNil(t, state.LastReadAt)
+
+	// An older message does not move the marker back.
+	state, advanced, err = repo.MarkRead(ctx, chatID, 2, "651eb1234567890abcdef000")
+	require.NoError(t, err)
+	assert.False(t, advanced)
+	assert.Equal(t, "651eb1234567890abcdef123", state.LastReadMessageID)
+
+	_, advanced, err = repo.MarkRead(ctx, chatID, 2, "651eb1234567890abcdefabc")
+	require.NoError(t, err)
+	assert.True(t, advanced)
+
+	_, _, err = repo.MarkRead(ctx, chatID+1, 2, "651eb1234567890abcdefabc")
+	assert.ErrorIs(t, err, customerrors.ErrUserNotMemberOfChat)
+
+	states, err := repo.ListReadStates(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, chatID, states[0].ChatID)
+
+	chats, err := repo.ListOfChats(ctx, 2, time.Time{}, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, chats, 1)
+	assert.Equal(t, chatID, chats[0].ID)
+}
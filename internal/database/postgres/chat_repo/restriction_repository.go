@@ -0,0 +1,107 @@
+package chat_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// RestrictMember stores the restrictions, replacing the expiry of existing
+// ones of the same kind. Either all of them are stored or none. The users
+// must be members.
+func (c *ChatRepository) RestrictMember(ctx context.Context, restrictions []dom.MemberRestriction) error {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repository: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, r := range restrictions {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO chat_member_restrictions (chat_id, user_id, restriction, restricted_by, expires_at)
+			VALUES ($1, $2, $3, NULLIF($4, 0), $5)
+			ON CONFLICT (chat_id, user_id, restriction) DO UPDATE
+			SET restricted_by = EXCLUDED.restricted_by, expires_at = EXCLUDED.expires_at, created_at = NOW()`,
+			r.ChatID, r.UserID, r.Restriction, r.RestrictedBy, r.ExpiresAt)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return customerrors.ErrUserNotMemberOfChat
+			}
+			return fmt.Errorf("repository: failed to insert restriction: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repository: failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// LiftRestrictions deletes the member's restrictions of the given kinds, or
+// all of them if none are given.
+func (c *ChatRepository) LiftRestrictions(ctx context.Context, chatID, userID int64, restrictions []string) (int64, error) {
+	tag, err := c.pool.Exec(ctx, `
+		DELETE FROM chat_member_restrictions
+		WHERE chat_id = $1 AND user_id = $2
+		  AND (COALESCE(cardinality($3::text[]), 0) = 0 OR restriction = ANY($3))`,
+		chatID, userID, restrictions)
+	if err != nil {
+		return 0, fmt.Errorf("repository: failed to delete restrictions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListRestrictions returns the restrictions in force in the chat.
+func (c *ChatRepository) ListRestrictions(ctx context.Context, chatID int64) ([]dom.MemberRestriction, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT chat_id, user_id, restriction, COALESCE(restricted_by, 0), expires_at, created_at
+		FROM chat_member_restrictions
+		WHERE chat_id = $1 AND expires_at > NOW()
+		ORDER BY user_id, restriction`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("repository: failed to list restrictions: %w", err)
+	}
+	defer rows.Close()
+
+	restrictions := []dom.MemberRestriction{}
+	for rows.Next() {
+		var r dom.MemberRestriction
+		if err := rows.Scan(&r.ChatID, &r.UserID, &r.Restriction, &r.RestrictedBy, &r.ExpiresAt, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("repository: failed to scan restriction: %w", err)
+		}
+		restrictions = append(restrictions, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository: rows iteration error: %w", err)
+	}
+	return restrictions, nil
+}
+
+// IsRestricted reports whether the member currently has the restriction.
+// Expired rows that the sweeper has not removed yet do not count.
+func (c *ChatRepository) IsRestricted(ctx context.Context, chatID, userID int64, restriction string) (bool, error) {
+	var restricted bool
+	err := c.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM chat_member_restrictions
+			WHERE chat_id = $1 AND user_id = $2 AND restriction = $3 AND expires_at > NOW()
+		)`, chatID, userID, restriction).Scan(&restricted)
+	if err != nil {
+		return false, fmt.Errorf("repository: failed to check restriction: %w", err)
+	}
+	return restricted, nil
+}
+
+// DeleteExpiredRestrictions removes restrictions past their expiry and
+// returns how many there were.
+func (c *ChatRepository) DeleteExpiredRestrictions(ctx context.Context) (int64, error) {
+	tag, err := c.pool.Exec(ctx, "DELETE FROM chat_member_restrictions WHERE expires_at <= NOW()")
+	if err != nil {
+		return 0, fmt.Errorf("repository: failed to delete expired restrictions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
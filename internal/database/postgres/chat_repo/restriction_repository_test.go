@@ -0,0 +1,74 @@
+package chat_repo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"main/internal/database/postgres/chat_repo"
+	dbtest "main/internal/database/postgres/repositoryTest"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemberRestrictions(t *testing.T) {
+	ctx := context.Background()
+	pool, teardown := dbtest.SetupTestDB(t)
+	defer teardown()
+	_, err := pool.Exec(ctx, `
+		INSERT INTO users (id, username, email, password_hash) VALUES
+		(1, 'alice', 'alice@example.com', 'hash'),
+		(2, 'bob', 'bob@example.com', 'hash'),
+		(3, 'carol', 'carol@example.com', 'hash')`)
+	require.NoError(t, err)
+	repo := chat_repo.NewChatRepository(pool, nil)
+
+	chatID, err := repo.CreateChat(ctx, 1, "Mutes", false, []int64{1, 2})
+	require.NoError(t, err)
+
+	hour := time.Now().Add(time.Hour)
+	require.NoError(t, repo.RestrictMember(ctx, []dom.MemberRestriction{
+		{ChatID: chatID, UserID: 2, Restriction: dom.RestrictSend, RestrictedBy: 1, ExpiresAt: hour},
+		{ChatID: chatID, UserID: 2, Restriction: dom.RestrictEdit, RestrictedBy: 1, ExpiresAt: time.Now().Add(-time.Minute)},
+	}))
+	// A failing restriction takes the others down with it.
+	err = repo.RestrictMember(ctx, []dom.MemberRestriction{
+		{ChatID: chatID, UserID: 2, Restriction: dom.RestrictAddMembers, ExpiresAt: hour},
+		{ChatID: chatID, UserID: 3, Restriction: dom.RestrictSend, ExpiresAt: hour},
+	})
+	assert.ErrorIs(t, err, customerrors.ErrUserNotMemberOfChat)
+	restricted, err := repo.IsRestricted(ctx, chatID, 2, dom.RestrictAddMembers)
+	require.NoError(t, err)
+	assert.False(t, restricted)
+
+	restricted, err = repo.IsRestricted(ctx, chatID, 2, dom.RestrictSend)
+	require.NoError(t, err)
+	assert.True(t, restricted)
+	restricted, err = repo.IsRestricted(ctx, chatID, 2, dom.RestrictEdit)
+	require.NoError(t, err)
+	assert.False(t, restricted, "expired restrictions stop applying before the sweep")
+
+	list, err := repo.ListRestrictions(ctx, chatID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, dom.RestrictSend, list[0].Restriction)
+
+	swept, err := repo.DeleteExpiredRestrictions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), swept)
+
+	lifted, err := repo.LiftRestrictions(ctx, chatID, 2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), lifted)
+
+	// Restrictions go with the membership.
+	require.NoError(t, repo.RestrictMember(ctx, []dom.MemberRestriction{
+		{ChatID: chatID, UserID: 2, Restriction: dom.RestrictSend, ExpiresAt: hour}}))
+	require.NoError(t, repo.RemoveMember(ctx, chatID, 2))
+	var left int
+	require.NoError(t, pool.QueryRow(ctx, "SELECT COUNT(*) FROM chat_member_restrictions").Scan(&left))
+	assert.Zero(t, left)
+}
@@ -0,0 +1,168 @@
+package export_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const exportColumns = `id::text, user_id, status, COALESCE(error, ''), COALESCE(size_bytes, 0),
+	created_at, completed_at, expires_at`
+
+type ExportRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewExportRepository(pool *pgxpool.Pool) *ExportRepository {
+	return &ExportRepository{
+		pool: pool,
+	}
+}
+
+// CreateExport queues an export for the user. If one is already pending or
+// running, that one is returned instead.
+func (r *ExportRepository) CreateExport(ctx context.Context, userID int64) (dom.DataExport, error) {
+	export, err := scanExport(r.pool.QueryRow(ctx, `
+		INSERT INTO data_exports (id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) WHERE status IN ('pending', 'running') DO NOTHING
+		RETURNING `+exportColumns, uuid.NewString(), userID))
+	if err == nil {
+		return export, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return dom.DataExport{}, fmt.Errorf("repo: create export: %w", err)
+	}
+
+	export, err = scanExport(r.pool.QueryRow(ctx, `
+		SELECT `+exportColumns+` FROM data_exports
+		WHERE user_id = $1 AND status IN ('pending', 'running')`, userID))
+	if err != nil {
+		return dom.DataExport{}, fmt.Errorf("repo: create export: active export: %w", err)
+	}
+	return export, nil
+}
+
+func (r *ExportRepository) GetExport(ctx context.Context, exportID string) (dom.DataExport, error) {
+	if _, err := uuid.Parse(exportID); err != nil {
+		return dom.DataExport{}, customerrors.ErrNotFound
+	}
+	export, err := scanExport(r.pool.QueryRow(ctx,
+		"SELECT "+exportColumns+" FROM data_exports WHERE id = $1", exportID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.DataExport{}, customerrors.ErrNotFound
+		}
+		return dom.DataExport{}, fmt.Errorf("repo: get export: %w", err)
+	}
+	return export, nil
+}
+
+// ListExports returns the exports of the user, newest first.
+func (r *ExportRepository) ListExports(ctx context.Context, userID int64) ([]dom.DataExport, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+exportColumns+` FROM data_exports
+		WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repo: list exports: %w", err)
+	}
+	defer rows.Close()
+
+	exports := []dom.DataExport{}
+	for rows.Next() {
+		export, err := scanExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repo: list exports: scan: %w", err)
+		}
+		exports = append(exports, export)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: list exports: %w", err)
+	}
+	return exports, nil
+}
+
+// ClaimExport marks the oldest pending export as running and returns it, or
+// ErrNotFound if there is nothing to do. Exports left running for longer
+// than stale, by an instance that died, are claimed again.
+func (r *ExportRepository) ClaimExport(ctx context.Context, stale time.Duration) (dom.DataExport, error) {
+	export, err := scanExport(r.pool.QueryRow(ctx, `
+		UPDATE data_exports SET status = 'running', started_at = NOW()
+		WHERE id = (
+			SELECT id FROM data_exports
+			WHERE status = 'pending' OR (status = 'running' AND started_at < NOW() - $1::bigint * INTERVAL '1 second')
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+exportColumns, int64(stale.Seconds())))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.DataExport{}, customerrors.ErrNotFound
+		}
+		return dom.DataExport{}, fmt.Errorf("repo: claim export: %w", err)
+	}
+	return export, nil
+}
+
+func (r *ExportRepository) CompleteExport(ctx context.Context, exportID string, size int64, expiresAt time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE data_exports
+		SET status = 'ready', size_bytes = $2, completed_at = NOW(), expires_at = $3
+		WHERE id = $1`, exportID, size, expiresAt)
+	if err != nil {
+		return fmt.Errorf("repo: complete export: %w", err)
+	}
+	return nil
+}
+
+func (r *ExportRepository) FailExport(ctx context.Context, exportID, reason string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE data_exports SET status = 'failed', error = $2, completed_at = NOW()
+		WHERE id = $1`, exportID, reason)
+	if err != nil {
+		return fmt.Errorf("repo: fail export: %w", err)
+	}
+	return nil
+}
+
+// ExpireExports marks ready exports past their expiry as expired and returns
+// their IDs, so their archives can be removed.
+func (r *ExportRepository) ExpireExports(ctx context.Context) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE data_exports SET status = 'expired'
+		WHERE status = 'ready' AND expires_at <= NOW()
+		RETURNING id::text`)
+	if err != nil {
+		return nil, fmt.Errorf("repo: expire exports: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("repo: expire exports: scan: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: expire exports: %w", err)
+	}
+	return ids, nil
+}
+
+func scanExport(row pgx.Row) (dom.DataExport, error) {
+	var e dom.DataExport
+	err := row.Scan(&e.ID, &e.UserID, &e.Status, &e.Error, &e.SizeBytes,
+		&e.CreatedAt, &e.CompletedAt, &e.ExpiresAt)
+	return e, err
+}
@@ -0,0 +1,64 @@
+package export_repo_test
+
+import (
+	"context"
+	"main/internal/database/postgres/export_repo"
+	repositoryT "main/internal/database/postgres/repositoryTest"
+	"main/internal/database/postgres/user_repo"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportQueue(t *testing.T) {
+	pool, teardown := repositoryT.SetupTestDB(t)
+	defer teardown()
+
+	users := user_repo.NewUserRepository(pool)
+	repo := export_repo.NewExportRepository(pool)
+	ctx := context.Background()
+
+	user, err := users.RegisterUser(ctx, "exporter", "exporter@example.com", "hash")
+	require.NoError(t, err)
+
+	export, err := repo.CreateExport(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, dom.ExportPending, export.Status)
+
+	again, err := repo.CreateExport(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, export.ID, again.ID, "one active export per user")
+
+	claimed, err := repo.ClaimExport(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, export.ID, claimed.ID)
+	assert.Equal(t, dom.ExportRunning, claimed.Status)
+
+	_, err = repo.ClaimExport(ctx, time.Hour)
+	assert.ErrorIs(t, err, customerrors.ErrNotFound)
+
+	require.NoError(t, repo.CompleteExport(ctx, export.ID, 42, time.Now().Add(-time.Second)))
+	got, err := repo.GetExport(ctx, export.ID)
+	require.NoError(t, err)
+	assert.Equal(t, dom.ExportReady, got.Status)
+	assert.Equal(t, int64(42), got.SizeBytes)
+
+	expired, err := repo.ExpireExports(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{export.ID}, expired)
+
+	next, err := repo.CreateExport(ctx, user.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, export.ID, next.ID)
+
+	list, err := repo.ListExports(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	_, err = repo.GetExport(ctx, "not-a-uuid")
+	assert.ErrorIs(t, err, customerrors.ErrNotFound)
+}
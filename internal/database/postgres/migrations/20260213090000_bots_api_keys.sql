@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Bots are users owned by a human. They have no password and sign in with
+-- API keys only.
+ALTER TABLE users ADD COLUMN bot_owner_id BIGINT REFERENCES users(id) ON DELETE CASCADE;
+CREATE INDEX idx_users_bot_owner ON users(bot_owner_id) WHERE bot_owner_id IS NOT NULL;
+
+CREATE TABLE api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    bot_id BIGINT NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    FOREIGN KEY (bot_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_api_keys_bot ON api_keys(bot_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_keys;
+ALTER TABLE users DROP COLUMN IF EXISTS bot_owner_id;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The first admin is promoted by hand:
+-- UPDATE users SET role = 'admin' WHERE username = '...';
+ALTER TABLE users ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'moderator', 'admin'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- actor_id has no foreign key: the trail outlives the accounts it mentions.
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    actor_id BIGINT,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32),
+    target_id VARCHAR(255),
+    ip VARCHAR(64),
+    user_agent TEXT,
+    request_id VARCHAR(128),
+    metadata JSONB NOT NULL DEFAULT '{}'
+);
+CREATE INDEX idx_audit_events_actor ON audit_events(actor_id, id DESC);
+CREATE INDEX idx_audit_events_action ON audit_events(action, id DESC);
+CREATE INDEX idx_audit_events_target ON audit_events(target_type, target_id, id DESC);
+CREATE INDEX idx_audit_events_occurred_at ON audit_events(occurred_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_events;
+-- +goose StatementEnd
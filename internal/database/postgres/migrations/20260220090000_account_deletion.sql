@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- deletion_scheduled_at is when a requested account deletion is carried
+-- out; the account can be restored until then.
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMPTZ;
+CREATE INDEX idx_users_deletion_scheduled_at ON users(deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_scheduled_at;
+-- +goose StatementEnd
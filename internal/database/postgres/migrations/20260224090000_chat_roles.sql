@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chat_members ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'member'
+    CHECK (role IN ('owner', 'admin', 'member'));
+
+-- Chats created before roles existed are owned by their earliest member.
+UPDATE chat_members cm SET role = 'owner'
+FROM (
+    SELECT DISTINCT ON (chat_id) id
+    FROM chat_members
+    ORDER BY chat_id, joined_at, id
+) first
+WHERE cm.id = first.id;
+
+CREATE UNIQUE INDEX chat_members_one_owner_idx ON chat_members (chat_id) WHERE role = 'owner';
+
+ALTER TABLE chats ADD COLUMN pinned_message_id VARCHAR(24);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chats DROP COLUMN IF EXISTS pinned_message_id;
+DROP INDEX IF EXISTS chat_members_one_owner_idx;
+ALTER TABLE chat_members DROP COLUMN IF EXISTS role;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chats ADD COLUMN type VARCHAR(16) NOT NULL DEFAULT 'group'
+    CHECK (type IN ('group', 'direct'));
+
+-- One direct chat per pair of users, stored with the smaller id first.
+CREATE TABLE direct_chats (
+    chat_id BIGINT PRIMARY KEY REFERENCES chats(id) ON DELETE CASCADE,
+    user_low BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_high BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    CHECK (user_low < user_high),
+    UNIQUE (user_low, user_high)
+);
+
+CREATE INDEX direct_chats_user_high_idx ON direct_chats (user_high);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS direct_chats;
+ALTER TABLE chats DROP COLUMN IF EXISTS type;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chats ADD COLUMN is_public BOOLEAN NOT NULL DEFAULT FALSE;
+-- Handles are stored lowercase without the leading @.
+ALTER TABLE chats ADD COLUMN handle VARCHAR(32) UNIQUE;
+ALTER TABLE chats ADD COLUMN description VARCHAR(512) NOT NULL DEFAULT '';
+ALTER TABLE chats ADD CONSTRAINT chats_public_handle_check CHECK (NOT is_public OR handle IS NOT NULL);
+
+CREATE INDEX chats_public_idx ON chats (id) WHERE is_public;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS chats_public_idx;
+ALTER TABLE chats DROP CONSTRAINT IF EXISTS chats_public_handle_check;
+ALTER TABLE chats DROP COLUMN IF EXISTS description;
+ALTER TABLE chats DROP COLUMN IF EXISTS handle;
+ALTER TABLE chats DROP COLUMN IF EXISTS is_public;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A reference to the avatar in the media store, not the image itself.
+ALTER TABLE chats ADD COLUMN avatar_url VARCHAR(512) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chats DROP COLUMN IF EXISTS avatar_url;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Users banned from a chat cannot be added back or join until the ban is
+-- lifted or expires_at passes. A NULL expires_at bans for good.
+CREATE TABLE chat_bans (
+    chat_id BIGINT NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    banned_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    reason VARCHAR(512) NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (chat_id, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chat_bans;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Temporary limits on what a member may do in a chat. Rows go away with the
+-- membership and are swept once expired.
+CREATE TABLE chat_member_restrictions (
+    chat_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    restriction VARCHAR(16) NOT NULL CHECK (restriction IN ('send', 'edit', 'add_members')),
+    restricted_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (chat_id, user_id, restriction),
+    FOREIGN KEY (chat_id, user_id) REFERENCES chat_members(chat_id, user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX chat_member_restrictions_expires_at_idx ON chat_member_restrictions (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chat_member_restrictions;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Requests to join private chats. Decided requests are kept for the record;
+-- a user has at most one pending request per chat. Redemptions of invites
+-- that need approval are filed here too and remember the invite, so admins
+-- review a single queue.
+CREATE TABLE chat_join_requests (
+    id BIGSERIAL PRIMARY KEY,
+    chat_id BIGINT NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invite_id BIGINT REFERENCES chat_invites(id) ON DELETE SET NULL,
+    message VARCHAR(512) NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    decided_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX chat_join_requests_pending_idx ON chat_join_requests (chat_id, user_id) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chat_join_requests;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- last_read_message_id is the hex ObjectID of the newest message the member
+-- has read; last_read_at is when they read it.
+ALTER TABLE chat_members ADD COLUMN last_read_message_id VARCHAR(24);
+ALTER TABLE chat_members ADD COLUMN last_read_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chat_members DROP COLUMN IF EXISTS last_read_at;
+ALTER TABLE chat_members DROP COLUMN IF EXISTS last_read_message_id;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chats ADD COLUMN last_message_sender_id BIGINT REFERENCES users(id) ON DELETE SET NULL;
+
+-- Per-member preferences shown in the chat list.
+ALTER TABLE chat_members ADD COLUMN is_pinned BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE chat_members ADD COLUMN is_muted BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX chat_members_user_idx ON chat_members (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS chat_members_user_idx;
+ALTER TABLE chat_members DROP COLUMN IF EXISTS is_muted;
+ALTER TABLE chat_members DROP COLUMN IF EXISTS is_pinned;
+ALTER TABLE chats DROP COLUMN IF EXISTS last_message_sender_id;
+-- +goose StatementEnd
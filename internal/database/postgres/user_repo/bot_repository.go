@@ -0,0 +1,151 @@
+package user_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// botEmailDomain gives bots a unique address that can never receive mail.
+const botEmailDomain = "bots.invalid"
+
+// CreateBot creates a bot user owned by ownerID. Bots have no password and a
+// placeholder email that counts as verified, so they can post messages.
+func (r *UserRepository) CreateBot(ctx context.Context, ownerID int64, username string) (dom.Bot, error) {
+	if r.CheckUsernameExists(ctx, username) {
+		return dom.Bot{}, customerrors.ErrUsernameAlreadyExists
+	}
+
+	bot := dom.Bot{Username: username, OwnerID: ownerID}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO users (username, email, password_hash, email_verified_at, bot_owner_id)
+		VALUES ($1, $1 || '@' || $2, '', NOW(), $3)
+		RETURNING id`,
+		username, botEmailDomain, ownerID).Scan(&bot.ID)
+	if err != nil {
+		return dom.Bot{}, fmt.Errorf("repo: create bot: %w", err)
+	}
+	return bot, nil
+}
+
+func (r *UserRepository) ListBots(ctx context.Context, ownerID int64) ([]dom.Bot, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT id, username, bot_owner_id FROM users WHERE bot_owner_id=$1 ORDER BY id", ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("repo: list bots: %w", err)
+	}
+	defer rows.Close()
+
+	bots := []dom.Bot{}
+	for rows.Next() {
+		var bot dom.Bot
+		if err := rows.Scan(&bot.ID, &bot.Username, &bot.OwnerID); err != nil {
+			return nil, fmt.Errorf("repo: list bots: scan: %w", err)
+		}
+		bots = append(bots, bot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: list bots: %w", err)
+	}
+	return bots, nil
+}
+
+// GetBot returns the bot if it is owned by ownerID, and ErrNotFound otherwise.
+func (r *UserRepository) GetBot(ctx context.Context, ownerID, botID int64) (dom.Bot, error) {
+	bot := dom.Bot{ID: botID}
+	err := r.pool.QueryRow(ctx,
+		"SELECT username, bot_owner_id FROM users WHERE id=$1 AND bot_owner_id=$2", botID, ownerID).
+		Scan(&bot.Username, &bot.OwnerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.Bot{}, customerrors.ErrNotFound
+		}
+		return dom.Bot{}, fmt.Errorf("repo: get bot: %w", err)
+	}
+	return bot, nil
+}
+
+// IsBot reports whether the user is a bot.
+func (r *UserRepository) IsBot(ctx context.Context, userID int64) (bool, error) {
+	var isBot bool
+	err := r.pool.QueryRow(ctx,
+		"SELECT bot_owner_id IS NOT NULL FROM users WHERE id=$1", userID).Scan(&isBot)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, customerrors.ErrUserNotFound
+		}
+		return false, fmt.Errorf("repo: is bot: %w", err)
+	}
+	return isBot, nil
+}
+
+func (r *UserRepository) CreateAPIKey(ctx context.Context, key dom.APIKey, keyHash string) (dom.APIKey, error) {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO api_keys (bot_id, name, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`,
+		key.BotID, key.Name, key.Prefix, keyHash, key.Scopes, key.ExpiresAt).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return dom.APIKey{}, fmt.Errorf("repo: create api key: %w", err)
+	}
+	return key, nil
+}
+
+func (r *UserRepository) ListAPIKeys(ctx context.Context, botID int64) ([]dom.APIKey, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, bot_id, name, prefix, scopes, created_at, expires_at, last_used_at, revoked_at
+		FROM api_keys WHERE bot_id=$1 ORDER BY id`, botID)
+	if err != nil {
+		return nil, fmt.Errorf("repo: list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []dom.APIKey{}
+	for rows.Next() {
+		var k dom.APIKey
+		if err := rows.Scan(&k.ID, &k.BotID, &k.Name, &k.Prefix, &k.Scopes,
+			&k.CreatedAt, &k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt); err != nil {
+			return nil, fmt.Errorf("repo: list api keys: scan: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *UserRepository) RevokeAPIKey(ctx context.Context, botID, keyID int64) error {
+	tag, err := r.pool.Exec(ctx,
+		"UPDATE api_keys SET revoked_at=NOW() WHERE id=$1 AND bot_id=$2 AND revoked_at IS NULL", keyID, botID)
+	if err != nil {
+		return fmt.Errorf("repo: revoke api key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
+// UseAPIKey returns the live key with the given hash and records its use.
+// Revoked, expired and unknown keys all give ErrInvalidAPIKey.
+func (r *UserRepository) UseAPIKey(ctx context.Context, keyHash string) (dom.APIKey, error) {
+	var k dom.APIKey
+	err := r.pool.QueryRow(ctx, `
+		UPDATE api_keys SET last_used_at=NOW()
+		WHERE key_hash=$1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at>NOW())
+		RETURNING id, bot_id, name, prefix, scopes, created_at, expires_at, last_used_at`,
+		keyHash).Scan(&k.ID, &k.BotID, &k.Name, &k.Prefix, &k.Scopes, &k.CreatedAt, &k.ExpiresAt, &k.LastUsedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.APIKey{}, customerrors.ErrInvalidAPIKey
+		}
+		return dom.APIKey{}, fmt.Errorf("repo: use api key: %w", err)
+	}
+	return k, nil
+}
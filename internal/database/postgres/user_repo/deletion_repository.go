@@ -0,0 +1,139 @@
+package user_repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"main/pkg/customerrors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ScheduleDeletion schedules the deletion of the user at the given time and
+// returns the time it is scheduled for. An earlier request is kept.
+func (r *UserRepository) ScheduleDeletion(ctx context.Context, userID int64, at time.Time) (time.Time, error) {
+	var scheduled time.Time
+	err := r.pool.QueryRow(ctx, `
+		UPDATE users SET deletion_scheduled_at = COALESCE(deletion_scheduled_at, $2)
+		WHERE id = $1 AND bot_owner_id IS NULL
+		RETURNING deletion_scheduled_at`, userID, at).Scan(&scheduled)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, customerrors.ErrUserNotFound
+		}
+		return time.Time{}, fmt.Errorf("repo: schedule deletion: %w", err)
+	}
+	return scheduled, nil
+}
+
+// CancelDeletion cancels a scheduled deletion that is not due yet, and
+// returns ErrNotFound when there is none.
+func (r *UserRepository) CancelDeletion(ctx context.Context, userID int64) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE users SET deletion_scheduled_at = NULL
+		WHERE id = $1 AND deletion_scheduled_at > NOW()`, userID)
+	if err != nil {
+		return fmt.Errorf("repo: cancel deletion: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
+// ListDueDeletions returns up to limit users whose deletion is due, the
+// longest overdue first.
+func (r *UserRepository) ListDueDeletions(ctx context.Context, limit int) ([]int64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id FROM users
+		WHERE deletion_scheduled_at <= NOW()
+		ORDER BY deletion_scheduled_at
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repo: list due deletions: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("repo: list due deletions: scan: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: list due deletions: %w", err)
+	}
+	return ids, nil
+}
+
+// DeleteUser deletes a user whose deletion is due. Memberships, sessions,
+// refresh tokens, identities and owned bots go with it through ON DELETE
+// CASCADE. Groups owned by the user or their bots pass to the oldest admin,
+// else the oldest member; groups nobody else is left in are deleted.
+func (r *UserRepository) DeleteUser(ctx context.Context, userID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repo: delete user: begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var id int64
+	err = tx.QueryRow(ctx, `
+		SELECT id FROM users
+		WHERE id = $1 AND deletion_scheduled_at <= NOW()
+		FOR UPDATE`, userID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return customerrors.ErrUserNotFound
+		}
+		return fmt.Errorf("repo: delete user: %w", err)
+	}
+
+	// The owner row goes first, as a chat has at most one owner.
+	rows, err := tx.Query(ctx, `
+		DELETE FROM chat_members
+		WHERE role = 'owner'
+		  AND user_id IN (SELECT id FROM users WHERE id = $1 OR bot_owner_id = $1)
+		RETURNING chat_id`, userID)
+	if err != nil {
+		return fmt.Errorf("repo: delete user: release ownership: %w", err)
+	}
+	chatIDs, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return fmt.Errorf("repo: delete user: release ownership: %w", err)
+	}
+
+	if len(chatIDs) > 0 {
+		_, err = tx.Exec(ctx, `
+			UPDATE chat_members SET role = 'owner'
+			WHERE id IN (
+				SELECT DISTINCT ON (chat_id) id FROM chat_members
+				WHERE chat_id = ANY($1)
+				  AND user_id NOT IN (SELECT id FROM users WHERE bot_owner_id = $2)
+				ORDER BY chat_id, role = 'admin' DESC, joined_at, id
+			)`, chatIDs, userID)
+		if err != nil {
+			return fmt.Errorf("repo: delete user: promote successors: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			DELETE FROM chats
+			WHERE id = ANY($1)
+			  AND NOT EXISTS (SELECT 1 FROM chat_members WHERE chat_id = chats.id AND role = 'owner')`, chatIDs)
+		if err != nil {
+			return fmt.Errorf("repo: delete user: delete abandoned chats: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM users WHERE id = $1", userID); err != nil {
+		return fmt.Errorf("repo: delete user: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repo: delete user: commit: %w", err)
+	}
+	return nil
+}
@@ -2,9 +2,12 @@ package user_repo
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	dom "main/internal/domain/entity"
 	"main/pkg/customerrors"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -41,17 +44,16 @@ func (r *UserRepository) RegisterUser(
 		return dom.User{}, customerrors.ErrEmailAlreadyExists
 	}
 
-	tag, err := r.pool.Exec(ctx,
-		"INSERT INTO users (email, password_hash, username) VALUES ($1, $2, $3);",
-		email, passwordHash, username)
+	var userID int64
+	err := r.pool.QueryRow(ctx,
+		"INSERT INTO users (email, password_hash, username) VALUES ($1, $2, $3) RETURNING id;",
+		email, passwordHash, username).Scan(&userID)
 	if err != nil {
 		return dom.User{}, err
 	}
 
-	if tag.RowsAffected() == 0 {
-		return dom.User{}, err
-	}
 	userRes = dom.User{
+		ID:       userID,
 		Username: username,
 		Email:    email,
 	}
@@ -59,6 +61,58 @@ func (r *UserRepository) RegisterUser(
 	return userRes, nil
 }
 
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (dom.User, error) {
+	var user dom.User
+	err := r.pool.QueryRow(ctx,
+		"SELECT id, username, email, role, email_verified_at IS NOT NULL FROM users WHERE email=$1", email).
+		Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.EmailVerified)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.User{}, customerrors.ErrUserNotFound
+		}
+		return dom.User{}, fmt.Errorf("repo: get user by email: %w", err)
+	}
+	return user, nil
+}
+
+func (r *UserRepository) GetUserByID(ctx context.Context, userID int64) (dom.User, error) {
+	var user dom.User
+	err := r.pool.QueryRow(ctx,
+		"SELECT id, username, email, role, email_verified_at IS NOT NULL FROM users WHERE id=$1", userID).
+		Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.EmailVerified)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dom.User{}, customerrors.ErrUserNotFound
+		}
+		return dom.User{}, fmt.Errorf("repo: get user by id: %w", err)
+	}
+	return user, nil
+}
+
+func (r *UserRepository) SetUserRole(ctx context.Context, userID int64, role string) error {
+	tag, err := r.pool.Exec(ctx, "UPDATE users SET role=$2 WHERE id=$1", userID, role)
+	if err != nil {
+		return fmt.Errorf("repo: set user role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return customerrors.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) IsEmailVerified(ctx context.Context, userID int64) (bool, error) {
+	var verified bool
+	err := r.pool.QueryRow(ctx,
+		"SELECT email_verified_at IS NOT NULL FROM users WHERE id=$1", userID).Scan(&verified)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, customerrors.ErrUserNotFound
+		}
+		return false, fmt.Errorf("repo: is email verified: %w", err)
+	}
+	return verified, nil
+}
+
 func (r *UserRepository) SearchUser(ctx context.Context, q string) ([]dom.User, error) {
 	var users []dom.User
 	rows, err := r.pool.Query(ctx,
@@ -4,8 +4,10 @@ import (
 	"context"
 	repositoryT "main/internal/database/postgres/repositoryTest"
 	"main/internal/database/postgres/user_repo"
+	dom "main/internal/domain/entity"
 	"main/pkg/customerrors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -167,3 +169,155 @@ func TestSearchUser(t *testing.T) {
 		})
 	}
 }
+
+func TestBotAPIKeys(t *testing.T) {
+	pool, teardown := repositoryT.SetupTestDB(t)
+	defer teardown()
+
+	repo := user_repo.NewUserRepository(pool)
+	ctx := context.Background()
+
+	owner, err := repo.RegisterUser(ctx, "owner", "owner@example.com", "hash")
+	require.NoError(t, err)
+
+	bot, err := repo.CreateBot(ctx, owner.ID, "ci-bot")
+	require.NoError(t, err)
+
+	isBot, err := repo.IsBot(ctx, bot.ID)
+	require.NoError(t, err)
+	assert.True(t, isBot)
+	verified, err := repo.IsEmailVerified(ctx, bot.ID)
+	require.NoError(t, err)
+	assert.True(t, verified)
+
+	_, err = repo.GetBot(ctx, bot.ID, bot.ID)
+	assert.ErrorIs(t, err, customerrors.ErrNotFound, "only the owner sees the bot")
+
+	expired := time.Now().Add(-time.Minute)
+	_, err = repo.CreateAPIKey(ctx, dom.APIKey{BotID: bot.ID, Name: "old", Prefix: "bot_old", ExpiresAt: &expired}, "hash-old")
+	require.NoError(t, err)
+	key, err := repo.CreateAPIKey(ctx, dom.APIKey{BotID: bot.ID, Name: "ci", Prefix: "bot_new", Scopes: []string{"messages:write:1"}}, "hash-new")
+	require.NoError(t, err)
+
+	_, err = repo.UseAPIKey(ctx, "hash-old")
+	assert.ErrorIs(t, err, customerrors.ErrInvalidAPIKey)
+
+	used, err := repo.UseAPIKey(ctx, "hash-new")
+	require.NoError(t, err)
+	assert.Equal(t, bot.ID, used.BotID)
+	assert.Equal(t, []string{"messages:write:1"}, used.Scopes)
+	assert.NotNil(t, used.LastUsedAt)
+
+	require.NoError(t, repo.RevokeAPIKey(ctx, bot.ID, key.ID))
+	assert.ErrorIs(t, repo.RevokeAPIKey(ctx, bot.ID, key.ID), customerrors.ErrNotFound)
+	_, err = repo.UseAPIKey(ctx, "hash-new")
+	assert.ErrorIs(t, err, customerrors.ErrInvalidAPIKey)
+
+	keys, err := repo.ListAPIKeys(ctx, bot.ID)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+}
+
+func TestSetUserRole(t *testing.T) {
+	pool, teardown := repositoryT.SetupTestDB(t)
+	defer teardown()
+
+	repo := user_repo.NewUserRepository(pool)
+	ctx := context.Background()
+
+	user, err := repo.RegisterUser(ctx, "staff", "staff@example.com", "hash")
+	require.NoError(t, err)
+
+	got, err := repo.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "user", got.Role)
+
+	require.NoError(t, repo.SetUserRole(ctx, user.ID, "moderator"))
+	got, err = repo.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "moderator", got.Role)
+
+	assert.Error(t, repo.SetUserRole(ctx, user.ID, "root"), "rejected by the check constraint")
+	assert.ErrorIs(t, repo.SetUserRole(ctx, user.ID+100, "admin"), customerrors.ErrUserNotFound)
+}
+
+func TestAccountDeletion(t *testing.T) {
+	pool, teardown := repositoryT.SetupTestDB(t)
+	defer teardown()
+
+	repo := user_repo.NewUserRepository(pool)
+	ctx := context.Background()
+
+	user, err := repo.RegisterUser(ctx, "leaving", "leaving@example.com", "hash")
+	require.NoError(t, err)
+
+	later := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	scheduled, err := repo.ScheduleDeletion(ctx, user.ID, later)
+	require.NoError(t, err)
+	assert.True(t, scheduled.Equal(later))
+
+	again, err := repo.ScheduleDeletion(ctx, user.ID, later.Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, again.Equal(later), "the first request is kept")
+
+	due, err := repo.ListDueDeletions(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	assert.ErrorIs(t, repo.DeleteUser(ctx, user.ID), customerrors.ErrUserNotFound, "not due yet")
+
+	require.NoError(t, repo.CancelDeletion(ctx, user.ID))
+	assert.ErrorIs(t, repo.CancelDeletion(ctx, user.ID), customerrors.ErrNotFound)
+
+	_, err = repo.ScheduleDeletion(ctx, user.ID, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.CancelDeletion(ctx, user.ID), customerrors.ErrNotFound, "the grace period is over")
+
+	due, err = repo.ListDueDeletions(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{user.ID}, due)
+
+	require.NoError(t, repo.DeleteUser(ctx, user.ID))
+	assert.False(t, repo.CheckUserExists(ctx, user.ID))
+
+	_, err = repo.ScheduleDeletion(ctx, user.ID, later)
+	assert.ErrorIs(t, err, customerrors.ErrUserNotFound)
+}
+
+func TestDeleteUserPassesOwnership(t *testing.T) {
+	pool, teardown := repositoryT.SetupTestDB(t)
+	defer teardown()
+
+	repo := user_repo.NewUserRepository(pool)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `
+		INSERT INTO users (id, username, email, password_hash, deletion_scheduled_at) VALUES
+		(1, 'owner', 'owner@example.com', 'hash', NOW() - INTERVAL '1 minute'),
+		(2, 'member', 'member@example.com', 'hash', NULL),
+		(3, 'admin', 'admin@example.com', 'hash', NULL);
+		INSERT INTO chats (id, title, is_private) VALUES (10, 'with admin', TRUE), (11, 'members only', TRUE), (12, 'alone', TRUE);
+		INSERT INTO chat_members (chat_id, user_id, role, joined_at) VALUES
+		(10, 1, 'owner', NOW() - INTERVAL '3 hours'),
+		(10, 2, 'member', NOW() - INTERVAL '2 hours'),
+		(10, 3, 'admin', NOW() - INTERVAL '1 hour'),
+		(11, 1, 'owner', NOW() - INTERVAL '3 hours'),
+		(11, 3, 'member', NOW() - INTERVAL '2 hours'),
+		(11, 2, 'member', NOW() - INTERVAL '1 hour'),
+		(12, 1, 'owner', NOW())`)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.DeleteUser(ctx, 1))
+
+	owner := func(chatID int64) int64 {
+		var id int64
+		require.NoError(t, pool.QueryRow(ctx,
+			"SELECT user_id FROM chat_members WHERE chat_id = $1 AND role = 'owner'", chatID).Scan(&id))
+		return id
+	}
+	assert.Equal(t, int64(3), owner(10), "the admin before older members")
+	assert.Equal(t, int64(3), owner(11), "the oldest member without admins")
+
+	var exists bool
+	require.NoError(t, pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM chats WHERE id = 12)").Scan(&exists))
+	assert.False(t, exists, "nobody left to own it")
+}
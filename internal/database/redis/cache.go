@@ -52,3 +52,27 @@ func (c *Cache) Exists(ctx context.Context, key string) (bool, error) {
 	}
 	return result > 0, nil
 }
+
+// Incr increments the counter at key and (re)starts its expiry, so the
+// counter lives until ttl has passed without another increment.
+func (c *Cache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	pipe := c.Client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to increment in redis: %w", err)
+	}
+	return incr.Val(), nil
+}
+
+// TTL returns the remaining lifetime of key, or zero if it does not exist.
+func (c *Cache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.Client.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get ttl from redis: %w", err)
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
@@ -2,12 +2,21 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"strconv"
+
+	interceptor "main/internal/delivery/grpc/middleware"
 	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+	ctxHelper "main/pkg/jwt/context"
 	auth_gen "main/pkg/proto/gen/auth/v1"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type AuthHandler struct {
@@ -17,9 +26,24 @@ type AuthHandler struct {
 }
 
 type AuthService interface {
-	LoginUser(ctx context.Context, username, password string) (accessToken string, err error)
+	LoginUser(ctx context.Context, username, password string, device dom.Session) (accessToken string, refreshToken dom.RefreshToken, challengeToken string, err error)
 	LogoutUser(ctx context.Context, accessToken string) (bool, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (accessToken string, newRefreshToken dom.RefreshToken, err error)
 	RegisterUser(ctx context.Context, username, email, password string) (dom.User, error)
+	ListSessions(ctx context.Context, userID int64) ([]dom.Session, error)
+	RevokeSession(ctx context.Context, userID int64, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID int64) error
+	VerifyTOTP(ctx context.Context, challengeToken, code string) (accessToken string, refreshToken dom.RefreshToken, err error)
+	EnrollTOTP(ctx context.Context, userID int64) (dom.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID int64, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID int64, code string) error
+	VerifyEmail(ctx context.Context, email, code string) error
+	ResendVerificationEmail(ctx context.Context, userID int64) error
+	RequestPasswordReset(ctx context.Context, email, ip string) error
+	ConfirmPasswordReset(ctx context.Context, token, newPassword string) error
+	ChangePassword(ctx context.Context, userID int64, oldPassword, newPassword string) error
+	StartOIDCLogin(ctx context.Context, provider string, device dom.Session) (string, error)
+	CompleteOIDCLogin(ctx context.Context, provider, state, code string) (accessToken string, refreshToken dom.RefreshToken, challengeToken string, err error)
 }
 
 func NewAuthHandler(authUsecase AuthService, logger *slog.Logger) *AuthHandler {
@@ -30,15 +54,33 @@ func NewAuthHandler(authUsecase AuthService, logger *slog.Logger) *AuthHandler {
 }
 
 func (h *AuthHandler) Login(ctx context.Context, req *auth_gen.LoginRequest) (*auth_gen.LoginResponse, error) {
-	accessToken, err := h.authUsecase.LoginUser(ctx, req.GetEmail(), req.GetPassword())
+	userAgent, ip := interceptor.ClientInfo(ctx)
+	device := dom.Session{
+		DeviceName: req.GetDeviceName(),
+		UserAgent:  userAgent,
+		IP:         ip,
+	}
+
+	accessToken, refreshToken, challengeToken, err := h.authUsecase.LoginUser(ctx, req.GetEmail(), req.GetPassword(), device)
 	if err != nil {
+		var retry *customerrors.RetryAfterError
+		if errors.As(err, &retry) {
+			h.log.Warn("login locked", "email", req.GetEmail(), "ip", ip)
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(retry.RetryAfter.Seconds())+1)))
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
 		h.log.Error("could not login user", "error", err, "email", req.GetEmail())
 		return nil, status.Errorf(codes.Internal, "could not login user: %v", err)
 	}
+	if challengeToken != "" {
+		h.log.Info("two-factor code required", "email", req.GetEmail())
+		return &auth_gen.LoginResponse{ChallengeToken: challengeToken}, nil
+	}
 	h.log.Info("user logged in", "email", req.GetEmail())
 
 	return &auth_gen.LoginResponse{
-		Token: accessToken,
+		Token:        accessToken,
+		RefreshToken: refreshToken.Token,
 	}, nil
 }
 
@@ -57,8 +99,15 @@ func (h *AuthHandler) Logout(ctx context.Context, req *auth_gen.LogoutRequest) (
 }
 
 func (h *AuthHandler) Register(ctx context.Context, req *auth_gen.RegisterRequest) (*auth_gen.RegisterResponse, error) {
-	user, err := h.authUsecase.RegisterUser(ctx, req.GetEmail(), req.GetPassword(), req.GetUsername())
+	user, err := h.authUsecase.RegisterUser(ctx, req.GetUsername(), req.GetEmail(), req.GetPassword())
 	if err != nil {
+		switch {
+		case errors.Is(err, customerrors.ErrInvalidInput):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, customerrors.ErrUsernameAlreadyExists),
+			errors.Is(err, customerrors.ErrEmailAlreadyExists):
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
 		h.log.Error("could not register user", "error", err)
 		return nil, status.Errorf(codes.Internal, "could not register user: %v", err)
 	}
@@ -67,3 +116,293 @@ func (h *AuthHandler) Register(ctx context.Context, req *auth_gen.RegisterReques
 		UserId: user.ID,
 	}, nil
 }
+
+func (h *AuthHandler) Refresh(ctx context.Context, req *auth_gen.RefreshRequest) (*auth_gen.RefreshResponse, error) {
+	accessToken, refreshToken, err := h.authUsecase.RefreshTokens(ctx, req.GetRefreshToken())
+	if err != nil {
+		switch {
+		case errors.Is(err, customerrors.ErrRefreshTokenReused):
+			h.log.Warn("refresh token reuse detected, token family revoked")
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case errors.Is(err, customerrors.ErrRefreshTokenNotFound),
+			errors.Is(err, customerrors.ErrRefreshTokenExpired),
+			errors.Is(err, customerrors.ErrRefreshTokenRevoked):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		h.log.Error("could not refresh tokens", "error", err)
+		return nil, status.Errorf(codes.Internal, "could not refresh tokens: %v", err)
+	}
+	h.log.Info("tokens refreshed", "user_id", refreshToken.UserID)
+
+	return &auth_gen.RefreshResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken.Token,
+	}, nil
+}
+
+func (h *AuthHandler) ListSessions(ctx context.Context, req *auth_gen.ListSessionsRequest) (*auth_gen.ListSessionsResponse, error) {
+	claims, ok := ctxHelper.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	sessions, err := h.authUsecase.ListSessions(ctx, claims.UserID)
+	if err != nil {
+		h.log.Error("could not list sessions", "error", err, "user_id", claims.UserID)
+		return nil, status.Errorf(codes.Internal, "could not list sessions: %v", err)
+	}
+
+	res := &auth_gen.ListSessionsResponse{
+		Sessions: make([]*auth_gen.Session, 0, len(sessions)),
+	}
+	for _, s := range sessions {
+		res.Sessions = append(res.Sessions, &auth_gen.Session{
+			Id:         s.ID,
+			DeviceName: s.DeviceName,
+			UserAgent:  s.UserAgent,
+			Ip:         s.IP,
+			CreatedAt:  timestamppb.New(s.CreatedAt),
+			LastUsedAt: timestamppb.New(s.LastUsedAt),
+			Current:    s.ID == claims.SessionID,
+		})
+	}
+	return res, nil
+}
+
+func (h *AuthHandler) RevokeSession(ctx context.Context, req *auth_gen.RevokeSessionRequest) (*auth_gen.RevokeSessionResponse, error) {
+	claims, ok := ctxHelper.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	if err := h.authUsecase.RevokeSession(ctx, claims.UserID, req.GetSessionId()); err != nil {
+		switch {
+		case errors.Is(err, customerrors.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "session not found")
+		case errors.Is(err, customerrors.ErrInvalidInput):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		h.log.Error("could not revoke session", "error", err, "user_id", claims.UserID)
+		return nil, status.Errorf(codes.Internal, "could not revoke session: %v", err)
+	}
+	h.log.Info("session revoked", "user_id", claims.UserID, "session_id", req.GetSessionId())
+	return &auth_gen.RevokeSessionResponse{}, nil
+}
+
+func (h *AuthHandler) RevokeAllSessions(ctx context.Context, req *auth_gen.RevokeAllSessionsRequest) (*auth_gen.RevokeAllSessionsResponse, error) {
+	claims, ok := ctxHelper.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	if err := h.authUsecase.RevokeAllSessions(ctx, claims.UserID); err != nil {
+		h.log.Error("could not revoke sessions", "error", err, "user_id", claims.UserID)
+		return nil, status.Errorf(codes.Internal, "could not revoke sessions: %v", err)
+	}
+	h.log.Info("all sessions revoked", "user_id", claims.UserID)
+	return &auth_gen.RevokeAllSessionsResponse{}, nil
+}
+
+func (h *AuthHandler) VerifyEmail(ctx context.Context, req *auth_gen.VerifyEmailRequest) (*auth_gen.VerifyEmailResponse, error) {
+	if err := h.authUsecase.VerifyEmail(ctx, req.GetEmail(), req.GetCode()); err != nil {
+		switch {
+		case errors.Is(err, customerrors.ErrInvalidEmailCode):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, customerrors.ErrEmailAlreadyVerified):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		h.log.Error("could not verify email", "error", err, "email", req.GetEmail())
+		return nil, status.Errorf(codes.Internal, "could not verify email: %v", err)
+	}
+	h.log.Info("email verified", "email", req.GetEmail())
+	return &auth_gen.VerifyEmailResponse{}, nil
+}
+
+func (h *AuthHandler) ResendVerificationEmail(ctx context.Context, req *auth_gen.ResendVerificationEmailRequest) (*auth_gen.ResendVerificationEmailResponse, error) {
+	claims, ok := ctxHelper.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	if err := h.authUsecase.ResendVerificationEmail(ctx, claims.UserID); err != nil {
+		if errors.Is(err, customerrors.ErrEmailAlreadyVerified) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		var retry *customerrors.RetryAfterError
+		if errors.As(err, &retry) {
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(retry.RetryAfter.Seconds())+1)))
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
+		h.log.Error("could not resend verification email", "error", err, "user_id", claims.UserID)
+		return nil, status.Errorf(codes.Internal, "could not resend verification email: %v", err)
+	}
+	return &auth_gen.ResendVerificationEmailResponse{}, nil
+}
+
+func (h *AuthHandler) RequestPasswordReset(ctx context.Context, req *auth_gen.RequestPasswordResetRequest) (*auth_gen.RequestPasswordResetResponse, error) {
+	_, ip := interceptor.ClientInfo(ctx)
+	if err := h.authUsecase.RequestPasswordReset(ctx, req.GetEmail(), ip); err != nil {
+		if errors.Is(err, customerrors.ErrInvalidInput) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		h.log.Error("could not request password reset", "error", err)
+		return nil, status.Error(codes.Internal, "could not request password reset")
+	}
+	return &auth_gen.RequestPasswordResetResponse{}, nil
+}
+
+func (h *AuthHandler) ConfirmPasswordReset(ctx context.Context, req *auth_gen.ConfirmPasswordResetRequest) (*auth_gen.ConfirmPasswordResetResponse, error) {
+	if err := h.authUsecase.ConfirmPasswordReset(ctx, req.GetToken(), req.GetNewPassword()); err != nil {
+		switch {
+		case errors.Is(err, customerrors.ErrInvalidResetToken),
+			errors.Is(err, customerrors.ErrInvalidInput):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		h.log.Error("could not reset password", "error", err)
+		return nil, status.Errorf(codes.Internal, "could not reset password: %v", err)
+	}
+	h.log.Info("password reset")
+	return &auth_gen.ConfirmPasswordResetResponse{}, nil
+}
+
+func (h *AuthHandler) ChangePassword(ctx context.Context, req *auth_gen.ChangePasswordRequest) (*auth_gen.ChangePasswordResponse, error) {
+	claims, ok := ctxHelper.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	if err := h.authUsecase.ChangePassword(ctx, claims.UserID, req.GetOldPassword(), req.GetNewPassword()); err != nil {
+		switch {
+		case errors.Is(err, customerrors.ErrWrongPassword):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		case errors.Is(err, customerrors.ErrInvalidInput):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		h.log.Error("could not change password", "error", err, "user_id", claims.UserID)
+		return nil, status.Errorf(codes.Internal, "could not change password: %v", err)
+	}
+	h.log.Info("password changed", "user_id", claims.UserID)
+	return &auth_gen.ChangePasswordResponse{}, nil
+}
+
+func (h *AuthHandler) VerifyTOTP(ctx context.Context, req *auth_gen.VerifyTOTPRequest) (*auth_gen.VerifyTOTPResponse, error) {
+	accessToken, refreshToken, err := h.authUsecase.VerifyTOTP(ctx, req.GetChallengeToken(), req.GetCode())
+	if err != nil {
+		if errors.Is(err, customerrors.ErrChallengeNotFound) || errors.Is(err, customerrors.ErrInvalidTOTPCode) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		h.log.Error("could not verify two-factor code", "error", err)
+		return nil, status.Errorf(codes.Internal, "could not verify two-factor code: %v", err)
+	}
+	h.log.Info("user logged in with two-factor code", "user_id", refreshToken.UserID)
+
+	return &auth_gen.VerifyTOTPResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken.Token,
+	}, nil
+}
+
+func (h *AuthHandler) EnrollTOTP(ctx context.Context, req *auth_gen.EnrollTOTPRequest) (*auth_gen.EnrollTOTPResponse, error) {
+	claims, ok := ctxHelper.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	enrollment, err := h.authUsecase.EnrollTOTP(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrTOTPAlreadyEnabled) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		h.log.Error("could not enroll two-factor authentication", "error", err, "user_id", claims.UserID)
+		return nil, status.Errorf(codes.Internal, "could not enroll two-factor authentication: %v", err)
+	}
+	return &auth_gen.EnrollTOTPResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningUri: enrollment.ProvisioningURI,
+	}, nil
+}
+
+func (h *AuthHandler) ConfirmTOTP(ctx context.Context, req *auth_gen.ConfirmTOTPRequest) (*auth_gen.ConfirmTOTPResponse, error) {
+	claims, ok := ctxHelper.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	recoveryCodes, err := h.authUsecase.ConfirmTOTP(ctx, claims.UserID, req.GetCode())
+	if err != nil {
+		return nil, h.totpError(err, claims.UserID, "could not confirm two-factor authentication")
+	}
+	h.log.Info("two-factor authentication enabled", "user_id", claims.UserID)
+	return &auth_gen.ConfirmTOTPResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (h *AuthHandler) DisableTOTP(ctx context.Context, req *auth_gen.DisableTOTPRequest) (*auth_gen.DisableTOTPResponse, error) {
+	claims, ok := ctxHelper.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	if err := h.authUsecase.DisableTOTP(ctx, claims.UserID, req.GetCode()); err != nil {
+		return nil, h.totpError(err, claims.UserID, "could not disable two-factor authentication")
+	}
+	h.log.Info("two-factor authentication disabled", "user_id", claims.UserID)
+	return &auth_gen.DisableTOTPResponse{}, nil
+}
+
+func (h *AuthHandler) StartOIDCLogin(ctx context.Context, req *auth_gen.StartOIDCLoginRequest) (*auth_gen.StartOIDCLoginResponse, error) {
+	userAgent, ip := interceptor.ClientInfo(ctx)
+	device := dom.Session{
+		DeviceName: req.GetDeviceName(),
+		UserAgent:  userAgent,
+		IP:         ip,
+	}
+
+	authURL, err := h.authUsecase.StartOIDCLogin(ctx, req.GetProvider(), device)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrUnknownOIDCProvider) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		h.log.Error("could not start oidc login", "error", err, "provider", req.GetProvider())
+		return nil, status.Errorf(codes.Unavailable, "could not start oidc login: %v", err)
+	}
+	return &auth_gen.StartOIDCLoginResponse{AuthorizationUrl: authURL}, nil
+}
+
+func (h *AuthHandler) CompleteOIDCLogin(ctx context.Context, req *auth_gen.CompleteOIDCLoginRequest) (*auth_gen.CompleteOIDCLoginResponse, error) {
+	accessToken, refreshToken, challengeToken, err := h.authUsecase.CompleteOIDCLogin(ctx, req.GetProvider(), req.GetState(), req.GetCode())
+	if err != nil {
+		switch {
+		case errors.Is(err, customerrors.ErrUnknownOIDCProvider):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, customerrors.ErrInvalidOIDCState),
+			errors.Is(err, customerrors.ErrOIDCLogin):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case errors.Is(err, customerrors.ErrIdentityNotLinkable):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		h.log.Error("could not complete oidc login", "error", err, "provider", req.GetProvider())
+		return nil, status.Errorf(codes.Internal, "could not complete oidc login: %v", err)
+	}
+	if challengeToken != "" {
+		h.log.Info("two-factor code required", "provider", req.GetProvider())
+		return &auth_gen.CompleteOIDCLoginResponse{ChallengeToken: challengeToken}, nil
+	}
+	h.log.Info("user logged in with oidc", "user_id", refreshToken.UserID, "provider", req.GetProvider())
+
+	return &auth_gen.CompleteOIDCLoginResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken.Token,
+	}, nil
+}
+
+func (h *AuthHandler) totpError(err error, userID int64, msg string) error {
+	switch {
+	case errors.Is(err, customerrors.ErrInvalidTOTPCode):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, customerrors.ErrTOTPNotEnrolled),
+		errors.Is(err, customerrors.ErrTOTPAlreadyEnabled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	h.log.Error(msg, "error", err, "user_id", userID)
+	return status.Errorf(codes.Internal, "%s: %v", msg, err)
+}
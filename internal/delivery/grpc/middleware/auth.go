@@ -2,22 +2,94 @@ package middleware
 
 import (
 	"context"
-	ctxHelper "main/pkg/jwt/context"
+	"errors"
+	"main/pkg/apikey"
+	"main/pkg/customerrors"
 	"main/pkg/jwt"
+	ctxHelper "main/pkg/jwt/context"
+	authv1 "main/pkg/proto/gen/auth/v1"
 	"strings"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
-var publicMethods = map[string]struct{}{
-	"/auth.AuthService/Login":    {},
-	"/auth.AuthService/Register": {},
+type TokenParser interface {
+	Parse(accessToken string) (*jwt.TokenClaims, error)
+}
+
+type TokenBlacklistChecker interface {
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// APIKeyAuthenticator resolves the API key of a bot to its claims.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*jwt.TokenClaims, error)
+}
+
+// MethodPolicy is the auth configuration of one RPC, declared with the
+// auth.v1.public and auth.v1.api_key_scope method options.
+type MethodPolicy struct {
+	Public      bool
+	APIKeyScope string
+}
+
+// Authenticator checks the credentials of gRPC calls the same way JWTAuth
+// does for HTTP: access tokens must parse and be neither blacklisted nor
+// belong to a revoked session, and API keys need the scope of the method.
+type Authenticator struct {
+	parser    TokenParser
+	blacklist TokenBlacklistChecker
+	apiKeys   APIKeyAuthenticator
+	policies  map[string]MethodPolicy
+}
+
+// NewAuthenticator reads the method policies of every service registered in
+// the global proto registry. apiKeys may be nil to accept access tokens only.
+func NewAuthenticator(parser TokenParser, blacklist TokenBlacklistChecker, apiKeys APIKeyAuthenticator) *Authenticator {
+	return &Authenticator{
+		parser:    parser,
+		blacklist: blacklist,
+		apiKeys:   apiKeys,
+		policies:  MethodPolicies(protoregistry.GlobalFiles),
+	}
 }
 
-func AuthInterceptor(jwtManager *jwt.Manager) grpc.UnaryServerInterceptor {
+// MethodPolicies collects the policies of all methods in files, keyed by
+// full method name as seen by interceptors ("/auth.v1.AuthService/Login").
+func MethodPolicies(files *protoregistry.Files) map[string]MethodPolicy {
+	policies := make(map[string]MethodPolicy)
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		services := fd.Services()
+		for i := 0; i < services.Len(); i++ {
+			sd := services.Get(i)
+			methods := sd.Methods()
+			for j := 0; j < methods.Len(); j++ {
+				md := methods.Get(j)
+				opts := md.Options()
+				if opts == nil {
+					continue
+				}
+				policy := MethodPolicy{
+					Public:      proto.GetExtension(opts, authv1.E_Public).(bool),
+					APIKeyScope: proto.GetExtension(opts, authv1.E_ApiKeyScope).(string),
+				}
+				if policy != (MethodPolicy{}) {
+					policies["/"+string(sd.FullName())+"/"+string(md.Name())] = policy
+				}
+			}
+		}
+		return true
+	})
+	return policies
+}
+
+func (a *Authenticator) UnaryInterceptor() grpc.UnaryServerInterceptor {
 	return func(
 		ctx context.Context,
 		req interface{},
@@ -29,27 +101,102 @@ func AuthInterceptor(jwtManager *jwt.Manager) grpc.UnaryServerInterceptor {
 			return nil, status.Errorf(codes.Unauthenticated, "request is nil")
 		}
 
-		if _, ok := publicMethods[info.FullMethod]; ok {
-			return handler(ctx, req)
+		newCtx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
 		}
+		return handler(newCtx, req)
+	}
+}
 
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, status.Errorf(codes.Unauthenticated, "metadata is missing")
-		}
+func (a *Authenticator) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
 
-		values := md.Get("authorization")
-		if len(values) == 0 {
-			return nil, status.Errorf(codes.Unauthenticated, "authorization token is not provided")
+		newCtx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
 		}
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: newCtx})
+	}
+}
 
-		accessToken := strings.TrimPrefix(values[0], "Bearer ")
-		claims, err := jwtManager.Parse(accessToken)
-		if err != nil {
+// wrappedStream hands a derived context to stream handlers.
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate returns ctx with the caller's claims, or a status error.
+func (a *Authenticator) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	policy := a.policies[fullMethod]
+	if policy.Public {
+		return ctx, nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "metadata is missing")
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "authorization token is not provided")
+	}
+
+	accessToken := strings.TrimPrefix(values[0], "Bearer ")
+	if a.apiKeys != nil && apikey.IsKey(accessToken) {
+		return a.authenticateAPIKey(ctx, policy, accessToken)
+	}
+
+	claims, err := jwt.Authenticate(ctx, a.parser, a.blacklist, accessToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, jwt.ErrTokenRevoked), errors.Is(err, jwt.ErrSessionRevoked):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case errors.Is(err, jwt.ErrInvalidToken):
 			return nil, status.Errorf(codes.Unauthenticated, "access token is invalid: %v", err)
 		}
-		newCtx := ctxHelper.ToContext(ctx, claims)
+		return nil, status.Error(codes.Internal, "could not check token blacklist")
+	}
 
-		return handler(newCtx, req)
+	return ctxHelper.ToContext(ctx, claims), nil
+}
+
+func (a *Authenticator) authenticateAPIKey(ctx context.Context, policy MethodPolicy, key string) (context.Context, error) {
+	if policy.APIKeyScope == "" {
+		return nil, status.Error(codes.PermissionDenied, "method is not available to api keys")
+	}
+	claims, err := a.apiKeys.AuthenticateAPIKey(ctx, key)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidAPIKey) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, status.Error(codes.Internal, "could not check api key")
+	}
+	if !claims.Allows(policy.APIKeyScope, 0) {
+		return nil, status.Error(codes.PermissionDenied, customerrors.ErrInsufficientScope.Error())
+	}
+	return ctxHelper.ToContext(ctx, claims), nil
+}
+
+// RequireRole returns a PermissionDenied status unless the caller in ctx holds
+// role or a higher one.
+func RequireRole(ctx context.Context, role string) error {
+	claims, ok := ctxHelper.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	if !claims.HasRole(role) {
+		return status.Error(codes.PermissionDenied, customerrors.ErrInsufficientRole.Error())
 	}
+	return nil
 }
@@ -0,0 +1,177 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	middleware "main/internal/delivery/grpc/middleware"
+	"main/pkg/jwt"
+	ctxHelper "main/pkg/jwt/context"
+	_ "main/pkg/proto/gen/auth/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	loginMethod        = "/auth.v1.AuthService/Login"
+	listSessionsMethod = "/auth.v1.AuthService/ListSessions"
+)
+
+type MockJWTManager struct {
+	mock.Mock
+}
+
+func (m *MockJWTManager) Parse(accessToken string) (*jwt.TokenClaims, error) {
+	args := m.Called(accessToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*jwt.TokenClaims), args.Error(1)
+}
+
+func (m *MockJWTManager) Exists(ctx context.Context, key string) (bool, error) {
+	args := m.Called(ctx, key)
+	return args.Bool(0), args.Error(1)
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestMethodPolicies(t *testing.T) {
+	policies := middleware.MethodPolicies(protoregistry.GlobalFiles)
+
+	assert.True(t, policies[loginMethod].Public)
+	assert.True(t, policies["/auth.v1.AuthService/Register"].Public)
+	assert.False(t, policies[listSessionsMethod].Public)
+	assert.False(t, policies["/auth.v1.AuthService/Logout"].Public)
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		ctx          context.Context
+		mockBehavior func(m *MockJWTManager)
+		expectedCode codes.Code
+		expectedUser int64
+	}{
+		{
+			name:         "Public method without token",
+			method:       loginMethod,
+			ctx:          context.Background(),
+			mockBehavior: func(m *MockJWTManager) {},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "Missing token",
+			method:       listSessionsMethod,
+			ctx:          metadata.NewIncomingContext(context.Background(), metadata.MD{}),
+			mockBehavior: func(m *MockJWTManager) {},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:   "Valid token",
+			method: listSessionsMethod,
+			ctx:    withToken("valid_token"),
+			mockBehavior: func(m *MockJWTManager) {
+				m.On("Exists", mock.Anything, "valid_token").Return(false, nil)
+				m.On("Parse", "valid_token").Return(&jwt.TokenClaims{UserID: 10, SessionID: "sid"}, nil)
+				m.On("Exists", mock.Anything, jwt.RevokedSessionKey("sid")).Return(false, nil)
+			},
+			expectedCode: codes.OK,
+			expectedUser: 10,
+		},
+		{
+			name:   "Blacklisted token",
+			method: listSessionsMethod,
+			ctx:    withToken("logged_out"),
+			mockBehavior: func(m *MockJWTManager) {
+				m.On("Exists", mock.Anything, "logged_out").Return(true, nil)
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:   "Revoked session",
+			method: listSessionsMethod,
+			ctx:    withToken("revoked"),
+			mockBehavior: func(m *MockJWTManager) {
+				m.On("Exists", mock.Anything, "revoked").Return(false, nil)
+				m.On("Parse", "revoked").Return(&jwt.TokenClaims{UserID: 10, SessionID: "sid"}, nil)
+				m.On("Exists", mock.Anything, jwt.RevokedSessionKey("sid")).Return(true, nil)
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:   "Invalid token",
+			method: listSessionsMethod,
+			ctx:    withToken("garbage"),
+			mockBehavior: func(m *MockJWTManager) {
+				m.On("Exists", mock.Anything, "garbage").Return(false, nil)
+				m.On("Parse", "garbage").Return(nil, assert.AnError)
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(MockJWTManager)
+			tt.mockBehavior(m)
+			interceptor := middleware.NewAuthenticator(m, m, nil).UnaryInterceptor()
+
+			var gotUser int64
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				if claims, ok := ctxHelper.FromContext(ctx); ok {
+					gotUser = claims.UserID
+				}
+				return "ok", nil
+			}
+
+			_, err := interceptor(tt.ctx, struct{}{}, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			assert.Equal(t, tt.expectedUser, gotUser)
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	m := new(MockJWTManager)
+	m.On("Exists", mock.Anything, "valid_token").Return(false, nil)
+	m.On("Parse", "valid_token").Return(&jwt.TokenClaims{UserID: 10}, nil)
+	m.On("Exists", mock.Anything, "logged_out").Return(true, nil)
+	interceptor := middleware.NewAuthenticator(m, m, nil).StreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: listSessionsMethod}
+
+	var gotUser int64
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		claims, ok := ctxHelper.FromContext(ss.Context())
+		if assert.True(t, ok) {
+			gotUser = claims.UserID
+		}
+		return nil
+	}
+
+	err := interceptor(nil, &fakeStream{ctx: withToken("valid_token")}, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), gotUser)
+
+	err = interceptor(nil, &fakeStream{ctx: withToken("logged_out")}, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
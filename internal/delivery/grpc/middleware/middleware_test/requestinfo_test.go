@@ -0,0 +1,59 @@
+package middleware_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	middleware "main/internal/delivery/grpc/middleware"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func clientIPFor(t *testing.T, trusted []string, peerAddr string, forwarded ...string) string {
+	t.Helper()
+	proxies, err := middleware.ParseTrustedProxies(trusted)
+	require.NoError(t, err)
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(peerAddr), Port: 4000}})
+	if len(forwarded) > 0 {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", forwarded[0]))
+	}
+
+	var ip string
+	_, err = middleware.RequestInfoInterceptor(proxies)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: loginMethod},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			_, ip = middleware.ClientInfo(ctx)
+			return nil, nil
+		})
+	require.NoError(t, err)
+	return ip
+}
+
+func TestClientIPIgnoresForwardedFromUntrustedPeers(t *testing.T) {
+	assert.Equal(t, "203.0.113.9", clientIPFor(t, nil, "203.0.113.9", "198.51.100.1"))
+	assert.Equal(t, "203.0.113.9", clientIPFor(t, []string{"10.0.0.0/8"}, "203.0.113.9", "198.51.100.1"))
+}
+
+func TestClientIPTakesRightmostUntrustedHop(t *testing.T) {
+	trusted := []string{"10.0.0.0/8", "127.0.0.1"}
+
+	// The client prepended a spoofed address; the gateway appended the real one.
+	assert.Equal(t, "198.51.100.7", clientIPFor(t, trusted, "127.0.0.1", "1.2.3.4, 198.51.100.7"))
+	// Proxies in front of the gateway are skipped.
+	assert.Equal(t, "198.51.100.7", clientIPFor(t, trusted, "127.0.0.1", "1.2.3.4, 198.51.100.7, 10.1.2.3"))
+	// Without the header the trusted peer itself is the client.
+	assert.Equal(t, "127.0.0.1", clientIPFor(t, trusted, "127.0.0.1"))
+	assert.Equal(t, "10.1.2.3", clientIPFor(t, trusted, "127.0.0.1", "garbage, 10.1.2.3"))
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	_, err := middleware.ParseTrustedProxies([]string{"10.0.0.0/8", "::1", " 127.0.0.1 "})
+	assert.NoError(t, err)
+	_, err = middleware.ParseTrustedProxies([]string{"not-an-ip"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"main/pkg/requestinfo"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// TrustedProxies are the networks of proxies, such as the REST gateway,
+// whose x-forwarded-for entries are believed. Everybody else can put
+// anything in that header.
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses CIDRs or single addresses.
+func ParseTrustedProxies(entries []string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			if ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		proxies = append(proxies, ipNet)
+	}
+	return proxies, nil
+}
+
+func (t TrustedProxies) contains(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, ipNet := range t {
+		if ipNet.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestInfoInterceptor stores the client details of every call in the
+// context for the audit trail. The request ID is taken from the x-request-id
+// metadata or generated.
+func RequestInfoInterceptor(trusted TrustedProxies) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		return handler(withRequestInfo(ctx, trusted), req)
+	}
+}
+
+func RequestInfoStreamInterceptor(trusted TrustedProxies) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: withRequestInfo(ss.Context(), trusted)})
+	}
+}
+
+func withRequestInfo(ctx context.Context, trusted TrustedProxies) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	info := requestinfo.Info{IP: clientIP(ctx, md, trusted)}
+	if v := md.Get("grpcgateway-user-agent"); len(v) > 0 {
+		info.UserAgent = v[0]
+	} else if v := md.Get("user-agent"); len(v) > 0 {
+		info.UserAgent = v[0]
+	}
+	if v := md.Get("x-request-id"); len(v) > 0 {
+		info.RequestID = v[0]
+	}
+	if info.RequestID == "" {
+		info.RequestID = uuid.NewString()
+	}
+	return requestinfo.ToContext(ctx, info)
+}
+
+// clientIP returns the peer address unless the peer is a trusted proxy. In
+// that case x-forwarded-for is walked from the right, where each proxy
+// appends the address it got the request from, and the first hop that is
+// not a trusted proxy is the client.
+func clientIP(ctx context.Context, md metadata.MD, trusted TrustedProxies) string {
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+	if !trusted.contains(ip) {
+		return ip
+	}
+
+	var hops []string
+	for _, v := range md.Get("x-forwarded-for") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			// Garbage the proxy passed along; nothing left of it is reliable.
+			break
+		}
+		ip = hop
+		if !trusted.contains(hop) {
+			break
+		}
+	}
+	return ip
+}
+
+// ClientInfo returns the caller's user agent and IP address as resolved by
+// the request info interceptors.
+func ClientInfo(ctx context.Context) (userAgent, ip string) {
+	info, _ := requestinfo.FromContext(ctx)
+	return info.UserAgent, info.IP
+}
@@ -0,0 +1,244 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	mwMiddleware "main/internal/delivery/http/middleware/auth"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+	"main/pkg/jwt"
+)
+
+type AccountService interface {
+	RequestDeletion(ctx context.Context, userID int64) (time.Time, error)
+	CancelDeletion(ctx context.Context, userID int64) error
+}
+
+type ExportService interface {
+	RequestExport(ctx context.Context, userID int64) (dom.DataExport, error)
+	ListExports(ctx context.Context, userID int64) ([]dom.DataExport, error)
+	GetExport(ctx context.Context, userID int64, exportID string) (dom.DataExport, error)
+	DownloadToken(ctx context.Context, userID int64, exportID string) (string, time.Time, error)
+	OpenDownload(ctx context.Context, exportID, token string) (dom.DataExport, io.ReadSeekCloser, error)
+}
+
+type JWTManager interface {
+	Exists(context.Context, string) (bool, error)
+	Parse(string) (*jwt.TokenClaims, error)
+	AuthenticateAPIKey(context.Context, string) (*jwt.TokenClaims, error)
+}
+
+type AccountHandler struct {
+	AccountSrv AccountService
+	ExportSrv  ExportService
+	logger     *slog.Logger
+	Manager    JWTManager
+}
+
+func NewAccountHandler(accountSrv AccountService, exportSrv ExportService, logger *slog.Logger, tokenManager JWTManager) *AccountHandler {
+	return &AccountHandler{
+		AccountSrv: accountSrv,
+		ExportSrv:  exportSrv,
+		logger:     logger,
+		Manager:    tokenManager,
+	}
+}
+
+type deletionResponse struct {
+	ScheduledFor time.Time `json:"scheduled_for"`
+}
+
+type downloadLinkResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// /account
+func (h *AccountHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(mwMiddleware.JWTAuth(h.Manager, h.Manager, h.Manager, h.logger))
+		r.Use(mwMiddleware.HumanOnly)
+		r.Post("/deletion", h.RequestDeletionHandler)
+		r.Delete("/deletion", h.CancelDeletionHandler)
+		r.Post("/exports", h.RequestExportHandler)
+		r.Get("/exports", h.ListExportsHandler)
+		r.Get("/exports/{export_id}", h.GetExportHandler)
+		r.Post("/exports/{export_id}/link", h.DownloadLinkHandler)
+	})
+
+	// The signed token in the link authenticates the download.
+	r.Get("/exports/{export_id}/download", h.DownloadHandler)
+}
+
+// RequestDeletionHandler schedules the deletion of the caller's account.
+func (h *AccountHandler) RequestDeletionHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	scheduled, err := h.AccountSrv.RequestDeletion(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrUserNotFound) {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("failed to schedule account deletion", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(deletionResponse{ScheduledFor: scheduled}); err != nil {
+		h.logger.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+// CancelDeletionHandler keeps the caller's account if the grace period is not
+// over yet.
+func (h *AccountHandler) CancelDeletionHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.AccountSrv.CancelDeletion(r.Context(), userID); err != nil {
+		if errors.Is(err, customerrors.ErrNotFound) {
+			http.Error(w, "no deletion is scheduled", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("failed to cancel account deletion", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequestExportHandler queues an export of the caller's data.
+func (h *AccountHandler) RequestExportHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	export, err := h.ExportSrv.RequestExport(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to request data export", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, http.StatusAccepted, export)
+}
+
+func (h *AccountHandler) ListExportsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	exports, err := h.ExportSrv.ListExports(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list data exports", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, exports)
+}
+
+func (h *AccountHandler) GetExportHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	export, err := h.ExportSrv.GetExport(r.Context(), userID, chi.URLParam(r, "export_id"))
+	if err != nil {
+		if errors.Is(err, customerrors.ErrNotFound) {
+			http.Error(w, "export not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("failed to get data export", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, export)
+}
+
+// DownloadLinkHandler returns a short-lived link to the archive of a ready
+// export.
+func (h *AccountHandler) DownloadLinkHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	exportID := chi.URLParam(r, "export_id")
+
+	token, expires, err := h.ExportSrv.DownloadToken(r.Context(), userID, exportID)
+	if err != nil {
+		switch {
+		case errors.Is(err, customerrors.ErrNotFound):
+			http.Error(w, "export not found", http.StatusNotFound)
+		case errors.Is(err, customerrors.ErrExportNotReady):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			h.logger.Error("failed to create download link", slog.String("error", err.Error()))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	link := "/account/exports/" + url.PathEscape(exportID) + "/download?token=" + url.QueryEscape(token)
+	h.writeJSON(w, http.StatusOK, downloadLinkResponse{URL: link, ExpiresAt: expires})
+}
+
+func (h *AccountHandler) DownloadHandler(w http.ResponseWriter, r *http.Request) {
+	export, archive, err := h.ExportSrv.OpenDownload(r.Context(), chi.URLParam(r, "export_id"), r.URL.Query().Get("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, customerrors.ErrInvalidDownloadLink):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, customerrors.ErrNotFound):
+			http.Error(w, "export not found", http.StatusNotFound)
+		case errors.Is(err, customerrors.ErrExportNotReady):
+			http.Error(w, "export is no longer available", http.StatusGone)
+		default:
+			h.logger.Error("failed to open data export", slog.String("error", err.Error()))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	defer archive.Close()
+
+	var modified time.Time
+	if export.CompletedAt != nil {
+		modified = *export.CompletedAt
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="export-`+export.ID+`.zip"`)
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeContent(w, r, "", modified, archive)
+}
+
+func (h *AccountHandler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
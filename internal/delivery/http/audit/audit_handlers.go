@@ -0,0 +1,149 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	mwMiddleware "main/internal/delivery/http/middleware/auth"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+	"main/pkg/jwt"
+)
+
+type AuditService interface {
+	Record(ctx context.Context, event dom.AuditEvent)
+	Query(ctx context.Context, filter dom.AuditFilter) ([]dom.AuditEvent, int64, error)
+	Export(ctx context.Context, filter dom.AuditFilter, w io.Writer) error
+}
+
+type JWTManager interface {
+	Exists(context.Context, string) (bool, error)
+	Parse(string) (*jwt.TokenClaims, error)
+	AuthenticateAPIKey(context.Context, string) (*jwt.TokenClaims, error)
+}
+
+type AuditHandler struct {
+	AuditSrv AuditService
+	logger   *slog.Logger
+	Manager  JWTManager
+}
+
+func NewAuditHandler(auditSrv AuditService, logger *slog.Logger, tokenManager JWTManager) *AuditHandler {
+	return &AuditHandler{
+		AuditSrv: auditSrv,
+		logger:   logger,
+		Manager:  tokenManager,
+	}
+}
+
+type auditPage struct {
+	Events     []dom.AuditEvent `json:"events"`
+	NextCursor int64            `json:"next_cursor,omitempty"`
+}
+
+// /admin/audit
+func (h *AuditHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(mwMiddleware.JWTAuth(h.Manager, h.Manager, h.Manager, h.logger))
+		r.Use(mwMiddleware.RequireRole(jwt.RoleAdmin))
+		r.Get("/", h.QueryHandler)
+		r.Get("/export", h.ExportHandler)
+	})
+}
+
+// QueryHandler returns a page of events. Filters: actor_id, action,
+// target_type, target_id, from and to (RFC 3339), cursor and limit.
+func (h *AuditHandler) QueryHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, next, err := h.AuditSrv.Query(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("failed to query audit events", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(auditPage{Events: events, NextCursor: next}); err != nil {
+		h.logger.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+// ExportHandler streams every event matching the filters as JSON lines. The
+// export itself is audited.
+func (h *AuditHandler) ExportHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.AuditSrv.Record(r.Context(), dom.AuditEvent{
+		Action:   dom.AuditExported,
+		Metadata: map[string]interface{}{"query": r.URL.RawQuery},
+	})
+
+	name := fmt.Sprintf("audit-%s.jsonl", time.Now().UTC().Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	if err := h.AuditSrv.Export(r.Context(), filter, w); err != nil {
+		// Part of the body may be sent already, so the status cannot change;
+		// the export is cut short instead.
+		h.logger.Error("failed to export audit events", slog.String("error", err.Error()))
+	}
+}
+
+func parseFilter(q url.Values) (dom.AuditFilter, error) {
+	filter := dom.AuditFilter{
+		Action:     q.Get("action"),
+		TargetType: q.Get("target_type"),
+		TargetID:   q.Get("target_id"),
+	}
+
+	var err error
+	if v := q.Get("actor_id"); v != "" {
+		if filter.ActorID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return dom.AuditFilter{}, fmt.Errorf("invalid actor_id")
+		}
+	}
+	if v := q.Get("cursor"); v != "" {
+		if filter.BeforeID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return dom.AuditFilter{}, fmt.Errorf("invalid cursor")
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			return dom.AuditFilter{}, fmt.Errorf("invalid limit")
+		}
+	}
+	if v := q.Get("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return dom.AuditFilter{}, fmt.Errorf("invalid from, expected RFC 3339")
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return dom.AuditFilter{}, fmt.Errorf("invalid to, expected RFC 3339")
+		}
+	}
+	return filter, nil
+}
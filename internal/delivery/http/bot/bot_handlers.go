@@ -0,0 +1,207 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	mwMiddleware "main/internal/delivery/http/middleware/auth"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+	"main/pkg/jwt"
+)
+
+type BotService interface {
+	CreateBot(ctx context.Context, ownerID int64, username string) (dom.Bot, error)
+	ListBots(ctx context.Context, ownerID int64) ([]dom.Bot, error)
+	CreateAPIKey(ctx context.Context, ownerID, botID int64, name string, scopes []string, ttl time.Duration) (string, dom.APIKey, error)
+	ListAPIKeys(ctx context.Context, ownerID, botID int64) ([]dom.APIKey, error)
+	RevokeAPIKey(ctx context.Context, ownerID, botID, keyID int64) error
+}
+
+type JWTManager interface {
+	Exists(context.Context, string) (bool, error)
+	Parse(string) (*jwt.TokenClaims, error)
+	AuthenticateAPIKey(context.Context, string) (*jwt.TokenClaims, error)
+}
+
+type BotHandler struct {
+	BotSrv  BotService
+	logger  *slog.Logger
+	Manager JWTManager
+}
+
+func NewBotHandler(botSrv BotService, logger *slog.Logger, tokenManager JWTManager) *BotHandler {
+	return &BotHandler{
+		BotSrv:  botSrv,
+		logger:  logger,
+		Manager: tokenManager,
+	}
+}
+
+type createBotDTO struct {
+	Username string `json:"username"`
+}
+
+type createAPIKeyDTO struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// ExpiresIn is the lifetime of the key in seconds; zero never expires.
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+type createAPIKeyResponse struct {
+	Key    string     `json:"key"`
+	APIKey dom.APIKey `json:"api_key"`
+}
+
+// /bots
+func (h *BotHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(mwMiddleware.JWTAuth(h.Manager, h.Manager, h.Manager, h.logger))
+		r.Use(mwMiddleware.HumanOnly)
+		r.Post("/", h.CreateBotHandler)
+		r.Get("/", h.ListBotsHandler)
+		r.Post("/{bot_id}/keys", h.CreateAPIKeyHandler)
+		r.Get("/{bot_id}/keys", h.ListAPIKeysHandler)
+		r.Delete("/{bot_id}/keys/{key_id}", h.RevokeAPIKeyHandler)
+	})
+}
+
+func (h *BotHandler) CreateBotHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var request createBotDTO
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bot, err := h.BotSrv.CreateBot(r.Context(), userID, request.Username)
+	if err != nil {
+		h.writeError(w, err, "failed to create bot")
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, bot)
+}
+
+func (h *BotHandler) ListBotsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	bots, err := h.BotSrv.ListBots(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, err, "failed to list bots")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, bots)
+}
+
+func (h *BotHandler) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	botID, err := strconv.ParseInt(chi.URLParam(r, "bot_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid bot id", http.StatusBadRequest)
+		return
+	}
+
+	var request createAPIKeyDTO
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	key, record, err := h.BotSrv.CreateAPIKey(r.Context(), userID, botID, request.Name, request.Scopes,
+		time.Duration(request.ExpiresIn)*time.Second)
+	if err != nil {
+		h.writeError(w, err, "failed to create api key")
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	h.writeJSON(w, http.StatusCreated, createAPIKeyResponse{Key: key, APIKey: record})
+}
+
+func (h *BotHandler) ListAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	botID, err := strconv.ParseInt(chi.URLParam(r, "bot_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid bot id", http.StatusBadRequest)
+		return
+	}
+
+	keys, err := h.BotSrv.ListAPIKeys(r.Context(), userID, botID)
+	if err != nil {
+		h.writeError(w, err, "failed to list api keys")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, keys)
+}
+
+func (h *BotHandler) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	botID, err := strconv.ParseInt(chi.URLParam(r, "bot_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid bot id", http.StatusBadRequest)
+		return
+	}
+	keyID, err := strconv.ParseInt(chi.URLParam(r, "key_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid key id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.BotSrv.RevokeAPIKey(r.Context(), userID, botID, keyID); err != nil {
+		h.writeError(w, err, "failed to revoke api key")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *BotHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, customerrors.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, customerrors.ErrNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, customerrors.ErrUsernameAlreadyExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, customerrors.ErrInsufficientScope):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		h.logger.Error(msg, slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func (h *BotHandler) writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
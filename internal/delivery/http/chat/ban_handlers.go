@@ -0,0 +1,67 @@
+package chat
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+// BanMemberHandler bans {user_id} from the chat with an optional reason and
+// expires_at; without expires_at the ban is permanent.
+func (h *ChatHandler) BanMemberHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, actorID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		UserID    int64      `json:"user_id"`
+		Reason    string     `json:"reason"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ChatSrv.BanMember(r.Context(), chatID, actorID, req.UserID, req.Reason, req.ExpiresAt); err != nil {
+		h.writeError(w, "failed to ban chat member", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ChatHandler) ListBansHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, actorID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	bans, err := h.ChatSrv.ListBans(r.Context(), chatID, actorID)
+	if err != nil {
+		h.writeError(w, "failed to list chat bans", err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, bans)
+}
+
+func (h *ChatHandler) UnbanMemberHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, actorID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ChatSrv.UnbanMember(r.Context(), chatID, actorID, userID); err != nil {
+		h.writeError(w, "failed to unban chat member", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
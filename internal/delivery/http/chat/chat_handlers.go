@@ -3,23 +3,28 @@ package chat
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi"
 
 	mwMiddleware "main/internal/delivery/http/middleware/auth"
+	"main/internal/delivery/ws"
 	dom "main/internal/domain/entity"
 	"main/pkg/customerrors"
 	"main/pkg/jwt"
 )
 
 type ChatHandler struct {
-	MessSrv MessageService
-	ChatSrv ChatService
-	logger  *slog.Logger
-	Manager JWTManager
+	MessSrv   MessageService
+	ChatSrv   ChatService
+	InviteSrv InviteService
+	logger    *slog.Logger
+	ws        *ws.Manager
+	Manager   JWTManager
 }
 
 type MessageService interface {
@@ -27,41 +32,104 @@ type MessageService interface {
 }
 
 type ChatService interface {
-	CreateChat(ctx context.Context, title string, isPrivate bool, members []int64) (dom.Chat, error)
-	ListOfChats(ctx context.Context, userID int64) ([]dom.Chat, error)
+	CreateChat(ctx context.Context, ownerID int64, title string, isPrivate bool, members []int64) (dom.Chat, error)
+	ListOfChats(ctx context.Context, userID int64, cursor string, limit int) ([]dom.Chat, string, error)
+	UpdateChatSettings(ctx context.Context, chatID, userID int64, settings dom.ChatSettings) error
 	GetChatDetails(ctx context.Context, chatID int64, userID int64) (dom.Chat, error)
-	DeleteChat(ctx context.Context, chatID int64) error
+	DeleteChat(ctx context.Context, chatID int64, userID int64) error
 	AddMembers(ctx context.Context, chatID, userID int64, members []int64) error
+	KickMember(ctx context.Context, chatID, actorID, userID int64) error
+	BanMember(ctx context.Context, chatID, actorID, userID int64, reason string, expiresAt *time.Time) error
+	UnbanMember(ctx context.Context, chatID, actorID, userID int64) error
+	ListBans(ctx context.Context, chatID, actorID int64) ([]dom.ChatBan, error)
+	RestrictMember(ctx context.Context, chatID, actorID, userID int64, restrictions []string, duration time.Duration) ([]dom.MemberRestriction, error)
+	LiftRestrictions(ctx context.Context, chatID, actorID, userID int64, restrictions []string) error
+	ListRestrictions(ctx context.Context, chatID, actorID int64) ([]dom.MemberRestriction, error)
+	SetMemberRole(ctx context.Context, chatID, actorID, userID int64, role string) error
+	TransferOwnership(ctx context.Context, chatID, actorID, newOwnerID int64) error
+	PinMessage(ctx context.Context, chatID, userID int64, messageID string) error
+	UnpinMessage(ctx context.Context, chatID, userID int64) error
+	GetDirectChat(ctx context.Context, userID, otherID int64) (dom.Chat, error)
+	OpenDirectChat(ctx context.Context, userID, otherID int64) (dom.Chat, bool, error)
+	CreatePublicChat(ctx context.Context, ownerID int64, title, handle, description string) (dom.Chat, error)
+	SearchPublicChats(ctx context.Context, query string, cursor int64, limit int) ([]dom.Chat, int64, error)
+	PreviewPublicChat(ctx context.Context, chatID int64, limit int64) (dom.Chat, []dom.Message, error)
+	JoinPublicChat(ctx context.Context, chatID, userID int64) error
+	LeaveChat(ctx context.Context, chatID, userID int64) error
+	UpdateChat(ctx context.Context, chatID, userID int64, upd dom.ChatUpdate) (dom.Chat, *dom.Message, error)
+	ChatMemberIDs(ctx context.Context, chatID, userID int64) ([]int64, error)
+	RequestToJoin(ctx context.Context, chatID, userID int64, message string) (dom.JoinRequest, error)
+	ListJoinRequests(ctx context.Context, chatID, actorID int64) ([]dom.JoinRequest, error)
+	ApproveJoinRequest(ctx context.Context, chatID, actorID, requestID int64) (dom.JoinRequest, error)
+	RejectJoinRequest(ctx context.Context, chatID, actorID, requestID int64) (dom.JoinRequest, error)
+	ChatAdminIDs(ctx context.Context, chatID int64) ([]int64, error)
+	MarkRead(ctx context.Context, chatID, userID int64, messageID string) (dom.ReadState, bool, error)
 }
 
 type JWTManager interface {
 	Exists(context.Context, string) (bool, error)
 	Parse(string) (*jwt.TokenClaims, error)
+	AuthenticateAPIKey(context.Context, string) (*jwt.TokenClaims, error)
 }
 
 func NewChatHandler(
 	messSrv MessageService,
 	chatSrv ChatService,
+	inviteSrv InviteService,
 	logger *slog.Logger,
+	wsManager *ws.Manager,
 	tokenManager JWTManager,
 
 ) *ChatHandler {
 	return &ChatHandler{
-		MessSrv: messSrv,
-		ChatSrv: chatSrv,
-		logger:  logger,
-		Manager: tokenManager,
+		MessSrv:   messSrv,
+		ChatSrv:   chatSrv,
+		InviteSrv: inviteSrv,
+		logger:    logger,
+		ws:        wsManager,
+		Manager:   tokenManager,
 	}
 }
 
 func (h *ChatHandler) RegisterRoutes(r chi.Router) {
 	r.Group(func(r chi.Router) {
-		r.Use(mwMiddleware.JWTAuth(h.Manager, h.Manager, h.logger))
-		r.Post("/", h.CreateChatHandler)
-		r.Get("/", h.GetChatsHandler)
+		r.Use(mwMiddleware.JWTAuth(h.Manager, h.Manager, h.Manager, h.logger))
+		r.With(mwMiddleware.RequireScope(jwt.ScopeChatsWrite)).Post("/", h.CreateChatHandler)
+		r.With(mwMiddleware.RequireScope(jwt.ScopeChatsRead)).Get("/", h.GetChatsHandler)
+		r.With(mwMiddleware.RequireScope(jwt.ScopeChatsRead)).Get("/direct/{user_id}", h.GetDirectChatHandler)
+		r.With(mwMiddleware.RequireScope(jwt.ScopeChatsWrite)).Post("/direct/{user_id}", h.OpenDirectChatHandler)
+		r.With(mwMiddleware.RequireScope(jwt.ScopeChatsWrite)).Post("/public", h.CreatePublicChatHandler)
+		r.With(mwMiddleware.RequireScope(jwt.ScopeChatsRead)).Get("/public", h.SearchPublicChatsHandler)
 		r.Get("/{chat_id}", h.OpenChatHandler)
 		r.Delete("/{chat_id}", h.DeleteChatHandler)
+		r.Patch("/{chat_id}", h.UpdateChatHandler)
 		r.Post("/{chat_id}/members", h.AddMembersHandler)
+		r.Delete("/{chat_id}/members/{user_id}", h.KickMemberHandler)
+		r.Post("/{chat_id}/bans", h.BanMemberHandler)
+		r.Get("/{chat_id}/bans", h.ListBansHandler)
+		r.Delete("/{chat_id}/bans/{user_id}", h.UnbanMemberHandler)
+		r.Post("/{chat_id}/restrictions", h.RestrictMemberHandler)
+		r.Get("/{chat_id}/restrictions", h.ListRestrictionsHandler)
+		r.Delete("/{chat_id}/restrictions/{user_id}", h.LiftRestrictionsHandler)
+		r.Put("/{chat_id}/members/{user_id}/role", h.SetMemberRoleHandler)
+		r.Post("/{chat_id}/owner", h.TransferOwnershipHandler)
+		r.Put("/{chat_id}/title", h.RenameChatHandler)
+		r.Put("/{chat_id}/pin", h.PinMessageHandler)
+		r.Delete("/{chat_id}/pin", h.UnpinMessageHandler)
+		r.Get("/{chat_id}/preview", h.PreviewPublicChatHandler)
+		r.Post("/{chat_id}/join", h.JoinPublicChatHandler)
+		r.Post("/{chat_id}/leave", h.LeaveChatHandler)
+		r.Post("/{chat_id}/read", h.MarkReadHandler)
+		r.Patch("/{chat_id}/settings", h.UpdateChatSettingsHandler)
+		r.Post("/{chat_id}/join-requests", h.RequestToJoinHandler)
+		r.Get("/{chat_id}/join-requests", h.ListJoinRequestsHandler)
+		r.Post("/{chat_id}/join-requests/{request_id}/approve", h.ApproveJoinRequestHandler)
+		r.Post("/{chat_id}/join-requests/{request_id}/reject", h.RejectJoinRequestHandler)
+
+		r.Post("/{chat_id}/invites", h.CreateInviteHandler)
+		r.Get("/{chat_id}/invites", h.ListInvitesHandler)
+		r.Delete("/{chat_id}/invites/{invite_id}", h.RevokeInviteHandler)
+		r.With(mwMiddleware.HumanOnly).Post("/invites/{code}", h.RedeemInviteHandler)
 	})
 }
 
@@ -83,18 +151,7 @@ func (h *ChatHandler) CreateChatHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	hasSelf := false
-	for _, m := range members {
-		if m == userID {
-			hasSelf = true
-			break
-		}
-	}
-	if !hasSelf {
-		members = append(members, userID)
-	}
-
-	createdChat, err := h.ChatSrv.CreateChat(r.Context(), title, chat.IsPrivate, members)
+	createdChat, err := h.ChatSrv.CreateChat(r.Context(), userID, title, chat.IsPrivate, members)
 	if err != nil {
 		h.logger.Error("failed to create chat", slog.String("error", err.Error()))
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -106,6 +163,13 @@ func (h *ChatHandler) CreateChatHandler(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(createdChat)
 }
 
+type chatPage struct {
+	Chats      []dom.Chat `json:"chats"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// GetChatsHandler lists the caller's chats, most recently active first.
+// Pages are continued with cursor.
 func (h *ChatHandler) GetChatsHandler(w http.ResponseWriter, r *http.Request) {
 	userID, ok := mwMiddleware.GetUserID(r.Context())
 	if !ok {
@@ -113,19 +177,45 @@ func (h *ChatHandler) GetChatsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	chats, err := h.ChatSrv.ListOfChats(r.Context(), userID)
+	q := r.URL.Query()
+	var limit int
+	if v := q.Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = l
+	}
+
+	chats, next, err := h.ChatSrv.ListOfChats(r.Context(), userID, q.Get("cursor"), limit)
 	if err != nil {
-		h.logger.Error("failed to get list of chats", slog.String("error", err.Error()))
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.writeError(w, "failed to get list of chats", err)
 		return
 	}
+	h.writeJSON(w, http.StatusOK, chatPage{Chats: chats, NextCursor: next})
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(chats); err != nil {
-		h.logger.Error("failed to encode response", slog.String("error", err.Error()))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+// UpdateChatSettingsHandler pins or mutes the chat for the caller.
+func (h *ChatHandler) UpdateChatSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, userID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var settings dom.ChatSettings
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&settings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ChatSrv.UpdateChatSettings(r.Context(), chatID, userID, settings); err != nil {
+		h.writeError(w, "failed to update chat settings", err)
 		return
 	}
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (h *ChatHandler) OpenChatHandler(w http.ResponseWriter, r *http.Request) {
@@ -141,6 +231,11 @@ func (h *ChatHandler) OpenChatHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
+	if !mwMiddleware.HasScope(r.Context(), jwt.ScopeChatsRead, chatID) ||
+		!mwMiddleware.HasScope(r.Context(), jwt.ScopeMessagesRead, chatID) {
+		http.Error(w, customerrors.ErrInsufficientScope.Error(), http.StatusForbidden)
+		return
+	}
 
 	limitStr := r.URL.Query().Get("limit")
 	limit, err := strconv.ParseInt(limitStr, 10, 64)
@@ -187,10 +282,18 @@ func (h *ChatHandler) DeleteChatHandler(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "invalid chat id", http.StatusBadRequest)
 		return
 	}
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !mwMiddleware.HasScope(r.Context(), jwt.ScopeChatsWrite, chatID) {
+		http.Error(w, customerrors.ErrInsufficientScope.Error(), http.StatusForbidden)
+		return
+	}
 
-	if err := h.ChatSrv.DeleteChat(r.Context(), chatID); err != nil {
-		h.logger.Error("failed to delete chat", slog.String("error", err.Error()))
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.ChatSrv.DeleteChat(r.Context(), chatID, userID); err != nil {
+		h.writeError(w, "failed to delete chat", err)
 		return
 	}
 
@@ -210,6 +313,10 @@ func (h *ChatHandler) AddMembersHandler(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
+	if !mwMiddleware.HasScope(r.Context(), jwt.ScopeChatsWrite, chatID) {
+		http.Error(w, customerrors.ErrInsufficientScope.Error(), http.StatusForbidden)
+		return
+	}
 
 	var requestData struct {
 		Members []int64 `json:"members"`
@@ -222,12 +329,13 @@ func (h *ChatHandler) AddMembersHandler(w http.ResponseWriter, r *http.Request)
 
 	if err := h.ChatSrv.AddMembers(r.Context(), chatID, userID, requestData.Members); err != nil {
 		h.logger.Error("failed to add members to chat", slog.String("error", err.Error()))
-		switch err {
-		case customerrors.ErrUserAlreadyInChat:
+		switch {
+		case errors.Is(err, customerrors.ErrUserAlreadyInChat):
 			http.Error(w, "conflict", http.StatusConflict)
-		case customerrors.ErrInvalidInput:
+		case errors.Is(err, customerrors.ErrInvalidInput):
 			http.Error(w, "not found", http.StatusNotFound)
-		case customerrors.ErrUserNotMemberOfChat:
+		case errors.Is(err, customerrors.ErrUserNotMemberOfChat),
+			errors.Is(err, customerrors.ErrChatPermissionDenied):
 			http.Error(w, "no permission", http.StatusForbidden)
 		default:
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -236,3 +344,238 @@ func (h *ChatHandler) AddMembersHandler(w http.ResponseWriter, r *http.Request)
 	}
 	w.WriteHeader(http.StatusCreated)
 }
+
+// KickMemberHandler removes the member from the chat. Removing yourself is
+// the same as leaving.
+func (h *ChatHandler) KickMemberHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, actorID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if userID == actorID {
+		err = h.ChatSrv.LeaveChat(r.Context(), chatID, userID)
+	} else {
+		err = h.ChatSrv.KickMember(r.Context(), chatID, actorID, userID)
+	}
+	if err != nil {
+		h.writeError(w, "failed to remove chat member", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ChatHandler) SetMemberRoleHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, actorID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	var requestData struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ChatSrv.SetMemberRole(r.Context(), chatID, actorID, userID, requestData.Role); err != nil {
+		h.writeError(w, "failed to change member role", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ChatHandler) TransferOwnershipHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, actorID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData struct {
+		UserID int64 `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ChatSrv.TransferOwnership(r.Context(), chatID, actorID, requestData.UserID); err != nil {
+		h.writeError(w, "failed to transfer chat ownership", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RenameChatHandler changes the title only; it is UpdateChatHandler with a
+// single field, kept for older clients.
+func (h *ChatHandler) RenameChatHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, userID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData struct {
+		Title string `json:"title"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	chat, msg, err := h.ChatSrv.UpdateChat(r.Context(), chatID, userID, dom.ChatUpdate{Title: &requestData.Title})
+	if err != nil {
+		h.writeError(w, "failed to rename chat", err)
+		return
+	}
+	h.notifyChatUpdated(chatID, userID, chat, msg)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateChatHandler changes the title, description or avatar_url of the
+// chat; omitted fields are kept. Members are sent chat_updated and the
+// system message over the websocket.
+func (h *ChatHandler) UpdateChatHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, userID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var upd dom.ChatUpdate
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&upd); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	chat, msg, err := h.ChatSrv.UpdateChat(r.Context(), chatID, userID, upd)
+	if err != nil {
+		h.writeError(w, "failed to update chat", err)
+		return
+	}
+	h.notifyChatUpdated(chatID, userID, chat, msg)
+	h.writeJSON(w, http.StatusOK, chat)
+}
+
+// notifyChatUpdated sends chat_updated and the system message, if any, to
+// the members of the chat.
+func (h *ChatHandler) notifyChatUpdated(chatID, userID int64, chat dom.Chat, msg *dom.Message) {
+	go func(chatID, userID int64) {
+		bctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		members, err := h.ChatSrv.ChatMemberIDs(bctx, chatID, userID)
+		if err != nil {
+			h.logger.Error("failed to get chat members", slog.String("error", err.Error()))
+			return
+		}
+		for _, memberID := range members {
+			h.ws.WsUnicast(memberID, map[string]interface{}{
+				"type": "chat_updated",
+				"data": chat,
+			})
+			if msg != nil {
+				h.ws.WsUnicast(memberID, map[string]interface{}{
+					"type": "new_message",
+					"data": msg,
+				})
+			}
+		}
+	}(chatID, userID)
+}
+
+func (h *ChatHandler) PinMessageHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, userID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData struct {
+		MessageID string `json:"message_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ChatSrv.PinMessage(r.Context(), chatID, userID, requestData.MessageID); err != nil {
+		h.writeError(w, "failed to pin message", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ChatHandler) UnpinMessageHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, userID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.ChatSrv.UnpinMessage(r.Context(), chatID, userID); err != nil {
+		h.writeError(w, "failed to unpin message", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// chatWriteRequest reads the chat id and the caller of a request that changes
+// the chat, writing the error response itself when either is unusable.
+func (h *ChatHandler) chatWriteRequest(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	chatID, err := strconv.ParseInt(chi.URLParam(r, "chat_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid chat id", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return 0, 0, false
+	}
+	if !mwMiddleware.HasScope(r.Context(), jwt.ScopeChatsWrite, chatID) {
+		http.Error(w, customerrors.ErrInsufficientScope.Error(), http.StatusForbidden)
+		return 0, 0, false
+	}
+	return chatID, userID, true
+}
+
+// writeError maps chat service errors to responses.
+func (h *ChatHandler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, customerrors.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, customerrors.ErrNotFound):
+		http.Error(w, "chat not found", http.StatusNotFound)
+	case errors.Is(err, customerrors.ErrUserNotFound):
+		http.Error(w, customerrors.ErrUserNotFound.Error(), http.StatusNotFound)
+	case errors.Is(err, customerrors.ErrUserNotMemberOfChat):
+		http.Error(w, customerrors.ErrUserNotMemberOfChat.Error(), http.StatusForbidden)
+	case errors.Is(err, customerrors.ErrChatPermissionDenied):
+		http.Error(w, customerrors.ErrChatPermissionDenied.Error(), http.StatusForbidden)
+	case errors.Is(err, customerrors.ErrInvalidInvite):
+		http.Error(w, customerrors.ErrInvalidInvite.Error(), http.StatusGone)
+	case errors.Is(err, customerrors.ErrUserAlreadyInChat):
+		http.Error(w, customerrors.ErrUserAlreadyInChat.Error(), http.StatusConflict)
+	case errors.Is(err, customerrors.ErrMemberRestricted):
+		http.Error(w, customerrors.ErrMemberRestricted.Error(), http.StatusForbidden)
+	case errors.Is(err, customerrors.ErrUserBanned):
+		http.Error(w, customerrors.ErrUserBanned.Error(), http.StatusForbidden)
+	case errors.Is(err, customerrors.ErrMessageDoesNotExists):
+		http.Error(w, customerrors.ErrMessageDoesNotExists.Error(), http.StatusNotFound)
+	case errors.Is(err, customerrors.ErrHandleTaken):
+		http.Error(w, customerrors.ErrHandleTaken.Error(), http.StatusConflict)
+	default:
+		h.logger.Error(msg, slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,61 @@
+package chat
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+
+	mwMiddleware "main/internal/delivery/http/middleware/auth"
+)
+
+// GetDirectChatHandler returns the caller's direct chat with the user, or 404
+// if they have none yet.
+func (h *ChatHandler) GetDirectChatHandler(w http.ResponseWriter, r *http.Request) {
+	userID, otherID, ok := h.directChatRequest(w, r)
+	if !ok {
+		return
+	}
+
+	chat, err := h.ChatSrv.GetDirectChat(r.Context(), userID, otherID)
+	if err != nil {
+		h.writeError(w, "failed to get direct chat", err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, chat)
+}
+
+// OpenDirectChatHandler returns the caller's direct chat with the user,
+// creating it on first use. Repeated calls return the same chat.
+func (h *ChatHandler) OpenDirectChatHandler(w http.ResponseWriter, r *http.Request) {
+	userID, otherID, ok := h.directChatRequest(w, r)
+	if !ok {
+		return
+	}
+
+	chat, created, err := h.ChatSrv.OpenDirectChat(r.Context(), userID, otherID)
+	if err != nil {
+		h.writeError(w, "failed to open direct chat", err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	h.writeJSON(w, status, chat)
+}
+
+func (h *ChatHandler) directChatRequest(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return 0, 0, false
+	}
+	otherID, err := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return userID, otherID, true
+}
@@ -0,0 +1,109 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+
+	mwMiddleware "main/internal/delivery/http/middleware/auth"
+	dom "main/internal/domain/entity"
+)
+
+type InviteService interface {
+	CreateInvite(ctx context.Context, chatID, userID int64, opts dom.InviteOptions) (dom.ChatInvite, error)
+	ListInvites(ctx context.Context, chatID, userID int64) ([]dom.ChatInvite, error)
+	RevokeInvite(ctx context.Context, chatID, userID, inviteID int64) error
+	RedeemInvite(ctx context.Context, userID int64, code string) (int64, *dom.JoinRequest, error)
+}
+
+type redeemInviteResponse struct {
+	ChatID  int64 `json:"chat_id"`
+	Pending bool  `json:"pending"`
+}
+
+func (h *ChatHandler) CreateInviteHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, userID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var opts dom.InviteOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	invite, err := h.InviteSrv.CreateInvite(r.Context(), chatID, userID, opts)
+	if err != nil {
+		h.writeError(w, "failed to create invite", err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, invite)
+}
+
+func (h *ChatHandler) ListInvitesHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, userID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	invites, err := h.InviteSrv.ListInvites(r.Context(), chatID, userID)
+	if err != nil {
+		h.writeError(w, "failed to list invites", err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, invites)
+}
+
+func (h *ChatHandler) RevokeInviteHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, userID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+	inviteID, err := strconv.ParseInt(chi.URLParam(r, "invite_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid invite id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.InviteSrv.RevokeInvite(r.Context(), chatID, userID, inviteID); err != nil {
+		h.writeError(w, "failed to revoke invite", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RedeemInviteHandler joins the caller to the chat of the invite. Invites
+// that need approval file a join request, tell the admins and answer 202.
+func (h *ChatHandler) RedeemInviteHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	chatID, req, err := h.InviteSrv.RedeemInvite(r.Context(), userID, chi.URLParam(r, "code"))
+	if err != nil {
+		h.writeError(w, "failed to redeem invite", err)
+		return
+	}
+
+	status := http.StatusOK
+	if req != nil {
+		h.notifyJoinRequest(*req)
+		status = http.StatusAccepted
+	}
+	h.writeJSON(w, status, redeemInviteResponse{ChatID: chatID, Pending: req != nil})
+}
+
+func (h *ChatHandler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,111 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	dom "main/internal/domain/entity"
+
+	"github.com/go-chi/chi"
+)
+
+// RequestToJoinHandler files a request to join a private chat with an
+// optional message and tells the chat admins about it.
+func (h *ChatHandler) RequestToJoinHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, userID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Message string `json:"message"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	req, err := h.ChatSrv.RequestToJoin(r.Context(), chatID, userID, body.Message)
+	if err != nil {
+		h.writeError(w, "failed to request to join chat", err)
+		return
+	}
+
+	h.notifyJoinRequest(req)
+	h.writeJSON(w, http.StatusAccepted, req)
+}
+
+// notifyJoinRequest sends join_request to the admins of the chat.
+func (h *ChatHandler) notifyJoinRequest(req dom.JoinRequest) {
+	go func(req dom.JoinRequest) {
+		bctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		admins, err := h.ChatSrv.ChatAdminIDs(bctx, req.ChatID)
+		if err != nil {
+			h.logger.Error("failed to get chat admins", slog.String("error", err.Error()))
+			return
+		}
+		for _, adminID := range admins {
+			h.ws.WsUnicast(adminID, map[string]interface{}{
+				"type": "join_request",
+				"data": req,
+			})
+		}
+	}(req)
+}
+
+func (h *ChatHandler) ListJoinRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, actorID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	requests, err := h.ChatSrv.ListJoinRequests(r.Context(), chatID, actorID)
+	if err != nil {
+		h.writeError(w, "failed to list join requests", err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, requests)
+}
+
+func (h *ChatHandler) ApproveJoinRequestHandler(w http.ResponseWriter, r *http.Request) {
+	h.decideJoinRequest(w, r, h.ChatSrv.ApproveJoinRequest, "failed to approve join request")
+}
+
+func (h *ChatHandler) RejectJoinRequestHandler(w http.ResponseWriter, r *http.Request) {
+	h.decideJoinRequest(w, r, h.ChatSrv.RejectJoinRequest, "failed to reject join request")
+}
+
+// decideJoinRequest runs decide on {request_id} and lets the requester know
+// the outcome.
+func (h *ChatHandler) decideJoinRequest(w http.ResponseWriter, r *http.Request,
+	decide func(ctx context.Context, chatID, actorID, requestID int64) (dom.JoinRequest, error), errMsg string) {
+	chatID, actorID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+	requestID, err := strconv.ParseInt(chi.URLParam(r, "request_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid request id", http.StatusBadRequest)
+		return
+	}
+
+	req, err := decide(r.Context(), chatID, actorID, requestID)
+	if err != nil {
+		h.writeError(w, errMsg, err)
+		return
+	}
+
+	go h.ws.WsUnicast(req.UserID, map[string]interface{}{
+		"type": "join_request_decided",
+		"data": req,
+	})
+
+	h.writeJSON(w, http.StatusOK, req)
+}
@@ -0,0 +1,129 @@
+package chat
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+
+	mwMiddleware "main/internal/delivery/http/middleware/auth"
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+	"main/pkg/jwt"
+)
+
+type publicChatPage struct {
+	Chats      []dom.Chat `json:"chats"`
+	NextCursor int64      `json:"next_cursor,omitempty"`
+}
+
+// CreatePublicChatHandler creates a public chat from {title, handle,
+// description}; the caller becomes its owner.
+func (h *ChatHandler) CreatePublicChatHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Title       string `json:"title"`
+		Handle      string `json:"handle"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	chat, err := h.ChatSrv.CreatePublicChat(r.Context(), userID, req.Title, req.Handle, req.Description)
+	if err != nil {
+		h.writeError(w, "failed to create public chat", err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, chat)
+}
+
+// SearchPublicChatsHandler lists public chats matching the q parameter by
+// title or handle. Pages are continued with cursor.
+func (h *ChatHandler) SearchPublicChatsHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var cursor int64
+	if v := q.Get("cursor"); v != "" {
+		c, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+		cursor = c
+	}
+	var limit int
+	if v := q.Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = l
+	}
+
+	chats, next, err := h.ChatSrv.SearchPublicChats(r.Context(), q.Get("q"), cursor, limit)
+	if err != nil {
+		h.writeError(w, "failed to search public chats", err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, publicChatPage{Chats: chats, NextCursor: next})
+}
+
+// PreviewPublicChatHandler returns a public chat with its latest messages.
+// The caller does not have to be a member.
+func (h *ChatHandler) PreviewPublicChatHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, err := strconv.ParseInt(chi.URLParam(r, "chat_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid chat id", http.StatusBadRequest)
+		return
+	}
+	if !mwMiddleware.HasScope(r.Context(), jwt.ScopeChatsRead, chatID) ||
+		!mwMiddleware.HasScope(r.Context(), jwt.ScopeMessagesRead, chatID) {
+		http.Error(w, customerrors.ErrInsufficientScope.Error(), http.StatusForbidden)
+		return
+	}
+
+	// A missing or bad limit falls back to the service default.
+	limit, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64)
+
+	chat, messages, err := h.ChatSrv.PreviewPublicChat(r.Context(), chatID, limit)
+	if err != nil {
+		h.writeError(w, "failed to preview public chat", err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, struct {
+		Chat     dom.Chat      `json:"chat"`
+		Messages []dom.Message `json:"messages"`
+	}{Chat: chat, Messages: messages})
+}
+
+func (h *ChatHandler) JoinPublicChatHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, userID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := h.ChatSrv.JoinPublicChat(r.Context(), chatID, userID); err != nil {
+		h.writeError(w, "failed to join chat", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ChatHandler) LeaveChatHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, userID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := h.ChatSrv.LeaveChat(r.Context(), chatID, userID); err != nil {
+		h.writeError(w, "failed to leave chat", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
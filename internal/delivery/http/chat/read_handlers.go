@@ -0,0 +1,64 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	dom "main/internal/domain/entity"
+)
+
+// MarkReadHandler marks the chat read up to message_id and returns the
+// caller's unread count. When the marker moves, the other members get a
+// read receipt.
+func (h *ChatHandler) MarkReadHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, userID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		MessageID string `json:"message_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	state, advanced, err := h.ChatSrv.MarkRead(r.Context(), chatID, userID, req.MessageID)
+	if err != nil {
+		h.writeError(w, "failed to mark chat read", err)
+		return
+	}
+
+	if advanced {
+		go func(state dom.ReadState) {
+			bctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			members, err := h.ChatSrv.ChatMemberIDs(bctx, state.ChatID, state.UserID)
+			if err != nil {
+				h.logger.Error("failed to get chat members", slog.String("error", err.Error()))
+				return
+			}
+			receipt := map[string]interface{}{
+				"chat_id":    state.ChatID,
+				"user_id":    state.UserID,
+				"message_id": state.LastReadMessageID,
+				"read_at":    state.LastReadAt,
+			}
+			for _, memberID := range members {
+				if memberID == state.UserID {
+					continue
+				}
+				h.ws.WsUnicast(memberID, map[string]interface{}{
+					"type": "read_receipt",
+					"data": receipt,
+				})
+			}
+		}(state)
+	}
+
+	h.writeJSON(w, http.StatusOK, state)
+}
@@ -0,0 +1,75 @@
+package chat
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+// RestrictMemberHandler takes actions away from a member for a while, e.g.
+// {"user_id": 7, "restrictions": ["send"], "duration": "1h"}.
+func (h *ChatHandler) RestrictMemberHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, actorID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		UserID       int64    `json:"user_id"`
+		Restrictions []string `json:"restrictions"`
+		Duration     string   `json:"duration"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		http.Error(w, "invalid duration", http.StatusBadRequest)
+		return
+	}
+
+	restrictions, err := h.ChatSrv.RestrictMember(r.Context(), chatID, actorID, req.UserID, req.Restrictions, duration)
+	if err != nil {
+		h.writeError(w, "failed to restrict chat member", err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, restrictions)
+}
+
+func (h *ChatHandler) ListRestrictionsHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, actorID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	restrictions, err := h.ChatSrv.ListRestrictions(r.Context(), chatID, actorID)
+	if err != nil {
+		h.writeError(w, "failed to list chat restrictions", err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, restrictions)
+}
+
+// LiftRestrictionsHandler ends the restrictions named by repeated
+// restriction parameters, or all of them if there are none.
+func (h *ChatHandler) LiftRestrictionsHandler(w http.ResponseWriter, r *http.Request) {
+	chatID, actorID, ok := h.chatWriteRequest(w, r)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ChatSrv.LiftRestrictions(r.Context(), chatID, actorID, userID, r.URL.Query()["restriction"]); err != nil {
+		h.writeError(w, "failed to lift chat restrictions", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
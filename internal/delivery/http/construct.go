@@ -3,27 +3,41 @@ package handlers
 import (
 	"log/slog"
 
+	account "main/internal/delivery/http/account"
+	audit "main/internal/delivery/http/audit"
+	bot "main/internal/delivery/http/bot"
 	chat "main/internal/delivery/http/chat"
 	message "main/internal/delivery/http/message"
 	user "main/internal/delivery/http/user"
+	wellknown "main/internal/delivery/http/wellknown"
 
 	"github.com/go-chi/chi"
 )
 
 type HTTPHandler struct {
-	UserHandler    *user.UserHandler
-	ChatHandler    *chat.ChatHandler
-	MessageHandler *message.MessageHandler
-	Logger         *slog.Logger
+	UserHandler      *user.UserHandler
+	ChatHandler      *chat.ChatHandler
+	MessageHandler   *message.MessageHandler
+	WellKnownHandler *wellknown.WellKnownHandler
+	BotHandler       *bot.BotHandler
+	AuditHandler     *audit.AuditHandler
+	AccountHandler   *account.AccountHandler
+	Logger           *slog.Logger
 }
 
 func NewHTTPHandler(userHandler *user.UserHandler, chatHandler *chat.ChatHandler,
-	messageHandler *message.MessageHandler, logger *slog.Logger) *HTTPHandler {
+	messageHandler *message.MessageHandler, wellKnownHandler *wellknown.WellKnownHandler,
+	botHandler *bot.BotHandler, auditHandler *audit.AuditHandler,
+	accountHandler *account.AccountHandler, logger *slog.Logger) *HTTPHandler {
 	return &HTTPHandler{
-		UserHandler:    userHandler,
-		ChatHandler:    chatHandler,
-		MessageHandler: messageHandler,
-		Logger:         logger,
+		UserHandler:      userHandler,
+		ChatHandler:      chatHandler,
+		MessageHandler:   messageHandler,
+		WellKnownHandler: wellKnownHandler,
+		BotHandler:       botHandler,
+		AuditHandler:     auditHandler,
+		AccountHandler:   accountHandler,
+		Logger:           logger,
 	}
 }
 
@@ -40,4 +54,20 @@ func (h *HTTPHandler) RegisterRoutes(r chi.Router) {
 	r.Route("/messages", func(r chi.Router) {
 		h.MessageHandler.RegisterRoutes(r)
 	})
+
+	r.Route("/bots", func(r chi.Router) {
+		h.BotHandler.RegisterRoutes(r)
+	})
+
+	r.Route("/account", func(r chi.Router) {
+		h.AccountHandler.RegisterRoutes(r)
+	})
+
+	r.Route("/admin/audit", func(r chi.Router) {
+		h.AuditHandler.RegisterRoutes(r)
+	})
+
+	r.Route("/.well-known", func(r chi.Router) {
+		h.WellKnownHandler.RegisterRoutes(r)
+	})
 }
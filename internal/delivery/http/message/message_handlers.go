@@ -3,6 +3,7 @@ package message
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -13,6 +14,7 @@ import (
 	mwMiddleware "main/internal/delivery/http/middleware/auth"
 	"main/internal/delivery/ws"
 	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
 	"main/pkg/jwt"
 )
 
@@ -37,15 +39,16 @@ type MessageService interface {
 }
 
 type ChatService interface {
-	CreateChat(ctx context.Context, title string, isPrivate bool, members []int64) (dom.Chat, error)
+	CreateChat(ctx context.Context, ownerID int64, title string, isPrivate bool, members []int64) (dom.Chat, error)
 	AddMembers(ctx context.Context, chatID, userID int64, members []int64) error
-	RemoveMember(ctx context.Context, chatID, userID int64) error
+	KickMember(ctx context.Context, chatID, actorID, userID int64) error
 	GetChatDetails(ctx context.Context, chatID int64, userID int64) (dom.Chat, error)
 }
 
 type JWTManager interface {
 	Exists(context.Context, string) (bool, error)
 	Parse(string) (*jwt.TokenClaims, error)
+	AuthenticateAPIKey(context.Context, string) (*jwt.TokenClaims, error)
 }
 
 type MessageHandler struct {
@@ -75,7 +78,7 @@ func NewMessageHandler(
 // /chats/{id}/messages
 func (h *MessageHandler) RegisterRoutes(r chi.Router) {
 	r.Group(func(r chi.Router) {
-		r.Use(mwMiddleware.JWTAuth(h.Manager, h.Manager, h.logger))
+		r.Use(mwMiddleware.JWTAuth(h.Manager, h.Manager, h.Manager, h.logger))
 
 		r.Post("/", h.SendMessage)
 		r.Delete("/{msg_id}", h.DeleteMessageHandler)
@@ -93,10 +96,12 @@ func (h *MessageHandler) ConnectWebSocket(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	claims, err := h.Manager.Parse(tokenString)
+	claims, err := jwt.Authenticate(r.Context(), h.Manager, h.Manager, tokenString)
 	if err != nil {
 		h.logger.Error("ws auth failed", "error", err)
-		http.Error(w, "invalid token", http.StatusUnauthorized)
+		if !mwMiddleware.WriteTokenError(w, err) {
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -113,6 +118,9 @@ func (h *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	if !h.authorize(w, r, jwt.ScopeMessagesWrite, request.ChatID, &request.SenderID) {
+		return
+	}
 
 	message, err := h.MessSrv.SendMessage(r.Context(),
 		request.ChatID,
@@ -120,6 +128,10 @@ func (h *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		request.SenderUsername,
 		request.Text)
 	if err != nil {
+		if errors.Is(err, customerrors.ErrEmailNotVerified) || errors.Is(err, customerrors.ErrMemberRestricted) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		h.logger.Error("failed to send message", slog.Any("error", err.Error()))
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -161,6 +173,9 @@ func (h *MessageHandler) DeleteMessageHandler(w http.ResponseWriter, r *http.Req
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	if !h.authorize(w, r, jwt.ScopeMessagesWrite, request.ChatID, &request.UserID) {
+		return
+	}
 
 	if err := h.MessSrv.DeleteMessage(r.Context(), request.UserID, request.ChatID, request.MessageID); err != nil {
 		h.logger.Error("failed to delete message", slog.Any("error", err.Error()))
@@ -200,8 +215,15 @@ func (h *MessageHandler) EditMessage(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	if !h.authorize(w, r, jwt.ScopeMessagesWrite, request.ChatID, &request.SenderID) {
+		return
+	}
 
 	if err := h.MessSrv.EditMessage(r.Context(), request.SenderID, request.ChatID, request.MessageID, request.NewText); err != nil {
+		if errors.Is(err, customerrors.ErrMemberRestricted) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		h.logger.Error("failed to edit message", slog.Any("error", err.Error()))
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -246,6 +268,10 @@ func (h *MessageHandler) ListMessageHandlers(w http.ResponseWriter, r *http.Requ
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	if !mwMiddleware.HasScope(r.Context(), jwt.ScopeMessagesRead, chatID) {
+		http.Error(w, customerrors.ErrInsufficientScope.Error(), http.StatusForbidden)
+		return
+	}
 
 	messages, err := h.MessSrv.GetMessages(r.Context(), userID, chatID, lastMsgStr, lastMsgID, 50)
 	if err != nil {
@@ -261,3 +287,19 @@ func (h *MessageHandler) ListMessageHandlers(w http.ResponseWriter, r *http.Requ
 		return
 	}
 }
+
+// authorize checks the caller's scope on the chat and makes the
+// authenticated user the acting user, whatever the request body claims.
+func (h *MessageHandler) authorize(w http.ResponseWriter, r *http.Request, scope string, chatID int64, actingUserID *int64) bool {
+	userID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+	if !mwMiddleware.HasScope(r.Context(), scope, chatID) {
+		http.Error(w, customerrors.ErrInsufficientScope.Error(), http.StatusForbidden)
+		return false
+	}
+	*actingUserID = userID
+	return true
+}
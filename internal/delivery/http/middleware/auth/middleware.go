@@ -2,20 +2,18 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	ctxHelper "main/pkg/jwt/context"
 	"net/http"
 	"strings"
 
+	"main/pkg/apikey"
 	"main/pkg/customerrors"
 	"main/pkg/jwt"
 )
 
-type ContextKey string
-
-const UserIDKey ContextKey = "user_id"
-
 type TokenParser interface {
 	Parse(accessToken string) (*jwt.TokenClaims, error)
 }
@@ -24,7 +22,15 @@ type TokenBlacklistChecker interface {
 	Exists(ctx context.Context, key string) (bool, error)
 }
 
-func JWTAuth(parser TokenParser, blacklist TokenBlacklistChecker, log *slog.Logger) func(http.Handler) http.Handler {
+// APIKeyAuthenticator resolves the API key of a bot to its claims.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*jwt.TokenClaims, error)
+}
+
+// JWTAuth authenticates requests with a bearer access token or, when apiKeys
+// is not nil, with a bot's API key. Handlers check the scopes of key holders
+// with HasScope or RequireScope.
+func JWTAuth(parser TokenParser, blacklist TokenBlacklistChecker, apiKeys APIKeyAuthenticator, log *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			var tokenString string
@@ -50,20 +56,27 @@ func JWTAuth(parser TokenParser, blacklist TokenBlacklistChecker, log *slog.Logg
 				tokenString = parts[1]
 			}
 
-			isBanned, err := blacklist.Exists(r.Context(), tokenString)
-			if err != nil {
-				log.Error("failed to check blacklist", slog.String("error", err.Error()))
-				http.Error(w, "internal server error", http.StatusInternalServerError)
-				return
-			}
-			if isBanned {
-				http.Error(w, "token is revoked", http.StatusUnauthorized)
+			if apiKeys != nil && apikey.IsKey(tokenString) {
+				claims, err := apiKeys.AuthenticateAPIKey(r.Context(), tokenString)
+				if err != nil {
+					if errors.Is(err, customerrors.ErrInvalidAPIKey) {
+						http.Error(w, "invalid api key", http.StatusUnauthorized)
+						return
+					}
+					log.Error("failed to check api key", slog.String("error", err.Error()))
+					http.Error(w, "internal server error", http.StatusInternalServerError)
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(ctxHelper.ToContext(r.Context(), claims)))
 				return
 			}
 
-			claims, err := parser.Parse(tokenString)
+			claims, err := jwt.Authenticate(r.Context(), parser, blacklist, tokenString)
 			if err != nil {
-				http.Error(w, "invalid token", http.StatusUnauthorized)
+				if !WriteTokenError(w, err) {
+					log.Error("failed to check token", slog.String("error", err.Error()))
+					http.Error(w, "internal server error", http.StatusInternalServerError)
+				}
 				return
 			}
 			ctx := ctxHelper.ToContext(r.Context(), claims)
@@ -73,9 +86,78 @@ func JWTAuth(parser TokenParser, blacklist TokenBlacklistChecker, log *slog.Logg
 	}
 }
 
+// WriteTokenError answers 401 for the rejections of jwt.Authenticate and
+// reports whether err was one of them.
+func WriteTokenError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, jwt.ErrTokenRevoked), errors.Is(err, jwt.ErrSessionRevoked):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, jwt.ErrInvalidToken):
+		http.Error(w, jwt.ErrInvalidToken.Error(), http.StatusUnauthorized)
+	default:
+		return false
+	}
+	return true
+}
+
 func GetUserID(ctx context.Context) (int64, bool) {
-	userID, ok := ctx.Value(UserIDKey).(int64)
-	return userID, ok
+	claims, ok := ctxHelper.FromContext(ctx)
+	if !ok {
+		return 0, false
+	}
+	return claims.UserID, true
+}
+
+// HasScope reports whether the caller may use scope on chatID; see
+// jwt.TokenClaims.Allows.
+func HasScope(ctx context.Context, scope string, chatID int64) bool {
+	claims, ok := ctxHelper.FromContext(ctx)
+	return ok && claims.Allows(scope, chatID)
+}
+
+// RequireScope rejects API key holders lacking scope across all chats. Use
+// HasScope in the handler for operations on a single chat.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !HasScope(r.Context(), scope, 0) {
+				http.Error(w, customerrors.ErrInsufficientScope.Error(), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// HumanOnly rejects callers authenticated with an API key.
+func HumanOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if claims, ok := ctxHelper.FromContext(r.Context()); !ok || claims.APIKeyID != 0 {
+			http.Error(w, "not available to api keys", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// HasRole reports whether the caller holds role or a higher one.
+func HasRole(ctx context.Context, role string) bool {
+	claims, ok := ctxHelper.FromContext(ctx)
+	return ok && claims.HasRole(role)
+}
+
+// RequireRole rejects callers below role. API keys never pass a role above
+// jwt.RoleUser.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !HasRole(r.Context(), role) {
+				http.Error(w, customerrors.ErrInsufficientRole.Error(), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
 func isWebSocket(r *http.Request) bool {
@@ -84,7 +166,7 @@ func isWebSocket(r *http.Request) bool {
 }
 
 func GetUserIDFromContext(ctx context.Context) (int64, error) {
-	userID, ok := ctx.Value("user_id").(int64)
+	userID, ok := GetUserID(ctx)
 	if !ok {
 		return 0, fmt.Errorf("user id not found in context: %w", customerrors.ErrUserNotFound)
 	}
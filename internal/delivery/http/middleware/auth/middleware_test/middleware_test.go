@@ -10,7 +10,9 @@ import (
 	"testing"
 
 	middleware "main/internal/delivery/http/middleware/auth"
+	"main/pkg/customerrors"
 	"main/pkg/jwt"
+	ctxHelper "main/pkg/jwt/context"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -33,6 +35,14 @@ func (m *MockJWTManager) Exists(ctx context.Context, token string) (bool, error)
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockJWTManager) AuthenticateAPIKey(ctx context.Context, key string) (*jwt.TokenClaims, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*jwt.TokenClaims), args.Error(1)
+}
+
 func TestJWTAuth(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
@@ -87,6 +97,17 @@ func TestJWTAuth(t *testing.T) {
 			},
 			expectedCode: 500,
 		},
+		{
+			name:        "Session Revoked",
+			headerName:  "Authorization",
+			headerValue: "Bearer session_token",
+			mockBehavior: func(m *MockJWTManager) {
+				m.On("Exists", mock.Anything, "session_token").Return(false, nil)
+				m.On("Parse", "session_token").Return(&jwt.TokenClaims{UserID: 10, SessionID: "sid", Exp: 1234567890}, nil)
+				m.On("Exists", mock.Anything, jwt.RevokedSessionKey("sid")).Return(true, nil)
+			},
+			expectedCode: 401,
+		},
 		{
 			name:        "Invalid Token Signature (Parse Error)",
 			headerName:  "Authorization",
@@ -97,6 +118,26 @@ func TestJWTAuth(t *testing.T) {
 			},
 			expectedCode: 401,
 		},
+		{
+			name:        "Valid API Key",
+			headerName:  "Authorization",
+			headerValue: "Bearer bot_valid",
+			mockBehavior: func(m *MockJWTManager) {
+				m.On("AuthenticateAPIKey", mock.Anything, "bot_valid").
+					Return(&jwt.TokenClaims{UserID: 20, APIKeyID: 1, Scopes: []string{jwt.ScopeMessagesWrite}}, nil)
+			},
+			expectedCode:   200,
+			expectedUserID: 20,
+		},
+		{
+			name:        "Revoked API Key",
+			headerName:  "Authorization",
+			headerValue: "Bearer bot_revoked",
+			mockBehavior: func(m *MockJWTManager) {
+				m.On("AuthenticateAPIKey", mock.Anything, "bot_revoked").Return(nil, customerrors.ErrInvalidAPIKey)
+			},
+			expectedCode: 401,
+		},
 	}
 
 	for _, tt := range tests {
@@ -104,7 +145,7 @@ func TestJWTAuth(t *testing.T) {
 			mockManager := new(MockJWTManager)
 			tt.mockBehavior(mockManager)
 
-			mw := middleware.JWTAuth(mockManager, mockManager, logger)
+			mw := middleware.JWTAuth(mockManager, mockManager, mockManager, logger)
 
 			nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				if tt.expectedUserID != 0 {
@@ -129,3 +170,91 @@ func TestJWTAuth(t *testing.T) {
 		})
 	}
 }
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name         string
+		claims       *jwt.TokenClaims
+		expectedCode int
+	}{
+		{
+			name:         "User token",
+			claims:       &jwt.TokenClaims{UserID: 1},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Key with scope",
+			claims:       &jwt.TokenClaims{UserID: 2, APIKeyID: 1, Scopes: []string{jwt.ScopeChatsRead}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Key with scope on one chat only",
+			claims:       &jwt.TokenClaims{UserID: 2, APIKeyID: 1, Scopes: []string{jwt.ScopeChatsRead + ":5"}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Key without scope",
+			claims:       &jwt.TokenClaims{UserID: 2, APIKeyID: 1, Scopes: []string{jwt.ScopeMessagesWrite}},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			req := httptest.NewRequest("GET", "/", nil)
+			req = req.WithContext(ctxHelper.ToContext(req.Context(), tt.claims))
+			w := httptest.NewRecorder()
+
+			middleware.RequireScope(jwt.ScopeChatsRead)(next).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name         string
+		claims       *jwt.TokenClaims
+		expectedCode int
+	}{
+		{
+			name:         "Admin",
+			claims:       &jwt.TokenClaims{UserID: 1, Role: jwt.RoleAdmin},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Moderator",
+			claims:       &jwt.TokenClaims{UserID: 1, Role: jwt.RoleModerator},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "User",
+			claims:       &jwt.TokenClaims{UserID: 1, Role: jwt.RoleUser},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "API key of an admin's bot",
+			claims:       &jwt.TokenClaims{UserID: 2, Role: jwt.RoleAdmin, APIKeyID: 1},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			req := httptest.NewRequest("GET", "/", nil)
+			req = req.WithContext(ctxHelper.ToContext(req.Context(), tt.claims))
+			w := httptest.NewRecorder()
+
+			middleware.RequireRole(jwt.RoleModerator)(next).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package requestinfo
+
+import (
+	"net"
+	"net/http"
+
+	"main/pkg/requestinfo"
+
+	"github.com/go-chi/chi/middleware"
+)
+
+// RequestInfo stores the client details of the request in the context for
+// the audit trail. It must run after chi's RequestID middleware; behind a
+// proxy, add chi's RealIP before it so RemoteAddr is the client address.
+func RequestInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+		ctx := requestinfo.ToContext(r.Context(), requestinfo.Info{
+			IP:        ip,
+			UserAgent: r.UserAgent(),
+			RequestID: middleware.GetReqID(r.Context()),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
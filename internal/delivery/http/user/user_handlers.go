@@ -3,23 +3,28 @@ package user
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi"
 
 	mwMiddleware "main/internal/delivery/http/middleware/auth"
 	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
 	"main/pkg/jwt"
 )
 
 type UserService interface {
 	SearchUser(ctx context.Context, query string) ([]dom.User, error)
+	SetRole(ctx context.Context, actorID, userID int64, role string) error
 }
 
 type JWTParser interface {
 	Exists(context.Context, string) (bool, error)
 	Parse(string) (*jwt.TokenClaims, error)
+	AuthenticateAPIKey(context.Context, string) (*jwt.TokenClaims, error)
 }
 
 type UserHandler struct {
@@ -38,6 +43,10 @@ func NewUserHandler(userSrv UserService,
 	}
 }
 
+type setRoleDTO struct {
+	Role string `json:"role"`
+}
+
 type registrationDTO struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
@@ -47,11 +56,45 @@ type registrationDTO struct {
 func (h *UserHandler) RegisterRoutes(r chi.Router) {
 
 	r.Group(func(r chi.Router) {
-		r.Use(mwMiddleware.JWTAuth(h.tokenParser, h.tokenParser, h.logger))
-		r.Get("/search", h.usersSearch)
+		r.Use(mwMiddleware.JWTAuth(h.tokenParser, h.tokenParser, h.tokenParser, h.logger))
+		r.With(mwMiddleware.RequireScope(jwt.ScopeUsersRead)).Get("/search", h.usersSearch)
+		r.With(mwMiddleware.RequireRole(jwt.RoleAdmin)).Put("/{user_id}/role", h.setRole)
 	})
 }
 
+func (h *UserHandler) setRole(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := mwMiddleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	userID, err := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	var request setRoleDTO
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.UserSrv.SetRole(r.Context(), actorID, userID, request.Role); err != nil {
+		switch {
+		case errors.Is(err, customerrors.ErrInvalidInput):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, customerrors.ErrUserNotFound):
+			http.Error(w, "user not found", http.StatusNotFound)
+		default:
+			h.logger.Error("failed to set user role", slog.String("error", err.Error()))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *UserHandler) usersSearch(w http.ResponseWriter, r *http.Request) {
 
 	query := r.URL.Query().Get("query")
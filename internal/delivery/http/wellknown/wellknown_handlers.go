@@ -0,0 +1,48 @@
+package wellknown
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	"main/pkg/jwt"
+)
+
+type KeySetProvider interface {
+	JWKS() jwt.JWKS
+}
+
+type WellKnownHandler struct {
+	keys   KeySetProvider
+	logger *slog.Logger
+}
+
+func NewWellKnownHandler(keys KeySetProvider, logger *slog.Logger) *WellKnownHandler {
+	return &WellKnownHandler{
+		keys:   keys,
+		logger: logger,
+	}
+}
+
+func (h *WellKnownHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/jwks.json", h.jwks)
+}
+
+func (h *WellKnownHandler) jwks(w http.ResponseWriter, r *http.Request) {
+	b, err := json.Marshal(h.keys.JWKS())
+	if err != nil {
+		h.logger.Error("failed to marshal key set", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(b); err != nil {
+		h.logger.Error("failed to write response", slog.String("error", err.Error()))
+		return
+	}
+}
@@ -0,0 +1,52 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"main/internal/domain/events"
+)
+
+type MemberLister interface {
+	ListMemberIDs(ctx context.Context, chatID int64) ([]int64, error)
+}
+
+// MembershipNotifier pushes membership changes to the connected members of
+// the chat and to the users the change is about, who may have just left.
+type MembershipNotifier struct {
+	manager *Manager
+	members MemberLister
+	logger  *slog.Logger
+}
+
+func NewMembershipNotifier(manager *Manager, members MemberLister, logger *slog.Logger) *MembershipNotifier {
+	return &MembershipNotifier{
+		manager: manager,
+		members: members,
+		logger:  logger,
+	}
+}
+
+func (n *MembershipNotifier) SendMembershipChanged(ctx context.Context, event events.MembershipChanged) error {
+	members, err := n.members.ListMemberIDs(ctx, event.ChatID)
+	if err != nil {
+		return fmt.Errorf("ws: failed to list chat members: %w", err)
+	}
+
+	payload := map[string]interface{}{
+		"type": "membership_changed",
+		"data": event,
+	}
+	go func() {
+		sent := make(map[int64]bool, len(members)+len(event.UserIDs))
+		for _, userID := range append(members, event.UserIDs...) {
+			if sent[userID] {
+				continue
+			}
+			sent[userID] = true
+			n.manager.WsUnicast(userID, payload)
+		}
+	}()
+	return nil
+}
@@ -7,18 +7,153 @@ import (
 )
 
 type Chat struct {
-	ID              int64     `json:"chat_id" `
+	ID               int64     `json:"chat_id" `
 	Title            string    `json:"title"`
+	Type             string    `json:"type,omitempty"`
+	Handle           string    `json:"handle,omitempty"`
+	Description      string    `json:"description,omitempty"`
+	AvatarURL        string    `json:"avatar_url,omitempty"`
 	IsPrivate        bool      `json:"is_private"`
+	IsPublic         bool      `json:"is_public,omitempty"`
 	CreatedAt        time.Time `json:"created_at"`
 	MembersID        []int64   `json:"members"`
 	MembersUsernames []string  `json:"members_usernames"`
 	MembersCount     int       `json:"members_count"`
+	PinnedMessageID  string    `json:"pinned_message_id,omitempty"`
+	UnreadCount      int64     `json:"unread_count"`
+
+	// Set in the chat list only.
+	LastMessagePreview  string     `json:"last_message_preview,omitempty"`
+	LastMessageSenderID int64      `json:"last_message_sender_id,omitempty"`
+	LastMessageSender   string     `json:"last_message_sender,omitempty"`
+	LastMessageAt       *time.Time `json:"last_message_at,omitempty"`
+	IsPinned            bool       `json:"is_pinned,omitempty"`
+	IsMuted             bool       `json:"is_muted,omitempty"`
+}
+
+// Chat types. Direct chats have exactly two members and are shown under the
+// name of the other one.
+const (
+	ChatTypeGroup  = "group"
+	ChatTypeDirect = "direct"
+)
+
+// Roles of chat members, from most to least privileged.
+const (
+	ChatRoleOwner  = "owner"
+	ChatRoleAdmin  = "admin"
+	ChatRoleMember = "member"
+)
+
+// ChatUpdate holds the chat metadata to change; nil fields are kept.
+type ChatUpdate struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	AvatarURL   *string `json:"avatar_url"`
+}
+
+// ChatSettings are the preferences of a member for one chat; nil fields
+// are kept.
+type ChatSettings struct {
+	Pinned *bool `json:"pinned"`
+	Muted  *bool `json:"muted"`
+}
+
+// ReadState is how far a member has read a chat. Until the first receipt
+// LastReadMessageID is empty and everything since JoinedAt is unread.
+type ReadState struct {
+	ChatID            int64      `json:"chat_id"`
+	UserID            int64      `json:"user_id"`
+	LastReadMessageID string     `json:"last_read_message_id,omitempty"`
+	LastReadAt        *time.Time `json:"last_read_at,omitempty"`
+	JoinedAt          time.Time  `json:"-"`
+	UnreadCount       int64      `json:"unread_count"`
 }
 
 type ChatMember struct {
-	ChatID int64 `json:"chat_id"`
-	UserID int64 `json:"user_id"`
+	ChatID int64  `json:"chat_id"`
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// ChatInvite is a shareable code that lets users join a chat. A nil
+// ExpiresAt or MaxUses means no limit.
+type ChatInvite struct {
+	ID               int64      `json:"id"`
+	ChatID           int64      `json:"chat_id"`
+	Code             string     `json:"code"`
+	CreatedBy        int64      `json:"created_by,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	MaxUses          *int       `json:"max_uses,omitempty"`
+	Uses             int        `json:"uses"`
+	RequiresApproval bool       `json:"requires_approval"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// InviteOptions limit how a new invite can be used. Nil fields mean no
+// limit.
+type InviteOptions struct {
+	ExpiresAt        *time.Time `json:"expires_at"`
+	MaxUses          *int       `json:"max_uses"`
+	RequiresApproval bool       `json:"requires_approval"`
+}
+
+// Statuses of a join request.
+const (
+	JoinRequestPending  = "pending"
+	JoinRequestApproved = "approved"
+	JoinRequestRejected = "rejected"
+)
+
+// JoinRequest is a user asking to be let into a private chat, either
+// directly or by redeeming an invite that needs approval, in which case
+// InviteID is set.
+type JoinRequest struct {
+	ID        int64      `json:"id"`
+	ChatID    int64      `json:"chat_id"`
+	UserID    int64      `json:"user_id"`
+	InviteID  int64      `json:"invite_id,omitempty"`
+	Message   string     `json:"message,omitempty"`
+	Status    string     `json:"status"`
+	DecidedBy int64      `json:"decided_by,omitempty"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ChatBan keeps a user out of a chat. A nil ExpiresAt bans for good.
+type ChatBan struct {
+	ChatID    int64      `json:"chat_id"`
+	UserID    int64      `json:"user_id"`
+	BannedBy  int64      `json:"banned_by,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// What a restricted member may not do in a chat.
+const (
+	RestrictSend       = "send"
+	RestrictEdit       = "edit"
+	RestrictAddMembers = "add_members"
+)
+
+// MemberRestriction stops a member from doing one thing in a chat until
+// ExpiresAt.
+type MemberRestriction struct {
+	ChatID       int64     `json:"chat_id"`
+	UserID       int64     `json:"user_id"`
+	Restriction  string    `json:"restriction"`
+	RestrictedBy int64     `json:"restricted_by,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Membership is a chat the user belongs to.
+type Membership struct {
+	ChatID   int64     `json:"chat_id"`
+	Title    string    `json:"title"`
+	JoinedAt time.Time `json:"joined_at"`
 }
 
 type Message struct {
@@ -28,19 +163,173 @@ type Message struct {
 	ChatID         int64              `json:"chat_id" bson:"chat_id"`
 	SenderID       int64              `json:"sender_id" bson:"sender_id"`
 	SenderUsername string             `json:"sender_username" bson:"sender_username"`
+	Type           string             `json:"type,omitempty" bson:"type,omitempty"`
+	ActorID        int64              `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
 }
 
+// MessageTypeSystem marks messages the server posts about changes to the
+// chat. They have no sender, so nobody can edit or delete them; ActorID is the
+// member who made the change.
+const MessageTypeSystem = "system"
+
 type User struct {
+	ID            int64  `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	Password      string `json:"password"`
+	Role          string `json:"role,omitempty"`
+	EmailVerified bool   `json:"-"`
+	TOTPEnabled   bool   `json:"-"`
+}
+
+type RefreshToken struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	Token     string     `json:"refresh_token"`
+	FamilyID  string     `json:"family_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     int64      `json:"user_id"`
+	DeviceName string     `json:"device_name"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// TOTP is the two-factor state of a user. Secret is set once enrollment has
+// started; Enabled only after the user confirmed it with a valid code.
+type TOTP struct {
+	UserID   int64
+	Username string
+	Secret   string
+	Enabled  bool
+}
+
+// TOTPEnrollment is what an authenticator app needs to add the account.
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// ExternalIdentity is an account at an OpenID provider linked to a user.
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// Bot is a user without a password, owned and managed by a human user.
+type Bot struct {
 	ID       int64  `json:"id"`
 	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	OwnerID  int64  `json:"owner_id"`
 }
 
-type RefreshToken struct {
-	ID        int64     `json:"id"`
-	UserID    int64     `json:"user_id"`
-	Token     string    `json:"refresh_token"`
-	CreatedAt time.Time `json:"created_at"`
-	ExpiresAt time.Time `json:"expires_at"`
+// APIKey is a long-lived credential of a bot. Only a hash of the key is
+// stored; Prefix is kept to recognise it in listings.
+type APIKey struct {
+	ID         int64      `json:"id"`
+	BotID      int64      `json:"bot_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Data export statuses.
+const (
+	ExportPending = "pending"
+	ExportRunning = "running"
+	ExportReady   = "ready"
+	ExportFailed  = "failed"
+	ExportExpired = "expired"
+)
+
+// DataExport is an archive of everything stored about a user. The archive
+// can be downloaded while the export is ready, until ExpiresAt.
+type DataExport struct {
+	ID          string     `json:"id"`
+	UserID      int64      `json:"-"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	SizeBytes   int64      `json:"size_bytes,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// DeletedUsername replaces the sender name of messages whose author deleted
+// their account.
+const DeletedUsername = "Deleted account"
+
+// Audit actions.
+const (
+	AuditRegister            = "auth.register"
+	AuditLogin               = "auth.login"
+	AuditLoginFailed         = "auth.login_failed"
+	AuditLogout              = "auth.logout"
+	AuditPasswordChanged     = "auth.password_changed"
+	AuditPasswordReset       = "auth.password_reset"
+	AuditRoleChanged         = "user.role_changed"
+	AuditDeletionRequested   = "user.deletion_requested"
+	AuditDeletionCancelled   = "user.deletion_cancelled"
+	AuditAccountDeleted      = "user.deleted"
+	AuditExportRequested     = "user.export_requested"
+	AuditExportDownloaded    = "user.export_downloaded"
+	AuditMemberAdded         = "chat.member_added"
+	AuditMemberRemoved       = "chat.member_removed"
+	AuditMemberLeft          = "chat.member_left"
+	AuditMemberBanned        = "chat.member_banned"
+	AuditMemberUnbanned      = "chat.member_unbanned"
+	AuditMemberRestricted    = "chat.member_restricted"
+	AuditRestrictionLifted   = "chat.restriction_lifted"
+	AuditJoinRequestRejected = "chat.join_request_rejected"
+	AuditChatDeleted         = "chat.deleted"
+	AuditChatRoleChanged     = "chat.role_changed"
+	AuditChatOwnerChanged    = "chat.owner_changed"
+	AuditChatUpdated         = "chat.updated"
+	AuditInviteCreated       = "chat.invite_created"
+	AuditInviteRevoked       = "chat.invite_revoked"
+	AuditExported            = "audit.exported"
+)
+
+// AuditEvent is one entry of the security audit trail. ActorID is zero when
+// the caller is not known, e.g. for failed logins.
+type AuditEvent struct {
+	ID         int64                  `json:"id"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	ActorID    int64                  `json:"actor_id,omitempty"`
+	Action     string                 `json:"action"`
+	TargetType string                 `json:"target_type,omitempty"`
+	TargetID   string                 `json:"target_id,omitempty"`
+	IP         string                 `json:"ip,omitempty"`
+	UserAgent  string                 `json:"user_agent,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// AuditFilter selects audit events. Zero fields match everything. Events are
+// returned newest first; BeforeID continues after the last event of the
+// previous page.
+type AuditFilter struct {
+	ActorID    int64
+	Action     string
+	TargetType string
+	TargetID   string
+	From       time.Time
+	To         time.Time
+	BeforeID   int64
+	Limit      int
 }
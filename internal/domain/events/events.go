@@ -16,3 +16,32 @@ type MessageDeleted struct {
 	MessageIDs []string `json:"message_ids"`
 	ChatID     int64    `json:"chat_id"`
 }
+
+// AccountDeleted is published once a user account and the bots it owned are
+// gone. Messages is how their messages were handled: "anonymized" or
+// "deleted".
+type AccountDeleted struct {
+	UserID    int64     `json:"user_id"`
+	BotIDs    []int64   `json:"bot_ids,omitempty"`
+	Messages  string    `json:"messages"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// How a chat membership changed.
+const (
+	MembershipJoined  = "joined"
+	MembershipLeft    = "left"
+	MembershipRemoved = "removed"
+	MembershipBanned  = "banned"
+)
+
+// MembershipChanged is published when users join or leave a chat. ActorID is
+// who made the change and Via how, e.g. "invite".
+type MembershipChanged struct {
+	ChatID    int64     `json:"chat_id"`
+	UserIDs   []int64   `json:"user_ids"`
+	Change    string    `json:"change"`
+	ActorID   int64     `json:"actor_id,omitempty"`
+	Via       string    `json:"via,omitempty"`
+	ChangedAt time.Time `json:"changed_at"`
+}
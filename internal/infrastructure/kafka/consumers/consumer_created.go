@@ -64,5 +64,5 @@ func (c *ConsumerCreated) processMessage(ctx context.Context, msg kafka.Message)
 
 	}
 
-	return c.chat.UpdateChatLastMessage(ctx, EventMessageCreated.ChatID, EventMessageCreated.Text, EventMessageCreated.CreatedAt)
+	return c.chat.UpdateChatLastMessage(ctx, EventMessageCreated.ChatID, EventMessageCreated.SenderID, EventMessageCreated.Text, EventMessageCreated.CreatedAt)
 }
@@ -66,5 +66,5 @@ func (c *ConsumerDeleted) processMessage(ctx context.Context, msg kafka.Message)
 	if err != nil {
 		return fmt.Errorf("failed to get latest message: %w", err)
 	}
-	return c.chat.UpdateChatLastMessage(ctx, EventMessageCreated.ChatID, res.Text, res.CreatedAt)
+	return c.chat.UpdateChatLastMessage(ctx, EventMessageCreated.ChatID, res.SenderID, res.Text, res.CreatedAt)
 }
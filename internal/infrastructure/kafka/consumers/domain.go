@@ -11,5 +11,5 @@ type MessageRepository interface {
 }
 
 type ChatPostgresUpdater interface {
-	UpdateChatLastMessage(ctx context.Context, chatID int64, messageText string, createdAt time.Time) error
+	UpdateChatLastMessage(ctx context.Context, chatID, senderID int64, messageText string, createdAt time.Time) error
 }
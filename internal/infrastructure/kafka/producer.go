@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"main/internal/domain/events"
+	"strconv"
 
 	"github.com/segmentio/kafka-go"
 )
@@ -12,6 +13,8 @@ import (
 type Producer struct {
 	createdWriter *kafka.Writer
 	deletedWriter *kafka.Writer
+	accountWriter *kafka.Writer
+	memberWriter  *kafka.Writer
 }
 
 func NewProducer(brokers []string) *Producer {
@@ -26,6 +29,16 @@ func NewProducer(brokers []string) *Producer {
 			Topic:    "msg_deleted", 
 			Balancer: &kafka.LeastBytes{},
 		},
+		accountWriter: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    "account_deleted",
+			Balancer: &kafka.LeastBytes{},
+		},
+		memberWriter: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    "chat_membership",
+			Balancer: &kafka.Hash{},
+		},
 	}
 }
 
@@ -39,8 +52,25 @@ func (p *Producer) SendMessageDeleted(ctx context.Context, event events.MessageD
 	return p.deletedWriter.WriteMessages(ctx, kafka.Message{Value: payload})
 }
 
+func (p *Producer) SendAccountDeleted(ctx context.Context, event events.AccountDeleted) error {
+	payload, _ := json.Marshal(event)
+	return p.accountWriter.WriteMessages(ctx, kafka.Message{Value: payload})
+}
+
+// SendMembershipChanged keys the event by chat so the changes of one chat stay
+// in order.
+func (p *Producer) SendMembershipChanged(ctx context.Context, event events.MembershipChanged) error {
+	payload, _ := json.Marshal(event)
+	return p.memberWriter.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(strconv.FormatInt(event.ChatID, 10)),
+		Value: payload,
+	})
+}
+
 func (p *Producer) Close() error {
 	p.createdWriter.Close()
 	p.deletedWriter.Close()
+	p.accountWriter.Close()
+	p.memberWriter.Close()
 	return nil
 }
@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileMailer is a stand-in for local runs. It logs every message and, when
+// dir is set, also writes it there as an .eml file.
+type FileMailer struct {
+	dir    string
+	from   string
+	logger *slog.Logger
+}
+
+func NewFileMailer(dir, from string, logger *slog.Logger) (*FileMailer, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("create mail dir: %w", err)
+		}
+	}
+	return &FileMailer{
+		dir:    dir,
+		from:   from,
+		logger: logger,
+	}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, to, subject, body string) error {
+	m.logger.Info("mail sent", slog.String("to", to), slog.String("subject", subject), slog.String("body", body))
+	if m.dir == "" {
+		return nil
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), strings.NewReplacer("@", "_at_", "/", "_").Replace(to))
+	file := filepath.Join(m.dir, name)
+	if err := os.WriteFile(file, buildMessage(m.from, to, subject, body), 0o600); err != nil {
+		return fmt.Errorf("write mail %s: %w", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package account
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/internal/domain/events"
+	"main/internal/usecase/audit"
+)
+
+// How the messages of deleted accounts are handled.
+const (
+	MessagesAnonymize = "anonymize"
+	MessagesDelete    = "delete"
+)
+
+const defaultDeletionGrace = 30 * 24 * time.Hour
+
+//go:generate mockgen -source=account_usecase.go -destination=mock/account_mock.go -package=mock
+type AccountRepository interface {
+	ScheduleDeletion(ctx context.Context, userID int64, at time.Time) (time.Time, error)
+	CancelDeletion(ctx context.Context, userID int64) error
+	ListDueDeletions(ctx context.Context, limit int) ([]int64, error)
+	ListBots(ctx context.Context, ownerID int64) ([]dom.Bot, error)
+	DeleteUser(ctx context.Context, userID int64) error
+}
+
+type MessageRepository interface {
+	AnonymizeSenderMessages(ctx context.Context, senderIDs []int64, username string) (int64, error)
+	DeleteSenderMessages(ctx context.Context, senderIDs []int64) ([]int64, error)
+}
+
+type SessionRevoker interface {
+	RevokeAllSessions(ctx context.Context, userID int64) error
+}
+
+type EventPublisher interface {
+	SendMessageDeleted(ctx context.Context, event events.MessageDeleted) error
+	SendAccountDeleted(ctx context.Context, event events.AccountDeleted) error
+}
+
+// ExportRemover removes stored data exports of a user.
+type ExportRemover interface {
+	DeleteUserExports(ctx context.Context, userID int64) error
+}
+
+// AuditRecorder stores events for the security audit trail.
+type AuditRecorder interface {
+	Record(ctx context.Context, event dom.AuditEvent)
+}
+
+type Options struct {
+	// DeletionGrace is how long a deletion request can be cancelled.
+	DeletionGrace time.Duration
+	// DeletedMessages is MessagesAnonymize or MessagesDelete.
+	DeletedMessages string
+	// Exports, when set, has the archives of deleted accounts removed.
+	Exports ExportRemover
+	Audit   AuditRecorder
+}
+
+type AccountService struct {
+	repo      AccountRepository
+	messages  MessageRepository
+	sessions  SessionRevoker
+	publisher EventPublisher
+	exports   ExportRemover
+	audit     AuditRecorder
+	logger    *slog.Logger
+
+	deletionGrace   time.Duration
+	deletedMessages string
+}
+
+func NewAccountService(repo AccountRepository, messages MessageRepository, sessions SessionRevoker,
+	publisher EventPublisher, logger *slog.Logger, opts Options) *AccountService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if opts.DeletionGrace <= 0 {
+		opts.DeletionGrace = defaultDeletionGrace
+	}
+	switch opts.DeletedMessages {
+	case MessagesAnonymize, MessagesDelete:
+	default:
+		logger.Warn("unknown policy for messages of deleted accounts, anonymizing them",
+			slog.String("policy", opts.DeletedMessages))
+		opts.DeletedMessages = MessagesAnonymize
+	}
+	if opts.Audit == nil {
+		opts.Audit = audit.Discard{}
+	}
+	return &AccountService{
+		repo:            repo,
+		messages:        messages,
+		sessions:        sessions,
+		publisher:       publisher,
+		exports:         opts.Exports,
+		audit:           opts.Audit,
+		logger:          logger,
+		deletionGrace:   opts.DeletionGrace,
+		deletedMessages: opts.DeletedMessages,
+	}
+}
@@ -0,0 +1,185 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/internal/domain/events"
+	"main/pkg/customerrors"
+)
+
+const purgeBatchSize = 100
+
+// RequestDeletion schedules the deletion of the account once the grace
+// period is over and returns when that is. Asking again keeps the first
+// schedule.
+func (s *AccountService) RequestDeletion(ctx context.Context, userID int64) (time.Time, error) {
+	if userID <= 0 {
+		return time.Time{}, customerrors.ErrInvalidInput
+	}
+
+	scheduled, err := s.repo.ScheduleDeletion(ctx, userID, time.Now().Add(s.deletionGrace))
+	if err != nil {
+		return time.Time{}, err
+	}
+	s.audit.Record(ctx, dom.AuditEvent{
+		ActorID:    userID,
+		Action:     dom.AuditDeletionRequested,
+		TargetType: "user",
+		TargetID:   strconv.FormatInt(userID, 10),
+		Metadata:   map[string]interface{}{"scheduled_for": scheduled},
+	})
+	s.logger.Info("account deletion scheduled", "user_id", userID, "scheduled_for", scheduled)
+	return scheduled, nil
+}
+
+// CancelDeletion keeps the account. It fails with ErrNotFound if no deletion
+// is scheduled or the grace period is already over.
+func (s *AccountService) CancelDeletion(ctx context.Context, userID int64) error {
+	if userID <= 0 {
+		return customerrors.ErrInvalidInput
+	}
+
+	if err := s.repo.CancelDeletion(ctx, userID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, dom.AuditEvent{
+		ActorID:    userID,
+		Action:     dom.AuditDeletionCancelled,
+		TargetType: "user",
+		TargetID:   strconv.FormatInt(userID, 10),
+	})
+	s.logger.Info("account deletion cancelled", "user_id", userID)
+	return nil
+}
+
+// PurgeDue deletes the accounts whose grace period is over and returns how
+// many were deleted. An account that fails is logged and retried on the
+// next run.
+func (s *AccountService) PurgeDue(ctx context.Context) (int, error) {
+	userIDs, err := s.repo.ListDueDeletions(ctx, purgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, userID := range userIDs {
+		if err := s.deleteAccount(ctx, userID); err != nil {
+			s.logger.Error("failed to delete account",
+				slog.Int64("user_id", userID),
+				slog.String("error", err.Error()))
+			continue
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// StartPurge runs PurgeDue every interval until ctx is done.
+func (s *AccountService) StartPurge(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.PurgeDue(ctx)
+			if err != nil {
+				s.logger.Error("failed to purge deleted accounts", slog.String("error", err.Error()))
+			} else if n > 0 {
+				s.logger.Info("deleted accounts purged", slog.Int("accounts", n))
+			}
+		}
+	}
+}
+
+// deleteAccount removes the account and everything tied to it. Every step can
+// be repeated, so an account that fails halfway is finished by a later run.
+// Mongo has no foreign keys, so the messages of the user and their bots are
+// handled first, while the bots can still be listed.
+func (s *AccountService) deleteAccount(ctx context.Context, userID int64) error {
+	bots, err := s.repo.ListBots(ctx, userID)
+	if err != nil {
+		return err
+	}
+	senderIDs := []int64{userID}
+	botIDs := make([]int64, 0, len(bots))
+	for _, bot := range bots {
+		senderIDs = append(senderIDs, bot.ID)
+		botIDs = append(botIDs, bot.ID)
+	}
+
+	messages := "anonymized"
+	if s.deletedMessages == MessagesDelete {
+		messages = "deleted"
+		chatIDs, err := s.messages.DeleteSenderMessages(ctx, senderIDs)
+		if err != nil {
+			return fmt.Errorf("service: delete messages: %w", err)
+		}
+		// Chats may show a deleted message as their last one.
+		for _, chatID := range chatIDs {
+			if err := s.publisher.SendMessageDeleted(ctx, events.MessageDeleted{ChatID: chatID}); err != nil {
+				s.logger.Warn("failed to publish message deleted event",
+					slog.Int64("chat_id", chatID),
+					slog.String("error", err.Error()))
+			}
+		}
+	} else if _, err := s.messages.AnonymizeSenderMessages(ctx, senderIDs, dom.DeletedUsername); err != nil {
+		return fmt.Errorf("service: anonymize messages: %w", err)
+	}
+
+	if s.exports != nil {
+		if err := s.exports.DeleteUserExports(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	// Access tokens outlive the sessions table rows, so they are blacklisted
+	// before the rows go.
+	if err := s.sessions.RevokeAllSessions(ctx, userID); err != nil {
+		return err
+	}
+
+	// Memberships, refresh tokens, bots and their API keys cascade; owned
+	// groups pass to a successor.
+	if err := s.repo.DeleteUser(ctx, userID); err != nil {
+		if errors.Is(err, customerrors.ErrUserNotFound) {
+			// Deleted by another instance in the meantime.
+			return nil
+		}
+		return err
+	}
+
+	evt := events.AccountDeleted{
+		UserID:    userID,
+		BotIDs:    botIDs,
+		Messages:  messages,
+		DeletedAt: time.Now(),
+	}
+	if err := s.publisher.SendAccountDeleted(ctx, evt); err != nil {
+		// The account is gone and cannot be retried; downstream consumers
+		// miss this one.
+		s.logger.Error("failed to publish account deleted event",
+			slog.Int64("user_id", userID),
+			slog.String("error", err.Error()))
+	}
+	s.audit.Record(ctx, dom.AuditEvent{
+		Action:     dom.AuditAccountDeleted,
+		TargetType: "user",
+		TargetID:   strconv.FormatInt(userID, 10),
+		Metadata:   map[string]interface{}{"bots": len(botIDs), "messages": messages},
+	})
+	s.logger.Info("account deleted", "user_id", userID, "bots", len(botIDs), "messages", messages)
+	return nil
+}
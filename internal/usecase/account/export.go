@@ -0,0 +1,415 @@
+package account
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/internal/usecase/audit"
+	"main/pkg/customerrors"
+)
+
+const (
+	defaultExportRetention = 7 * 24 * time.Hour
+	defaultLinkTTL         = 15 * time.Minute
+	// An export running for longer than this is assumed to have been
+	// abandoned by an instance that stopped, and is started again.
+	staleExportAfter = time.Hour
+)
+
+//go:generate mockgen -source=export.go -destination=mock/export_mock.go -package=mock
+type ExportRepository interface {
+	CreateExport(ctx context.Context, userID int64) (dom.DataExport, error)
+	GetExport(ctx context.Context, exportID string) (dom.DataExport, error)
+	ListExports(ctx context.Context, userID int64) ([]dom.DataExport, error)
+	ClaimExport(ctx context.Context, stale time.Duration) (dom.DataExport, error)
+	CompleteExport(ctx context.Context, exportID string, size int64, expiresAt time.Time) error
+	FailExport(ctx context.Context, exportID, reason string) error
+	ExpireExports(ctx context.Context) ([]string, error)
+}
+
+type ProfileReader interface {
+	GetUserByID(ctx context.Context, userID int64) (dom.User, error)
+}
+
+type MembershipReader interface {
+	ListMemberships(ctx context.Context, userID int64) ([]dom.Membership, error)
+}
+
+type MessageReader interface {
+	StreamSenderMessages(ctx context.Context, senderID int64, fn func(dom.Message) error) error
+}
+
+type BlobStore interface {
+	Put(key string, write func(w io.Writer) error) (int64, error)
+	Open(key string) (io.ReadSeekCloser, error)
+	Remove(key string) error
+}
+
+type ExportOptions struct {
+	// LinkSecret signs download links.
+	LinkSecret string
+	// LinkTTL is how long a download link works.
+	LinkTTL time.Duration
+	// Retention is how long a finished archive is kept.
+	Retention time.Duration
+	Audit     AuditRecorder
+}
+
+// ExportService builds archives of everything stored about a user in the
+// background, and serves them through signed, short-lived links.
+type ExportService struct {
+	repo        ExportRepository
+	profiles    ProfileReader
+	memberships MembershipReader
+	messages    MessageReader
+	blobs       BlobStore
+	audit       AuditRecorder
+	logger      *slog.Logger
+
+	linkSecret []byte
+	linkTTL    time.Duration
+	retention  time.Duration
+	wake       chan struct{}
+}
+
+func NewExportService(repo ExportRepository, profiles ProfileReader, memberships MembershipReader,
+	messages MessageReader, blobs BlobStore, logger *slog.Logger, opts ExportOptions) *ExportService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if opts.LinkTTL <= 0 {
+		opts.LinkTTL = defaultLinkTTL
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = defaultExportRetention
+	}
+	if opts.Audit == nil {
+		opts.Audit = audit.Discard{}
+	}
+	return &ExportService{
+		repo:        repo,
+		profiles:    profiles,
+		memberships: memberships,
+		messages:    messages,
+		blobs:       blobs,
+		audit:       opts.Audit,
+		logger:      logger,
+		linkSecret:  []byte(opts.LinkSecret),
+		linkTTL:     opts.LinkTTL,
+		retention:   opts.Retention,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// RequestExport queues an export of the user's data. While one is pending or
+// running, that one is returned.
+func (s *ExportService) RequestExport(ctx context.Context, userID int64) (dom.DataExport, error) {
+	if userID <= 0 {
+		return dom.DataExport{}, customerrors.ErrInvalidInput
+	}
+
+	export, err := s.repo.CreateExport(ctx, userID)
+	if err != nil {
+		return dom.DataExport{}, err
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	s.audit.Record(ctx, dom.AuditEvent{
+		ActorID:    userID,
+		Action:     dom.AuditExportRequested,
+		TargetType: "data_export",
+		TargetID:   export.ID,
+	})
+	return export, nil
+}
+
+func (s *ExportService) ListExports(ctx context.Context, userID int64) ([]dom.DataExport, error) {
+	if userID <= 0 {
+		return nil, customerrors.ErrInvalidInput
+	}
+	return s.repo.ListExports(ctx, userID)
+}
+
+// GetExport returns the export if it belongs to the user, and ErrNotFound
+// otherwise.
+func (s *ExportService) GetExport(ctx context.Context, userID int64, exportID string) (dom.DataExport, error) {
+	export, err := s.repo.GetExport(ctx, exportID)
+	if err != nil {
+		return dom.DataExport{}, err
+	}
+	if export.UserID != userID {
+		return dom.DataExport{}, customerrors.ErrNotFound
+	}
+	return export, nil
+}
+
+// DownloadToken returns a token that lets anyone holding it download the
+// user's archive until the returned time. The token goes in the download
+// link, so the archive can be fetched without an Authorization header.
+func (s *ExportService) DownloadToken(ctx context.Context, userID int64, exportID string) (string, time.Time, error) {
+	export, err := s.GetExport(ctx, userID, exportID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if !downloadable(export, time.Now()) {
+		return "", time.Time{}, customerrors.ErrExportNotReady
+	}
+
+	expires := time.Now().Add(s.linkTTL)
+	if export.ExpiresAt.Before(expires) {
+		expires = *export.ExpiresAt
+	}
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return exp + "." + s.sign(export.ID, exp), time.Unix(expires.Unix(), 0), nil
+}
+
+// OpenDownload checks the token and returns the archive. The caller must
+// close it.
+func (s *ExportService) OpenDownload(ctx context.Context, exportID, token string) (dom.DataExport, io.ReadSeekCloser, error) {
+	exp, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.sign(exportID, exp))) {
+		return dom.DataExport{}, nil, customerrors.ErrInvalidDownloadLink
+	}
+	expUnix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() >= expUnix {
+		return dom.DataExport{}, nil, customerrors.ErrInvalidDownloadLink
+	}
+
+	export, err := s.repo.GetExport(ctx, exportID)
+	if err != nil {
+		return dom.DataExport{}, nil, err
+	}
+	if !downloadable(export, time.Now()) {
+		return dom.DataExport{}, nil, customerrors.ErrExportNotReady
+	}
+	archive, err := s.blobs.Open(archiveKey(export.ID))
+	if err != nil {
+		return dom.DataExport{}, nil, fmt.Errorf("service: open export archive: %w", err)
+	}
+
+	s.audit.Record(ctx, dom.AuditEvent{
+		ActorID:    export.UserID,
+		Action:     dom.AuditExportDownloaded,
+		TargetType: "data_export",
+		TargetID:   export.ID,
+	})
+	return export, archive, nil
+}
+
+// DeleteUserExports removes the archives of the user. Their rows go with the
+// user.
+func (s *ExportService) DeleteUserExports(ctx context.Context, userID int64) error {
+	exports, err := s.repo.ListExports(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, export := range exports {
+		if err := s.blobs.Remove(archiveKey(export.ID)); err != nil {
+			return fmt.Errorf("service: remove export archive: %w", err)
+		}
+	}
+	return nil
+}
+
+// Start builds queued exports and removes expired archives, every interval
+// and whenever an export is requested, until ctx is done.
+func (s *ExportService) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunPending(ctx); err != nil {
+			s.logger.Error("failed to run data exports", slog.String("error", err.Error()))
+		}
+		if err := s.ExpireArchives(ctx); err != nil {
+			s.logger.Error("failed to expire data exports", slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// RunPending builds queued exports until there are none left and returns how
+// many were built.
+func (s *ExportService) RunPending(ctx context.Context) (int, error) {
+	built := 0
+	for ctx.Err() == nil {
+		export, err := s.repo.ClaimExport(ctx, staleExportAfter)
+		if err != nil {
+			if errors.Is(err, customerrors.ErrNotFound) {
+				return built, nil
+			}
+			return built, err
+		}
+		if err := s.build(ctx, export); err != nil {
+			return built, err
+		}
+		built++
+	}
+	return built, ctx.Err()
+}
+
+// ExpireArchives removes the archives of exports past their retention.
+func (s *ExportService) ExpireArchives(ctx context.Context) error {
+	ids, err := s.repo.ExpireExports(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := s.blobs.Remove(archiveKey(id)); err != nil {
+			s.logger.Warn("failed to remove expired export archive",
+				slog.String("export_id", id),
+				slog.String("error", err.Error()))
+		}
+	}
+	return nil
+}
+
+// build writes the archive of export. A failure to write it fails the export;
+// only failing to record the outcome is returned.
+func (s *ExportService) build(ctx context.Context, export dom.DataExport) error {
+	size, err := s.blobs.Put(archiveKey(export.ID), func(w io.Writer) error {
+		return s.writeArchive(ctx, export.UserID, w)
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			// Shutting down; the export is picked up again once stale.
+			return ctx.Err()
+		}
+		s.logger.Error("failed to build data export",
+			slog.String("export_id", export.ID),
+			slog.Int64("user_id", export.UserID),
+			slog.String("error", err.Error()))
+		// The error may name internals, so the user only learns that it failed.
+		return s.repo.FailExport(ctx, export.ID, "the export could not be built")
+	}
+
+	if err := s.repo.CompleteExport(ctx, export.ID, size, time.Now().Add(s.retention)); err != nil {
+		return err
+	}
+	s.logger.Info("data export ready", "export_id", export.ID, "user_id", export.UserID, "size", size)
+	return nil
+}
+
+// exportProfile is what the archive holds about the account itself. The
+// password hash is left out.
+type exportProfile struct {
+	ID            int64  `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Role          string `json:"role"`
+}
+
+// writeArchive writes a zip with profile.json, memberships.json and
+// messages.json.
+func (s *ExportService) writeArchive(ctx context.Context, userID int64, w io.Writer) error {
+	user, err := s.profiles.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("read profile: %w", err)
+	}
+	memberships, err := s.memberships.ListMemberships(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("read memberships: %w", err)
+	}
+
+	zw := zip.NewWriter(w)
+	profile := exportProfile{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Role:          user.Role,
+	}
+	if err := writeJSONFile(zw, "profile.json", profile); err != nil {
+		return err
+	}
+	if err := writeJSONFile(zw, "memberships.json", memberships); err != nil {
+		return err
+	}
+
+	// Messages are streamed, as there may be too many to hold in memory.
+	f, err := zw.Create("messages.json")
+	if err != nil {
+		return fmt.Errorf("write messages.json: %w", err)
+	}
+	sep := "[\n"
+	err = s.messages.StreamSenderMessages(ctx, userID, func(msg dom.Message) error {
+		b, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, sep); err != nil {
+			return err
+		}
+		sep = ",\n"
+		_, err = f.Write(b)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("write messages.json: %w", err)
+	}
+	if sep == "[\n" {
+		_, err = io.WriteString(f, "[]\n")
+	} else {
+		_, err = io.WriteString(f, "\n]\n")
+	}
+	if err != nil {
+		return fmt.Errorf("write messages.json: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("close archive: %w", err)
+	}
+	return nil
+}
+
+func writeJSONFile(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *ExportService) sign(exportID, expires string) string {
+	mac := hmac.New(sha256.New, s.linkSecret)
+	mac.Write([]byte("data-export:" + exportID + ":" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func downloadable(export dom.DataExport, now time.Time) bool {
+	return export.Status == dom.ExportReady && export.ExpiresAt != nil && now.Before(*export.ExpiresAt)
+}
+
+func archiveKey(exportID string) string {
+	return exportID + ".zip"
+}
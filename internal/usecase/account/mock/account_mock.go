@@ -0,0 +1,335 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: account_usecase.go
+//
+// Generated by this command:
+//
+//	mockgen -source=account_usecase.go -destination=mock/account_mock.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	entity "main/internal/domain/entity"
+	events "main/internal/domain/events"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAccountRepository is a mock of AccountRepository interface.
+type MockAccountRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAccountRepositoryMockRecorder is the mock recorder for MockAccountRepository.
+type MockAccountRepositoryMockRecorder struct {
+	mock *MockAccountRepository
+}
+
+// NewMockAccountRepository creates a new mock instance.
+func NewMockAccountRepository(ctrl *gomock.Controller) *MockAccountRepository {
+	mock := &MockAccountRepository{ctrl: ctrl}
+	mock.recorder = &MockAccountRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountRepository) EXPECT() *MockAccountRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CancelDeletion mocks base method.
+func (m *MockAccountRepository) CancelDeletion(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelDeletion", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelDeletion indicates an expected call of CancelDeletion.
+func (mr *MockAccountRepositoryMockRecorder) CancelDeletion(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelDeletion", reflect.TypeOf((*MockAccountRepository)(nil).CancelDeletion), ctx, userID)
+}
+
+// DeleteUser mocks base method.
+func (m *MockAccountRepository) DeleteUser(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockAccountRepositoryMockRecorder) DeleteUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAccountRepository)(nil).DeleteUser), ctx, userID)
+}
+
+// ListBots mocks base method.
+func (m *MockAccountRepository) ListBots(ctx context.Context, ownerID int64) ([]entity.Bot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBots", ctx, ownerID)
+	ret0, _ := ret[0].([]entity.Bot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBots indicates an expected call of ListBots.
+func (mr *MockAccountRepositoryMockRecorder) ListBots(ctx, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBots", reflect.TypeOf((*MockAccountRepository)(nil).ListBots), ctx, ownerID)
+}
+
+// ListDueDeletions mocks base method.
+func (m *MockAccountRepository) ListDueDeletions(ctx context.Context, limit int) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDueDeletions", ctx, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDueDeletions indicates an expected call of ListDueDeletions.
+func (mr *MockAccountRepositoryMockRecorder) ListDueDeletions(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDueDeletions", reflect.TypeOf((*MockAccountRepository)(nil).ListDueDeletions), ctx, limit)
+}
+
+// ScheduleDeletion mocks base method.
+func (m *MockAccountRepository) ScheduleDeletion(ctx context.Context, userID int64, at time.Time) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleDeletion", ctx, userID, at)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleDeletion indicates an expected call of ScheduleDeletion.
+func (mr *MockAccountRepositoryMockRecorder) ScheduleDeletion(ctx, userID, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleDeletion", reflect.TypeOf((*MockAccountRepository)(nil).ScheduleDeletion), ctx, userID, at)
+}
+
+// MockMessageRepository is a mock of MessageRepository interface.
+type MockMessageRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMessageRepositoryMockRecorder is the mock recorder for MockMessageRepository.
+type MockMessageRepositoryMockRecorder struct {
+	mock *MockMessageRepository
+}
+
+// NewMockMessageRepository creates a new mock instance.
+func NewMockMessageRepository(ctrl *gomock.Controller) *MockMessageRepository {
+	mock := &MockMessageRepository{ctrl: ctrl}
+	mock.recorder = &MockMessageRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageRepository) EXPECT() *MockMessageRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AnonymizeSenderMessages mocks base method.
+func (m *MockMessageRepository) AnonymizeSenderMessages(ctx context.Context, senderIDs []int64, username string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeSenderMessages", ctx, senderIDs, username)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeSenderMessages indicates an expected call of AnonymizeSenderMessages.
+func (mr *MockMessageRepositoryMockRecorder) AnonymizeSenderMessages(ctx, senderIDs, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeSenderMessages", reflect.TypeOf((*MockMessageRepository)(nil).AnonymizeSenderMessages), ctx, senderIDs, username)
+}
+
+// DeleteSenderMessages mocks base method.
+func (m *MockMessageRepository) DeleteSenderMessages(ctx context.Context, senderIDs []int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSenderMessages", ctx, senderIDs)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSenderMessages indicates an expected call of DeleteSenderMessages.
+func (mr *MockMessageRepositoryMockRecorder) DeleteSenderMessages(ctx, senderIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSenderMessages", reflect.TypeOf((*MockMessageRepository)(nil).DeleteSenderMessages), ctx, senderIDs)
+}
+
+// MockSessionRevoker is a mock of SessionRevoker interface.
+type MockSessionRevoker struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionRevokerMockRecorder
+	isgomock struct{}
+}
+
+// MockSessionRevokerMockRecorder is the mock recorder for MockSessionRevoker.
+type MockSessionRevokerMockRecorder struct {
+	mock *MockSessionRevoker
+}
+
+// NewMockSessionRevoker creates a new mock instance.
+func NewMockSessionRevoker(ctrl *gomock.Controller) *MockSessionRevoker {
+	mock := &MockSessionRevoker{ctrl: ctrl}
+	mock.recorder = &MockSessionRevokerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionRevoker) EXPECT() *MockSessionRevokerMockRecorder {
+	return m.recorder
+}
+
+// RevokeAllSessions mocks base method.
+func (m *MockSessionRevoker) RevokeAllSessions(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllSessions", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllSessions indicates an expected call of RevokeAllSessions.
+func (mr *MockSessionRevokerMockRecorder) RevokeAllSessions(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllSessions", reflect.TypeOf((*MockSessionRevoker)(nil).RevokeAllSessions), ctx, userID)
+}
+
+// MockEventPublisher is a mock of EventPublisher interface.
+type MockEventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventPublisherMockRecorder
+	isgomock struct{}
+}
+
+// MockEventPublisherMockRecorder is the mock recorder for MockEventPublisher.
+type MockEventPublisherMockRecorder struct {
+	mock *MockEventPublisher
+}
+
+// NewMockEventPublisher creates a new mock instance.
+func NewMockEventPublisher(ctrl *gomock.Controller) *MockEventPublisher {
+	mock := &MockEventPublisher{ctrl: ctrl}
+	mock.recorder = &MockEventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventPublisher) EXPECT() *MockEventPublisherMockRecorder {
+	return m.recorder
+}
+
+// SendAccountDeleted mocks base method.
+func (m *MockEventPublisher) SendAccountDeleted(ctx context.Context, event events.AccountDeleted) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendAccountDeleted", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendAccountDeleted indicates an expected call of SendAccountDeleted.
+func (mr *MockEventPublisherMockRecorder) SendAccountDeleted(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAccountDeleted", reflect.TypeOf((*MockEventPublisher)(nil).SendAccountDeleted), ctx, event)
+}
+
+// SendMessageDeleted mocks base method.
+func (m *MockEventPublisher) SendMessageDeleted(ctx context.Context, event events.MessageDeleted) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessageDeleted", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMessageDeleted indicates an expected call of SendMessageDeleted.
+func (mr *MockEventPublisherMockRecorder) SendMessageDeleted(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessageDeleted", reflect.TypeOf((*MockEventPublisher)(nil).SendMessageDeleted), ctx, event)
+}
+
+// MockExportRemover is a mock of ExportRemover interface.
+type MockExportRemover struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportRemoverMockRecorder
+	isgomock struct{}
+}
+
+// MockExportRemoverMockRecorder is the mock recorder for MockExportRemover.
+type MockExportRemoverMockRecorder struct {
+	mock *MockExportRemover
+}
+
+// NewMockExportRemover creates a new mock instance.
+func NewMockExportRemover(ctrl *gomock.Controller) *MockExportRemover {
+	mock := &MockExportRemover{ctrl: ctrl}
+	mock.recorder = &MockExportRemoverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExportRemover) EXPECT() *MockExportRemoverMockRecorder {
+	return m.recorder
+}
+
+// DeleteUserExports mocks base method.
+func (m *MockExportRemover) DeleteUserExports(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserExports", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserExports indicates an expected call of DeleteUserExports.
+func (mr *MockExportRemoverMockRecorder) DeleteUserExports(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserExports", reflect.TypeOf((*MockExportRemover)(nil).DeleteUserExports), ctx, userID)
+}
+
+// MockAuditRecorder is a mock of AuditRecorder interface.
+type MockAuditRecorder struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRecorderMockRecorder
+	isgomock struct{}
+}
+
+// MockAuditRecorderMockRecorder is the mock recorder for MockAuditRecorder.
+type MockAuditRecorderMockRecorder struct {
+	mock *MockAuditRecorder
+}
+
+// NewMockAuditRecorder creates a new mock instance.
+func NewMockAuditRecorder(ctrl *gomock.Controller) *MockAuditRecorder {
+	mock := &MockAuditRecorder{ctrl: ctrl}
+	mock.recorder = &MockAuditRecorderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRecorder) EXPECT() *MockAuditRecorderMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method.
+func (m *MockAuditRecorder) Record(ctx context.Context, event entity.AuditEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", ctx, event)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockAuditRecorderMockRecorder) Record(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditRecorder)(nil).Record), ctx, event)
+}
@@ -0,0 +1,129 @@
+package mock_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/internal/domain/events"
+	"main/internal/usecase/account"
+	mock "main/internal/usecase/account/mock"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type mocks struct {
+	repo      *mock.MockAccountRepository
+	messages  *mock.MockMessageRepository
+	sessions  *mock.MockSessionRevoker
+	publisher *mock.MockEventPublisher
+}
+
+func newService(t *testing.T, opts account.Options) (*account.AccountService, mocks) {
+	ctrl := gomock.NewController(t)
+	m := mocks{
+		repo:      mock.NewMockAccountRepository(ctrl),
+		messages:  mock.NewMockMessageRepository(ctrl),
+		sessions:  mock.NewMockSessionRevoker(ctrl),
+		publisher: mock.NewMockEventPublisher(ctrl),
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return account.NewAccountService(m.repo, m.messages, m.sessions, m.publisher, logger, opts), m
+}
+
+func TestRequestDeletion(t *testing.T) {
+	service, m := newService(t, account.Options{DeletionGrace: 48 * time.Hour})
+
+	scheduled := time.Now().Add(48 * time.Hour)
+	m.repo.EXPECT().ScheduleDeletion(gomock.Any(), int64(1), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int64, at time.Time) (time.Time, error) {
+			assert.WithinDuration(t, scheduled, at, time.Minute)
+			return at, nil
+		})
+
+	got, err := service.RequestDeletion(context.Background(), 1)
+	require.NoError(t, err)
+	assert.WithinDuration(t, scheduled, got, time.Minute)
+
+	_, err = service.RequestDeletion(context.Background(), 0)
+	assert.ErrorIs(t, err, customerrors.ErrInvalidInput)
+}
+
+func TestCancelDeletion(t *testing.T) {
+	service, m := newService(t, account.Options{})
+
+	m.repo.EXPECT().CancelDeletion(gomock.Any(), int64(1)).Return(nil)
+	assert.NoError(t, service.CancelDeletion(context.Background(), 1))
+
+	m.repo.EXPECT().CancelDeletion(gomock.Any(), int64(2)).Return(customerrors.ErrNotFound)
+	assert.ErrorIs(t, service.CancelDeletion(context.Background(), 2), customerrors.ErrNotFound)
+}
+
+func TestPurgeDueAnonymizes(t *testing.T) {
+	service, m := newService(t, account.Options{DeletedMessages: account.MessagesAnonymize})
+
+	m.repo.EXPECT().ListDueDeletions(gomock.Any(), gomock.Any()).Return([]int64{1}, nil)
+	gomock.InOrder(
+		m.repo.EXPECT().ListBots(gomock.Any(), int64(1)).Return([]dom.Bot{{ID: 5, OwnerID: 1}}, nil),
+		m.messages.EXPECT().AnonymizeSenderMessages(gomock.Any(), []int64{1, 5}, dom.DeletedUsername).Return(int64(12), nil),
+		m.sessions.EXPECT().RevokeAllSessions(gomock.Any(), int64(1)).Return(nil),
+		m.repo.EXPECT().DeleteUser(gomock.Any(), int64(1)).Return(nil),
+		m.publisher.EXPECT().SendAccountDeleted(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, evt events.AccountDeleted) error {
+				assert.Equal(t, int64(1), evt.UserID)
+				assert.Equal(t, []int64{5}, evt.BotIDs)
+				assert.Equal(t, "anonymized", evt.Messages)
+				return nil
+			}),
+	)
+
+	n, err := service.PurgeDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestPurgeDueDeletesMessages(t *testing.T) {
+	service, m := newService(t, account.Options{DeletedMessages: account.MessagesDelete})
+
+	m.repo.EXPECT().ListDueDeletions(gomock.Any(), gomock.Any()).Return([]int64{1}, nil)
+	m.repo.EXPECT().ListBots(gomock.Any(), int64(1)).Return([]dom.Bot{}, nil)
+	m.messages.EXPECT().DeleteSenderMessages(gomock.Any(), []int64{1}).Return([]int64{10, 11}, nil)
+	m.publisher.EXPECT().SendMessageDeleted(gomock.Any(), events.MessageDeleted{ChatID: 10}).Return(nil)
+	m.publisher.EXPECT().SendMessageDeleted(gomock.Any(), events.MessageDeleted{ChatID: 11}).Return(errors.New("kafka down"))
+	m.sessions.EXPECT().RevokeAllSessions(gomock.Any(), int64(1)).Return(nil)
+	m.repo.EXPECT().DeleteUser(gomock.Any(), int64(1)).Return(nil)
+	m.publisher.EXPECT().SendAccountDeleted(gomock.Any(), gomock.Any()).Return(nil)
+
+	n, err := service.PurgeDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestPurgeDueRetriesFailures(t *testing.T) {
+	service, m := newService(t, account.Options{})
+
+	m.repo.EXPECT().ListDueDeletions(gomock.Any(), gomock.Any()).Return([]int64{1, 2}, nil)
+
+	// The first account stops before anything is deleted in Postgres, so the
+	// next run picks it up again.
+	m.repo.EXPECT().ListBots(gomock.Any(), int64(1)).Return(nil, nil)
+	m.messages.EXPECT().AnonymizeSenderMessages(gomock.Any(), []int64{1}, gomock.Any()).
+		Return(int64(0), errors.New("mongo down"))
+
+	// The second was deleted by another instance in the meantime.
+	m.repo.EXPECT().ListBots(gomock.Any(), int64(2)).Return(nil, nil)
+	m.messages.EXPECT().AnonymizeSenderMessages(gomock.Any(), []int64{2}, gomock.Any()).Return(int64(0), nil)
+	m.sessions.EXPECT().RevokeAllSessions(gomock.Any(), int64(2)).Return(nil)
+	m.repo.EXPECT().DeleteUser(gomock.Any(), int64(2)).Return(customerrors.ErrUserNotFound)
+
+	n, err := service.PurgeDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
@@ -0,0 +1,331 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: export.go
+//
+// Generated by this command:
+//
+//	mockgen -source=export.go -destination=mock/export_mock.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	io "io"
+	entity "main/internal/domain/entity"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockExportRepository is a mock of ExportRepository interface.
+type MockExportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockExportRepositoryMockRecorder is the mock recorder for MockExportRepository.
+type MockExportRepositoryMockRecorder struct {
+	mock *MockExportRepository
+}
+
+// NewMockExportRepository creates a new mock instance.
+func NewMockExportRepository(ctrl *gomock.Controller) *MockExportRepository {
+	mock := &MockExportRepository{ctrl: ctrl}
+	mock.recorder = &MockExportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExportRepository) EXPECT() *MockExportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimExport mocks base method.
+func (m *MockExportRepository) ClaimExport(ctx context.Context, stale time.Duration) (entity.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimExport", ctx, stale)
+	ret0, _ := ret[0].(entity.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimExport indicates an expected call of ClaimExport.
+func (mr *MockExportRepositoryMockRecorder) ClaimExport(ctx, stale any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimExport", reflect.TypeOf((*MockExportRepository)(nil).ClaimExport), ctx, stale)
+}
+
+// CompleteExport mocks base method.
+func (m *MockExportRepository) CompleteExport(ctx context.Context, exportID string, size int64, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteExport", ctx, exportID, size, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteExport indicates an expected call of CompleteExport.
+func (mr *MockExportRepositoryMockRecorder) CompleteExport(ctx, exportID, size, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteExport", reflect.TypeOf((*MockExportRepository)(nil).CompleteExport), ctx, exportID, size, expiresAt)
+}
+
+// CreateExport mocks base method.
+func (m *MockExportRepository) CreateExport(ctx context.Context, userID int64) (entity.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateExport", ctx, userID)
+	ret0, _ := ret[0].(entity.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateExport indicates an expected call of CreateExport.
+func (mr *MockExportRepositoryMockRecorder) CreateExport(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateExport", reflect.TypeOf((*MockExportRepository)(nil).CreateExport), ctx, userID)
+}
+
+// ExpireExports mocks base method.
+func (m *MockExportRepository) ExpireExports(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireExports", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireExports indicates an expected call of ExpireExports.
+func (mr *MockExportRepositoryMockRecorder) ExpireExports(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireExports", reflect.TypeOf((*MockExportRepository)(nil).ExpireExports), ctx)
+}
+
+// FailExport mocks base method.
+func (m *MockExportRepository) FailExport(ctx context.Context, exportID, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailExport", ctx, exportID, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailExport indicates an expected call of FailExport.
+func (mr *MockExportRepositoryMockRecorder) FailExport(ctx, exportID, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailExport", reflect.TypeOf((*MockExportRepository)(nil).FailExport), ctx, exportID, reason)
+}
+
+// GetExport mocks base method.
+func (m *MockExportRepository) GetExport(ctx context.Context, exportID string) (entity.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExport", ctx, exportID)
+	ret0, _ := ret[0].(entity.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExport indicates an expected call of GetExport.
+func (mr *MockExportRepositoryMockRecorder) GetExport(ctx, exportID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExport", reflect.TypeOf((*MockExportRepository)(nil).GetExport), ctx, exportID)
+}
+
+// ListExports mocks base method.
+func (m *MockExportRepository) ListExports(ctx context.Context, userID int64) ([]entity.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExports", ctx, userID)
+	ret0, _ := ret[0].([]entity.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExports indicates an expected call of ListExports.
+func (mr *MockExportRepositoryMockRecorder) ListExports(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExports", reflect.TypeOf((*MockExportRepository)(nil).ListExports), ctx, userID)
+}
+
+// MockProfileReader is a mock of ProfileReader interface.
+type MockProfileReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockProfileReaderMockRecorder
+	isgomock struct{}
+}
+
+// MockProfileReaderMockRecorder is the mock recorder for MockProfileReader.
+type MockProfileReaderMockRecorder struct {
+	mock *MockProfileReader
+}
+
+// NewMockProfileReader creates a new mock instance.
+func NewMockProfileReader(ctrl *gomock.Controller) *MockProfileReader {
+	mock := &MockProfileReader{ctrl: ctrl}
+	mock.recorder = &MockProfileReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProfileReader) EXPECT() *MockProfileReaderMockRecorder {
+	return m.recorder
+}
+
+// GetUserByID mocks base method.
+func (m *MockProfileReader) GetUserByID(ctx context.Context, userID int64) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockProfileReaderMockRecorder) GetUserByID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockProfileReader)(nil).GetUserByID), ctx, userID)
+}
+
+// MockMembershipReader is a mock of MembershipReader interface.
+type MockMembershipReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockMembershipReaderMockRecorder
+	isgomock struct{}
+}
+
+// MockMembershipReaderMockRecorder is the mock recorder for MockMembershipReader.
+type MockMembershipReaderMockRecorder struct {
+	mock *MockMembershipReader
+}
+
+// NewMockMembershipReader creates a new mock instance.
+func NewMockMembershipReader(ctrl *gomock.Controller) *MockMembershipReader {
+	mock := &MockMembershipReader{ctrl: ctrl}
+	mock.recorder = &MockMembershipReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMembershipReader) EXPECT() *MockMembershipReaderMockRecorder {
+	return m.recorder
+}
+
+// ListMemberships mocks base method.
+func (m *MockMembershipReader) ListMemberships(ctx context.Context, userID int64) ([]entity.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMemberships", ctx, userID)
+	ret0, _ := ret[0].([]entity.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMemberships indicates an expected call of ListMemberships.
+func (mr *MockMembershipReaderMockRecorder) ListMemberships(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMemberships", reflect.TypeOf((*MockMembershipReader)(nil).ListMemberships), ctx, userID)
+}
+
+// MockMessageReader is a mock of MessageReader interface.
+type MockMessageReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageReaderMockRecorder
+	isgomock struct{}
+}
+
+// MockMessageReaderMockRecorder is the mock recorder for MockMessageReader.
+type MockMessageReaderMockRecorder struct {
+	mock *MockMessageReader
+}
+
+// NewMockMessageReader creates a new mock instance.
+func NewMockMessageReader(ctrl *gomock.Controller) *MockMessageReader {
+	mock := &MockMessageReader{ctrl: ctrl}
+	mock.recorder = &MockMessageReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageReader) EXPECT() *MockMessageReaderMockRecorder {
+	return m.recorder
+}
+
+// StreamSenderMessages mocks base method.
+func (m *MockMessageReader) StreamSenderMessages(ctx context.Context, senderID int64, fn func(entity.Message) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamSenderMessages", ctx, senderID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamSenderMessages indicates an expected call of StreamSenderMessages.
+func (mr *MockMessageReaderMockRecorder) StreamSenderMessages(ctx, senderID, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamSenderMessages", reflect.TypeOf((*MockMessageReader)(nil).StreamSenderMessages), ctx, senderID, fn)
+}
+
+// MockBlobStore is a mock of BlobStore interface.
+type MockBlobStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockBlobStoreMockRecorder is the mock recorder for MockBlobStore.
+type MockBlobStoreMockRecorder struct {
+	mock *MockBlobStore
+}
+
+// NewMockBlobStore creates a new mock instance.
+func NewMockBlobStore(ctrl *gomock.Controller) *MockBlobStore {
+	mock := &MockBlobStore{ctrl: ctrl}
+	mock.recorder = &MockBlobStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlobStore) EXPECT() *MockBlobStoreMockRecorder {
+	return m.recorder
+}
+
+// Open mocks base method.
+func (m *MockBlobStore) Open(key string) (io.ReadSeekCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", key)
+	ret0, _ := ret[0].(io.ReadSeekCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockBlobStoreMockRecorder) Open(key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockBlobStore)(nil).Open), key)
+}
+
+// Put mocks base method.
+func (m *MockBlobStore) Put(key string, write func(io.Writer) error) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", key, write)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockBlobStoreMockRecorder) Put(key, write any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockBlobStore)(nil).Put), key, write)
+}
+
+// Remove mocks base method.
+func (m *MockBlobStore) Remove(key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockBlobStoreMockRecorder) Remove(key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockBlobStore)(nil).Remove), key)
+}
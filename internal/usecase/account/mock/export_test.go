@@ -0,0 +1,167 @@
+package mock_test
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/internal/infrastructure/blob"
+	"main/internal/usecase/account"
+	mock "main/internal/usecase/account/mock"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const exportID = "5f0c6a4e-8a53-4a8e-9d0c-6f7e1d2b3c4d"
+
+type exportMocks struct {
+	repo        *mock.MockExportRepository
+	profiles    *mock.MockProfileReader
+	memberships *mock.MockMembershipReader
+	messages    *mock.MockMessageReader
+	dir         string
+}
+
+func newExportService(t *testing.T) (*account.ExportService, exportMocks) {
+	ctrl := gomock.NewController(t)
+	m := exportMocks{
+		repo:        mock.NewMockExportRepository(ctrl),
+		profiles:    mock.NewMockProfileReader(ctrl),
+		memberships: mock.NewMockMembershipReader(ctrl),
+		messages:    mock.NewMockMessageReader(ctrl),
+		dir:         t.TempDir(),
+	}
+	store, err := blob.NewLocalStore(m.dir)
+	require.NoError(t, err)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := account.NewExportService(m.repo, m.profiles, m.memberships, m.messages, store, logger,
+		account.ExportOptions{LinkSecret: "secret", LinkTTL: time.Minute, Retention: time.Hour})
+	return service, m
+}
+
+func readZip(t *testing.T, path string) map[string]string {
+	zr, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer zr.Close()
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	return files
+}
+
+func TestRunPendingBuildsArchive(t *testing.T) {
+	service, m := newExportService(t)
+
+	gomock.InOrder(
+		m.repo.EXPECT().ClaimExport(gomock.Any(), gomock.Any()).Return(dom.DataExport{ID: exportID, UserID: 1}, nil),
+		m.repo.EXPECT().ClaimExport(gomock.Any(), gomock.Any()).Return(dom.DataExport{}, customerrors.ErrNotFound),
+	)
+	m.profiles.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(dom.User{
+		ID: 1, Username: "alice", Email: "alice@example.com", Password: "$argon2id$hash", Role: "user",
+	}, nil)
+	m.memberships.EXPECT().ListMemberships(gomock.Any(), int64(1)).Return([]dom.Membership{{ChatID: 10, Title: "general"}}, nil)
+	m.messages.EXPECT().StreamSenderMessages(gomock.Any(), int64(1), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int64, fn func(dom.Message) error) error {
+			require.NoError(t, fn(dom.Message{ChatID: 10, SenderID: 1, Text: "hello"}))
+			return fn(dom.Message{ChatID: 10, SenderID: 1, Text: "bye"})
+		})
+	m.repo.EXPECT().CompleteExport(gomock.Any(), exportID, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, size int64, expiresAt time.Time) error {
+			assert.Positive(t, size)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+			return nil
+		})
+
+	n, err := service.RunPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	files := readZip(t, filepath.Join(m.dir, exportID+".zip"))
+	assert.Contains(t, files["profile.json"], "alice@example.com")
+	assert.NotContains(t, files["profile.json"], "argon2id", "the password hash is not exported")
+	assert.Contains(t, files["memberships.json"], "general")
+
+	var messages []dom.Message
+	require.NoError(t, json.Unmarshal([]byte(files["messages.json"]), &messages))
+	require.Len(t, messages, 2)
+	assert.Equal(t, "bye", messages[1].Text)
+}
+
+func TestRunPendingFailsExport(t *testing.T) {
+	service, m := newExportService(t)
+
+	gomock.InOrder(
+		m.repo.EXPECT().ClaimExport(gomock.Any(), gomock.Any()).Return(dom.DataExport{ID: exportID, UserID: 1}, nil),
+		m.repo.EXPECT().ClaimExport(gomock.Any(), gomock.Any()).Return(dom.DataExport{}, customerrors.ErrNotFound),
+	)
+	m.profiles.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(dom.User{ID: 1}, nil)
+	m.memberships.EXPECT().ListMemberships(gomock.Any(), int64(1)).Return(nil, nil)
+	m.messages.EXPECT().StreamSenderMessages(gomock.Any(), int64(1), gomock.Any()).Return(errors.New("mongo down"))
+	m.repo.EXPECT().FailExport(gomock.Any(), exportID, gomock.Any()).Return(nil)
+
+	n, err := service.RunPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NoFileExists(t, filepath.Join(m.dir, exportID+".zip"), "no partial archive is left")
+}
+
+func TestDownload(t *testing.T) {
+	service, m := newExportService(t)
+
+	expires := time.Now().Add(time.Hour)
+	ready := dom.DataExport{ID: exportID, UserID: 1, Status: dom.ExportReady, ExpiresAt: &expires}
+	m.repo.EXPECT().GetExport(gomock.Any(), exportID).Return(ready, nil).AnyTimes()
+
+	_, _, err := service.DownloadToken(context.Background(), 2, exportID)
+	assert.ErrorIs(t, err, customerrors.ErrNotFound, "other users cannot see the export")
+
+	token, linkExpires, err := service.DownloadToken(context.Background(), 1, exportID)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), linkExpires, 2*time.Second)
+
+	require.NoError(t, os.WriteFile(filepath.Join(m.dir, exportID+".zip"), []byte("archive"), 0o600))
+	export, archive, err := service.OpenDownload(context.Background(), exportID, token)
+	require.NoError(t, err)
+	defer archive.Close()
+	assert.Equal(t, exportID, export.ID)
+	b, err := io.ReadAll(archive)
+	require.NoError(t, err)
+	assert.Equal(t, "archive", string(b))
+
+	_, _, err = service.OpenDownload(context.Background(), exportID, strings.Replace(token, ".", ".x", 1))
+	assert.ErrorIs(t, err, customerrors.ErrInvalidDownloadLink)
+	_, _, err = service.OpenDownload(context.Background(), exportID, "")
+	assert.ErrorIs(t, err, customerrors.ErrInvalidDownloadLink)
+	_, _, err = service.OpenDownload(context.Background(), "6a1c0b2d-0000-4000-8000-000000000000", token)
+	assert.ErrorIs(t, err, customerrors.ErrInvalidDownloadLink, "tokens are bound to one export")
+}
+
+func TestDownloadNotReady(t *testing.T) {
+	service, m := newExportService(t)
+
+	m.repo.EXPECT().GetExport(gomock.Any(), exportID).
+		Return(dom.DataExport{ID: exportID, UserID: 1, Status: dom.ExportRunning}, nil)
+
+	_, _, err := service.DownloadToken(context.Background(), 1, exportID)
+	assert.ErrorIs(t, err, customerrors.ErrExportNotReady)
+}
@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+	ctxHelper "main/pkg/jwt/context"
+	"main/pkg/requestinfo"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+	exportPageSize  = 1000
+)
+
+//go:generate mockgen -source=audit_usecase.go -destination=mock/audit_mock.go -package=mock
+type AuditRepository interface {
+	InsertEvent(ctx context.Context, event dom.AuditEvent) error
+	ListEvents(ctx context.Context, filter dom.AuditFilter) ([]dom.AuditEvent, error)
+}
+
+type AuditService struct {
+	repo   AuditRepository
+	logger *slog.Logger
+}
+
+func NewAuditService(repo AuditRepository, logger *slog.Logger) *AuditService {
+	return &AuditService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Record stores event. Client details missing from the event are taken from
+// the request info in ctx, and the actor from the caller's claims. Failures
+// are logged rather than returned, so auditing never fails the operation
+// being audited.
+func (s *AuditService) Record(ctx context.Context, event dom.AuditEvent) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if info, ok := requestinfo.FromContext(ctx); ok {
+		if event.IP == "" {
+			event.IP = info.IP
+		}
+		if event.UserAgent == "" {
+			event.UserAgent = info.UserAgent
+		}
+		if event.RequestID == "" {
+			event.RequestID = info.RequestID
+		}
+	}
+	if claims, ok := ctxHelper.FromContext(ctx); ok {
+		if event.ActorID == 0 {
+			event.ActorID = claims.UserID
+		}
+		if claims.APIKeyID != 0 {
+			if event.Metadata == nil {
+				event.Metadata = map[string]interface{}{}
+			}
+			event.Metadata["api_key_id"] = claims.APIKeyID
+		}
+	}
+
+	// The event is written even if the request was cancelled right after the
+	// audited operation succeeded.
+	if err := s.repo.InsertEvent(context.WithoutCancel(ctx), event); err != nil {
+		s.logger.Error("failed to record audit event",
+			slog.String("action", event.Action),
+			slog.Int64("actor_id", event.ActorID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// Query returns a page of events matching filter, newest first, and the
+// cursor of the next page; zero means there are no more events.
+func (s *AuditService) Query(ctx context.Context, filter dom.AuditFilter) ([]dom.AuditEvent, int64, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit < 0 || filter.Limit > maxPageSize {
+		return nil, 0, fmt.Errorf("%w: limit must be between 1 and %d", customerrors.ErrInvalidInput, maxPageSize)
+	}
+	if err := validateFilter(filter); err != nil {
+		return nil, 0, err
+	}
+
+	// One extra row tells whether another page follows.
+	limit := filter.Limit
+	filter.Limit++
+	events, err := s.repo.ListEvents(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(events) <= limit {
+		return events, 0, nil
+	}
+	events = events[:limit]
+	return events, events[limit-1].ID, nil
+}
+
+// Export writes every event matching filter to w as JSON lines, newest
+// first. filter.Limit is ignored.
+func (s *AuditService) Export(ctx context.Context, filter dom.AuditFilter, w io.Writer) error {
+	if err := validateFilter(filter); err != nil {
+		return err
+	}
+	filter.Limit = exportPageSize
+
+	enc := json.NewEncoder(w)
+	for {
+		events, err := s.repo.ListEvents(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			if err := enc.Encode(e); err != nil {
+				return fmt.Errorf("service: export audit events: %w", err)
+			}
+		}
+		if len(events) < filter.Limit {
+			return nil
+		}
+		filter.BeforeID = events[len(events)-1].ID
+	}
+}
+
+func validateFilter(filter dom.AuditFilter) error {
+	if filter.ActorID < 0 || filter.BeforeID < 0 {
+		return customerrors.ErrInvalidInput
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return fmt.Errorf("%w: from must be before to", customerrors.ErrInvalidInput)
+	}
+	return nil
+}
+
+// Discard drops every event. Services built without an audit trail use it.
+type Discard struct{}
+
+func (Discard) Record(context.Context, dom.AuditEvent) {}
@@ -0,0 +1,172 @@
+package mock_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/internal/usecase/audit"
+	mock "main/internal/usecase/audit/mock"
+	"main/pkg/customerrors"
+	"main/pkg/jwt"
+	ctxHelper "main/pkg/jwt/context"
+	"main/pkg/requestinfo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func newService(t *testing.T) (*audit.AuditService, *mock.MockAuditRepository) {
+	ctrl := gomock.NewController(t)
+	repo := mock.NewMockAuditRepository(ctrl)
+	return audit.NewAuditService(repo, slog.New(slog.NewTextHandler(io.Discard, nil))), repo
+}
+
+func TestRecord(t *testing.T) {
+	service, repo := newService(t)
+
+	ctx := requestinfo.ToContext(context.Background(), requestinfo.Info{
+		IP:        "203.0.113.7",
+		UserAgent: "curl/8.0",
+		RequestID: "req-1",
+	})
+	ctx = ctxHelper.ToContext(ctx, &jwt.TokenClaims{UserID: 7, APIKeyID: 3})
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	repo.EXPECT().InsertEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, e dom.AuditEvent) error {
+		assert.NoError(t, ctx.Err(), "the insert outlives the request")
+		assert.Equal(t, int64(7), e.ActorID)
+		assert.Equal(t, "203.0.113.7", e.IP)
+		assert.Equal(t, "curl/8.0", e.UserAgent)
+		assert.Equal(t, "req-1", e.RequestID)
+		assert.Equal(t, int64(3), e.Metadata["api_key_id"])
+		assert.False(t, e.OccurredAt.IsZero())
+		return errors.New("db down")
+	})
+
+	// A failed insert is only logged.
+	service.Record(ctx, dom.AuditEvent{Action: dom.AuditRoleChanged})
+}
+
+func TestRecordKeepsExplicitFields(t *testing.T) {
+	service, repo := newService(t)
+
+	ctx := requestinfo.ToContext(context.Background(), requestinfo.Info{IP: "203.0.113.7"})
+	ctx = ctxHelper.ToContext(ctx, &jwt.TokenClaims{UserID: 7})
+
+	repo.EXPECT().InsertEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e dom.AuditEvent) error {
+		assert.Equal(t, int64(9), e.ActorID)
+		assert.Equal(t, "198.51.100.1", e.IP)
+		assert.Nil(t, e.Metadata)
+		return nil
+	})
+
+	service.Record(ctx, dom.AuditEvent{Action: dom.AuditLogin, ActorID: 9, IP: "198.51.100.1"})
+}
+
+func TestQuery(t *testing.T) {
+	events := func(ids ...int64) []dom.AuditEvent {
+		out := make([]dom.AuditEvent, 0, len(ids))
+		for _, id := range ids {
+			out = append(out, dom.AuditEvent{ID: id})
+		}
+		return out
+	}
+
+	tests := []struct {
+		name        string
+		filter      dom.AuditFilter
+		setupMock   func(*mock.MockAuditRepository)
+		expectIDs   []int64
+		expectNext  int64
+		expectError error
+	}{
+		{
+			name:   "Default page size",
+			filter: dom.AuditFilter{Action: dom.AuditLogin},
+			setupMock: func(repo *mock.MockAuditRepository) {
+				repo.EXPECT().ListEvents(gomock.Any(), dom.AuditFilter{Action: dom.AuditLogin, Limit: 51}).
+					Return(events(9, 8), nil)
+			},
+			expectIDs: []int64{9, 8},
+		},
+		{
+			name:   "More pages",
+			filter: dom.AuditFilter{Limit: 2, BeforeID: 10},
+			setupMock: func(repo *mock.MockAuditRepository) {
+				repo.EXPECT().ListEvents(gomock.Any(), dom.AuditFilter{Limit: 3, BeforeID: 10}).
+					Return(events(9, 8, 7), nil)
+			},
+			expectIDs:  []int64{9, 8},
+			expectNext: 8,
+		},
+		{
+			name:        "Limit too large",
+			filter:      dom.AuditFilter{Limit: 501},
+			setupMock:   func(repo *mock.MockAuditRepository) {},
+			expectError: customerrors.ErrInvalidInput,
+		},
+		{
+			name: "Empty time range",
+			filter: dom.AuditFilter{
+				From: time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			},
+			setupMock:   func(repo *mock.MockAuditRepository) {},
+			expectError: customerrors.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newService(t)
+			tt.setupMock(repo)
+
+			got, next, err := service.Query(context.Background(), tt.filter)
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			ids := make([]int64, 0, len(got))
+			for _, e := range got {
+				ids = append(ids, e.ID)
+			}
+			assert.Equal(t, tt.expectIDs, ids)
+			assert.Equal(t, tt.expectNext, next)
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	service, repo := newService(t)
+
+	full := make([]dom.AuditEvent, 1000)
+	for i := range full {
+		full[i] = dom.AuditEvent{ID: int64(2000 - i), Action: dom.AuditLogin}
+	}
+	gomock.InOrder(
+		repo.EXPECT().ListEvents(gomock.Any(), dom.AuditFilter{ActorID: 7, Limit: 1000}).Return(full, nil),
+		repo.EXPECT().ListEvents(gomock.Any(), dom.AuditFilter{ActorID: 7, Limit: 1000, BeforeID: 1001}).
+			Return([]dom.AuditEvent{{ID: 4, Action: dom.AuditLogout}}, nil),
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, service.Export(context.Background(), dom.AuditFilter{ActorID: 7, Limit: 5}, &buf))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1001)
+	var last dom.AuditEvent
+	require.NoError(t, json.Unmarshal([]byte(lines[1000]), &last))
+	assert.Equal(t, int64(4), last.ID)
+	assert.Equal(t, dom.AuditLogout, last.Action)
+}
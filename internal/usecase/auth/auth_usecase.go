@@ -2,31 +2,61 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strconv"
 	"time"
 
 	dom "main/internal/domain/entity"
+	"main/internal/usecase/audit"
 
 	"main/pkg/customerrors"
 	"main/pkg/jwt"
-	"main/pkg/utils"
+	"main/pkg/oidc"
+	"main/pkg/password"
 
-	"golang.org/x/crypto/bcrypt"
+	"github.com/google/uuid"
 )
 
 //go:generate mockgen -source=auth_usecase.go -destination=./mock/auth_usecase_mock.go -package=mock
 type AuthRepository interface {
 	GetCredentialsByUsername(ctx context.Context, username string) (dom.User, error)
-	SaveRefreshToken(ctx context.Context, refreshToken dom.RefreshToken) error
+	GetUserRole(ctx context.Context, userID int64) (string, error)
 	GetRefreshToken(ctx context.Context, userID int64) (string, error)
+	GetRefreshTokenByValue(ctx context.Context, token string) (dom.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, usedID int64, next dom.RefreshToken) error
+	RevokeTokenFamily(ctx context.Context, familyID string) error
 	DeleteRefreshToken(ctx context.Context, userID int64) error
+	CreateSession(ctx context.Context, session dom.Session, refreshToken dom.RefreshToken) error
+	ListSessions(ctx context.Context, userID int64) ([]dom.Session, error)
+	RevokeSession(ctx context.Context, userID int64, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID int64) ([]string, error)
+	GetTOTP(ctx context.Context, userID int64) (dom.TOTP, error)
+	SetTOTPSecret(ctx context.Context, userID int64, secret string) error
+	EnableTOTP(ctx context.Context, userID int64, codeHashes []string) error
+	DisableTOTP(ctx context.Context, userID int64) error
+	UseTOTPStep(ctx context.Context, userID int64, step int64) error
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) error
+	SaveEmailCode(ctx context.Context, userID int64, codeHash string, expiresAt time.Time) error
+	ConsumeEmailCode(ctx context.Context, userID int64, codeHash string, maxAttempts int) error
+	GetPasswordHash(ctx context.Context, userID int64) (string, error)
+	UpdatePassword(ctx context.Context, userID int64, passwordHash string) error
+	SavePasswordResetToken(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error
+	GetPasswordResetUserID(ctx context.Context, tokenHash string) (int64, error)
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string) (int64, error)
+	GetUserByIdentity(ctx context.Context, provider, subject string) (dom.User, error)
+	LinkIdentity(ctx context.Context, userID int64, identity dom.ExternalIdentity) error
+	CreateUserWithIdentity(ctx context.Context, username string, identity dom.ExternalIdentity) (dom.User, error)
 }
 type UserRepository interface {
 	RegisterUser(ctx context.Context, username, email, passwordHash string) (dom.User, error)
+	GetUserByEmail(ctx context.Context, email string) (dom.User, error)
+	GetUserByID(ctx context.Context, userID int64) (dom.User, error)
 }
 
 type TokenManager interface {
-	NewAccessToken(userID int64, ttl time.Duration) (string, error)
+	NewAccessToken(userID int64, sessionID, role string, ttl time.Duration) (string, error)
 	NewRefreshToken() (string, error)
 	Parse(accessToken string) (*jwt.TokenClaims, error)
 }
@@ -35,58 +65,320 @@ type TokenBlacklister interface {
 	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
 }
 
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// AuditRecorder stores events for the security audit trail.
+type AuditRecorder interface {
+	Record(ctx context.Context, event dom.AuditEvent)
+}
+
+// OIDCProvider is an OpenID provider users can sign in with.
+type OIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	Authenticate(ctx context.Context, code, verifier, nonce string) (oidc.Identity, error)
+}
+
+// Cache keeps short-lived login state: two-factor challenges, OIDC sign-ins
+// and failed attempt counters.
+type Cache interface {
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+const refreshTokenTTL = 15 * 24 * time.Hour
+
+// Options holds the settings of AuthService.
+type Options struct {
+	TokenTTL   time.Duration
+	TOTPIssuer string
+	// CodeSecret signs the email verification codes.
+	CodeSecret string
+	Lockout    LockoutPolicy
+	// OIDCProviders are the OpenID providers users can sign in with, by name.
+	OIDCProviders map[string]OIDCProvider
+	// PasswordHasher hashes new passwords; nil uses argon2id with
+	// password.DefaultParams. Older hashes are upgraded at login.
+	PasswordHasher *password.Hasher
+	// PasswordPolicy is checked for new passwords; nil uses
+	// password.DefaultPolicy.
+	PasswordPolicy *password.Policy
+	// Audit records logins, logouts and password changes; nil discards them.
+	Audit AuditRecorder
+}
+
 type AuthService struct {
-	repoAuth  AuthRepository
-	repoUser  UserRepository
-	tokenMgr  TokenManager
-	blacklist TokenBlacklister
-	tokenTTL  time.Duration
+	repoAuth      AuthRepository
+	repoUser      UserRepository
+	tokenMgr      TokenManager
+	blacklist     TokenBlacklister
+	cache         Cache
+	mailer        Mailer
+	logger        *slog.Logger
+	tokenTTL      time.Duration
+	totpIssuer    string
+	codeSecret    []byte
+	lockout       LockoutPolicy
+	oidcProviders map[string]OIDCProvider
+	hasher        *password.Hasher
+	policy        password.Policy
+	audit         AuditRecorder
 }
 
-func NewAuthService(repoAuth AuthRepository, repoUser UserRepository, tokenMgr TokenManager, blacklist TokenBlacklister, tokenTTL time.Duration) *AuthService {
+func NewAuthService(repoAuth AuthRepository, repoUser UserRepository, tokenMgr TokenManager, blacklist TokenBlacklister,
+	cache Cache, mailer Mailer, logger *slog.Logger, opts Options) *AuthService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	hasher := opts.PasswordHasher
+	if hasher == nil {
+		hasher = password.NewHasher(password.DefaultParams)
+	}
+	policy := password.DefaultPolicy
+	if opts.PasswordPolicy != nil {
+		policy = *opts.PasswordPolicy
+	}
+	var auditRecorder AuditRecorder = audit.Discard{}
+	if opts.Audit != nil {
+		auditRecorder = opts.Audit
+	}
 	return &AuthService{
-		repoAuth:  repoAuth,
-		repoUser:  repoUser,
-		tokenMgr:  tokenMgr,
-		blacklist: blacklist,
-		tokenTTL:  tokenTTL,
+		repoAuth:      repoAuth,
+		repoUser:      repoUser,
+		tokenMgr:      tokenMgr,
+		blacklist:     blacklist,
+		cache:         cache,
+		mailer:        mailer,
+		logger:        logger,
+		tokenTTL:      opts.TokenTTL,
+		totpIssuer:    opts.TOTPIssuer,
+		codeSecret:    []byte(opts.CodeSecret),
+		lockout:       opts.Lockout,
+		oidcProviders: opts.OIDCProviders,
+		hasher:        hasher,
+		policy:        policy,
+		audit:         auditRecorder,
 	}
 }
 
-func (s *AuthService) LoginUser(ctx context.Context, username, password string) (accessToken string, err error) {
+// LoginUser checks the credentials and opens a new session described by
+// device (device name, user agent and IP). Users with two-factor
+// authentication get no tokens yet; instead a challenge token is returned
+// that VerifyTOTP exchanges for them. A password hash made with bcrypt or
+// outdated parameters is replaced once the password has been verified.
+func (s *AuthService) LoginUser(ctx context.Context, username, plainPassword string, device dom.Session) (accessToken string, refreshToken dom.RefreshToken, challengeToken string, err error) {
+
+	if err := s.checkLockout(ctx, username, device.IP); err != nil {
+		return "", dom.RefreshToken{}, "", err
+	}
 
 	user, err := s.repoAuth.GetCredentialsByUsername(ctx, username)
 	if err != nil {
-		return "", err
+		if errors.Is(err, customerrors.ErrUserNotFound) {
+			if err := s.recordLoginFailure(ctx, username, device.IP); err != nil {
+				return "", dom.RefreshToken{}, "", err
+			}
+		}
+		return "", dom.RefreshToken{}, "", err
+	}
+
+	ok, rehash, err := s.hasher.Verify(plainPassword, user.Password)
+	if err != nil && !errors.Is(err, password.ErrUnknownHash) {
+		s.logger.Error("failed to verify password hash", slog.Int64("user_id", user.ID), slog.String("error", err.Error()))
+	}
+	if !ok {
+		if err := s.recordLoginFailure(ctx, username, device.IP); err != nil {
+			return "", dom.RefreshToken{}, "", err
+		}
+		return "", dom.RefreshToken{}, "", customerrors.ErrInvalidInput
+	}
+
+	if err := s.resetLoginFailures(ctx, username); err != nil {
+		return "", dom.RefreshToken{}, "", err
+	}
+
+	if rehash {
+		s.rehashPassword(ctx, user.ID, plainPassword)
+	}
+
+	if user.TOTPEnabled {
+		challengeToken, err := s.newLoginChallenge(ctx, user.ID, device)
+		if err != nil {
+			return "", dom.RefreshToken{}, "", err
+		}
+		return "", dom.RefreshToken{}, challengeToken, nil
+	}
+
+	accessToken, refreshToken, err = s.openSession(ctx, user.ID, device, "password")
+	if err != nil {
+		return "", dom.RefreshToken{}, "", err
+	}
+	return accessToken, refreshToken, "", nil
+}
+
+// openSession issues the first token pair of a new session. method names the
+// way the user signed in for the audit trail.
+func (s *AuthService) openSession(ctx context.Context, userID int64, device dom.Session, method string) (accessToken string, refreshToken dom.RefreshToken, err error) {
+	refreshTokenString, err := s.tokenMgr.NewRefreshToken()
+	if err != nil {
+		return "", dom.RefreshToken{}, fmt.Errorf("service: generate refresh: %w", err)
+	}
+
+	sessionID := uuid.NewString()
+
+	role, err := s.repoAuth.GetUserRole(ctx, userID)
+	if err != nil {
+		return "", dom.RefreshToken{}, err
+	}
+
+	accessTokenString, err := s.tokenMgr.NewAccessToken(userID, sessionID, role, s.tokenTTL)
+	if err != nil {
+		return "", dom.RefreshToken{}, fmt.Errorf("service: generate access: %w", err)
+	}
+
+	now := time.Now()
+	refreshToken = dom.RefreshToken{
+		UserID:    userID,
+		Token:     refreshTokenString,
+		FamilyID:  sessionID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(refreshTokenTTL),
+	}
+	session := dom.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		DeviceName: device.DeviceName,
+		UserAgent:  device.UserAgent,
+		IP:         device.IP,
+		CreatedAt:  now,
+		LastUsedAt: now,
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+	if err := s.repoAuth.CreateSession(ctx, session, refreshToken); err != nil {
+		return "", dom.RefreshToken{}, err
+	}
+
+	s.audit.Record(ctx, dom.AuditEvent{
+		ActorID:    userID,
+		Action:     dom.AuditLogin,
+		TargetType: "session",
+		TargetID:   sessionID,
+		IP:         device.IP,
+		UserAgent:  device.UserAgent,
+		Metadata:   map[string]interface{}{"method": method, "device_name": device.DeviceName},
+	})
+
+	return accessTokenString, refreshToken, nil
+}
+
+// RefreshTokens exchanges a refresh token for a new access/refresh pair.
+// The presented token is invalidated; presenting an already used token
+// revokes its whole family, since it means the token has leaked.
+func (s *AuthService) RefreshTokens(ctx context.Context, refreshToken string) (accessToken string, newRefreshToken dom.RefreshToken, err error) {
+	stored, err := s.repoAuth.GetRefreshTokenByValue(ctx, refreshToken)
+	if err != nil {
+		return "", dom.RefreshToken{}, err
+	}
 
-		return "", customerrors.ErrInvalidInput
+	if stored.RevokedAt != nil {
+		return "", dom.RefreshToken{}, customerrors.ErrRefreshTokenRevoked
+	}
+	if stored.UsedAt != nil {
+		return "", dom.RefreshToken{}, s.revokeFamily(ctx, stored.FamilyID)
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return "", dom.RefreshToken{}, customerrors.ErrRefreshTokenExpired
 	}
 
 	refreshTokenString, err := s.tokenMgr.NewRefreshToken()
 	if err != nil {
-		return "", fmt.Errorf("service: generate refresh: %w", err)
+		return "", dom.RefreshToken{}, fmt.Errorf("service: generate refresh: %w", err)
 	}
 
-	accessTokenString, err := s.tokenMgr.NewAccessToken(user.ID, s.tokenTTL)
+	// The role is read again so that promotions and demotions take effect
+	// with the next refresh.
+	role, err := s.repoAuth.GetUserRole(ctx, stored.UserID)
 	if err != nil {
-		return "", fmt.Errorf("service: generate access: %w", err)
+		return "", dom.RefreshToken{}, err
 	}
 
-	refreshToken := dom.RefreshToken{
-		UserID:    user.ID,
+	accessTokenString, err := s.tokenMgr.NewAccessToken(stored.UserID, stored.FamilyID, role, s.tokenTTL)
+	if err != nil {
+		return "", dom.RefreshToken{}, fmt.Errorf("service: generate access: %w", err)
+	}
+
+	next := dom.RefreshToken{
+		UserID:    stored.UserID,
 		Token:     refreshTokenString,
+		FamilyID:  stored.FamilyID,
 		CreatedAt: time.Now(),
-		ExpiresAt: time.Now().Add(24 * time.Hour * 15),
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	}
+
+	if err := s.repoAuth.RotateRefreshToken(ctx, stored.ID, next); err != nil {
+		if errors.Is(err, customerrors.ErrRefreshTokenReused) {
+			return "", dom.RefreshToken{}, s.revokeFamily(ctx, stored.FamilyID)
+		}
+		return "", dom.RefreshToken{}, err
+	}
+
+	return accessTokenString, next, nil
+}
+
+func (s *AuthService) revokeFamily(ctx context.Context, familyID string) error {
+	if err := s.repoAuth.RevokeTokenFamily(ctx, familyID); err != nil {
+		return fmt.Errorf("service: revoke token family: %w", err)
+	}
+	if err := s.blacklistSession(ctx, familyID); err != nil {
+		return err
 	}
+	return customerrors.ErrRefreshTokenReused
+}
+
+// blacklistSession rejects every access token issued for the session until
+// the longest of them has expired.
+func (s *AuthService) blacklistSession(ctx context.Context, sessionID string) error {
+	if err := s.blacklist.Set(ctx, jwt.RevokedSessionKey(sessionID), "revoked", s.tokenTTL); err != nil {
+		return fmt.Errorf("redis blacklist error: %w", err)
+	}
+	return nil
+}
 
-	if err := s.repoAuth.SaveRefreshToken(ctx, refreshToken); err != nil {
-		return "", err
+func (s *AuthService) ListSessions(ctx context.Context, userID int64) ([]dom.Session, error) {
+	if userID <= 0 {
+		return nil, customerrors.ErrInvalidInput
 	}
+	return s.repoAuth.ListSessions(ctx, userID)
+}
 
-	return accessTokenString, nil
+func (s *AuthService) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	if userID <= 0 || sessionID == "" {
+		return customerrors.ErrInvalidInput
+	}
+	if err := s.repoAuth.RevokeSession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+	return s.blacklistSession(ctx, sessionID)
+}
+
+func (s *AuthService) RevokeAllSessions(ctx context.Context, userID int64) error {
+	if userID <= 0 {
+		return customerrors.ErrInvalidInput
+	}
+	sessionIDs, err := s.repoAuth.RevokeAllSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, sessionID := range sessionIDs {
+		if err := s.blacklistSession(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *AuthService) LogoutUser(ctx context.Context, accessToken string) (bool, error) {
@@ -104,22 +396,40 @@ func (s *AuthService) LogoutUser(ctx context.Context, accessToken string) (bool,
 		}
 	}
 
-	if err := s.repoAuth.DeleteRefreshToken(ctx, claims.UserID); err != nil {
+	// Tokens issued before sessions existed carry no session ID, so the
+	// only option for them is to drop every refresh token of the user.
+	if claims.SessionID == "" {
+		if err := s.repoAuth.DeleteRefreshToken(ctx, claims.UserID); err != nil {
+			return false, fmt.Errorf("db error: %w", err)
+		}
+		return true, nil
+	}
+
+	if err := s.repoAuth.RevokeSession(ctx, claims.UserID, claims.SessionID); err != nil && !errors.Is(err, customerrors.ErrNotFound) {
 		return false, fmt.Errorf("db error: %w", err)
 	}
+	if err := s.blacklistSession(ctx, claims.SessionID); err != nil {
+		return false, err
+	}
+	s.audit.Record(ctx, dom.AuditEvent{
+		ActorID:    claims.UserID,
+		Action:     dom.AuditLogout,
+		TargetType: "session",
+		TargetID:   claims.SessionID,
+	})
 
 	return true, nil
 }
 
-func (s *AuthService) RegisterUser(ctx context.Context, username, email, password string) (dom.User, error) {
+func (s *AuthService) RegisterUser(ctx context.Context, username, email, plainPassword string) (dom.User, error) {
 
-	if !utils.ValidatePassword(password) {
-		return dom.User{}, customerrors.ErrInvalidInput
+	if err := s.policy.Check(plainPassword, username, email); err != nil {
+		return dom.User{}, err
 	}
 
-	passwordHash, err := utils.HashPassword(password)
+	passwordHash, err := s.hasher.Hash(plainPassword)
 	if err != nil {
-		return dom.User{}, err
+		return dom.User{}, fmt.Errorf("service: hash password: %w", err)
 	}
 
 	res, err := s.repoUser.RegisterUser(ctx, username, email, passwordHash)
@@ -127,6 +437,19 @@ func (s *AuthService) RegisterUser(ctx context.Context, username, email, passwor
 		return dom.User{}, err
 	}
 
+	s.audit.Record(ctx, dom.AuditEvent{
+		ActorID:    res.ID,
+		Action:     dom.AuditRegister,
+		TargetType: "user",
+		TargetID:   strconv.FormatInt(res.ID, 10),
+	})
+
+	// The account exists at this point; a failed mail only means the user
+	// has to ask for a new code.
+	if err := s.sendEmailCode(ctx, res); err != nil {
+		s.logger.Error("failed to send verification email", slog.Int64("user_id", res.ID), slog.String("error", err.Error()))
+	}
+
 	res = dom.User{
 		ID:       res.ID,
 		Username: res.Username,
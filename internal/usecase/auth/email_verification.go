@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+)
+
+const (
+	emailCodeTTL         = 24 * time.Hour
+	emailCodeDigits      = 6
+	maxEmailCodeAttempts = 5
+
+	// A resend starts a fresh code with fresh attempts, so resends are
+	// limited per user as well.
+	maxEmailCodeResends = 3
+	emailResendWindow   = time.Hour
+)
+
+func emailResendKey(userID int64) string {
+	return "email_code_resends:" + strconv.FormatInt(userID, 10)
+}
+
+// sendEmailCode mails a new verification code to the user. Only a signature
+// of the code is stored; it binds the code to the user and to the address it
+// was sent to.
+func (s *AuthService) sendEmailCode(ctx context.Context, user dom.User) error {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return fmt.Errorf("service: generate email code: %w", err)
+	}
+	code := fmt.Sprintf("%0*d", emailCodeDigits, n.Int64())
+
+	expiresAt := time.Now().Add(emailCodeTTL)
+	if err := s.repoAuth.SaveEmailCode(ctx, user.ID, s.signEmailCode(user, code), expiresAt); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nyour verification code is %s.\nIt expires in %s.\n",
+		user.Username, code, emailCodeTTL)
+	if err := s.mailer.Send(ctx, user.Email, "Confirm your email", body); err != nil {
+		return fmt.Errorf("service: send email code: %w", err)
+	}
+	return nil
+}
+
+func (s *AuthService) signEmailCode(user dom.User, code string) string {
+	mac := hmac.New(sha256.New, s.codeSecret)
+	fmt.Fprintf(mac, "%d:%s:%s", user.ID, strings.ToLower(user.Email), code)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyEmail confirms the address with a code sent by RegisterUser or
+// ResendVerificationEmail. Each code works once.
+func (s *AuthService) VerifyEmail(ctx context.Context, email, code string) error {
+	code = strings.TrimSpace(code)
+	if email == "" || len(code) != emailCodeDigits {
+		return customerrors.ErrInvalidEmailCode
+	}
+
+	user, err := s.repoUser.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrUserNotFound) {
+			return customerrors.ErrInvalidEmailCode
+		}
+		return err
+	}
+	if user.EmailVerified {
+		return customerrors.ErrEmailAlreadyVerified
+	}
+
+	return s.repoAuth.ConsumeEmailCode(ctx, user.ID, s.signEmailCode(user, code), maxEmailCodeAttempts)
+}
+
+// ResendVerificationEmail replaces the pending code of the user with a new one.
+// It fails with a RetryAfterError once the user asked too often.
+func (s *AuthService) ResendVerificationEmail(ctx context.Context, userID int64) error {
+	if userID <= 0 {
+		return customerrors.ErrInvalidInput
+	}
+	user, err := s.repoUser.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return customerrors.ErrEmailAlreadyVerified
+	}
+
+	n, err := s.cache.Incr(ctx, emailResendKey(userID), emailResendWindow)
+	if err != nil {
+		return fmt.Errorf("service: count email resends: %w", err)
+	}
+	if n > maxEmailCodeResends {
+		ttl, err := s.cache.TTL(ctx, emailResendKey(userID))
+		if err != nil {
+			return fmt.Errorf("service: check email resends: %w", err)
+		}
+		return &customerrors.RetryAfterError{Err: customerrors.ErrTooManyEmailCodes, RetryAfter: ttl}
+	}
+	return s.sendEmailCode(ctx, user)
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+	"main/pkg/metrics"
+)
+
+// LockoutPolicy limits failed password logins per username and per client
+// IP. Once a counter reaches its threshold every further failure locks the
+// key for BaseDelay, doubled per failure and capped at MaxDelay. A zero
+// threshold disables that counter.
+type LockoutPolicy struct {
+	MaxUserFailures int
+	MaxIPFailures   int
+	// Window is how long a counter is kept after the last failure.
+	Window    time.Duration
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+func (p LockoutPolicy) enabled() bool {
+	return p.MaxUserFailures > 0 || p.MaxIPFailures > 0
+}
+
+// delay returns the lockout for the n-th failure, or zero below threshold.
+func (p LockoutPolicy) delay(n int64, threshold int) time.Duration {
+	if threshold <= 0 || n < int64(threshold) {
+		return 0
+	}
+	d := p.BaseDelay
+	for i := int64(threshold); i < n && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxDelay)
+}
+
+type lockoutKey struct {
+	name      string
+	threshold int
+}
+
+func (s *AuthService) lockoutKeys(username, ip string) []lockoutKey {
+	keys := []lockoutKey{{name: "user:" + username, threshold: s.lockout.MaxUserFailures}}
+	if ip != "" {
+		keys = append(keys, lockoutKey{name: "ip:" + ip, threshold: s.lockout.MaxIPFailures})
+	}
+	return keys
+}
+
+func loginFailuresKey(name string) string {
+	return "login_failures:" + name
+}
+
+func loginLockKey(name string) string {
+	return "login_lock:" + name
+}
+
+// checkLockout rejects the attempt while the username or the IP is locked.
+func (s *AuthService) checkLockout(ctx context.Context, username, ip string) error {
+	if !s.lockout.enabled() {
+		return nil
+	}
+	for _, key := range s.lockoutKeys(username, ip) {
+		if key.threshold <= 0 {
+			continue
+		}
+		ttl, err := s.cache.TTL(ctx, loginLockKey(key.name))
+		if err != nil {
+			return fmt.Errorf("service: check lockout: %w", err)
+		}
+		if ttl > 0 {
+			metrics.LoginFailuresTotal.WithLabelValues("locked").Inc()
+			s.recordFailedLogin(ctx, username, ip, "locked")
+			return &customerrors.RetryAfterError{Err: customerrors.ErrTooManyLoginAttempts, RetryAfter: ttl}
+		}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt and locks the keys whose
+// counter reached the threshold.
+func (s *AuthService) recordLoginFailure(ctx context.Context, username, ip string) error {
+	metrics.LoginFailuresTotal.WithLabelValues("invalid_credentials").Inc()
+	s.recordFailedLogin(ctx, username, ip, "invalid_credentials")
+	if !s.lockout.enabled() {
+		return nil
+	}
+	for _, key := range s.lockoutKeys(username, ip) {
+		if key.threshold <= 0 {
+			continue
+		}
+		n, err := s.cache.Incr(ctx, loginFailuresKey(key.name), s.lockout.Window)
+		if err != nil {
+			return fmt.Errorf("service: count login failure: %w", err)
+		}
+		if d := s.lockout.delay(n, key.threshold); d > 0 {
+			if err := s.cache.Set(ctx, loginLockKey(key.name), n, d); err != nil {
+				return fmt.Errorf("service: lock login: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// resetLoginFailures forgets the failures of a username after a successful
+// login. The IP counter is left to expire, since many users may share an IP
+// and one of them knowing their password says nothing about the others.
+func (s *AuthService) resetLoginFailures(ctx context.Context, username string) error {
+	if s.lockout.MaxUserFailures <= 0 {
+		return nil
+	}
+	if err := s.cache.Delete(ctx, loginFailuresKey("user:"+username)); err != nil {
+		return fmt.Errorf("service: reset login failures: %w", err)
+	}
+	return nil
+}
+
+func (s *AuthService) recordFailedLogin(ctx context.Context, username, ip, reason string) {
+	s.audit.Record(ctx, dom.AuditEvent{
+		Action:     dom.AuditLoginFailed,
+		TargetType: "username",
+		TargetID:   username,
+		IP:         ip,
+		Metadata:   map[string]interface{}{"reason": reason},
+	})
+}
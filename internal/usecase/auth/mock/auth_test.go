@@ -3,11 +3,18 @@ package mock_test
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	dom "main/internal/domain/entity"
 	"main/internal/usecase/auth"
 	mock "main/internal/usecase/auth/mock"
 	"main/pkg/customerrors"
 	"main/pkg/jwt"
+	"main/pkg/oidc"
+	"main/pkg/password"
+	"main/pkg/totp"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -42,7 +49,7 @@ func TestLoginUser(t *testing.T) {
 		expectError        error
 	}{
 		{
-			name:     "Success login",
+			name:     "Success login upgrades bcrypt hash",
 			ctx:      context.Background(),
 			username: "user",
 			password: password,
@@ -51,14 +58,20 @@ func TestLoginUser(t *testing.T) {
 					repo.EXPECT().
 						GetCredentialsByUsername(gomock.Any(), "user").
 						Return(validUser, nil),
+					repo.EXPECT().
+						UpdatePassword(gomock.Any(), userID, gomock.Any()).
+						Return(nil),
 					token.EXPECT().
 						NewRefreshToken().
 						Return(refreshToken, nil),
+					repo.EXPECT().
+						GetUserRole(gomock.Any(), userID).
+						Return(jwt.RoleUser, nil),
 					token.EXPECT().
-						NewAccessToken(userID, defaultTTL).
+						NewAccessToken(userID, gomock.Any(), jwt.RoleUser, defaultTTL).
 						Return(accessToken, nil),
 					repo.EXPECT().
-						SaveRefreshToken(gomock.Any(), gomock.Any()).
+						CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).
 						Return(nil),
 				)
 			},
@@ -116,6 +129,7 @@ func TestLoginUser(t *testing.T) {
 			setupMock: func(repo *mock.MockAuthRepository, token *mock.MockTokenManager) {
 				gomock.InOrder(
 					repo.EXPECT().GetCredentialsByUsername(gomock.Any(), "user").Return(validUser, nil),
+					repo.EXPECT().UpdatePassword(gomock.Any(), userID, gomock.Any()).Return(nil),
 					token.EXPECT().NewRefreshToken().Return("", customerrors.ErrTokenCreationFailed),
 				)
 			},
@@ -137,8 +151,8 @@ func TestLoginUser(t *testing.T) {
 				tt.setupMock(repo, token)
 			}
 
-			s := auth.NewAuthService(repo, token, nil, defaultTTL)
-			gotAccess, gotRefresh, err := s.LoginUser(tt.ctx, tt.username, tt.password)
+			s := auth.NewAuthService(repo, nil, token, nil, nil, nil, nil, auth.Options{TokenTTL: defaultTTL})
+			gotAccess, gotRefresh, _, err := s.LoginUser(tt.ctx, tt.username, tt.password, dom.Session{DeviceName: "laptop"})
 
 			if tt.expectError != nil {
 				assert.Error(t, err)
@@ -186,6 +200,29 @@ func TestLogoutUser(t *testing.T) {
 			},
 			expectError: nil,
 		},
+		{
+			name:    "Success logout of a session",
+			ctx:     context.Background(),
+			access:  accessToken,
+			refresh: refreshToken,
+			setupMock: func(repo *mock.MockAuthRepository, token *mock.MockTokenManager, blacklist *mock.MockTokenBlacklister) {
+				gomock.InOrder(
+					token.EXPECT().
+						Parse(accessToken).
+						Return(&jwt.TokenClaims{UserID: userID, SessionID: "sid", Exp: expTime.Unix()}, nil),
+					blacklist.EXPECT().
+						Set(gomock.Any(), accessToken, "blacklisted", gomock.Any()).
+						Return(nil),
+					repo.EXPECT().
+						RevokeSession(gomock.Any(), userID, "sid").
+						Return(nil),
+					blacklist.EXPECT().
+						Set(gomock.Any(), jwt.RevokedSessionKey("sid"), "revoked", gomock.Any()).
+						Return(nil),
+				)
+			},
+			expectError: nil,
+		},
 		{
 			name:    "Invalid access token",
 			ctx:     context.Background(),
@@ -250,8 +287,8 @@ func TestLogoutUser(t *testing.T) {
 				tt.setupMock(repo, token, blacklist)
 			}
 
-			s := auth.NewAuthService(repo, token, blacklist, 15*time.Minute)
-			err := s.LogoutUser(tt.ctx, tt.access, tt.refresh)
+			s := auth.NewAuthService(repo, nil, token, blacklist, nil, nil, nil, auth.Options{TokenTTL: 15 * time.Minute})
+			_, err := s.LogoutUser(tt.ctx, tt.access)
 
 			if tt.expectError != nil {
 				assert.Error(t, err)
@@ -262,3 +299,965 @@ func TestLogoutUser(t *testing.T) {
 		})
 	}
 }
+
+func TestRefreshTokens(t *testing.T) {
+	oldRefresh := "old_refresh"
+	newRefresh := "new_refresh"
+	newAccess := "new_access"
+	familyID := "8f6a2c9e-3d1b-4e5f-9a7c-1b2d3e4f5a6b"
+	userID := int64(7)
+	defaultTTL := 15 * time.Minute
+	usedAt := time.Now().Add(-time.Minute)
+
+	stored := dom.RefreshToken{
+		ID:        42,
+		UserID:    userID,
+		Token:     oldRefresh,
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name               string
+		setupMock          func(*mock.MockAuthRepository, *mock.MockTokenManager, *mock.MockTokenBlacklister)
+		expectAccessToken  string
+		expectRefreshToken string
+		expectError        error
+	}{
+		{
+			name: "Success rotation",
+			setupMock: func(repo *mock.MockAuthRepository, token *mock.MockTokenManager, blacklist *mock.MockTokenBlacklister) {
+				gomock.InOrder(
+					repo.EXPECT().GetRefreshTokenByValue(gomock.Any(), oldRefresh).Return(stored, nil),
+					token.EXPECT().NewRefreshToken().Return(newRefresh, nil),
+					repo.EXPECT().GetUserRole(gomock.Any(), userID).Return(jwt.RoleModerator, nil),
+					token.EXPECT().NewAccessToken(userID, familyID, jwt.RoleModerator, defaultTTL).Return(newAccess, nil),
+					repo.EXPECT().
+						RotateRefreshToken(gomock.Any(), stored.ID, gomock.Any()).
+						DoAndReturn(func(_ context.Context, _ int64, next dom.RefreshToken) error {
+							assert.Equal(t, familyID, next.FamilyID)
+							assert.Equal(t, newRefresh, next.Token)
+							return nil
+						}),
+				)
+			},
+			expectAccessToken:  newAccess,
+			expectRefreshToken: newRefresh,
+		},
+		{
+			name: "Unknown token",
+			setupMock: func(repo *mock.MockAuthRepository, token *mock.MockTokenManager, blacklist *mock.MockTokenBlacklister) {
+				repo.EXPECT().GetRefreshTokenByValue(gomock.Any(), oldRefresh).
+					Return(dom.RefreshToken{}, customerrors.ErrRefreshTokenNotFound)
+			},
+			expectError: customerrors.ErrRefreshTokenNotFound,
+		},
+		{
+			name: "Expired token",
+			setupMock: func(repo *mock.MockAuthRepository, token *mock.MockTokenManager, blacklist *mock.MockTokenBlacklister) {
+				expired := stored
+				expired.ExpiresAt = time.Now().Add(-time.Hour)
+				repo.EXPECT().GetRefreshTokenByValue(gomock.Any(), oldRefresh).Return(expired, nil)
+			},
+			expectError: customerrors.ErrRefreshTokenExpired,
+		},
+		{
+			name: "Reused token revokes family",
+			setupMock: func(repo *mock.MockAuthRepository, token *mock.MockTokenManager, blacklist *mock.MockTokenBlacklister) {
+				used := stored
+				used.UsedAt = &usedAt
+				gomock.InOrder(
+					repo.EXPECT().GetRefreshTokenByValue(gomock.Any(), oldRefresh).Return(used, nil),
+					repo.EXPECT().RevokeTokenFamily(gomock.Any(), familyID).Return(nil),
+					blacklist.EXPECT().Set(gomock.Any(), jwt.RevokedSessionKey(familyID), "revoked", defaultTTL).Return(nil),
+				)
+			},
+			expectError: customerrors.ErrRefreshTokenReused,
+		},
+		{
+			name: "Concurrent reuse revokes family",
+			setupMock: func(repo *mock.MockAuthRepository, token *mock.MockTokenManager, blacklist *mock.MockTokenBlacklister) {
+				gomock.InOrder(
+					repo.EXPECT().GetRefreshTokenByValue(gomock.Any(), oldRefresh).Return(stored, nil),
+					token.EXPECT().NewRefreshToken().Return(newRefresh, nil),
+					repo.EXPECT().GetUserRole(gomock.Any(), userID).Return(jwt.RoleModerator, nil),
+					token.EXPECT().NewAccessToken(userID, familyID, jwt.RoleModerator, defaultTTL).Return(newAccess, nil),
+					repo.EXPECT().RotateRefreshToken(gomock.Any(), stored.ID, gomock.Any()).
+						Return(customerrors.ErrRefreshTokenReused),
+					repo.EXPECT().RevokeTokenFamily(gomock.Any(), familyID).Return(nil),
+					blacklist.EXPECT().Set(gomock.Any(), jwt.RevokedSessionKey(familyID), "revoked", defaultTTL).Return(nil),
+				)
+			},
+			expectError: customerrors.ErrRefreshTokenReused,
+		},
+		{
+			name: "Revoked token",
+			setupMock: func(repo *mock.MockAuthRepository, token *mock.MockTokenManager, blacklist *mock.MockTokenBlacklister) {
+				revoked := stored
+				revoked.RevokedAt = &usedAt
+				repo.EXPECT().GetRefreshTokenByValue(gomock.Any(), oldRefresh).Return(revoked, nil)
+			},
+			expectError: customerrors.ErrRefreshTokenRevoked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockAuthRepository(ctrl)
+			token := mock.NewMockTokenManager(ctrl)
+			blacklist := mock.NewMockTokenBlacklister(ctrl)
+
+			if tt.setupMock != nil {
+				tt.setupMock(repo, token, blacklist)
+			}
+
+			s := auth.NewAuthService(repo, nil, token, blacklist, nil, nil, nil, auth.Options{TokenTTL: defaultTTL})
+			gotAccess, gotRefresh, err := s.RefreshTokens(context.Background(), oldRefresh)
+
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectAccessToken, gotAccess)
+				assert.Equal(t, tt.expectRefreshToken, gotRefresh.Token)
+			}
+		})
+	}
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	userID := int64(5)
+	defaultTTL := 15 * time.Minute
+
+	tests := []struct {
+		name        string
+		userID      int64
+		setupMock   func(*mock.MockAuthRepository, *mock.MockTokenBlacklister)
+		expectError error
+	}{
+		{
+			name:   "Success",
+			userID: userID,
+			setupMock: func(repo *mock.MockAuthRepository, blacklist *mock.MockTokenBlacklister) {
+				gomock.InOrder(
+					repo.EXPECT().RevokeAllSessions(gomock.Any(), userID).Return([]string{"a", "b"}, nil),
+					blacklist.EXPECT().Set(gomock.Any(), jwt.RevokedSessionKey("a"), "revoked", defaultTTL).Return(nil),
+					blacklist.EXPECT().Set(gomock.Any(), jwt.RevokedSessionKey("b"), "revoked", defaultTTL).Return(nil),
+				)
+			},
+		},
+		{
+			name:        "Invalid user",
+			userID:      0,
+			setupMock:   func(repo *mock.MockAuthRepository, blacklist *mock.MockTokenBlacklister) {},
+			expectError: customerrors.ErrInvalidInput,
+		},
+		{
+			name:   "DB error",
+			userID: userID,
+			setupMock: func(repo *mock.MockAuthRepository, blacklist *mock.MockTokenBlacklister) {
+				repo.EXPECT().RevokeAllSessions(gomock.Any(), userID).Return(nil, customerrors.ErrDatabase)
+			},
+			expectError: customerrors.ErrDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockAuthRepository(ctrl)
+			blacklist := mock.NewMockTokenBlacklister(ctrl)
+			tt.setupMock(repo, blacklist)
+
+			s := auth.NewAuthService(repo, nil, nil, blacklist, nil, nil, nil, auth.Options{TokenTTL: defaultTTL})
+			err := s.RevokeAllSessions(context.Background(), tt.userID)
+
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	password := "securepassword"
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	defaultTTL := 15 * time.Minute
+	userID := int64(3)
+	secret, _ := totp.GenerateSecret()
+	state := dom.TOTP{UserID: userID, Username: "user", Secret: secret, Enabled: true}
+
+	ctrl := gomock.NewController(t)
+	repo := mock.NewMockAuthRepository(ctrl)
+	token := mock.NewMockTokenManager(ctrl)
+	cache := mock.NewMockCache(ctrl)
+	s := auth.NewAuthService(repo, nil, token, nil, cache, nil, nil, auth.Options{TokenTTL: defaultTTL})
+
+	var stored []byte
+	cache.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, value interface{}, ttl time.Duration) error {
+			assert.LessOrEqual(t, ttl, 5*time.Minute)
+			stored = value.([]byte)
+			return nil
+		}).AnyTimes()
+	cache.EXPECT().Get(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, string) ([]byte, error) { return stored, nil }).AnyTimes()
+	attempts := map[string]int64{}
+	cache.EXPECT().Incr(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, key string, ttl time.Duration) (int64, error) {
+			assert.LessOrEqual(t, ttl, 5*time.Minute)
+			attempts[key]++
+			return attempts[key], nil
+		}).AnyTimes()
+
+	repo.EXPECT().GetCredentialsByUsername(gomock.Any(), "user").
+		Return(dom.User{ID: userID, Username: "user", Password: string(hashedPassword), TOTPEnabled: true}, nil)
+	repo.EXPECT().UpdatePassword(gomock.Any(), userID, gomock.Any()).Return(nil)
+
+	access, _, challenge, err := s.LoginUser(context.Background(), "user", password, dom.Session{DeviceName: "phone"})
+	assert.NoError(t, err)
+	assert.Empty(t, access)
+	assert.NotEmpty(t, challenge)
+
+	t.Run("Wrong code", func(t *testing.T) {
+		repo.EXPECT().GetTOTP(gomock.Any(), userID).Return(state, nil)
+
+		_, _, err := s.VerifyTOTP(context.Background(), challenge, "000000")
+		assert.ErrorIs(t, err, customerrors.ErrInvalidTOTPCode)
+		assert.Equal(t, int64(1), attempts["login_challenge_attempts:"+challenge])
+	})
+
+	t.Run("Valid code", func(t *testing.T) {
+		code, _ := totp.Code(secret, totp.Step(time.Now()))
+		gomock.InOrder(
+			repo.EXPECT().GetTOTP(gomock.Any(), userID).Return(state, nil),
+			repo.EXPECT().UseTOTPStep(gomock.Any(), userID, gomock.Any()).Return(nil),
+			cache.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil),
+			token.EXPECT().NewRefreshToken().Return("refresh", nil),
+			repo.EXPECT().GetUserRole(gomock.Any(), userID).Return(jwt.RoleUser, nil),
+			token.EXPECT().NewAccessToken(userID, gomock.Any(), jwt.RoleUser, defaultTTL).Return("access", nil),
+			repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, session dom.Session, _ dom.RefreshToken) error {
+					assert.Equal(t, "phone", session.DeviceName)
+					return nil
+				}),
+		)
+
+		gotAccess, gotRefresh, err := s.VerifyTOTP(context.Background(), challenge, code)
+		assert.NoError(t, err)
+		assert.Equal(t, "access", gotAccess)
+		assert.Equal(t, "refresh", gotRefresh.Token)
+	})
+
+	t.Run("Recovery code", func(t *testing.T) {
+		gomock.InOrder(
+			repo.EXPECT().GetTOTP(gomock.Any(), userID).Return(state, nil),
+			repo.EXPECT().UseRecoveryCode(gomock.Any(), userID, gomock.Any()).Return(customerrors.ErrInvalidTOTPCode),
+		)
+
+		_, _, err := s.VerifyTOTP(context.Background(), challenge, "abcde-12345")
+		assert.ErrorIs(t, err, customerrors.ErrInvalidTOTPCode)
+	})
+
+	t.Run("Too many codes", func(t *testing.T) {
+		attempts["login_challenge_attempts:"+challenge] = 4
+		gomock.InOrder(
+			repo.EXPECT().GetTOTP(gomock.Any(), userID).Return(state, nil),
+			cache.EXPECT().Delete(gomock.Any(), "login_challenge:"+challenge).Return(nil),
+		)
+		_, _, err := s.VerifyTOTP(context.Background(), challenge, "000000")
+		assert.ErrorIs(t, err, customerrors.ErrChallengeNotFound, "the fifth wrong code drops the challenge")
+
+		// A request that read the challenge before it was dropped gets no
+		// extra try.
+		cache.EXPECT().Delete(gomock.Any(), "login_challenge:"+challenge).Return(nil)
+		_, _, err = s.VerifyTOTP(context.Background(), challenge, "000000")
+		assert.ErrorIs(t, err, customerrors.ErrChallengeNotFound)
+	})
+
+	t.Run("Expired challenge", func(t *testing.T) {
+		stored = nil
+
+		_, _, err := s.VerifyTOTP(context.Background(), challenge, "123456")
+		assert.ErrorIs(t, err, customerrors.ErrChallengeNotFound)
+	})
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	userID := int64(4)
+	secret, _ := totp.GenerateSecret()
+	pending := dom.TOTP{UserID: userID, Username: "user", Secret: secret}
+
+	tests := []struct {
+		name        string
+		code        func() string
+		setupMock   func(*mock.MockAuthRepository)
+		expectError error
+	}{
+		{
+			name: "Success",
+			code: func() string {
+				code, _ := totp.Code(secret, totp.Step(time.Now()))
+				return code
+			},
+			setupMock: func(repo *mock.MockAuthRepository) {
+				gomock.InOrder(
+					repo.EXPECT().GetTOTP(gomock.Any(), userID).Return(pending, nil),
+					repo.EXPECT().UseTOTPStep(gomock.Any(), userID, gomock.Any()).Return(nil),
+					repo.EXPECT().EnableTOTP(gomock.Any(), userID, gomock.Len(10)).Return(nil),
+				)
+			},
+		},
+		{
+			name: "Wrong code",
+			code: func() string { return "000000" },
+			setupMock: func(repo *mock.MockAuthRepository) {
+				repo.EXPECT().GetTOTP(gomock.Any(), userID).Return(pending, nil)
+			},
+			expectError: customerrors.ErrInvalidTOTPCode,
+		},
+		{
+			name: "Not enrolled",
+			code: func() string { return "000000" },
+			setupMock: func(repo *mock.MockAuthRepository) {
+				repo.EXPECT().GetTOTP(gomock.Any(), userID).Return(dom.TOTP{UserID: userID}, nil)
+			},
+			expectError: customerrors.ErrTOTPNotEnrolled,
+		},
+		{
+			name: "Already enabled",
+			code: func() string { return "000000" },
+			setupMock: func(repo *mock.MockAuthRepository) {
+				enabled := pending
+				enabled.Enabled = true
+				repo.EXPECT().GetTOTP(gomock.Any(), userID).Return(enabled, nil)
+			},
+			expectError: customerrors.ErrTOTPAlreadyEnabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockAuthRepository(ctrl)
+			tt.setupMock(repo)
+
+			s := auth.NewAuthService(repo, nil, nil, nil, nil, nil, nil, auth.Options{TokenTTL: 15 * time.Minute})
+			codes, err := s.ConfirmTOTP(context.Background(), userID, tt.code())
+
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, codes, 10)
+			}
+		})
+	}
+}
+
+func TestEmailVerification(t *testing.T) {
+	user := dom.User{ID: 8, Username: "user", Email: "user@example.com"}
+
+	ctrl := gomock.NewController(t)
+	repo := mock.NewMockAuthRepository(ctrl)
+	users := mock.NewMockUserRepository(ctrl)
+	mailer := mock.NewMockMailer(ctrl)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := auth.NewAuthService(repo, users, nil, nil, nil, mailer, logger, auth.Options{CodeSecret: "secret"})
+
+	var savedHash, mailedCode string
+	gomock.InOrder(
+		users.EXPECT().RegisterUser(gomock.Any(), "user", "user@example.com", gomock.Any()).Return(user, nil),
+		repo.EXPECT().SaveEmailCode(gomock.Any(), user.ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ int64, hash string, _ time.Time) error {
+				savedHash = hash
+				return nil
+			}),
+		mailer.EXPECT().Send(gomock.Any(), "user@example.com", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _, body string) error {
+				mailedCode = regexp.MustCompile(`\d{6}`).FindString(body)
+				return nil
+			}),
+	)
+
+	_, err := s.RegisterUser(context.Background(), "user", "user@example.com", "CorrectHorse9")
+	assert.NoError(t, err)
+	assert.Len(t, mailedCode, 6)
+	assert.NotContains(t, savedHash, mailedCode)
+
+	t.Run("Valid code", func(t *testing.T) {
+		gomock.InOrder(
+			users.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Return(user, nil),
+			repo.EXPECT().ConsumeEmailCode(gomock.Any(), user.ID, savedHash, gomock.Any()).Return(nil),
+		)
+		assert.NoError(t, s.VerifyEmail(context.Background(), user.Email, mailedCode))
+	})
+
+	t.Run("Unknown email", func(t *testing.T) {
+		users.EXPECT().GetUserByEmail(gomock.Any(), "ghost@example.com").Return(dom.User{}, customerrors.ErrUserNotFound)
+		err := s.VerifyEmail(context.Background(), "ghost@example.com", mailedCode)
+		assert.ErrorIs(t, err, customerrors.ErrInvalidEmailCode)
+	})
+
+	t.Run("Already verified", func(t *testing.T) {
+		verified := user
+		verified.EmailVerified = true
+		users.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(verified, nil)
+		err := s.ResendVerificationEmail(context.Background(), user.ID)
+		assert.ErrorIs(t, err, customerrors.ErrEmailAlreadyVerified)
+	})
+}
+
+func TestResendVerificationEmailLimit(t *testing.T) {
+	user := dom.User{ID: 8, Username: "user", Email: "user@example.com"}
+
+	ctrl := gomock.NewController(t)
+	repo := mock.NewMockAuthRepository(ctrl)
+	users := mock.NewMockUserRepository(ctrl)
+	mailer := mock.NewMockMailer(ctrl)
+	cache := mock.NewMockCache(ctrl)
+	s := auth.NewAuthService(repo, users, nil, nil, cache, mailer, nil, auth.Options{CodeSecret: "secret"})
+
+	users.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(user, nil).Times(4)
+	var sent int64
+	cache.EXPECT().Incr(gomock.Any(), "email_code_resends:8", time.Hour).
+		DoAndReturn(func(context.Context, string, time.Duration) (int64, error) {
+			sent++
+			return sent, nil
+		}).Times(4)
+	repo.EXPECT().SaveEmailCode(gomock.Any(), user.ID, gomock.Any(), gomock.Any()).Return(nil).Times(3)
+	mailer.EXPECT().Send(gomock.Any(), user.Email, gomock.Any(), gomock.Any()).Return(nil).Times(3)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, s.ResendVerificationEmail(context.Background(), user.ID))
+	}
+
+	cache.EXPECT().TTL(gomock.Any(), "email_code_resends:8").Return(40*time.Minute, nil)
+	err := s.ResendVerificationEmail(context.Background(), user.ID)
+	assert.ErrorIs(t, err, customerrors.ErrTooManyEmailCodes)
+	var retry *customerrors.RetryAfterError
+	if assert.ErrorAs(t, err, &retry) {
+		assert.Equal(t, 40*time.Minute, retry.RetryAfter)
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	user := dom.User{ID: 9, Username: "user", Email: "user@example.com"}
+	defaultTTL := 15 * time.Minute
+
+	ctrl := gomock.NewController(t)
+	repo := mock.NewMockAuthRepository(ctrl)
+	users := mock.NewMockUserRepository(ctrl)
+	mailer := mock.NewMockMailer(ctrl)
+	blacklist := mock.NewMockTokenBlacklister(ctrl)
+	cache := mock.NewMockCache(ctrl)
+	s := auth.NewAuthService(repo, users, nil, blacklist, cache, mailer, nil, auth.Options{TokenTTL: defaultTTL})
+	ip := "203.0.113.7"
+
+	var savedHash, mailedToken string
+	gomock.InOrder(
+		cache.EXPECT().Incr(gomock.Any(), "password_resets:email:user@example.com", time.Hour).Return(int64(1), nil),
+		cache.EXPECT().Incr(gomock.Any(), "password_resets:ip:"+ip, time.Hour).Return(int64(1), nil),
+		users.EXPECT().GetUserByEmail(gomock.Any(), user.Email).Return(user, nil),
+		repo.EXPECT().SavePasswordResetToken(gomock.Any(), user.ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ int64, hash string, _ time.Time) error {
+				savedHash = hash
+				return nil
+			}),
+		mailer.EXPECT().Send(gomock.Any(), user.Email, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _, body string) error {
+				mailedToken = regexp.MustCompile(`[0-9a-f]{64}`).FindString(body)
+				return nil
+			}),
+	)
+	assert.NoError(t, s.RequestPasswordReset(context.Background(), user.Email, ip))
+	assert.NotEmpty(t, mailedToken)
+	assert.NotEqual(t, mailedToken, savedHash)
+
+	t.Run("Unknown email is not reported", func(t *testing.T) {
+		cache.EXPECT().Incr(gomock.Any(), "password_resets:email:ghost@example.com", time.Hour).Return(int64(1), nil)
+		users.EXPECT().GetUserByEmail(gomock.Any(), "ghost@example.com").Return(dom.User{}, customerrors.ErrUserNotFound)
+		assert.NoError(t, s.RequestPasswordReset(context.Background(), "ghost@example.com", ""))
+	})
+
+	t.Run("Too many mails to the address are not reported", func(t *testing.T) {
+		cache.EXPECT().Incr(gomock.Any(), "password_resets:email:user@example.com", time.Hour).Return(int64(4), nil)
+		cache.EXPECT().Incr(gomock.Any(), "password_resets:ip:"+ip, time.Hour).Return(int64(2), nil)
+		assert.NoError(t, s.RequestPasswordReset(context.Background(), "User@example.com", ip))
+	})
+
+	t.Run("Too many mails from the IP are not reported", func(t *testing.T) {
+		cache.EXPECT().Incr(gomock.Any(), "password_resets:email:other@example.com", time.Hour).Return(int64(1), nil)
+		cache.EXPECT().Incr(gomock.Any(), "password_resets:ip:"+ip, time.Hour).Return(int64(11), nil)
+		assert.NoError(t, s.RequestPasswordReset(context.Background(), "other@example.com", ip))
+	})
+
+	t.Run("Counter error", func(t *testing.T) {
+		cache.EXPECT().Incr(gomock.Any(), "password_resets:email:user@example.com", time.Hour).Return(int64(0), fmt.Errorf("redis down"))
+		assert.Error(t, s.RequestPasswordReset(context.Background(), user.Email, ip))
+	})
+
+	t.Run("Confirm revokes sessions", func(t *testing.T) {
+		gomock.InOrder(
+			repo.EXPECT().GetPasswordResetUserID(gomock.Any(), savedHash).Return(user.ID, nil),
+			users.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(user, nil),
+			repo.EXPECT().ResetPassword(gomock.Any(), savedHash, gomock.Any()).Return(user.ID, nil),
+			repo.EXPECT().RevokeAllSessions(gomock.Any(), user.ID).Return([]string{"sid"}, nil),
+			blacklist.EXPECT().Set(gomock.Any(), jwt.RevokedSessionKey("sid"), "revoked", defaultTTL).Return(nil),
+		)
+		assert.NoError(t, s.ConfirmPasswordReset(context.Background(), mailedToken, "NewPassword1"))
+	})
+
+	t.Run("Weak password", func(t *testing.T) {
+		repo.EXPECT().GetPasswordResetUserID(gomock.Any(), savedHash).Return(user.ID, nil)
+		users.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(user, nil)
+		err := s.ConfirmPasswordReset(context.Background(), mailedToken, "weak")
+		assert.ErrorIs(t, err, customerrors.ErrInvalidInput)
+	})
+
+	t.Run("Password repeats the email", func(t *testing.T) {
+		repo.EXPECT().GetPasswordResetUserID(gomock.Any(), savedHash).Return(user.ID, nil)
+		users.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(user, nil)
+		err := s.ConfirmPasswordReset(context.Background(), mailedToken, "User@example.com")
+		assert.ErrorIs(t, err, customerrors.ErrWeakPassword)
+	})
+
+	t.Run("Used token", func(t *testing.T) {
+		repo.EXPECT().GetPasswordResetUserID(gomock.Any(), savedHash).Return(int64(0), customerrors.ErrInvalidResetToken)
+		err := s.ConfirmPasswordReset(context.Background(), mailedToken, "NewPassword1")
+		assert.ErrorIs(t, err, customerrors.ErrInvalidResetToken)
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	userID := int64(10)
+	user := dom.User{ID: userID, Username: "Someone1", Email: "someone@example.com"}
+	defaultTTL := 15 * time.Minute
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("OldPassword1"), bcrypt.MinCost)
+
+	tests := []struct {
+		name        string
+		oldPassword string
+		newPassword string
+		setupMock   func(*mock.MockAuthRepository, *mock.MockUserRepository, *mock.MockTokenBlacklister)
+		expectError error
+	}{
+		{
+			name:        "Success",
+			oldPassword: "OldPassword1",
+			newPassword: "NewPassword1",
+			setupMock: func(repo *mock.MockAuthRepository, users *mock.MockUserRepository, blacklist *mock.MockTokenBlacklister) {
+				gomock.InOrder(
+					repo.EXPECT().GetPasswordHash(gomock.Any(), userID).Return(string(hashedPassword), nil),
+					users.EXPECT().GetUserByID(gomock.Any(), userID).Return(user, nil),
+					repo.EXPECT().UpdatePassword(gomock.Any(), userID, gomock.Any()).
+						DoAndReturn(func(_ context.Context, _ int64, hash string) error {
+							assert.True(t, strings.HasPrefix(hash, "$argon2id$"))
+							ok, rehash, err := password.NewHasher(password.DefaultParams).Verify("NewPassword1", hash)
+							assert.NoError(t, err)
+							assert.True(t, ok)
+							assert.False(t, rehash)
+							return nil
+						}),
+					repo.EXPECT().RevokeAllSessions(gomock.Any(), userID).Return([]string{"a", "b"}, nil),
+					blacklist.EXPECT().Set(gomock.Any(), jwt.RevokedSessionKey("a"), "revoked", defaultTTL).Return(nil),
+					blacklist.EXPECT().Set(gomock.Any(), jwt.RevokedSessionKey("b"), "revoked", defaultTTL).Return(nil),
+				)
+			},
+		},
+		{
+			name:        "Wrong old password",
+			oldPassword: "Guess1234",
+			newPassword: "NewPassword1",
+			setupMock: func(repo *mock.MockAuthRepository, users *mock.MockUserRepository, blacklist *mock.MockTokenBlacklister) {
+				repo.EXPECT().GetPasswordHash(gomock.Any(), userID).Return(string(hashedPassword), nil)
+			},
+			expectError: customerrors.ErrWrongPassword,
+		},
+		{
+			name:        "Weak new password",
+			oldPassword: "OldPassword1",
+			newPassword: "short",
+			setupMock: func(repo *mock.MockAuthRepository, users *mock.MockUserRepository, blacklist *mock.MockTokenBlacklister) {
+				repo.EXPECT().GetPasswordHash(gomock.Any(), userID).Return(string(hashedPassword), nil)
+				users.EXPECT().GetUserByID(gomock.Any(), userID).Return(user, nil)
+			},
+			expectError: customerrors.ErrInvalidInput,
+		},
+		{
+			name:        "New password repeats the username",
+			oldPassword: "OldPassword1",
+			newPassword: "Someone1",
+			setupMock: func(repo *mock.MockAuthRepository, users *mock.MockUserRepository, blacklist *mock.MockTokenBlacklister) {
+				repo.EXPECT().GetPasswordHash(gomock.Any(), userID).Return(string(hashedPassword), nil)
+				users.EXPECT().GetUserByID(gomock.Any(), userID).Return(user, nil)
+			},
+			expectError: customerrors.ErrWeakPassword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockAuthRepository(ctrl)
+			users := mock.NewMockUserRepository(ctrl)
+			blacklist := mock.NewMockTokenBlacklister(ctrl)
+			tt.setupMock(repo, users, blacklist)
+
+			s := auth.NewAuthService(repo, users, nil, blacklist, nil, nil, nil, auth.Options{TokenTTL: defaultTTL})
+			err := s.ChangePassword(context.Background(), userID, tt.oldPassword, tt.newPassword)
+
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("Password1"), bcrypt.MinCost)
+	validUser := dom.User{ID: 11, Username: "user", Password: string(hashedPassword)}
+	device := dom.Session{IP: "10.0.0.1"}
+	policy := auth.LockoutPolicy{
+		MaxUserFailures: 5,
+		MaxIPFailures:   20,
+		Window:          15 * time.Minute,
+		BaseDelay:       30 * time.Second,
+		MaxDelay:        time.Hour,
+	}
+
+	tests := []struct {
+		name        string
+		password    string
+		setupMock   func(*mock.MockAuthRepository, *mock.MockCache)
+		expectRetry time.Duration
+		expectError error
+	}{
+		{
+			name:     "Locked username",
+			password: "Password1",
+			setupMock: func(repo *mock.MockAuthRepository, cache *mock.MockCache) {
+				cache.EXPECT().TTL(gomock.Any(), "login_lock:user:user").Return(10*time.Second, nil)
+			},
+			expectRetry: 10 * time.Second,
+			expectError: customerrors.ErrTooManyLoginAttempts,
+		},
+		{
+			name:     "Locked IP",
+			password: "Password1",
+			setupMock: func(repo *mock.MockAuthRepository, cache *mock.MockCache) {
+				gomock.InOrder(
+					cache.EXPECT().TTL(gomock.Any(), "login_lock:user:user").Return(time.Duration(0), nil),
+					cache.EXPECT().TTL(gomock.Any(), "login_lock:ip:10.0.0.1").Return(time.Minute, nil),
+				)
+			},
+			expectRetry: time.Minute,
+			expectError: customerrors.ErrTooManyLoginAttempts,
+		},
+		{
+			name:     "Failure below threshold",
+			password: "wrong",
+			setupMock: func(repo *mock.MockAuthRepository, cache *mock.MockCache) {
+				cache.EXPECT().TTL(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).Times(2)
+				repo.EXPECT().GetCredentialsByUsername(gomock.Any(), "user").Return(validUser, nil)
+				cache.EXPECT().Incr(gomock.Any(), "login_failures:user:user", 15*time.Minute).Return(int64(4), nil)
+				cache.EXPECT().Incr(gomock.Any(), "login_failures:ip:10.0.0.1", 15*time.Minute).Return(int64(4), nil)
+			},
+			expectError: customerrors.ErrInvalidInput,
+		},
+		{
+			name:     "Failures past threshold back off exponentially",
+			password: "wrong",
+			setupMock: func(repo *mock.MockAuthRepository, cache *mock.MockCache) {
+				cache.EXPECT().TTL(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).Times(2)
+				repo.EXPECT().GetCredentialsByUsername(gomock.Any(), "user").Return(validUser, nil)
+				cache.EXPECT().Incr(gomock.Any(), "login_failures:user:user", gomock.Any()).Return(int64(7), nil)
+				cache.EXPECT().Set(gomock.Any(), "login_lock:user:user", int64(7), 2*time.Minute).Return(nil)
+				cache.EXPECT().Incr(gomock.Any(), "login_failures:ip:10.0.0.1", gomock.Any()).Return(int64(30), nil)
+				cache.EXPECT().Set(gomock.Any(), "login_lock:ip:10.0.0.1", int64(30), time.Hour).Return(nil)
+			},
+			expectError: customerrors.ErrInvalidInput,
+		},
+		{
+			name:     "Unknown username counts as failure",
+			password: "Password1",
+			setupMock: func(repo *mock.MockAuthRepository, cache *mock.MockCache) {
+				cache.EXPECT().TTL(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).Times(2)
+				repo.EXPECT().GetCredentialsByUsername(gomock.Any(), "user").Return(dom.User{}, customerrors.ErrUserNotFound)
+				cache.EXPECT().Incr(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(2)
+			},
+			expectError: customerrors.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock.NewMockAuthRepository(ctrl)
+			cache := mock.NewMockCache(ctrl)
+			tt.setupMock(repo, cache)
+
+			s := auth.NewAuthService(repo, nil, nil, nil, cache, nil, nil, auth.Options{Lockout: policy})
+			_, _, _, err := s.LoginUser(context.Background(), "user", tt.password, device)
+
+			assert.ErrorIs(t, err, tt.expectError)
+			if tt.expectRetry > 0 {
+				var retry *customerrors.RetryAfterError
+				assert.ErrorAs(t, err, &retry)
+				assert.Equal(t, tt.expectRetry, retry.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestOIDCLogin(t *testing.T) {
+	defaultTTL := 15 * time.Minute
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	ctrl := gomock.NewController(t)
+	repo := mock.NewMockAuthRepository(ctrl)
+	users := mock.NewMockUserRepository(ctrl)
+	token := mock.NewMockTokenManager(ctrl)
+	cache := mock.NewMockCache(ctrl)
+	provider := mock.NewMockOIDCProvider(ctrl)
+	s := auth.NewAuthService(repo, users, token, nil, cache, nil, logger, auth.Options{
+		TokenTTL:      defaultTTL,
+		OIDCProviders: map[string]auth.OIDCProvider{"idp": provider},
+	})
+
+	// start runs StartOIDCLogin and returns the state and the stored data the
+	// provider would send back.
+	start := func(t *testing.T) (state string, stored []byte) {
+		var verifier, nonce string
+		provider.EXPECT().AuthCodeURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, st, n, v string) (string, error) {
+				state, nonce, verifier = st, n, v
+				return "https://idp/authorize?state=" + st, nil
+			})
+		cache.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), 10*time.Minute).
+			DoAndReturn(func(_ context.Context, _ string, value interface{}, _ time.Duration) error {
+				stored = value.([]byte)
+				return nil
+			})
+
+		authURL, err := s.StartOIDCLogin(context.Background(), "idp", dom.Session{DeviceName: "laptop"})
+		assert.NoError(t, err)
+		assert.Contains(t, authURL, state)
+		assert.NotEqual(t, nonce, verifier)
+		return state, stored
+	}
+
+	expectSession := func(userID int64) {
+		repo.EXPECT().GetTOTP(gomock.Any(), userID).Return(dom.TOTP{UserID: userID}, nil)
+		token.EXPECT().NewRefreshToken().Return("refresh", nil)
+		repo.EXPECT().GetUserRole(gomock.Any(), userID).Return(jwt.RoleAdmin, nil)
+		token.EXPECT().NewAccessToken(userID, gomock.Any(), jwt.RoleAdmin, defaultTTL).Return("access", nil)
+		repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, session dom.Session, _ dom.RefreshToken) error {
+				assert.Equal(t, "laptop", session.DeviceName)
+				return nil
+			})
+	}
+
+	redeem := func(state string, stored []byte, identity oidc.Identity) {
+		cache.EXPECT().Get(gomock.Any(), "oidc_state:"+state).Return(stored, nil)
+		cache.EXPECT().Delete(gomock.Any(), "oidc_state:"+state).Return(nil)
+		provider.EXPECT().Authenticate(gomock.Any(), "code", gomock.Any(), gomock.Any()).Return(identity, nil)
+	}
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		_, err := s.StartOIDCLogin(context.Background(), "other", dom.Session{})
+		assert.ErrorIs(t, err, customerrors.ErrUnknownOIDCProvider)
+	})
+
+	t.Run("Linked identity", func(t *testing.T) {
+		state, stored := start(t)
+		redeem(state, stored, oidc.Identity{Subject: "sub-1", Email: "a@example.com", EmailVerified: true})
+		repo.EXPECT().GetUserByIdentity(gomock.Any(), "idp", "sub-1").Return(dom.User{ID: 1}, nil)
+		expectSession(1)
+
+		access, refresh, challenge, err := s.CompleteOIDCLogin(context.Background(), "idp", state, "code")
+		assert.NoError(t, err)
+		assert.Equal(t, "access", access)
+		assert.Equal(t, "refresh", refresh.Token)
+		assert.Empty(t, challenge)
+	})
+
+	// expectChallenge expects a login challenge instead of a session.
+	expectChallenge := func(userID int64) {
+		repo.EXPECT().GetTOTP(gomock.Any(), userID).Return(dom.TOTP{UserID: userID, Enabled: true}, nil)
+		cache.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, key string, _ interface{}, _ time.Duration) error {
+				assert.True(t, strings.HasPrefix(key, "login_challenge:"))
+				return nil
+			})
+	}
+
+	t.Run("Linked identity with two-factor authentication", func(t *testing.T) {
+		state, stored := start(t)
+		redeem(state, stored, oidc.Identity{Subject: "sub-5", Email: "d@example.com", EmailVerified: true})
+		repo.EXPECT().GetUserByIdentity(gomock.Any(), "idp", "sub-5").Return(dom.User{ID: 5}, nil)
+		expectChallenge(5)
+
+		access, refresh, challenge, err := s.CompleteOIDCLogin(context.Background(), "idp", state, "code")
+		assert.NoError(t, err)
+		assert.Empty(t, access)
+		assert.Empty(t, refresh.Token)
+		assert.NotEmpty(t, challenge)
+	})
+
+	t.Run("Account linked by email keeps two-factor authentication", func(t *testing.T) {
+		state, stored := start(t)
+		redeem(state, stored, oidc.Identity{Subject: "sub-6", Email: "e@example.com", EmailVerified: true})
+		existing := dom.User{ID: 6, Email: "e@example.com", EmailVerified: true}
+		repo.EXPECT().GetUserByIdentity(gomock.Any(), "idp", "sub-6").Return(dom.User{}, customerrors.ErrUserNotFound)
+		users.EXPECT().GetUserByEmail(gomock.Any(), "e@example.com").Return(existing, nil)
+		repo.EXPECT().LinkIdentity(gomock.Any(), int64(6), gomock.Any()).Return(nil)
+		expectChallenge(6)
+
+		access, _, challenge, err := s.CompleteOIDCLogin(context.Background(), "idp", state, "code")
+		assert.NoError(t, err)
+		assert.Empty(t, access)
+		assert.NotEmpty(t, challenge)
+	})
+
+	t.Run("New user", func(t *testing.T) {
+		state, stored := start(t)
+		identity := oidc.Identity{Subject: "sub-2", Email: "New.User@example.com", PreferredUsername: "new user!"}
+		redeem(state, stored, identity)
+		repo.EXPECT().GetUserByIdentity(gomock.Any(), "idp", "sub-2").Return(dom.User{}, customerrors.ErrUserNotFound)
+		users.EXPECT().GetUserByEmail(gomock.Any(), "new.user@example.com").Return(dom.User{}, customerrors.ErrUserNotFound)
+		repo.EXPECT().CreateUserWithIdentity(gomock.Any(), "newuser", dom.ExternalIdentity{
+			Provider: "idp",
+			Subject:  "sub-2",
+			Email:    "new.user@example.com",
+		}).Return(dom.User{ID: 2}, nil)
+		expectSession(2)
+
+		_, _, _, err := s.CompleteOIDCLogin(context.Background(), "idp", state, "code")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Existing account with verified email is linked", func(t *testing.T) {
+		state, stored := start(t)
+		redeem(state, stored, oidc.Identity{Subject: "sub-3", Email: "b@example.com", EmailVerified: true})
+		existing := dom.User{ID: 3, Email: "b@example.com", EmailVerified: true}
+		repo.EXPECT().GetUserByIdentity(gomock.Any(), "idp", "sub-3").Return(dom.User{}, customerrors.ErrUserNotFound)
+		users.EXPECT().GetUserByEmail(gomock.Any(), "b@example.com").Return(existing, nil)
+		repo.EXPECT().LinkIdentity(gomock.Any(), int64(3), gomock.Any()).Return(nil)
+		expectSession(3)
+
+		_, _, _, err := s.CompleteOIDCLogin(context.Background(), "idp", state, "code")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unverified email is not linked", func(t *testing.T) {
+		state, stored := start(t)
+		redeem(state, stored, oidc.Identity{Subject: "sub-4", Email: "c@example.com"})
+		repo.EXPECT().GetUserByIdentity(gomock.Any(), "idp", "sub-4").Return(dom.User{}, customerrors.ErrUserNotFound)
+		users.EXPECT().GetUserByEmail(gomock.Any(), "c@example.com").Return(dom.User{ID: 4, EmailVerified: true}, nil)
+
+		_, _, _, err := s.CompleteOIDCLogin(context.Background(), "idp", state, "code")
+		assert.ErrorIs(t, err, customerrors.ErrIdentityNotLinkable)
+	})
+
+	t.Run("Unknown state", func(t *testing.T) {
+		cache.EXPECT().Get(gomock.Any(), "oidc_state:forged").Return(nil, nil)
+		_, _, _, err := s.CompleteOIDCLogin(context.Background(), "idp", "forged", "code")
+		assert.ErrorIs(t, err, customerrors.ErrInvalidOIDCState)
+	})
+
+	t.Run("Provider rejects the code", func(t *testing.T) {
+		state, stored := start(t)
+		cache.EXPECT().Get(gomock.Any(), "oidc_state:"+state).Return(stored, nil)
+		cache.EXPECT().Delete(gomock.Any(), "oidc_state:"+state).Return(nil)
+		provider.EXPECT().Authenticate(gomock.Any(), "code", gomock.Any(), gomock.Any()).
+			Return(oidc.Identity{}, oidc.ErrInvalidIDToken)
+
+		_, _, _, err := s.CompleteOIDCLogin(context.Background(), "idp", state, "code")
+		assert.ErrorIs(t, err, customerrors.ErrOIDCLogin)
+	})
+}
+
+func TestPasswordRehash(t *testing.T) {
+	defaultTTL := 15 * time.Minute
+	fast := password.Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+	oldHash, _ := password.NewHasher(fast).Hash("OldParams1")
+	currentHash, _ := password.NewHasher(password.DefaultParams).Hash("Current1")
+
+	tests := []struct {
+		name        string
+		hash        string
+		password    string
+		expectStore bool
+	}{
+		{name: "Current parameters are kept", hash: currentHash, password: "Current1"},
+		{name: "Old parameters are upgraded", hash: oldHash, password: "OldParams1", expectStore: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := mock.NewMockAuthRepository(ctrl)
+			token := mock.NewMockTokenManager(ctrl)
+			s := auth.NewAuthService(repo, nil, token, nil, nil, nil, nil, auth.Options{TokenTTL: defaultTTL})
+
+			repo.EXPECT().GetCredentialsByUsername(gomock.Any(), "user").
+				Return(dom.User{ID: 1, Username: "user", Password: tt.hash}, nil)
+			if tt.expectStore {
+				repo.EXPECT().UpdatePassword(gomock.Any(), int64(1), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ int64, hash string) error {
+						assert.Contains(t, hash, "m=65536,t=3,p=2")
+						return nil
+					})
+			}
+			token.EXPECT().NewRefreshToken().Return("refresh", nil)
+			repo.EXPECT().GetUserRole(gomock.Any(), int64(1)).Return(jwt.RoleUser, nil)
+			token.EXPECT().NewAccessToken(int64(1), gomock.Any(), jwt.RoleUser, defaultTTL).Return("access", nil)
+			repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+			access, _, _, err := s.LoginUser(context.Background(), "user", tt.password, dom.Session{})
+			assert.NoError(t, err)
+			assert.Equal(t, "access", access)
+		})
+	}
+
+	t.Run("Failed upgrade does not block login", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := mock.NewMockAuthRepository(ctrl)
+		token := mock.NewMockTokenManager(ctrl)
+		s := auth.NewAuthService(repo, nil, token, nil, nil, nil, nil, auth.Options{TokenTTL: defaultTTL})
+
+		repo.EXPECT().GetCredentialsByUsername(gomock.Any(), "user").
+			Return(dom.User{ID: 1, Username: "user", Password: oldHash}, nil)
+		repo.EXPECT().UpdatePassword(gomock.Any(), int64(1), gomock.Any()).Return(customerrors.ErrDatabase)
+		token.EXPECT().NewRefreshToken().Return("refresh", nil)
+		repo.EXPECT().GetUserRole(gomock.Any(), int64(1)).Return(jwt.RoleUser, nil)
+		token.EXPECT().NewAccessToken(int64(1), gomock.Any(), jwt.RoleUser, defaultTTL).Return("access", nil)
+		repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+		_, _, _, err := s.LoginUser(context.Background(), "user", "OldParams1", dom.Session{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Breached password is rejected", func(t *testing.T) {
+		s := auth.NewAuthService(nil, nil, nil, nil, nil, nil, nil, auth.Options{TokenTTL: defaultTTL})
+		_, err := s.RegisterUser(context.Background(), "user", "user@example.com", "Password123")
+		assert.ErrorIs(t, err, customerrors.ErrWeakPassword)
+		assert.ErrorIs(t, err, customerrors.ErrInvalidInput)
+	})
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+	"main/pkg/oidc"
+)
+
+const oidcStateTTL = 10 * time.Minute
+
+// oidcState is kept between StartOIDCLogin and CompleteOIDCLogin. The PKCE
+// verifier never leaves the server.
+type oidcState struct {
+	Provider string      `json:"provider"`
+	Nonce    string      `json:"nonce"`
+	Verifier string      `json:"verifier"`
+	Device   dom.Session `json:"device"`
+}
+
+func oidcStateKey(state string) string {
+	return "oidc_state:" + state
+}
+
+// StartOIDCLogin returns the URL of the provider's sign-in page. The state
+// in it has to come back to CompleteOIDCLogin within oidcStateTTL.
+func (s *AuthService) StartOIDCLogin(ctx context.Context, provider string, device dom.Session) (string, error) {
+	p, ok := s.oidcProviders[provider]
+	if !ok {
+		return "", customerrors.ErrUnknownOIDCProvider
+	}
+
+	stored := oidcState{Provider: provider, Device: device}
+	var state string
+	for _, v := range []*string{&state, &stored.Nonce, &stored.Verifier} {
+		r, err := oidc.RandomString()
+		if err != nil {
+			return "", fmt.Errorf("service: generate oidc state: %w", err)
+		}
+		*v = r
+	}
+
+	authURL, err := p.AuthCodeURL(ctx, state, stored.Nonce, stored.Verifier)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return "", fmt.Errorf("service: marshal oidc state: %w", err)
+	}
+	if err := s.cache.Set(ctx, oidcStateKey(state), data, oidcStateTTL); err != nil {
+		return "", fmt.Errorf("service: save oidc state: %w", err)
+	}
+	return authURL, nil
+}
+
+// CompleteOIDCLogin exchanges the code the provider redirected back with and
+// opens a session for the linked user. A first login links the identity to
+// the account with the same email when both sides have verified it, and
+// creates a new account otherwise. Like LoginUser, it returns only a
+// challenge token for VerifyTOTP when the user has two-factor authentication.
+func (s *AuthService) CompleteOIDCLogin(ctx context.Context, provider, state, code string) (accessToken string, refreshToken dom.RefreshToken, challengeToken string, err error) {
+	if state == "" || code == "" {
+		return "", dom.RefreshToken{}, "", customerrors.ErrInvalidOIDCState
+	}
+	key := oidcStateKey(state)
+	data, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return "", dom.RefreshToken{}, "", fmt.Errorf("service: get oidc state: %w", err)
+	}
+	if data == nil {
+		return "", dom.RefreshToken{}, "", customerrors.ErrInvalidOIDCState
+	}
+	// The state is single use, whatever the outcome.
+	if err := s.cache.Delete(ctx, key); err != nil {
+		return "", dom.RefreshToken{}, "", fmt.Errorf("service: delete oidc state: %w", err)
+	}
+	var stored oidcState
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return "", dom.RefreshToken{}, "", fmt.Errorf("service: unmarshal oidc state: %w", err)
+	}
+	if stored.Provider != provider {
+		return "", dom.RefreshToken{}, "", customerrors.ErrInvalidOIDCState
+	}
+	p, ok := s.oidcProviders[provider]
+	if !ok {
+		return "", dom.RefreshToken{}, "", customerrors.ErrUnknownOIDCProvider
+	}
+
+	id, err := p.Authenticate(ctx, code, stored.Verifier, stored.Nonce)
+	if err != nil {
+		s.logger.Warn("oidc sign-in failed", "provider", provider, "error", err)
+		return "", dom.RefreshToken{}, "", customerrors.ErrOIDCLogin
+	}
+	identity := dom.ExternalIdentity{
+		Provider:      provider,
+		Subject:       id.Subject,
+		Email:         strings.ToLower(id.Email),
+		EmailVerified: id.EmailVerified,
+	}
+
+	user, err := s.repoAuth.GetUserByIdentity(ctx, provider, identity.Subject)
+	if errors.Is(err, customerrors.ErrUserNotFound) {
+		user, err = s.linkIdentity(ctx, identity, id.PreferredUsername)
+	}
+	if err != nil {
+		return "", dom.RefreshToken{}, "", err
+	}
+
+	// The provider vouches for the identity only; a second factor enabled
+	// here still applies, also to accounts linked by email just now.
+	totpState, err := s.repoAuth.GetTOTP(ctx, user.ID)
+	if err != nil {
+		return "", dom.RefreshToken{}, "", err
+	}
+	if totpState.Enabled {
+		challengeToken, err := s.newLoginChallenge(ctx, user.ID, stored.Device)
+		if err != nil {
+			return "", dom.RefreshToken{}, "", err
+		}
+		return "", dom.RefreshToken{}, challengeToken, nil
+	}
+
+	accessToken, refreshToken, err = s.openSession(ctx, user.ID, stored.Device, "oidc:"+provider)
+	if err != nil {
+		return "", dom.RefreshToken{}, "", err
+	}
+	return accessToken, refreshToken, "", nil
+}
+
+func (s *AuthService) linkIdentity(ctx context.Context, identity dom.ExternalIdentity, preferredUsername string) (dom.User, error) {
+	if identity.Email == "" {
+		return dom.User{}, customerrors.ErrOIDCLogin
+	}
+
+	user, err := s.repoUser.GetUserByEmail(ctx, identity.Email)
+	switch {
+	case err == nil:
+		// Linking on an unverified address on either side would hand the
+		// account to whoever registered that address first.
+		if !identity.EmailVerified || !user.EmailVerified {
+			return dom.User{}, customerrors.ErrIdentityNotLinkable
+		}
+		if err := s.repoAuth.LinkIdentity(ctx, user.ID, identity); err != nil {
+			return dom.User{}, err
+		}
+		s.logger.Info("linked external identity", "provider", identity.Provider, "user_id", user.ID)
+		return user, nil
+	case errors.Is(err, customerrors.ErrUserNotFound):
+		user, err = s.repoAuth.CreateUserWithIdentity(ctx, usernameFromIdentity(preferredUsername, identity.Email), identity)
+		if err != nil {
+			return dom.User{}, err
+		}
+		s.logger.Info("created user from external identity", "provider", identity.Provider, "user_id", user.ID)
+		return user, nil
+	default:
+		return dom.User{}, err
+	}
+}
+
+// usernameFromIdentity derives a username from the provider's suggestion or
+// from the local part of the email, keeping letters, digits, '_', '-' and '.'.
+func usernameFromIdentity(preferred, email string) string {
+	name := preferred
+	if name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+	var b strings.Builder
+	for _, r := range name {
+		if b.Len() == 20 {
+			break
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() < 3 {
+		return "user"
+	}
+	return b.String()
+}
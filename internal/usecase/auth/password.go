@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+)
+
+const (
+	passwordResetTTL = time.Hour
+
+	// Reset mails are limited per address, like verification resends, and
+	// per client IP so one caller cannot mail many addresses.
+	maxPasswordResetsPerEmail = 3
+	maxPasswordResetsPerIP    = 10
+	passwordResetWindow       = time.Hour
+)
+
+func passwordResetsKey(name string) string {
+	return "password_resets:" + name
+}
+
+// RequestPasswordReset mails a single-use reset token to the owner of email.
+// Unknown addresses and requests over the limit are not reported, so the
+// endpoint cannot be used to find out who has an account.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email, ip string) error {
+	if email == "" {
+		return customerrors.ErrInvalidInput
+	}
+	limited, err := s.passwordResetLimited(ctx, email, ip)
+	if err != nil {
+		return err
+	}
+	if limited {
+		s.logger.Warn("password reset limit reached", slog.String("ip", ip))
+		return nil
+	}
+	user, err := s.repoUser.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := randomHex(32)
+	if err != nil {
+		return fmt.Errorf("service: generate reset token: %w", err)
+	}
+	if err := s.repoAuth.SavePasswordResetToken(ctx, user.ID, hashResetToken(token), time.Now().Add(passwordResetTTL)); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nuse this token to choose a new password: %s\nIt expires in %s. "+
+		"If you did not ask for a reset, ignore this email.\n", user.Username, token, passwordResetTTL)
+	if err := s.mailer.Send(ctx, user.Email, "Reset your password", body); err != nil {
+		return fmt.Errorf("service: send reset token: %w", err)
+	}
+	return nil
+}
+
+// passwordResetLimited counts the request against the address and the IP
+// and reports whether either is over its limit.
+func (s *AuthService) passwordResetLimited(ctx context.Context, email, ip string) (bool, error) {
+	n, err := s.cache.Incr(ctx, passwordResetsKey("email:"+strings.ToLower(email)), passwordResetWindow)
+	if err != nil {
+		return false, fmt.Errorf("service: count password resets: %w", err)
+	}
+	limited := n > maxPasswordResetsPerEmail
+	if ip == "" {
+		return limited, nil
+	}
+
+	n, err = s.cache.Incr(ctx, passwordResetsKey("ip:"+ip), passwordResetWindow)
+	if err != nil {
+		return false, fmt.Errorf("service: count password resets: %w", err)
+	}
+	return limited || n > maxPasswordResetsPerIP, nil
+}
+
+// ConfirmPasswordReset sets a new password with a token from
+// RequestPasswordReset and signs the user out everywhere.
+func (s *AuthService) ConfirmPasswordReset(ctx context.Context, token, newPassword string) error {
+	if token == "" {
+		return customerrors.ErrInvalidResetToken
+	}
+	tokenHash := hashResetToken(token)
+	userID, err := s.repoAuth.GetPasswordResetUserID(ctx, tokenHash)
+	if err != nil {
+		return err
+	}
+	user, err := s.repoUser.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.policy.Check(newPassword, user.Username, user.Email); err != nil {
+		return err
+	}
+	passwordHash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return fmt.Errorf("service: hash password: %w", err)
+	}
+
+	userID, err = s.repoAuth.ResetPassword(ctx, tokenHash, passwordHash)
+	if err != nil {
+		return err
+	}
+	s.audit.Record(ctx, dom.AuditEvent{
+		ActorID:    userID,
+		Action:     dom.AuditPasswordReset,
+		TargetType: "user",
+		TargetID:   strconv.FormatInt(userID, 10),
+	})
+	return s.RevokeAllSessions(ctx, userID)
+}
+
+// ChangePassword replaces the password of a signed in user and signs them out
+// everywhere, including the session that made the request.
+func (s *AuthService) ChangePassword(ctx context.Context, userID int64, oldPassword, newPassword string) error {
+	if userID <= 0 {
+		return customerrors.ErrInvalidInput
+	}
+	current, err := s.repoAuth.GetPasswordHash(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if ok, _, _ := s.hasher.Verify(oldPassword, current); !ok {
+		return customerrors.ErrWrongPassword
+	}
+	user, err := s.repoUser.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.policy.Check(newPassword, user.Username, user.Email); err != nil {
+		return err
+	}
+
+	passwordHash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return fmt.Errorf("service: hash password: %w", err)
+	}
+	if err := s.repoAuth.UpdatePassword(ctx, userID, passwordHash); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, dom.AuditEvent{
+		ActorID:    userID,
+		Action:     dom.AuditPasswordChanged,
+		TargetType: "user",
+		TargetID:   strconv.FormatInt(userID, 10),
+	})
+	return s.RevokeAllSessions(ctx, userID)
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// rehashPassword replaces the stored hash of a password that has just been
+// verified. Failing is not fatal: the old hash still works and the upgrade
+// is retried at the next login.
+func (s *AuthService) rehashPassword(ctx context.Context, userID int64, plainPassword string) {
+	passwordHash, err := s.hasher.Hash(plainPassword)
+	if err == nil {
+		err = s.repoAuth.UpdatePassword(ctx, userID, passwordHash)
+	}
+	if err != nil {
+		s.logger.Warn("failed to upgrade password hash", slog.Int64("user_id", userID), slog.String("error", err.Error()))
+		return
+	}
+	s.logger.Info("password hash upgraded", slog.Int64("user_id", userID))
+}
@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+	"main/pkg/totp"
+)
+
+const (
+	challengeTTL         = 5 * time.Minute
+	maxChallengeAttempts = 5
+	recoveryCodeCount    = 10
+)
+
+// loginChallenge is stored while a user with two-factor authentication is
+// between the password and the code step.
+type loginChallenge struct {
+	UserID    int64       `json:"user_id"`
+	Device    dom.Session `json:"device"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+func loginChallengeKey(token string) string {
+	return "login_challenge:" + token
+}
+
+// loginChallengeAttemptsKey counts the codes tried against a challenge. It
+// expires with the challenge.
+func loginChallengeAttemptsKey(token string) string {
+	return "login_challenge_attempts:" + token
+}
+
+func (s *AuthService) newLoginChallenge(ctx context.Context, userID int64, device dom.Session) (string, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return "", fmt.Errorf("service: generate challenge: %w", err)
+	}
+	challenge := loginChallenge{
+		UserID:    userID,
+		Device:    device,
+		ExpiresAt: time.Now().Add(challengeTTL),
+	}
+	if err := s.saveLoginChallenge(ctx, token, challenge); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *AuthService) saveLoginChallenge(ctx context.Context, token string, challenge loginChallenge) error {
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		return fmt.Errorf("service: marshal challenge: %w", err)
+	}
+	if err := s.cache.Set(ctx, loginChallengeKey(token), data, time.Until(challenge.ExpiresAt)); err != nil {
+		return fmt.Errorf("service: save challenge: %w", err)
+	}
+	return nil
+}
+
+// VerifyTOTP completes a two-factor login. code is either the current code of
+// the authenticator app or one of the recovery codes. A challenge is dropped
+// after it succeeded or after too many wrong codes. Every try is counted
+// before the code is checked, so concurrent requests cannot get more tries.
+func (s *AuthService) VerifyTOTP(ctx context.Context, challengeToken, code string) (accessToken string, refreshToken dom.RefreshToken, err error) {
+	key := loginChallengeKey(challengeToken)
+	data, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return "", dom.RefreshToken{}, fmt.Errorf("service: get challenge: %w", err)
+	}
+	if data == nil {
+		return "", dom.RefreshToken{}, customerrors.ErrChallengeNotFound
+	}
+	var challenge loginChallenge
+	if err := json.Unmarshal(data, &challenge); err != nil {
+		return "", dom.RefreshToken{}, fmt.Errorf("service: unmarshal challenge: %w", err)
+	}
+
+	attempts, err := s.cache.Incr(ctx, loginChallengeAttemptsKey(challengeToken), time.Until(challenge.ExpiresAt))
+	if err != nil {
+		return "", dom.RefreshToken{}, fmt.Errorf("service: count challenge attempts: %w", err)
+	}
+	if attempts > maxChallengeAttempts {
+		if err := s.cache.Delete(ctx, key); err != nil {
+			return "", dom.RefreshToken{}, fmt.Errorf("service: delete challenge: %w", err)
+		}
+		return "", dom.RefreshToken{}, customerrors.ErrChallengeNotFound
+	}
+
+	state, err := s.repoAuth.GetTOTP(ctx, challenge.UserID)
+	if err != nil {
+		return "", dom.RefreshToken{}, err
+	}
+	if !state.Enabled {
+		return "", dom.RefreshToken{}, customerrors.ErrChallengeNotFound
+	}
+
+	if err := s.checkSecondFactor(ctx, state, code); err != nil {
+		if !errors.Is(err, customerrors.ErrInvalidTOTPCode) {
+			return "", dom.RefreshToken{}, err
+		}
+		s.audit.Record(ctx, dom.AuditEvent{
+			Action:     dom.AuditLoginFailed,
+			TargetType: "user",
+			TargetID:   strconv.FormatInt(challenge.UserID, 10),
+			IP:         challenge.Device.IP,
+			UserAgent:  challenge.Device.UserAgent,
+			Metadata:   map[string]interface{}{"reason": "invalid_totp_code"},
+		})
+		if attempts >= maxChallengeAttempts {
+			if err := s.cache.Delete(ctx, key); err != nil {
+				return "", dom.RefreshToken{}, fmt.Errorf("service: delete challenge: %w", err)
+			}
+			return "", dom.RefreshToken{}, customerrors.ErrChallengeNotFound
+		}
+		return "", dom.RefreshToken{}, customerrors.ErrInvalidTOTPCode
+	}
+
+	if err := s.cache.Delete(ctx, key); err != nil {
+		return "", dom.RefreshToken{}, fmt.Errorf("service: delete challenge: %w", err)
+	}
+	return s.openSession(ctx, challenge.UserID, challenge.Device, "totp")
+}
+
+// EnrollTOTP generates a new secret for the user. Two-factor authentication
+// stays off until ConfirmTOTP receives a code generated from it.
+func (s *AuthService) EnrollTOTP(ctx context.Context, userID int64) (dom.TOTPEnrollment, error) {
+	if userID <= 0 {
+		return dom.TOTPEnrollment{}, customerrors.ErrInvalidInput
+	}
+	state, err := s.repoAuth.GetTOTP(ctx, userID)
+	if err != nil {
+		return dom.TOTPEnrollment{}, err
+	}
+	if state.Enabled {
+		return dom.TOTPEnrollment{}, customerrors.ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return dom.TOTPEnrollment{}, err
+	}
+	if err := s.repoAuth.SetTOTPSecret(ctx, userID, secret); err != nil {
+		return dom.TOTPEnrollment{}, err
+	}
+
+	return dom.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.totpIssuer, state.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user proved the
+// authenticator app works. The returned recovery codes are shown only once.
+func (s *AuthService) ConfirmTOTP(ctx context.Context, userID int64, code string) ([]string, error) {
+	if userID <= 0 {
+		return nil, customerrors.ErrInvalidInput
+	}
+	state, err := s.repoAuth.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if state.Enabled {
+		return nil, customerrors.ErrTOTPAlreadyEnabled
+	}
+	if state.Secret == "" {
+		return nil, customerrors.ErrTOTPNotEnrolled
+	}
+
+	step, ok := totp.Validate(state.Secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return nil, customerrors.ErrInvalidTOTPCode
+	}
+	if err := s.repoAuth.UseTOTPStep(ctx, userID, step); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repoAuth.EnableTOTP(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTOTP turns two-factor authentication off. It requires a valid code,
+// so a stolen access token alone is not enough.
+func (s *AuthService) DisableTOTP(ctx context.Context, userID int64, code string) error {
+	if userID <= 0 {
+		return customerrors.ErrInvalidInput
+	}
+	state, err := s.repoAuth.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !state.Enabled {
+		return customerrors.ErrTOTPNotEnrolled
+	}
+	if err := s.checkSecondFactor(ctx, state, code); err != nil {
+		return err
+	}
+	return s.repoAuth.DisableTOTP(ctx, userID)
+}
+
+// checkSecondFactor accepts either a code of the authenticator app or an
+// unused recovery code. Each of them works only once.
+func (s *AuthService) checkSecondFactor(ctx context.Context, state dom.TOTP, code string) error {
+	code = strings.TrimSpace(code)
+	if step, ok := totp.Validate(state.Secret, code, time.Now()); ok {
+		return s.repoAuth.UseTOTPStep(ctx, state.UserID, step)
+	}
+	if len(code) == totp.Digits {
+		return customerrors.ErrInvalidTOTPCode
+	}
+	return s.repoAuth.UseRecoveryCode(ctx, state.UserID, hashRecoveryCode(code))
+}
+
+func generateRecoveryCodes() (codes, hashes []string, err error) {
+	for range recoveryCodeCount {
+		raw, err := randomHex(5)
+		if err != nil {
+			return nil, nil, fmt.Errorf("service: generate recovery code: %w", err)
+		}
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores case and separators so that codes typed by hand
+// still match.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
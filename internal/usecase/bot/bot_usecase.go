@@ -0,0 +1,189 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/apikey"
+	"main/pkg/customerrors"
+	"main/pkg/jwt"
+)
+
+const (
+	maxBotsPerOwner = 20
+	maxKeysPerBot   = 20
+	maxKeyNameLen   = 64
+)
+
+//go:generate mockgen -source=bot_usecase.go -destination=mock/bot_mock.go -package=mock
+type BotRepository interface {
+	CreateBot(ctx context.Context, ownerID int64, username string) (dom.Bot, error)
+	ListBots(ctx context.Context, ownerID int64) ([]dom.Bot, error)
+	GetBot(ctx context.Context, ownerID, botID int64) (dom.Bot, error)
+	IsBot(ctx context.Context, userID int64) (bool, error)
+	CreateAPIKey(ctx context.Context, key dom.APIKey, keyHash string) (dom.APIKey, error)
+	ListAPIKeys(ctx context.Context, botID int64) ([]dom.APIKey, error)
+	RevokeAPIKey(ctx context.Context, botID, keyID int64) error
+	UseAPIKey(ctx context.Context, keyHash string) (dom.APIKey, error)
+}
+
+type BotService struct {
+	repo   BotRepository
+	logger *slog.Logger
+}
+
+func NewBotService(repo BotRepository, logger *slog.Logger) *BotService {
+	return &BotService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// CreateBot creates a bot owned by ownerID. Bots cannot own bots.
+func (s *BotService) CreateBot(ctx context.Context, ownerID int64, username string) (dom.Bot, error) {
+	username = strings.TrimSpace(username)
+	if ownerID <= 0 || !validUsername(username) {
+		return dom.Bot{}, customerrors.ErrInvalidInput
+	}
+
+	isBot, err := s.repo.IsBot(ctx, ownerID)
+	if err != nil {
+		return dom.Bot{}, err
+	}
+	if isBot {
+		return dom.Bot{}, customerrors.ErrInsufficientScope
+	}
+
+	bots, err := s.repo.ListBots(ctx, ownerID)
+	if err != nil {
+		return dom.Bot{}, err
+	}
+	if len(bots) >= maxBotsPerOwner {
+		return dom.Bot{}, fmt.Errorf("%w: at most %d bots per user", customerrors.ErrInvalidInput, maxBotsPerOwner)
+	}
+
+	bot, err := s.repo.CreateBot(ctx, ownerID, username)
+	if err != nil {
+		return dom.Bot{}, err
+	}
+	s.logger.Info("bot created", "bot_id", bot.ID, "owner_id", ownerID)
+	return bot, nil
+}
+
+func (s *BotService) ListBots(ctx context.Context, ownerID int64) ([]dom.Bot, error) {
+	if ownerID <= 0 {
+		return nil, customerrors.ErrInvalidInput
+	}
+	return s.repo.ListBots(ctx, ownerID)
+}
+
+// CreateAPIKey issues a key for a bot of ownerID. The returned key is shown
+// only this once. A zero ttl gives a key that does not expire.
+func (s *BotService) CreateAPIKey(ctx context.Context, ownerID, botID int64, name string, scopes []string, ttl time.Duration) (string, dom.APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxKeyNameLen || len(scopes) == 0 || ttl < 0 {
+		return "", dom.APIKey{}, customerrors.ErrInvalidInput
+	}
+	for _, scope := range scopes {
+		if !jwt.ValidScope(scope) {
+			return "", dom.APIKey{}, fmt.Errorf("%w: unknown scope %q", customerrors.ErrInvalidInput, scope)
+		}
+	}
+
+	if _, err := s.repo.GetBot(ctx, ownerID, botID); err != nil {
+		return "", dom.APIKey{}, err
+	}
+	existing, err := s.repo.ListAPIKeys(ctx, botID)
+	if err != nil {
+		return "", dom.APIKey{}, err
+	}
+	live := 0
+	for _, k := range existing {
+		if k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(time.Now())) {
+			live++
+		}
+	}
+	if live >= maxKeysPerBot {
+		return "", dom.APIKey{}, fmt.Errorf("%w: at most %d keys per bot", customerrors.ErrInvalidInput, maxKeysPerBot)
+	}
+
+	key, hash, prefix, err := apikey.Generate()
+	if err != nil {
+		return "", dom.APIKey{}, fmt.Errorf("service: generate api key: %w", err)
+	}
+	record := dom.APIKey{
+		BotID:  botID,
+		Name:   name,
+		Prefix: prefix,
+		Scopes: scopes,
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		record.ExpiresAt = &expiresAt
+	}
+
+	record, err = s.repo.CreateAPIKey(ctx, record, hash)
+	if err != nil {
+		return "", dom.APIKey{}, err
+	}
+	s.logger.Info("api key created", "bot_id", botID, "key_id", record.ID, "owner_id", ownerID)
+	return key, record, nil
+}
+
+func (s *BotService) ListAPIKeys(ctx context.Context, ownerID, botID int64) ([]dom.APIKey, error) {
+	if _, err := s.repo.GetBot(ctx, ownerID, botID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAPIKeys(ctx, botID)
+}
+
+func (s *BotService) RevokeAPIKey(ctx context.Context, ownerID, botID, keyID int64) error {
+	if _, err := s.repo.GetBot(ctx, ownerID, botID); err != nil {
+		return err
+	}
+	if err := s.repo.RevokeAPIKey(ctx, botID, keyID); err != nil {
+		return err
+	}
+	s.logger.Info("api key revoked", "bot_id", botID, "key_id", keyID, "owner_id", ownerID)
+	return nil
+}
+
+// AuthenticateAPIKey returns the claims of the bot holding key. They carry
+// the key's scopes, which handlers check with TokenClaims.Allows.
+func (s *BotService) AuthenticateAPIKey(ctx context.Context, key string) (*jwt.TokenClaims, error) {
+	if !apikey.IsKey(key) {
+		return nil, customerrors.ErrInvalidAPIKey
+	}
+	record, err := s.repo.UseAPIKey(ctx, apikey.Hash(key))
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &jwt.TokenClaims{
+		UserID:   record.BotID,
+		APIKeyID: record.ID,
+		Scopes:   record.Scopes,
+	}
+	if record.ExpiresAt != nil {
+		claims.Exp = record.ExpiresAt.Unix()
+	}
+	return claims, nil
+}
+
+func validUsername(username string) bool {
+	if len(username) < 3 || len(username) > 20 {
+		return false
+	}
+	for _, r := range username {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
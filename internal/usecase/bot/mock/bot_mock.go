@@ -0,0 +1,161 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: bot_usecase.go
+//
+// Generated by this command:
+//
+//	mockgen -source=bot_usecase.go -destination=mock/bot_mock.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	entity "main/internal/domain/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBotRepository is a mock of BotRepository interface.
+type MockBotRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBotRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockBotRepositoryMockRecorder is the mock recorder for MockBotRepository.
+type MockBotRepositoryMockRecorder struct {
+	mock *MockBotRepository
+}
+
+// NewMockBotRepository creates a new mock instance.
+func NewMockBotRepository(ctrl *gomock.Controller) *MockBotRepository {
+	mock := &MockBotRepository{ctrl: ctrl}
+	mock.recorder = &MockBotRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBotRepository) EXPECT() *MockBotRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockBotRepository) CreateAPIKey(ctx context.Context, key entity.APIKey, keyHash string) (entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, key, keyHash)
+	ret0, _ := ret[0].(entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockBotRepositoryMockRecorder) CreateAPIKey(ctx, key, keyHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockBotRepository)(nil).CreateAPIKey), ctx, key, keyHash)
+}
+
+// CreateBot mocks base method.
+func (m *MockBotRepository) CreateBot(ctx context.Context, ownerID int64, username string) (entity.Bot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBot", ctx, ownerID, username)
+	ret0, _ := ret[0].(entity.Bot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBot indicates an expected call of CreateBot.
+func (mr *MockBotRepositoryMockRecorder) CreateBot(ctx, ownerID, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBot", reflect.TypeOf((*MockBotRepository)(nil).CreateBot), ctx, ownerID, username)
+}
+
+// GetBot mocks base method.
+func (m *MockBotRepository) GetBot(ctx context.Context, ownerID, botID int64) (entity.Bot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBot", ctx, ownerID, botID)
+	ret0, _ := ret[0].(entity.Bot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBot indicates an expected call of GetBot.
+func (mr *MockBotRepositoryMockRecorder) GetBot(ctx, ownerID, botID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBot", reflect.TypeOf((*MockBotRepository)(nil).GetBot), ctx, ownerID, botID)
+}
+
+// IsBot mocks base method.
+func (m *MockBotRepository) IsBot(ctx context.Context, userID int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBot", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBot indicates an expected call of IsBot.
+func (mr *MockBotRepositoryMockRecorder) IsBot(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBot", reflect.TypeOf((*MockBotRepository)(nil).IsBot), ctx, userID)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockBotRepository) ListAPIKeys(ctx context.Context, botID int64) ([]entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx, botID)
+	ret0, _ := ret[0].([]entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockBotRepositoryMockRecorder) ListAPIKeys(ctx, botID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockBotRepository)(nil).ListAPIKeys), ctx, botID)
+}
+
+// ListBots mocks base method.
+func (m *MockBotRepository) ListBots(ctx context.Context, ownerID int64) ([]entity.Bot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBots", ctx, ownerID)
+	ret0, _ := ret[0].([]entity.Bot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBots indicates an expected call of ListBots.
+func (mr *MockBotRepositoryMockRecorder) ListBots(ctx, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBots", reflect.TypeOf((*MockBotRepository)(nil).ListBots), ctx, ownerID)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockBotRepository) RevokeAPIKey(ctx context.Context, botID, keyID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, botID, keyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockBotRepositoryMockRecorder) RevokeAPIKey(ctx, botID, keyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockBotRepository)(nil).RevokeAPIKey), ctx, botID, keyID)
+}
+
+// UseAPIKey mocks base method.
+func (m *MockBotRepository) UseAPIKey(ctx context.Context, keyHash string) (entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseAPIKey", ctx, keyHash)
+	ret0, _ := ret[0].(entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseAPIKey indicates an expected call of UseAPIKey.
+func (mr *MockBotRepositoryMockRecorder) UseAPIKey(ctx, keyHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseAPIKey", reflect.TypeOf((*MockBotRepository)(nil).UseAPIKey), ctx, keyHash)
+}
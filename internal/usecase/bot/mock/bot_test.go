@@ -0,0 +1,131 @@
+package mock_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/internal/usecase/bot"
+	mock "main/internal/usecase/bot/mock"
+	"main/pkg/apikey"
+	"main/pkg/customerrors"
+	"main/pkg/jwt"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newService(t *testing.T) (*bot.BotService, *mock.MockBotRepository) {
+	ctrl := gomock.NewController(t)
+	repo := mock.NewMockBotRepository(ctrl)
+	return bot.NewBotService(repo, slog.New(slog.NewTextHandler(io.Discard, nil))), repo
+}
+
+func TestCreateBot(t *testing.T) {
+	tests := []struct {
+		name        string
+		username    string
+		setupMock   func(*mock.MockBotRepository)
+		expectError error
+	}{
+		{
+			name:     "Success",
+			username: "ci-notifier",
+			setupMock: func(repo *mock.MockBotRepository) {
+				repo.EXPECT().IsBot(gomock.Any(), int64(1)).Return(false, nil)
+				repo.EXPECT().ListBots(gomock.Any(), int64(1)).Return(nil, nil)
+				repo.EXPECT().CreateBot(gomock.Any(), int64(1), "ci-notifier").
+					Return(dom.Bot{ID: 5, Username: "ci-notifier", OwnerID: 1}, nil)
+			},
+		},
+		{
+			name:        "Invalid username",
+			username:    "no spaces",
+			setupMock:   func(repo *mock.MockBotRepository) {},
+			expectError: customerrors.ErrInvalidInput,
+		},
+		{
+			name:     "Bots cannot own bots",
+			username: "child",
+			setupMock: func(repo *mock.MockBotRepository) {
+				repo.EXPECT().IsBot(gomock.Any(), int64(1)).Return(true, nil)
+			},
+			expectError: customerrors.ErrInsufficientScope,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newService(t)
+			tt.setupMock(repo)
+
+			_, err := s.CreateBot(context.Background(), 1, tt.username)
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		s, repo := newService(t)
+		var storedHash string
+		gomock.InOrder(
+			repo.EXPECT().GetBot(gomock.Any(), int64(1), int64(5)).Return(dom.Bot{ID: 5, OwnerID: 1}, nil),
+			repo.EXPECT().ListAPIKeys(gomock.Any(), int64(5)).Return(nil, nil),
+			repo.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, key dom.APIKey, hash string) (dom.APIKey, error) {
+					storedHash = hash
+					assert.Equal(t, []string{"messages:write:42"}, key.Scopes)
+					assert.NotNil(t, key.ExpiresAt)
+					key.ID = 7
+					return key, nil
+				}),
+		)
+
+		key, record, err := s.CreateAPIKey(context.Background(), 1, 5, "alerts", []string{"messages:write:42"}, time.Hour)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(key, apikey.Prefix))
+		assert.True(t, strings.HasPrefix(key, record.Prefix))
+		assert.Equal(t, apikey.Hash(key), storedHash)
+		assert.NotContains(t, storedHash, key)
+	})
+
+	t.Run("Unknown scope", func(t *testing.T) {
+		s, _ := newService(t)
+		_, _, err := s.CreateAPIKey(context.Background(), 1, 5, "alerts", []string{"admin"}, 0)
+		assert.ErrorIs(t, err, customerrors.ErrInvalidInput)
+	})
+
+	t.Run("Someone else's bot", func(t *testing.T) {
+		s, repo := newService(t)
+		repo.EXPECT().GetBot(gomock.Any(), int64(2), int64(5)).Return(dom.Bot{}, customerrors.ErrNotFound)
+		_, _, err := s.CreateAPIKey(context.Background(), 2, 5, "alerts", []string{jwt.ScopeMessagesWrite}, 0)
+		assert.ErrorIs(t, err, customerrors.ErrNotFound)
+	})
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	s, repo := newService(t)
+
+	_, err := s.AuthenticateAPIKey(context.Background(), "header.payload.signature")
+	assert.ErrorIs(t, err, customerrors.ErrInvalidAPIKey)
+
+	key := apikey.Prefix + "abc"
+	repo.EXPECT().UseAPIKey(gomock.Any(), apikey.Hash(key)).
+		Return(dom.APIKey{ID: 7, BotID: 5, Scopes: []string{"messages:write:42"}}, nil)
+
+	claims, err := s.AuthenticateAPIKey(context.Background(), key)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), claims.UserID)
+	assert.True(t, claims.Allows(jwt.ScopeMessagesWrite, 42))
+	assert.False(t, claims.Allows(jwt.ScopeMessagesWrite, 43))
+	assert.False(t, claims.Allows(jwt.ScopeMessagesRead, 42))
+}
@@ -0,0 +1,83 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+)
+
+const (
+	defaultChatListLimit = 30
+	maxChatListLimit     = 100
+)
+
+// ListOfChats returns a page of the user's chats, most recently active
+// first, with unread counts, and the cursor of the next page, empty on the
+// last one.
+func (c *ChatService) ListOfChats(ctx context.Context, userID int64, cursor string, limit int) ([]dom.Chat, string, error) {
+	if userID <= 0 {
+		return nil, "", fmt.Errorf("chat service: invalid userID: %w", customerrors.ErrInvalidInput)
+	}
+	if limit == 0 {
+		limit = defaultChatListLimit
+	}
+	if limit < 0 || limit > maxChatListLimit {
+		return nil, "", fmt.Errorf("chat service: limit must be between 1 and %d: %w", maxChatListLimit, customerrors.ErrInvalidInput)
+	}
+	beforeAt, beforeID, err := parseChatCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// One extra row tells whether another page follows.
+	chats, err := c.Chat.ListOfChats(ctx, userID, beforeAt, beforeID, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if len(chats) > limit {
+		chats = chats[:limit]
+		next = chatCursor(chats[limit-1])
+	}
+	c.fillUnreadCounts(ctx, userID, chats)
+	return chats, next, nil
+}
+
+// UpdateChatSettings changes the user's own preferences for the chat.
+func (c *ChatService) UpdateChatSettings(ctx context.Context, chatID, userID int64, settings dom.ChatSettings) error {
+	if chatID <= 0 || userID <= 0 {
+		return fmt.Errorf("chat service: invalid chatID or userID: %w", customerrors.ErrInvalidInput)
+	}
+	if settings.Pinned == nil && settings.Muted == nil {
+		return fmt.Errorf("chat service: nothing to update: %w", customerrors.ErrInvalidInput)
+	}
+	return c.Chat.UpdateMemberSettings(ctx, chatID, userID, settings)
+}
+
+// chatCursor points after chat in the list. The list is ordered by the
+// last message, or by creation for chats without messages, then by ID.
+func chatCursor(chat dom.Chat) string {
+	at := chat.CreatedAt
+	if chat.LastMessageAt != nil {
+		at = *chat.LastMessageAt
+	}
+	return strconv.FormatInt(at.UnixMicro(), 10) + "_" + strconv.FormatInt(chat.ID, 10)
+}
+
+func parseChatCursor(cursor string) (time.Time, int64, error) {
+	if cursor == "" {
+		return time.Time{}, 0, nil
+	}
+	micros, id, ok := strings.Cut(cursor, "_")
+	at, errAt := strconv.ParseInt(micros, 10, 64)
+	chatID, errID := strconv.ParseInt(id, 10, 64)
+	if !ok || errAt != nil || errID != nil || chatID <= 0 {
+		return time.Time{}, 0, fmt.Errorf("chat service: invalid cursor: %w", customerrors.ErrInvalidInput)
+	}
+	return time.UnixMicro(at), chatID, nil
+}
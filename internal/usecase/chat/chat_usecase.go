@@ -5,64 +5,135 @@ import (
 	"fmt"
 	"log/slog"
 	dom "main/internal/domain/entity"
+	"main/internal/domain/events"
+	"main/internal/usecase/audit"
 	"main/pkg/customerrors"
+	"slices"
+	"strconv"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ChatService struct {
 	User   UserInterface
 	Chat   ChatRepositoryInterface
 	Msg    MessageRepositoryInterface
-	Logger *slog.Logger
+	Events MembershipPublisher
+	// Messages announces system messages; nil drops them.
+	Messages MessagePublisher
+	Audit    AuditRecorder
+	Logger   *slog.Logger
 }
 
 //go:generate mockgen -source=chat_usecase.go -destination=mock/chat_mocks.go -package=mock
 type ChatRepositoryInterface interface {
 	GetChatDetails(ctx context.Context, chatID int64) (dom.Chat, error)
-	ListOfChats(ctx context.Context, userID int64) ([]dom.Chat, error)
+	ListOfChats(ctx context.Context, userID int64, beforeAt time.Time, beforeID int64, limit int) ([]dom.Chat, error)
 	CheckIfChatExists(ctx context.Context, chatID int64) (bool, error)
 	DeleteChat(ctx context.Context, chatID int64) error
-	CreateChat(ctx context.Context, title string, isPrivate bool, members []int64) (int64, error)
+	CreateChat(ctx context.Context, ownerID int64, title string, isPrivate bool, members []int64) (int64, error)
 	CheckIsMemberOfChat(ctx context.Context, chatID int64, userID int64) (bool, error)
 	// OpenChat(ctx context.Context, chatID int64, userID int64) ([]dom.Message, error)
 	AddMembers(ctx context.Context, chatID int64, members []int64) error
 	RemoveMember(ctx context.Context, chatID int64, userID int64) error
+	GetMemberRole(ctx context.Context, chatID, userID int64) (string, error)
+	SetMemberRole(ctx context.Context, chatID, userID int64, role string) error
+	TransferOwnership(ctx context.Context, chatID, fromID, toID int64) error
+	SetPinnedMessage(ctx context.Context, chatID int64, messageID string) error
+	FindDirectChat(ctx context.Context, userID, otherID int64) (dom.Chat, error)
+	GetOrCreateDirectChat(ctx context.Context, userID, otherID int64) (dom.Chat, bool, error)
+	CreatePublicChat(ctx context.Context, ownerID int64, chat dom.Chat) (int64, error)
+	GetPublicChat(ctx context.Context, chatID int64) (dom.Chat, error)
+	SearchPublicChats(ctx context.Context, query string, beforeID int64, limit int) ([]dom.Chat, error)
+	BanMember(ctx context.Context, ban dom.ChatBan) (bool, error)
+	GetBan(ctx context.Context, chatID, userID int64) (dom.ChatBan, error)
+	UnbanMember(ctx context.Context, chatID, userID int64) error
+	ListBans(ctx context.Context, chatID int64) ([]dom.ChatBan, error)
+	BannedUsers(ctx context.Context, chatID int64, userIDs []int64) ([]int64, error)
+	RestrictMember(ctx context.Context, restrictions []dom.MemberRestriction) error
+	LiftRestrictions(ctx context.Context, chatID, userID int64, restrictions []string) (int64, error)
+	ListRestrictions(ctx context.Context, chatID int64) ([]dom.MemberRestriction, error)
+	IsRestricted(ctx context.Context, chatID, userID int64, restriction string) (bool, error)
+	DeleteExpiredRestrictions(ctx context.Context) (int64, error)
+	UpdateChat(ctx context.Context, chatID int64, upd dom.ChatUpdate) (dom.Chat, error)
+	ListMemberIDs(ctx context.Context, chatID int64) ([]int64, error)
+	GetChatSummary(ctx context.Context, chatID int64) (dom.Chat, error)
+	CreateJoinRequest(ctx context.Context, chatID, userID, inviteID int64, message string) (dom.JoinRequest, error)
+	ListJoinRequests(ctx context.Context, chatID int64) ([]dom.JoinRequest, error)
+	DecideJoinRequest(ctx context.Context, chatID, requestID int64, status string, decidedBy int64) (dom.JoinRequest, error)
+	ReopenJoinRequest(ctx context.Context, requestID int64) error
+	ListAdminIDs(ctx context.Context, chatID int64) ([]int64, error)
+	ClaimInviteUse(ctx context.Context, inviteID int64) error
+	ReleaseInviteUse(ctx context.Context, inviteID int64) error
+	MarkRead(ctx context.Context, chatID, userID int64, messageID string) (dom.ReadState, bool, error)
+	ListReadStates(ctx context.Context, userID int64) ([]dom.ReadState, error)
+	UpdateMemberSettings(ctx context.Context, chatID, userID int64, settings dom.ChatSettings) error
 }
 
 type MessageRepositoryInterface interface {
 	GetMessages(ctx context.Context, chatID int64, anchorTime time.Time, anchorID string, limit int64) ([]dom.Message, error)
+	SaveMessage(ctx context.Context, msg interface{}) (string, error)
+	GetMessage(ctx context.Context, chatID int64, msgID string) (dom.Message, error)
+	CountUnread(ctx context.Context, userID int64, states []dom.ReadState) (map[int64]int64, error)
 }
 
 type UserInterface interface {
 	CheckUserExists(ctx context.Context, userID int64) bool
 }
 
-func NewChatService(user UserInterface, chat ChatRepositoryInterface, logger *slog.Logger) *ChatService {
+// AuditRecorder stores events for the security audit trail.
+type AuditRecorder interface {
+	Record(ctx context.Context, event dom.AuditEvent)
+}
+
+// NewChatService builds a ChatService; a nil publisher drops membership
+// events and a nil auditRecorder discards the membership and deletion
+// events.
+func NewChatService(user UserInterface, chat ChatRepositoryInterface, msg MessageRepositoryInterface,
+	publisher MembershipPublisher, auditRecorder AuditRecorder, logger *slog.Logger) *ChatService {
+	if auditRecorder == nil {
+		auditRecorder = audit.Discard{}
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
 	return &ChatService{
 		User:   user,
 		Chat:   chat,
+		Msg:    msg,
+		Events: publisher,
+		Audit:  auditRecorder,
 		Logger: logger,
 	}
 }
+
+// CreateChat creates a chat owned by ownerID, who is added to the members if
+// missing.
 func (c *ChatService) CreateChat(
 	ctx context.Context,
+	ownerID int64,
 	title string,
 	isPrivate bool,
 	members []int64) (dom.Chat, error) {
 
+	if ownerID <= 0 {
+		return dom.Chat{}, fmt.Errorf("chat service: invalid ownerID: %w", customerrors.ErrInvalidInput)
+	}
+
 	if len(members) == 0 {
 		return dom.Chat{}, fmt.Errorf("chat service: amount of members cannot be less than 0: %w", customerrors.ErrInvalidInput)
 	}
 
-	if title == "" {
-		return dom.Chat{}, fmt.Errorf("chat service:chat title cannot be empty: %w", customerrors.ErrInvalidInput)
+	if err := validateTitle(title); err != nil {
+		return dom.Chat{}, err
 	}
 
-	if len(title) > 20 {
-		return dom.Chat{}, fmt.Errorf("chat service: chat title cannot be more than 20 characters: %w", customerrors.ErrInvalidInput)
+	if !slices.Contains(members, ownerID) {
+		members = append(members, ownerID)
 	}
 
-	chat_id, err := c.Chat.CreateChat(ctx, title, isPrivate, members)
+	chat_id, err := c.Chat.CreateChat(ctx, ownerID, title, isPrivate, members)
 	if err != nil {
 		return dom.Chat{}, customerrors.ErrDatabase
 	}
@@ -75,7 +146,19 @@ func (c *ChatService) CreateChat(
 	return chat, nil
 }
 
-func (c *ChatService) DeleteChat(ctx context.Context, chatID int64) error {
+func validateTitle(title string) error {
+	if title == "" {
+		return fmt.Errorf("chat service:chat title cannot be empty: %w", customerrors.ErrInvalidInput)
+	}
+
+	if len(title) > 20 {
+		return fmt.Errorf("chat service: chat title cannot be more than 20 characters: %w", customerrors.ErrInvalidInput)
+	}
+	return nil
+}
+
+// DeleteChat deletes the chat; only its owner may do so.
+func (c *ChatService) DeleteChat(ctx context.Context, chatID int64, userID int64) error {
 	if chatID <= 0 {
 		return fmt.Errorf("chat service: invalid chatID: %w", customerrors.ErrInvalidInput)
 	}
@@ -88,22 +171,23 @@ func (c *ChatService) DeleteChat(ctx context.Context, chatID int64) error {
 		return customerrors.ErrNotFound
 	}
 
+	if _, err := c.authorize(ctx, chatID, userID, actionDeleteChat); err != nil {
+		return err
+	}
+
 	err = c.Chat.DeleteChat(ctx, chatID)
 	if err != nil {
 		return err
 	}
+	c.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    userID,
+		Action:     dom.AuditChatDeleted,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+	})
 	return nil
 }
 
-func (c *ChatService) ListOfChats(ctx context.Context, userID int64) ([]dom.Chat, error) {
-	if userID <= 0 {
-		return nil, fmt.Errorf("chat service: invalid userID: %w", customerrors.ErrInvalidInput)
-	}
-
-	return c.Chat.ListOfChats(ctx, userID)
-
-}
-
 func (c *ChatService) GetChatDetails(ctx context.Context, chatID int64, userID int64) (dom.Chat, error) {
 	c.Logger.Info("GetChatDetails called", slog.Int64("chatID", chatID), slog.Int64("userID", userID))
 
@@ -174,12 +258,11 @@ func (c *ChatService) AddMembers(ctx context.Context, chatID int64, userID int64
 	if !c.User.CheckUserExists(ctx, userID) {
 		return customerrors.ErrUserNotFound
 	}
-	inChat, err := c.Chat.CheckIsMemberOfChat(ctx, chatID, userID)
-	if err != nil {
-		return fmt.Errorf("chat service: failed to check if user is member of chat: %w", customerrors.ErrFailedToCheck)
+	if _, err := c.authorize(ctx, chatID, userID, actionAddMembers); err != nil {
+		return err
 	}
-	if !inChat {
-		return fmt.Errorf("chat service: user is not a member of chat: %w", customerrors.ErrUserNotMemberOfChat)
+	if err := c.checkNotRestricted(ctx, chatID, userID, dom.RestrictAddMembers); err != nil {
+		return err
 	}
 
 	for _, memberID := range members {
@@ -194,31 +277,124 @@ func (c *ChatService) AddMembers(ctx context.Context, chatID int64, userID int64
 			return fmt.Errorf("chat service: user is already in chat: %w", customerrors.ErrUserAlreadyInChat)
 		}
 	}
+	if err := c.checkNotBanned(ctx, chatID, members); err != nil {
+		return err
+	}
 
-	err = c.Chat.AddMembers(ctx, chatID, members)
-	if err != nil {
+	if err := c.Chat.AddMembers(ctx, chatID, members); err != nil {
 		return err
 	}
 
+	c.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    userID,
+		Action:     dom.AuditMemberAdded,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+		Metadata:   map[string]interface{}{"members": members},
+	})
+	c.publishMembership(ctx, events.MembershipChanged{
+		ChatID:  chatID,
+		UserIDs: members,
+		Change:  events.MembershipJoined,
+		ActorID: userID,
+	})
 	return nil
 }
 
-func (c *ChatService) RemoveMember(ctx context.Context, chatID int64, userID int64) error {
-
+// SetMemberRole makes a member an admin or a plain member again. Only the
+// owner may change roles; ownership moves with TransferOwnership.
+func (c *ChatService) SetMemberRole(ctx context.Context, chatID, actorID, userID int64, role string) error {
 	if chatID <= 0 || userID <= 0 {
 		return fmt.Errorf("chat service: invalid chatID or userID: %w", customerrors.ErrInvalidInput)
 	}
+	if role != dom.ChatRoleAdmin && role != dom.ChatRoleMember {
+		return fmt.Errorf("chat service: unknown role %q: %w", role, customerrors.ErrInvalidInput)
+	}
+	if actorID == userID {
+		return fmt.Errorf("chat service: cannot change own role: %w", customerrors.ErrInvalidInput)
+	}
 
-	isMember, err := c.Chat.CheckIsMemberOfChat(ctx, chatID, userID)
+	if _, err := c.authorize(ctx, chatID, actorID, actionSetRole); err != nil {
+		return err
+	}
+	previous, err := c.memberRole(ctx, chatID, userID)
 	if err != nil {
-		return customerrors.ErrFailedToCheck
+		return err
 	}
-	if !isMember {
-		return customerrors.ErrUserNotMemberOfChat
+	if previous == role {
+		return nil
 	}
-	err = c.Chat.RemoveMember(ctx, chatID, userID)
-	if err != nil {
+
+	if err := c.Chat.SetMemberRole(ctx, chatID, userID, role); err != nil {
 		return err
 	}
+	c.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    actorID,
+		Action:     dom.AuditChatRoleChanged,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+		Metadata:   map[string]interface{}{"user_id": userID, "from": previous, "to": role},
+	})
 	return nil
 }
+
+// TransferOwnership hands the chat over to another member. The previous
+// owner stays on as an admin.
+func (c *ChatService) TransferOwnership(ctx context.Context, chatID, actorID, newOwnerID int64) error {
+	if chatID <= 0 || newOwnerID <= 0 {
+		return fmt.Errorf("chat service: invalid chatID or userID: %w", customerrors.ErrInvalidInput)
+	}
+	if actorID == newOwnerID {
+		return fmt.Errorf("chat service: user already owns the chat: %w", customerrors.ErrInvalidInput)
+	}
+
+	if _, err := c.authorize(ctx, chatID, actorID, actionTransferOwnership); err != nil {
+		return err
+	}
+	if _, err := c.memberRole(ctx, chatID, newOwnerID); err != nil {
+		return err
+	}
+
+	if err := c.Chat.TransferOwnership(ctx, chatID, actorID, newOwnerID); err != nil {
+		return err
+	}
+	c.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    actorID,
+		Action:     dom.AuditChatOwnerChanged,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+		Metadata:   map[string]interface{}{"from": actorID, "to": newOwnerID},
+	})
+	return nil
+}
+
+// PinMessage pins a message of the chat to its top, replacing the pinned
+// one.
+func (c *ChatService) PinMessage(ctx context.Context, chatID, userID int64, messageID string) error {
+	if chatID <= 0 {
+		return fmt.Errorf("chat service: invalid chatID: %w", customerrors.ErrInvalidInput)
+	}
+	if _, err := primitive.ObjectIDFromHex(messageID); err != nil {
+		return fmt.Errorf("chat service: invalid message id: %w", customerrors.ErrInvalidInput)
+	}
+
+	if _, err := c.authorize(ctx, chatID, userID, actionPinMessage); err != nil {
+		return err
+	}
+	// Only messages of this chat; pinning another chat's would show it here.
+	if _, err := c.Msg.GetMessage(ctx, chatID, messageID); err != nil {
+		return err
+	}
+	return c.Chat.SetPinnedMessage(ctx, chatID, messageID)
+}
+
+func (c *ChatService) UnpinMessage(ctx context.Context, chatID, userID int64) error {
+	if chatID <= 0 {
+		return fmt.Errorf("chat service: invalid chatID: %w", customerrors.ErrInvalidInput)
+	}
+
+	if _, err := c.authorize(ctx, chatID, userID, actionPinMessage); err != nil {
+		return err
+	}
+	return c.Chat.SetPinnedMessage(ctx, chatID, "")
+}
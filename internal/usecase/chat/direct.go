@@ -0,0 +1,38 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+)
+
+// GetDirectChat returns the existing direct chat of userID with otherID, or
+// ErrNotFound.
+func (c *ChatService) GetDirectChat(ctx context.Context, userID, otherID int64) (dom.Chat, error) {
+	if err := validateDirectPair(userID, otherID); err != nil {
+		return dom.Chat{}, err
+	}
+	return c.Chat.FindDirectChat(ctx, userID, otherID)
+}
+
+// OpenDirectChat returns the direct chat of userID with otherID, creating it
+// on first use; created reports whether it is new. The chat is titled with
+// the username of otherID.
+func (c *ChatService) OpenDirectChat(ctx context.Context, userID, otherID int64) (dom.Chat, bool, error) {
+	if err := validateDirectPair(userID, otherID); err != nil {
+		return dom.Chat{}, false, err
+	}
+	return c.Chat.GetOrCreateDirectChat(ctx, userID, otherID)
+}
+
+func validateDirectPair(userID, otherID int64) error {
+	if userID <= 0 || otherID <= 0 {
+		return fmt.Errorf("chat service: invalid userID: %w", customerrors.ErrInvalidInput)
+	}
+	if userID == otherID {
+		return fmt.Errorf("chat service: cannot open a direct chat with oneself: %w", customerrors.ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/internal/domain/events"
+)
+
+//go:generate mockgen -source=events.go -destination=mock/events_mocks.go -package=mock
+
+// MembershipPublisher announces membership changes to other services.
+type MembershipPublisher interface {
+	SendMembershipChanged(ctx context.Context, event events.MembershipChanged) error
+}
+
+// MessagePublisher announces messages the service posts itself, so chat
+// lists pick them up like any other message.
+type MessagePublisher interface {
+	SendMessageCreated(ctx context.Context, event events.MessageCreated) error
+}
+
+// MembershipPublishers sends each event to all of its publishers, e.g. the
+// event bus and the websocket fan-out.
+type MembershipPublishers []MembershipPublisher
+
+func (p MembershipPublishers) SendMembershipChanged(ctx context.Context, event events.MembershipChanged) error {
+	var errs []error
+	for _, pub := range p {
+		if err := pub.SendMembershipChanged(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// publishMembership announces a change that already happened, so a failure
+// is only logged.
+func (c *ChatService) publishMembership(ctx context.Context, evt events.MembershipChanged) {
+	if c.Events == nil {
+		return
+	}
+	if evt.ChangedAt.IsZero() {
+		evt.ChangedAt = time.Now()
+	}
+	if err := c.Events.SendMembershipChanged(ctx, evt); err != nil {
+		c.Logger.Warn("failed to publish membership event",
+			slog.Int64("chat_id", evt.ChatID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// publishMessage announces a message that is already saved, so a failure is
+// only logged.
+func (c *ChatService) publishMessage(ctx context.Context, msg dom.Message) {
+	if c.Messages == nil {
+		return
+	}
+	evt := events.MessageCreated{
+		MessageID: msg.ID.Hex(),
+		ChatID:    msg.ChatID,
+		SenderID:  msg.SenderID,
+		Text:      msg.Text,
+		CreatedAt: msg.CreatedAt,
+	}
+	if err := c.Messages.SendMessageCreated(ctx, evt); err != nil {
+		c.Logger.Warn("failed to publish message event",
+			slog.Int64("chat_id", msg.ChatID),
+			slog.String("error", err.Error()))
+	}
+}
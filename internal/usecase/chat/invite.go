@@ -0,0 +1,218 @@
+package chat
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/internal/domain/events"
+	"main/pkg/customerrors"
+)
+
+//go:generate mockgen -source=invite.go -destination=mock/invite_mocks.go -package=mock
+type InviteRepository interface {
+	CreateInvite(ctx context.Context, invite dom.ChatInvite) (dom.ChatInvite, error)
+	ListInvites(ctx context.Context, chatID int64) ([]dom.ChatInvite, error)
+	GetInviteByCode(ctx context.Context, code string) (dom.ChatInvite, error)
+	RevokeInvite(ctx context.Context, chatID, inviteID int64) error
+	ClaimInviteUse(ctx context.Context, inviteID int64) error
+	ReleaseInviteUse(ctx context.Context, inviteID int64) error
+}
+
+// InviteService manages the invite codes of chats. Joins go through the
+// same repository path and permission checks as ChatService.
+type InviteService struct {
+	chats   *ChatService
+	invites InviteRepository
+	logger  *slog.Logger
+}
+
+func NewInviteService(chats *ChatService, invites InviteRepository, logger *slog.Logger) *InviteService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &InviteService{
+		chats:   chats,
+		invites: invites,
+		logger:  logger,
+	}
+}
+
+// CreateInvite creates an invite code for the chat; only admins and the owner
+// may do so.
+func (s *InviteService) CreateInvite(ctx context.Context, chatID, userID int64, opts dom.InviteOptions) (dom.ChatInvite, error) {
+	if chatID <= 0 {
+		return dom.ChatInvite{}, fmt.Errorf("chat service: invalid chatID: %w", customerrors.ErrInvalidInput)
+	}
+	if opts.MaxUses != nil && *opts.MaxUses <= 0 {
+		return dom.ChatInvite{}, fmt.Errorf("chat service: max uses must be positive: %w", customerrors.ErrInvalidInput)
+	}
+	if opts.ExpiresAt != nil && !opts.ExpiresAt.After(time.Now()) {
+		return dom.ChatInvite{}, fmt.Errorf("chat service: invite expiry must be in the future: %w", customerrors.ErrInvalidInput)
+	}
+
+	if _, err := s.chats.authorize(ctx, chatID, userID, actionManageInvites); err != nil {
+		return dom.ChatInvite{}, err
+	}
+	// An invite adds members just like AddMembers does.
+	if err := s.chats.checkNotRestricted(ctx, chatID, userID, dom.RestrictAddMembers); err != nil {
+		return dom.ChatInvite{}, err
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		return dom.ChatInvite{}, err
+	}
+	invite, err := s.invites.CreateInvite(ctx, dom.ChatInvite{
+		ChatID:           chatID,
+		Code:             code,
+		CreatedBy:        userID,
+		ExpiresAt:        opts.ExpiresAt,
+		MaxUses:          opts.MaxUses,
+		RequiresApproval: opts.RequiresApproval,
+	})
+	if err != nil {
+		return dom.ChatInvite{}, err
+	}
+	s.chats.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    userID,
+		Action:     dom.AuditInviteCreated,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+		Metadata:   map[string]interface{}{"invite_id": invite.ID},
+	})
+	return invite, nil
+}
+
+func (s *InviteService) ListInvites(ctx context.Context, chatID, userID int64) ([]dom.ChatInvite, error) {
+	if _, err := s.chats.authorize(ctx, chatID, userID, actionManageInvites); err != nil {
+		return nil, err
+	}
+	return s.invites.ListInvites(ctx, chatID)
+}
+
+func (s *InviteService) RevokeInvite(ctx context.Context, chatID, userID, inviteID int64) error {
+	if _, err := s.chats.authorize(ctx, chatID, userID, actionManageInvites); err != nil {
+		return err
+	}
+	if err := s.invites.RevokeInvite(ctx, chatID, inviteID); err != nil {
+		return err
+	}
+	s.chats.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    userID,
+		Action:     dom.AuditInviteRevoked,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+		Metadata:   map[string]interface{}{"invite_id": inviteID},
+	})
+	return nil
+}
+
+// RedeemInvite joins the user to the chat of the invite and returns its id.
+// For invites that need approval a join request is filed instead and
+// returned; the admins approve it like any other.
+func (s *InviteService) RedeemInvite(ctx context.Context, userID int64, code string) (chatID int64, pending *dom.JoinRequest, err error) {
+	if userID <= 0 || code == "" {
+		return 0, nil, fmt.Errorf("chat service: invalid userID or code: %w", customerrors.ErrInvalidInput)
+	}
+
+	invite, err := s.invites.GetInviteByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrNotFound) {
+			return 0, nil, customerrors.ErrInvalidInvite
+		}
+		return 0, nil, err
+	}
+	if !usable(invite, time.Now()) {
+		return 0, nil, customerrors.ErrInvalidInvite
+	}
+	// Invites of an admin who may not add members stop working meanwhile.
+	if invite.CreatedBy != 0 {
+		if err := s.chats.checkNotRestricted(ctx, invite.ChatID, invite.CreatedBy, dom.RestrictAddMembers); err != nil {
+			if errors.Is(err, customerrors.ErrMemberRestricted) {
+				return 0, nil, customerrors.ErrInvalidInvite
+			}
+			return 0, nil, err
+		}
+	}
+
+	inChat, err := s.chats.Chat.CheckIsMemberOfChat(ctx, invite.ChatID, userID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("chat service: failed to check if user is member of chat: %w", customerrors.ErrFailedToCheck)
+	}
+	if inChat {
+		return invite.ChatID, nil, customerrors.ErrUserAlreadyInChat
+	}
+	if err := s.chats.checkNotBanned(ctx, invite.ChatID, []int64{userID}); err != nil {
+		return 0, nil, err
+	}
+
+	if invite.RequiresApproval {
+		req, err := s.chats.Chat.CreateJoinRequest(ctx, invite.ChatID, userID, invite.ID, "")
+		if err != nil {
+			return 0, nil, err
+		}
+		return invite.ChatID, &req, nil
+	}
+
+	if err := s.join(ctx, invite.ID, invite.ChatID, userID, userID); err != nil {
+		return 0, nil, err
+	}
+	return invite.ChatID, nil, nil
+}
+
+// join uses up one redemption of the invite and adds the user to the chat.
+func (s *InviteService) join(ctx context.Context, inviteID, chatID, userID, actorID int64) error {
+	if err := s.invites.ClaimInviteUse(ctx, inviteID); err != nil {
+		return err
+	}
+	if err := s.chats.Chat.AddMembers(ctx, chatID, []int64{userID}); err != nil {
+		if rerr := s.invites.ReleaseInviteUse(ctx, inviteID); rerr != nil {
+			s.logger.Warn("failed to release invite use",
+				slog.Int64("invite_id", inviteID),
+				slog.String("error", rerr.Error()))
+		}
+		return err
+	}
+
+	s.chats.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    actorID,
+		Action:     dom.AuditMemberAdded,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+		Metadata:   map[string]interface{}{"members": []int64{userID}, "invite_id": inviteID},
+	})
+	s.chats.publishMembership(ctx, events.MembershipChanged{
+		ChatID:  chatID,
+		UserIDs: []int64{userID},
+		Change:  events.MembershipJoined,
+		ActorID: actorID,
+		Via:     "invite",
+	})
+	return nil
+}
+
+// usable reports whether the invite can still be redeemed at now.
+func usable(invite dom.ChatInvite, now time.Time) bool {
+	if invite.RevokedAt != nil {
+		return false
+	}
+	if invite.ExpiresAt != nil && !invite.ExpiresAt.After(now) {
+		return false
+	}
+	return invite.MaxUses == nil || invite.Uses < *invite.MaxUses
+}
+
+func newInviteCode() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("chat service: failed to generate invite code: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
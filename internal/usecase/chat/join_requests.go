@@ -0,0 +1,160 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	dom "main/internal/domain/entity"
+	"main/internal/domain/events"
+	"main/pkg/customerrors"
+)
+
+const maxJoinRequestMessageLen = 512
+
+// RequestToJoin asks the admins of a private group chat to let userID in.
+// Asking again while a request is pending returns that request. Public
+// chats are joined directly and direct chats cannot be joined at all.
+func (c *ChatService) RequestToJoin(ctx context.Context, chatID, userID int64, message string) (dom.JoinRequest, error) {
+	if chatID <= 0 || userID <= 0 {
+		return dom.JoinRequest{}, fmt.Errorf("chat service: invalid chatID or userID: %w", customerrors.ErrInvalidInput)
+	}
+	if len(message) > maxJoinRequestMessageLen {
+		return dom.JoinRequest{}, fmt.Errorf("chat service: message cannot be more than %d characters: %w", maxJoinRequestMessageLen, customerrors.ErrInvalidInput)
+	}
+
+	chat, err := c.Chat.GetChatSummary(ctx, chatID)
+	if err != nil {
+		return dom.JoinRequest{}, err
+	}
+	if chat.Type != dom.ChatTypeGroup || !chat.IsPrivate || chat.IsPublic {
+		return dom.JoinRequest{}, fmt.Errorf("chat service: only private group chats take join requests: %w", customerrors.ErrInvalidInput)
+	}
+
+	inChat, err := c.Chat.CheckIsMemberOfChat(ctx, chatID, userID)
+	if err != nil {
+		return dom.JoinRequest{}, fmt.Errorf("chat service: failed to check if user is member of chat: %w", customerrors.ErrFailedToCheck)
+	}
+	if inChat {
+		return dom.JoinRequest{}, fmt.Errorf("chat service: user is already in chat: %w", customerrors.ErrUserAlreadyInChat)
+	}
+	if err := c.checkNotBanned(ctx, chatID, []int64{userID}); err != nil {
+		return dom.JoinRequest{}, err
+	}
+
+	return c.Chat.CreateJoinRequest(ctx, chatID, userID, 0, message)
+}
+
+// ListJoinRequests returns the pending join requests of the chat.
+func (c *ChatService) ListJoinRequests(ctx context.Context, chatID, actorID int64) ([]dom.JoinRequest, error) {
+	if _, err := c.authorize(ctx, chatID, actorID, actionReviewJoinRequest); err != nil {
+		return nil, err
+	}
+	return c.Chat.ListJoinRequests(ctx, chatID)
+}
+
+// ApproveJoinRequest adds the requesting user to the chat. A request made
+// through an invite uses up one redemption of it. A user who was banned, or
+// whose invite stopped working, while the request waited stays out and the
+// request stays pending until it is rejected.
+func (c *ChatService) ApproveJoinRequest(ctx context.Context, chatID, actorID, requestID int64) (dom.JoinRequest, error) {
+	if _, err := c.authorize(ctx, chatID, actorID, actionReviewJoinRequest); err != nil {
+		return dom.JoinRequest{}, err
+	}
+	if err := c.checkNotRestricted(ctx, chatID, actorID, dom.RestrictAddMembers); err != nil {
+		return dom.JoinRequest{}, err
+	}
+
+	// Deciding first makes sure only one admin carries out the approval.
+	req, err := c.Chat.DecideJoinRequest(ctx, chatID, requestID, dom.JoinRequestApproved, actorID)
+	if err != nil {
+		return dom.JoinRequest{}, err
+	}
+	inChat, err := c.Chat.CheckIsMemberOfChat(ctx, chatID, req.UserID)
+	if err != nil {
+		c.reopenJoinRequest(ctx, requestID)
+		return dom.JoinRequest{}, fmt.Errorf("chat service: failed to check if user is member of chat: %w", customerrors.ErrFailedToCheck)
+	}
+	if inChat {
+		// Joined some other way in the meantime; the request is settled.
+		return dom.JoinRequest{}, fmt.Errorf("chat service: user is already in chat: %w", customerrors.ErrUserAlreadyInChat)
+	}
+	if err := c.checkNotBanned(ctx, chatID, []int64{req.UserID}); err != nil {
+		c.reopenJoinRequest(ctx, requestID)
+		return dom.JoinRequest{}, err
+	}
+	if req.InviteID != 0 {
+		if err := c.Chat.ClaimInviteUse(ctx, req.InviteID); err != nil {
+			c.reopenJoinRequest(ctx, requestID)
+			return dom.JoinRequest{}, err
+		}
+	}
+	if err := c.Chat.AddMembers(ctx, chatID, []int64{req.UserID}); err != nil {
+		if req.InviteID != 0 {
+			if rerr := c.Chat.ReleaseInviteUse(ctx, req.InviteID); rerr != nil {
+				c.Logger.Warn("failed to release invite use",
+					slog.Int64("invite_id", req.InviteID),
+					slog.String("error", rerr.Error()))
+			}
+		}
+		c.reopenJoinRequest(ctx, requestID)
+		return dom.JoinRequest{}, err
+	}
+
+	metadata := map[string]interface{}{"members": []int64{req.UserID}, "join_request_id": requestID}
+	via := "request"
+	if req.InviteID != 0 {
+		metadata["invite_id"] = req.InviteID
+		via = "invite"
+	}
+	c.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    actorID,
+		Action:     dom.AuditMemberAdded,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+		Metadata:   metadata,
+	})
+	c.publishMembership(ctx, events.MembershipChanged{
+		ChatID:  chatID,
+		UserIDs: []int64{req.UserID},
+		Change:  events.MembershipJoined,
+		ActorID: actorID,
+		Via:     via,
+	})
+	return req, nil
+}
+
+// RejectJoinRequest turns the request down. The user may ask again.
+func (c *ChatService) RejectJoinRequest(ctx context.Context, chatID, actorID, requestID int64) (dom.JoinRequest, error) {
+	if _, err := c.authorize(ctx, chatID, actorID, actionReviewJoinRequest); err != nil {
+		return dom.JoinRequest{}, err
+	}
+
+	req, err := c.Chat.DecideJoinRequest(ctx, chatID, requestID, dom.JoinRequestRejected, actorID)
+	if err != nil {
+		return dom.JoinRequest{}, err
+	}
+	c.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    actorID,
+		Action:     dom.AuditJoinRequestRejected,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+		Metadata:   map[string]interface{}{"user_id": req.UserID, "join_request_id": requestID},
+	})
+	return req, nil
+}
+
+// ChatAdminIDs returns the members who review join requests, for
+// notifications.
+func (c *ChatService) ChatAdminIDs(ctx context.Context, chatID int64) ([]int64, error) {
+	return c.Chat.ListAdminIDs(ctx, chatID)
+}
+
+func (c *ChatService) reopenJoinRequest(ctx context.Context, requestID int64) {
+	if err := c.Chat.ReopenJoinRequest(ctx, requestID); err != nil {
+		c.Logger.Warn("failed to reopen join request",
+			slog.Int64("request_id", requestID),
+			slog.String("error", err.Error()))
+	}
+}
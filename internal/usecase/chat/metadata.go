@@ -0,0 +1,140 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const maxAvatarURLLen = 512
+
+// UpdateChat changes the title, description or avatar of the chat and posts
+// a system message about it. Admins and the owner may do so. The message is
+// nil if it could not be saved; the change itself is kept.
+func (c *ChatService) UpdateChat(ctx context.Context, chatID, userID int64, upd dom.ChatUpdate) (dom.Chat, *dom.Message, error) {
+	if chatID <= 0 {
+		return dom.Chat{}, nil, fmt.Errorf("chat service: invalid chatID: %w", customerrors.ErrInvalidInput)
+	}
+	if err := validateChatUpdate(upd); err != nil {
+		return dom.Chat{}, nil, err
+	}
+
+	if _, err := c.authorize(ctx, chatID, userID, actionEditChat); err != nil {
+		return dom.Chat{}, nil, err
+	}
+	chat, err := c.Chat.UpdateChat(ctx, chatID, upd)
+	if err != nil {
+		return dom.Chat{}, nil, err
+	}
+
+	c.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    userID,
+		Action:     dom.AuditChatUpdated,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+		Metadata:   map[string]interface{}{"fields": updatedFields(upd)},
+	})
+
+	msg := dom.Message{
+		ChatID:    chatID,
+		ActorID:   userID,
+		Text:      describeChatUpdate(upd),
+		Type:      dom.MessageTypeSystem,
+		CreatedAt: time.Now(),
+	}
+	id, err := c.Msg.SaveMessage(ctx, msg)
+	if err != nil {
+		c.Logger.Warn("failed to save chat update message",
+			slog.Int64("chat_id", chatID),
+			slog.String("error", err.Error()))
+		return chat, nil, nil
+	}
+	if msg.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		c.Logger.Warn("invalid chat update message id",
+			slog.Int64("chat_id", chatID),
+			slog.String("message_id", id))
+	}
+	c.publishMessage(ctx, msg)
+	return chat, &msg, nil
+}
+
+func validateChatUpdate(upd dom.ChatUpdate) error {
+	if upd.Title == nil && upd.Description == nil && upd.AvatarURL == nil {
+		return fmt.Errorf("chat service: nothing to update: %w", customerrors.ErrInvalidInput)
+	}
+	if upd.Title != nil {
+		if err := validateTitle(*upd.Title); err != nil {
+			return err
+		}
+	}
+	if upd.Description != nil && len(*upd.Description) > maxDescriptionLen {
+		return fmt.Errorf("chat service: description cannot be more than %d characters: %w",
+			maxDescriptionLen, customerrors.ErrInvalidInput)
+	}
+	if upd.AvatarURL != nil && *upd.AvatarURL != "" {
+		if len(*upd.AvatarURL) > maxAvatarURLLen {
+			return fmt.Errorf("chat service: avatar url cannot be more than %d characters: %w",
+				maxAvatarURLLen, customerrors.ErrInvalidInput)
+		}
+		u, err := url.Parse(*upd.AvatarURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("chat service: invalid avatar url: %w", customerrors.ErrInvalidInput)
+		}
+	}
+	return nil
+}
+
+func updatedFields(upd dom.ChatUpdate) []string {
+	var fields []string
+	if upd.Title != nil {
+		fields = append(fields, "title")
+	}
+	if upd.Description != nil {
+		fields = append(fields, "description")
+	}
+	if upd.AvatarURL != nil {
+		fields = append(fields, "avatar_url")
+	}
+	return fields
+}
+
+// describeChatUpdate is the text of the system message for upd.
+func describeChatUpdate(upd dom.ChatUpdate) string {
+	var parts []string
+	if upd.Title != nil {
+		parts = append(parts, fmt.Sprintf("changed the title to %q", *upd.Title))
+	}
+	if upd.Description != nil {
+		if *upd.Description == "" {
+			parts = append(parts, "removed the description")
+		} else {
+			parts = append(parts, "changed the description")
+		}
+	}
+	if upd.AvatarURL != nil {
+		if *upd.AvatarURL == "" {
+			parts = append(parts, "removed the avatar")
+		} else {
+			parts = append(parts, "changed the avatar")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ChatMemberIDs returns the members of the chat to notify about a change the
+// user made. The user has to be a member.
+func (c *ChatService) ChatMemberIDs(ctx context.Context, chatID, userID int64) ([]int64, error) {
+	if _, err := c.memberRole(ctx, chatID, userID); err != nil {
+		return nil, err
+	}
+	return c.Chat.ListMemberIDs(ctx, chatID)
+}
@@ -0,0 +1,69 @@
+package mock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dom "main/internal/domain/entity"
+	service "main/internal/usecase/chat"
+	"main/internal/usecase/chat/mock"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestListOfChatsPages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	msgRepo := mock.NewMockMessageRepositoryInterface(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, msgRepo, nil, nil, nil)
+	ctx := context.Background()
+
+	lastAt := time.UnixMicro(1700000000000001)
+	created := time.UnixMicro(1600000000000000)
+	chatRepo.EXPECT().ListOfChats(gomock.Any(), int64(10), time.Time{}, int64(0), 3).
+		Return([]dom.Chat{{ID: 5}, {ID: 4, LastMessageAt: &lastAt}, {ID: 3}}, nil)
+	chatRepo.EXPECT().ListReadStates(gomock.Any(), int64(10)).Return(nil, nil)
+	msgRepo.EXPECT().CountUnread(gomock.Any(), int64(10), gomock.Any()).Return(map[int64]int64{}, nil)
+	chats, next, err := ChatService.ListOfChats(ctx, 10, "", 2)
+	require.NoError(t, err)
+	assert.Len(t, chats, 2)
+	assert.Equal(t, "1700000000000001_4", next)
+
+	// The cursor continues after the last chat of the page.
+	chatRepo.EXPECT().ListOfChats(gomock.Any(), int64(10), gomock.Any(), int64(4), 3).
+		DoAndReturn(func(_ context.Context, _ int64, beforeAt time.Time, _ int64, _ int) ([]dom.Chat, error) {
+			assert.True(t, beforeAt.Equal(lastAt))
+			return []dom.Chat{{ID: 3, CreatedAt: created}}, nil
+		})
+	chatRepo.EXPECT().ListReadStates(gomock.Any(), int64(10)).Return(nil, nil)
+	msgRepo.EXPECT().CountUnread(gomock.Any(), int64(10), gomock.Any()).Return(map[int64]int64{}, nil)
+	chats, next, err = ChatService.ListOfChats(ctx, 10, next, 2)
+	require.NoError(t, err)
+	assert.Len(t, chats, 1)
+	assert.Empty(t, next)
+
+	_, _, err = ChatService.ListOfChats(ctx, 10, "garbage", 0)
+	assert.ErrorIs(t, err, customerrors.ErrInvalidInput)
+	_, _, err = ChatService.ListOfChats(ctx, 10, "", 101)
+	assert.ErrorIs(t, err, customerrors.ErrInvalidInput)
+}
+
+func TestUpdateChatSettings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, nil, nil, nil, nil)
+	ctx := context.Background()
+	muted := true
+
+	chatRepo.EXPECT().UpdateMemberSettings(gomock.Any(), int64(1), int64(10), dom.ChatSettings{Muted: &muted}).Return(nil)
+	require.NoError(t, ChatService.UpdateChatSettings(ctx, 1, 10, dom.ChatSettings{Muted: &muted}))
+
+	chatRepo.EXPECT().UpdateMemberSettings(gomock.Any(), int64(1), int64(20), gomock.Any()).Return(customerrors.ErrUserNotMemberOfChat)
+	assert.ErrorIs(t, ChatService.UpdateChatSettings(ctx, 1, 20, dom.ChatSettings{Muted: &muted}), customerrors.ErrUserNotMemberOfChat)
+
+	assert.ErrorIs(t, ChatService.UpdateChatSettings(ctx, 1, 10, dom.ChatSettings{}), customerrors.ErrInvalidInput)
+}
@@ -56,6 +56,36 @@ func (mr *MockChatRepositoryInterfaceMockRecorder) AddMembers(ctx, chatID, membe
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMembers", reflect.TypeOf((*MockChatRepositoryInterface)(nil).AddMembers), ctx, chatID, members)
 }
 
+// BanMember mocks base method.
+func (m *MockChatRepositoryInterface) BanMember(ctx context.Context, ban entity.ChatBan) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BanMember", ctx, ban)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BanMember indicates an expected call of BanMember.
+func (mr *MockChatRepositoryInterfaceMockRecorder) BanMember(ctx, ban any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanMember", reflect.TypeOf((*MockChatRepositoryInterface)(nil).BanMember), ctx, ban)
+}
+
+// BannedUsers mocks base method.
+func (m *MockChatRepositoryInterface) BannedUsers(ctx context.Context, chatID int64, userIDs []int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BannedUsers", ctx, chatID, userIDs)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BannedUsers indicates an expected call of BannedUsers.
+func (mr *MockChatRepositoryInterfaceMockRecorder) BannedUsers(ctx, chatID, userIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BannedUsers", reflect.TypeOf((*MockChatRepositoryInterface)(nil).BannedUsers), ctx, chatID, userIDs)
+}
+
 // CheckIfChatExists mocks base method.
 func (m *MockChatRepositoryInterface) CheckIfChatExists(ctx context.Context, chatID int64) (bool, error) {
 	m.ctrl.T.Helper()
@@ -86,19 +116,78 @@ func (mr *MockChatRepositoryInterfaceMockRecorder) CheckIsMemberOfChat(ctx, chat
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckIsMemberOfChat", reflect.TypeOf((*MockChatRepositoryInterface)(nil).CheckIsMemberOfChat), ctx, chatID, userID)
 }
 
+// ClaimInviteUse mocks base method.
+func (m *MockChatRepositoryInterface) ClaimInviteUse(ctx context.Context, inviteID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimInviteUse", ctx, inviteID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimInviteUse indicates an expected call of ClaimInviteUse.
+func (mr *MockChatRepositoryInterfaceMockRecorder) ClaimInviteUse(ctx, inviteID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimInviteUse", reflect.TypeOf((*MockChatRepositoryInterface)(nil).ClaimInviteUse), ctx, inviteID)
+}
+
 // CreateChat mocks base method.
-func (m *MockChatRepositoryInterface) CreateChat(ctx context.Context, title string, isPrivate bool, members []int64) (int64, error) {
+func (m *MockChatRepositoryInterface) CreateChat(ctx context.Context, ownerID int64, title string, isPrivate bool, members []int64) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateChat", ctx, title, isPrivate, members)
+	ret := m.ctrl.Call(m, "CreateChat", ctx, ownerID, title, isPrivate, members)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateChat indicates an expected call of CreateChat.
-func (mr *MockChatRepositoryInterfaceMockRecorder) CreateChat(ctx, title, isPrivate, members any) *gomock.Call {
+func (mr *MockChatRepositoryInterfaceMockRecorder) CreateChat(ctx, ownerID, title, isPrivate, members any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChat", reflect.TypeOf((*MockChatRepositoryInterface)(nil).CreateChat), ctx, title, isPrivate, members)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChat", reflect.TypeOf((*MockChatRepositoryInterface)(nil).CreateChat), ctx, ownerID, title, isPrivate, members)
+}
+
+// CreateJoinRequest mocks base method.
+func (m *MockChatRepositoryInterface) CreateJoinRequest(ctx context.Context, chatID, userID, inviteID int64, message string) (entity.JoinRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJoinRequest", ctx, chatID, userID, inviteID, message)
+	ret0, _ := ret[0].(entity.JoinRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJoinRequest indicates an expected call of CreateJoinRequest.
+func (mr *MockChatRepositoryInterfaceMockRecorder) CreateJoinRequest(ctx, chatID, userID, inviteID, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJoinRequest", reflect.TypeOf((*MockChatRepositoryInterface)(nil).CreateJoinRequest), ctx, chatID, userID, inviteID, message)
+}
+
+// CreatePublicChat mocks base method.
+func (m *MockChatRepositoryInterface) CreatePublicChat(ctx context.Context, ownerID int64, chat entity.Chat) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePublicChat", ctx, ownerID, chat)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePublicChat indicates an expected call of CreatePublicChat.
+func (mr *MockChatRepositoryInterfaceMockRecorder) CreatePublicChat(ctx, ownerID, chat any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePublicChat", reflect.TypeOf((*MockChatRepositoryInterface)(nil).CreatePublicChat), ctx, ownerID, chat)
+}
+
+// DecideJoinRequest mocks base method.
+func (m *MockChatRepositoryInterface) DecideJoinRequest(ctx context.Context, chatID, requestID int64, status string, decidedBy int64) (entity.JoinRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideJoinRequest", ctx, chatID, requestID, status, decidedBy)
+	ret0, _ := ret[0].(entity.JoinRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecideJoinRequest indicates an expected call of DecideJoinRequest.
+func (mr *MockChatRepositoryInterfaceMockRecorder) DecideJoinRequest(ctx, chatID, requestID, status, decidedBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideJoinRequest", reflect.TypeOf((*MockChatRepositoryInterface)(nil).DecideJoinRequest), ctx, chatID, requestID, status, decidedBy)
 }
 
 // DeleteChat mocks base method.
@@ -115,6 +204,51 @@ func (mr *MockChatRepositoryInterfaceMockRecorder) DeleteChat(ctx, chatID any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChat", reflect.TypeOf((*MockChatRepositoryInterface)(nil).DeleteChat), ctx, chatID)
 }
 
+// DeleteExpiredRestrictions mocks base method.
+func (m *MockChatRepositoryInterface) DeleteExpiredRestrictions(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredRestrictions", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredRestrictions indicates an expected call of DeleteExpiredRestrictions.
+func (mr *MockChatRepositoryInterfaceMockRecorder) DeleteExpiredRestrictions(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredRestrictions", reflect.TypeOf((*MockChatRepositoryInterface)(nil).DeleteExpiredRestrictions), ctx)
+}
+
+// FindDirectChat mocks base method.
+func (m *MockChatRepositoryInterface) FindDirectChat(ctx context.Context, userID, otherID int64) (entity.Chat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDirectChat", ctx, userID, otherID)
+	ret0, _ := ret[0].(entity.Chat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDirectChat indicates an expected call of FindDirectChat.
+func (mr *MockChatRepositoryInterfaceMockRecorder) FindDirectChat(ctx, userID, otherID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDirectChat", reflect.TypeOf((*MockChatRepositoryInterface)(nil).FindDirectChat), ctx, userID, otherID)
+}
+
+// GetBan mocks base method.
+func (m *MockChatRepositoryInterface) GetBan(ctx context.Context, chatID, userID int64) (entity.ChatBan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBan", ctx, chatID, userID)
+	ret0, _ := ret[0].(entity.ChatBan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBan indicates an expected call of GetBan.
+func (mr *MockChatRepositoryInterfaceMockRecorder) GetBan(ctx, chatID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBan", reflect.TypeOf((*MockChatRepositoryInterface)(nil).GetBan), ctx, chatID, userID)
+}
+
 // GetChatDetails mocks base method.
 func (m *MockChatRepositoryInterface) GetChatDetails(ctx context.Context, chatID int64) (entity.Chat, error) {
 	m.ctrl.T.Helper()
@@ -130,19 +264,230 @@ func (mr *MockChatRepositoryInterfaceMockRecorder) GetChatDetails(ctx, chatID an
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatDetails", reflect.TypeOf((*MockChatRepositoryInterface)(nil).GetChatDetails), ctx, chatID)
 }
 
+// GetChatSummary mocks base method.
+func (m *MockChatRepositoryInterface) GetChatSummary(ctx context.Context, chatID int64) (entity.Chat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatSummary", ctx, chatID)
+	ret0, _ := ret[0].(entity.Chat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatSummary indicates an expected call of GetChatSummary.
+func (mr *MockChatRepositoryInterfaceMockRecorder) GetChatSummary(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatSummary", reflect.TypeOf((*MockChatRepositoryInterface)(nil).GetChatSummary), ctx, chatID)
+}
+
+// GetMemberRole mocks base method.
+func (m *MockChatRepositoryInterface) GetMemberRole(ctx context.Context, chatID, userID int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberRole", ctx, chatID, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberRole indicates an expected call of GetMemberRole.
+func (mr *MockChatRepositoryInterfaceMockRecorder) GetMemberRole(ctx, chatID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberRole", reflect.TypeOf((*MockChatRepositoryInterface)(nil).GetMemberRole), ctx, chatID, userID)
+}
+
+// GetOrCreateDirectChat mocks base method.
+func (m *MockChatRepositoryInterface) GetOrCreateDirectChat(ctx context.Context, userID, otherID int64) (entity.Chat, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrCreateDirectChat", ctx, userID, otherID)
+	ret0, _ := ret[0].(entity.Chat)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetOrCreateDirectChat indicates an expected call of GetOrCreateDirectChat.
+func (mr *MockChatRepositoryInterfaceMockRecorder) GetOrCreateDirectChat(ctx, userID, otherID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrCreateDirectChat", reflect.TypeOf((*MockChatRepositoryInterface)(nil).GetOrCreateDirectChat), ctx, userID, otherID)
+}
+
+// GetPublicChat mocks base method.
+func (m *MockChatRepositoryInterface) GetPublicChat(ctx context.Context, chatID int64) (entity.Chat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublicChat", ctx, chatID)
+	ret0, _ := ret[0].(entity.Chat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublicChat indicates an expected call of GetPublicChat.
+func (mr *MockChatRepositoryInterfaceMockRecorder) GetPublicChat(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicChat", reflect.TypeOf((*MockChatRepositoryInterface)(nil).GetPublicChat), ctx, chatID)
+}
+
+// IsRestricted mocks base method.
+func (m *MockChatRepositoryInterface) IsRestricted(ctx context.Context, chatID, userID int64, restriction string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRestricted", ctx, chatID, userID, restriction)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRestricted indicates an expected call of IsRestricted.
+func (mr *MockChatRepositoryInterfaceMockRecorder) IsRestricted(ctx, chatID, userID, restriction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRestricted", reflect.TypeOf((*MockChatRepositoryInterface)(nil).IsRestricted), ctx, chatID, userID, restriction)
+}
+
+// LiftRestrictions mocks base method.
+func (m *MockChatRepositoryInterface) LiftRestrictions(ctx context.Context, chatID, userID int64, restrictions []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LiftRestrictions", ctx, chatID, userID, restrictions)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LiftRestrictions indicates an expected call of LiftRestrictions.
+func (mr *MockChatRepositoryInterfaceMockRecorder) LiftRestrictions(ctx, chatID, userID, restrictions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LiftRestrictions", reflect.TypeOf((*MockChatRepositoryInterface)(nil).LiftRestrictions), ctx, chatID, userID, restrictions)
+}
+
+// ListAdminIDs mocks base method.
+func (m *MockChatRepositoryInterface) ListAdminIDs(ctx context.Context, chatID int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAdminIDs", ctx, chatID)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAdminIDs indicates an expected call of ListAdminIDs.
+func (mr *MockChatRepositoryInterfaceMockRecorder) ListAdminIDs(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAdminIDs", reflect.TypeOf((*MockChatRepositoryInterface)(nil).ListAdminIDs), ctx, chatID)
+}
+
+// ListBans mocks base method.
+func (m *MockChatRepositoryInterface) ListBans(ctx context.Context, chatID int64) ([]entity.ChatBan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBans", ctx, chatID)
+	ret0, _ := ret[0].([]entity.ChatBan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBans indicates an expected call of ListBans.
+func (mr *MockChatRepositoryInterfaceMockRecorder) ListBans(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBans", reflect.TypeOf((*MockChatRepositoryInterface)(nil).ListBans), ctx, chatID)
+}
+
+// ListJoinRequests mocks base method.
+func (m *MockChatRepositoryInterface) ListJoinRequests(ctx context.Context, chatID int64) ([]entity.JoinRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJoinRequests", ctx, chatID)
+	ret0, _ := ret[0].([]entity.JoinRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJoinRequests indicates an expected call of ListJoinRequests.
+func (mr *MockChatRepositoryInterfaceMockRecorder) ListJoinRequests(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJoinRequests", reflect.TypeOf((*MockChatRepositoryInterface)(nil).ListJoinRequests), ctx, chatID)
+}
+
+// ListMemberIDs mocks base method.
+func (m *MockChatRepositoryInterface) ListMemberIDs(ctx context.Context, chatID int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMemberIDs", ctx, chatID)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMemberIDs indicates an expected call of ListMemberIDs.
+func (mr *MockChatRepositoryInterfaceMockRecorder) ListMemberIDs(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMemberIDs", reflect.TypeOf((*MockChatRepositoryInterface)(nil).ListMemberIDs), ctx, chatID)
+}
+
 // ListOfChats mocks base method.
-func (m *MockChatRepositoryInterface) ListOfChats(ctx context.Context, userID int64) ([]entity.Chat, error) {
+func (m *MockChatRepositoryInterface) ListOfChats(ctx context.Context, userID int64, beforeAt time.Time, beforeID int64, limit int) ([]entity.Chat, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListOfChats", ctx, userID)
+	ret := m.ctrl.Call(m, "ListOfChats", ctx, userID, beforeAt, beforeID, limit)
 	ret0, _ := ret[0].([]entity.Chat)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListOfChats indicates an expected call of ListOfChats.
-func (mr *MockChatRepositoryInterfaceMockRecorder) ListOfChats(ctx, userID any) *gomock.Call {
+func (mr *MockChatRepositoryInterfaceMockRecorder) ListOfChats(ctx, userID, beforeAt, beforeID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOfChats", reflect.TypeOf((*MockChatRepositoryInterface)(nil).ListOfChats), ctx, userID, beforeAt, beforeID, limit)
+}
+
+// ListReadStates mocks base method.
+func (m *MockChatRepositoryInterface) ListReadStates(ctx context.Context, userID int64) ([]entity.ReadState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReadStates", ctx, userID)
+	ret0, _ := ret[0].([]entity.ReadState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReadStates indicates an expected call of ListReadStates.
+func (mr *MockChatRepositoryInterfaceMockRecorder) ListReadStates(ctx, userID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOfChats", reflect.TypeOf((*MockChatRepositoryInterface)(nil).ListOfChats), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReadStates", reflect.TypeOf((*MockChatRepositoryInterface)(nil).ListReadStates), ctx, userID)
+}
+
+// ListRestrictions mocks base method.
+func (m *MockChatRepositoryInterface) ListRestrictions(ctx context.Context, chatID int64) ([]entity.MemberRestriction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRestrictions", ctx, chatID)
+	ret0, _ := ret[0].([]entity.MemberRestriction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRestrictions indicates an expected call of ListRestrictions.
+func (mr *MockChatRepositoryInterfaceMockRecorder) ListRestrictions(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRestrictions", reflect.TypeOf((*MockChatRepositoryInterface)(nil).ListRestrictions), ctx, chatID)
+}
+
+// MarkRead mocks base method.
+func (m *MockChatRepositoryInterface) MarkRead(ctx context.Context, chatID, userID int64, messageID string) (entity.ReadState, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", ctx, chatID, userID, messageID)
+	ret0, _ := ret[0].(entity.ReadState)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockChatRepositoryInterfaceMockRecorder) MarkRead(ctx, chatID, userID, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockChatRepositoryInterface)(nil).MarkRead), ctx, chatID, userID, messageID)
+}
+
+// ReleaseInviteUse mocks base method.
+func (m *MockChatRepositoryInterface) ReleaseInviteUse(ctx context.Context, inviteID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseInviteUse", ctx, inviteID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseInviteUse indicates an expected call of ReleaseInviteUse.
+func (mr *MockChatRepositoryInterfaceMockRecorder) ReleaseInviteUse(ctx, inviteID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseInviteUse", reflect.TypeOf((*MockChatRepositoryInterface)(nil).ReleaseInviteUse), ctx, inviteID)
 }
 
 // RemoveMember mocks base method.
@@ -159,6 +504,134 @@ func (mr *MockChatRepositoryInterfaceMockRecorder) RemoveMember(ctx, chatID, use
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockChatRepositoryInterface)(nil).RemoveMember), ctx, chatID, userID)
 }
 
+// ReopenJoinRequest mocks base method.
+func (m *MockChatRepositoryInterface) ReopenJoinRequest(ctx context.Context, requestID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReopenJoinRequest", ctx, requestID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReopenJoinRequest indicates an expected call of ReopenJoinRequest.
+func (mr *MockChatRepositoryInterfaceMockRecorder) ReopenJoinRequest(ctx, requestID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReopenJoinRequest", reflect.TypeOf((*MockChatRepositoryInterface)(nil).ReopenJoinRequest), ctx, requestID)
+}
+
+// RestrictMember mocks base method.
+func (m *MockChatRepositoryInterface) RestrictMember(ctx context.Context, restrictions []entity.MemberRestriction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestrictMember", ctx, restrictions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestrictMember indicates an expected call of RestrictMember.
+func (mr *MockChatRepositoryInterfaceMockRecorder) RestrictMember(ctx, restrictions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestrictMember", reflect.TypeOf((*MockChatRepositoryInterface)(nil).RestrictMember), ctx, restrictions)
+}
+
+// SearchPublicChats mocks base method.
+func (m *MockChatRepositoryInterface) SearchPublicChats(ctx context.Context, query string, beforeID int64, limit int) ([]entity.Chat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPublicChats", ctx, query, beforeID, limit)
+	ret0, _ := ret[0].([]entity.Chat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPublicChats indicates an expected call of SearchPublicChats.
+func (mr *MockChatRepositoryInterfaceMockRecorder) SearchPublicChats(ctx, query, beforeID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPublicChats", reflect.TypeOf((*MockChatRepositoryInterface)(nil).SearchPublicChats), ctx, query, beforeID, limit)
+}
+
+// SetMemberRole mocks base method.
+func (m *MockChatRepositoryInterface) SetMemberRole(ctx context.Context, chatID, userID int64, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMemberRole", ctx, chatID, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMemberRole indicates an expected call of SetMemberRole.
+func (mr *MockChatRepositoryInterfaceMockRecorder) SetMemberRole(ctx, chatID, userID, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMemberRole", reflect.TypeOf((*MockChatRepositoryInterface)(nil).SetMemberRole), ctx, chatID, userID, role)
+}
+
+// SetPinnedMessage mocks base method.
+func (m *MockChatRepositoryInterface) SetPinnedMessage(ctx context.Context, chatID int64, messageID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPinnedMessage", ctx, chatID, messageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPinnedMessage indicates an expected call of SetPinnedMessage.
+func (mr *MockChatRepositoryInterfaceMockRecorder) SetPinnedMessage(ctx, chatID, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPinnedMessage", reflect.TypeOf((*MockChatRepositoryInterface)(nil).SetPinnedMessage), ctx, chatID, messageID)
+}
+
+// TransferOwnership mocks base method.
+func (m *MockChatRepositoryInterface) TransferOwnership(ctx context.Context, chatID, fromID, toID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferOwnership", ctx, chatID, fromID, toID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransferOwnership indicates an expected call of TransferOwnership.
+func (mr *MockChatRepositoryInterfaceMockRecorder) TransferOwnership(ctx, chatID, fromID, toID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferOwnership", reflect.TypeOf((*MockChatRepositoryInterface)(nil).TransferOwnership), ctx, chatID, fromID, toID)
+}
+
+// UnbanMember mocks base method.
+func (m *MockChatRepositoryInterface) UnbanMember(ctx context.Context, chatID, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanMember", ctx, chatID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbanMember indicates an expected call of UnbanMember.
+func (mr *MockChatRepositoryInterfaceMockRecorder) UnbanMember(ctx, chatID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanMember", reflect.TypeOf((*MockChatRepositoryInterface)(nil).UnbanMember), ctx, chatID, userID)
+}
+
+// UpdateChat mocks base method.
+func (m *MockChatRepositoryInterface) UpdateChat(ctx context.Context, chatID int64, upd entity.ChatUpdate) (entity.Chat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateChat", ctx, chatID, upd)
+	ret0, _ := ret[0].(entity.Chat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateChat indicates an expected call of UpdateChat.
+func (mr *MockChatRepositoryInterfaceMockRecorder) UpdateChat(ctx, chatID, upd any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChat", reflect.TypeOf((*MockChatRepositoryInterface)(nil).UpdateChat), ctx, chatID, upd)
+}
+
+// UpdateMemberSettings mocks base method.
+func (m *MockChatRepositoryInterface) UpdateMemberSettings(ctx context.Context, chatID, userID int64, settings entity.ChatSettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMemberSettings", ctx, chatID, userID, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMemberSettings indicates an expected call of UpdateMemberSettings.
+func (mr *MockChatRepositoryInterfaceMockRecorder) UpdateMemberSettings(ctx, chatID, userID, settings any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberSettings", reflect.TypeOf((*MockChatRepositoryInterface)(nil).UpdateMemberSettings), ctx, chatID, userID, settings)
+}
+
 // MockMessageRepositoryInterface is a mock of MessageRepositoryInterface interface.
 type MockMessageRepositoryInterface struct {
 	ctrl     *gomock.Controller
@@ -183,6 +656,36 @@ func (m *MockMessageRepositoryInterface) EXPECT() *MockMessageRepositoryInterfac
 	return m.recorder
 }
 
+// CountUnread mocks base method.
+func (m *MockMessageRepositoryInterface) CountUnread(ctx context.Context, userID int64, states []entity.ReadState) (map[int64]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnread", ctx, userID, states)
+	ret0, _ := ret[0].(map[int64]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnread indicates an expected call of CountUnread.
+func (mr *MockMessageRepositoryInterfaceMockRecorder) CountUnread(ctx, userID, states any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnread", reflect.TypeOf((*MockMessageRepositoryInterface)(nil).CountUnread), ctx, userID, states)
+}
+
+// GetMessage mocks base method.
+func (m *MockMessageRepositoryInterface) GetMessage(ctx context.Context, chatID int64, msgID string) (entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessage", ctx, chatID, msgID)
+	ret0, _ := ret[0].(entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessage indicates an expected call of GetMessage.
+func (mr *MockMessageRepositoryInterfaceMockRecorder) GetMessage(ctx, chatID, msgID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessage", reflect.TypeOf((*MockMessageRepositoryInterface)(nil).GetMessage), ctx, chatID, msgID)
+}
+
 // GetMessages mocks base method.
 func (m *MockMessageRepositoryInterface) GetMessages(ctx context.Context, chatID int64, anchorTime time.Time, anchorID string, limit int64) ([]entity.Message, error) {
 	m.ctrl.T.Helper()
@@ -198,6 +701,21 @@ func (mr *MockMessageRepositoryInterfaceMockRecorder) GetMessages(ctx, chatID, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessages", reflect.TypeOf((*MockMessageRepositoryInterface)(nil).GetMessages), ctx, chatID, anchorTime, anchorID, limit)
 }
 
+// SaveMessage mocks base method.
+func (m *MockMessageRepositoryInterface) SaveMessage(ctx context.Context, msg any) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMessage", ctx, msg)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveMessage indicates an expected call of SaveMessage.
+func (mr *MockMessageRepositoryInterfaceMockRecorder) SaveMessage(ctx, msg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMessage", reflect.TypeOf((*MockMessageRepositoryInterface)(nil).SaveMessage), ctx, msg)
+}
+
 // MockUserInterface is a mock of UserInterface interface.
 type MockUserInterface struct {
 	ctrl     *gomock.Controller
@@ -235,3 +753,39 @@ func (mr *MockUserInterfaceMockRecorder) CheckUserExists(ctx, userID any) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUserExists", reflect.TypeOf((*MockUserInterface)(nil).CheckUserExists), ctx, userID)
 }
+
+// MockAuditRecorder is a mock of AuditRecorder interface.
+type MockAuditRecorder struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRecorderMockRecorder
+	isgomock struct{}
+}
+
+// MockAuditRecorderMockRecorder is the mock recorder for MockAuditRecorder.
+type MockAuditRecorderMockRecorder struct {
+	mock *MockAuditRecorder
+}
+
+// NewMockAuditRecorder creates a new mock instance.
+func NewMockAuditRecorder(ctrl *gomock.Controller) *MockAuditRecorder {
+	mock := &MockAuditRecorder{ctrl: ctrl}
+	mock.recorder = &MockAuditRecorderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRecorder) EXPECT() *MockAuditRecorderMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method.
+func (m *MockAuditRecorder) Record(ctx context.Context, event entity.AuditEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", ctx, event)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockAuditRecorderMockRecorder) Record(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditRecorder)(nil).Record), ctx, event)
+}
@@ -35,7 +35,7 @@ func TestCreateChat(t *testing.T) {
 			isPrivate: false,
 			members:   members,
 			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
-				chatRepo.EXPECT().CreateChat(gomock.Any(), title, false, members).Return(int64(1), nil)
+				chatRepo.EXPECT().CreateChat(gomock.Any(), int64(1), title, false, members).Return(int64(1), nil)
 			},
 			expectedChat: dom.Chat{
 				ID:        1,
@@ -75,7 +75,7 @@ func TestCreateChat(t *testing.T) {
 			isPrivate: false,
 			members:   members,
 			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
-				chatRepo.EXPECT().CreateChat(gomock.Any(), title, false, members).Return(int64(0), customerrors.ErrDatabase)
+				chatRepo.EXPECT().CreateChat(gomock.Any(), int64(1), title, false, members).Return(int64(0), customerrors.ErrDatabase)
 			},
 			expectedChat:  dom.Chat{},
 			expectedError: customerrors.ErrDatabase,
@@ -102,8 +102,8 @@ func TestCreateChat(t *testing.T) {
 			if tt.mockBehavior != nil {
 				tt.mockBehavior(mockChatRepo)
 			}
-			ChatService := service.NewChatService(nil, mockChatRepo, nil)
-			chat, err := ChatService.CreateChat(context.Background(), tt.title, tt.isPrivate, tt.members)
+			ChatService := service.NewChatService(nil, mockChatRepo, nil, nil, nil, nil)
+			chat, err := ChatService.CreateChat(context.Background(), 1, tt.title, tt.isPrivate, tt.members)
 
 			if !assert.Equal(t, tt.expectedChat, chat) {
 				t.Errorf("expected chat: %v, got: %v", tt.expectedChat, chat)
@@ -124,6 +124,7 @@ func TestCreateChat(t *testing.T) {
 
 func TestDeleteChat(t *testing.T) {
 	chatID := int64(1)
+	userID := int64(1)
 	tests := []struct {
 		name          string
 		chatID        int64
@@ -136,11 +137,32 @@ func TestDeleteChat(t *testing.T) {
 			chatID: chatID,
 			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
 				chatRepo.EXPECT().CheckIfChatExists(gomock.Any(), chatID).Return(true, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return(dom.ChatRoleOwner, nil)
 				chatRepo.EXPECT().DeleteChat(gomock.Any(), chatID).Return(nil)
 			},
 			expectedError: nil,
 			isErr:         false,
 		},
+		{
+			name:   "Admin cannot delete chat",
+			chatID: chatID,
+			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
+				chatRepo.EXPECT().CheckIfChatExists(gomock.Any(), chatID).Return(true, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return(dom.ChatRoleAdmin, nil)
+			},
+			expectedError: customerrors.ErrChatPermissionDenied,
+			isErr:         true,
+		},
+		{
+			name:   "Non-member cannot delete chat",
+			chatID: chatID,
+			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
+				chatRepo.EXPECT().CheckIfChatExists(gomock.Any(), chatID).Return(true, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return("", customerrors.ErrUserNotMemberOfChat)
+			},
+			expectedError: customerrors.ErrUserNotMemberOfChat,
+			isErr:         true,
+		},
 		{
 			name:   "Chat does not exist",
 			chatID: chatID,
@@ -164,6 +186,7 @@ func TestDeleteChat(t *testing.T) {
 			chatID: chatID,
 			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
 				chatRepo.EXPECT().CheckIfChatExists(gomock.Any(), chatID).Return(true, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return(dom.ChatRoleOwner, nil)
 				chatRepo.EXPECT().DeleteChat(gomock.Any(), chatID).Return(customerrors.ErrDatabase)
 			},
 			expectedError: customerrors.ErrDatabase,
@@ -185,8 +208,8 @@ func TestDeleteChat(t *testing.T) {
 			if tt.mockBehavior != nil {
 				tt.mockBehavior(mockChatRepo)
 			}
-			ChatService := service.NewChatService(nil, mockChatRepo, nil)
-			err := ChatService.DeleteChat(context.Background(), tt.chatID)
+			ChatService := service.NewChatService(nil, mockChatRepo, nil, nil, nil, nil)
+			err := ChatService.DeleteChat(context.Background(), tt.chatID, userID)
 			if tt.isErr {
 				if tt.expectedError != nil {
 					assert.ErrorIs(t, err, tt.expectedError)
@@ -320,11 +343,13 @@ func TestAddMembers(t *testing.T) {
 			members: testMembers,
 			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface, userSvc *mock.MockUserInterface) {
 				userSvc.EXPECT().CheckUserExists(gomock.Any(), userID).Return(true)
-				chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), chatID, userID).Return(true, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return(dom.ChatRoleAdmin, nil)
+				chatRepo.EXPECT().IsRestricted(gomock.Any(), chatID, userID, dom.RestrictAddMembers).Return(false, nil)
 				userSvc.EXPECT().CheckUserExists(gomock.Any(), testMembers[0]).Return(true)
 				chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), chatID, testMembers[0]).Return(false, nil)
 				userSvc.EXPECT().CheckUserExists(gomock.Any(), testMembers[1]).Return(true)
 				chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), chatID, testMembers[1]).Return(false, nil)
+				chatRepo.EXPECT().BannedUsers(gomock.Any(), chatID, testMembers).Return([]int64{}, nil)
 				chatRepo.EXPECT().AddMembers(gomock.Any(), chatID, testMembers).Return(nil)
 			},
 			expectedError: nil,
@@ -349,11 +374,23 @@ func TestAddMembers(t *testing.T) {
 			members: testMembers,
 			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface, userSvc *mock.MockUserInterface) {
 				userSvc.EXPECT().CheckUserExists(gomock.Any(), userID).Return(true)
-				chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), chatID, userID).Return(false, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return("", customerrors.ErrUserNotMemberOfChat)
 			},
 			expectedError: customerrors.ErrUserNotMemberOfChat,
 			isErr:         true,
 		},
+		{
+			name:    "Plain member cannot add members",
+			chatID:  chatID,
+			userID:  userID,
+			members: testMembers,
+			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface, userSvc *mock.MockUserInterface) {
+				userSvc.EXPECT().CheckUserExists(gomock.Any(), userID).Return(true)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return(dom.ChatRoleMember, nil)
+			},
+			expectedError: customerrors.ErrChatPermissionDenied,
+			isErr:         true,
+		},
 		{
 			name:    "New member not found",
 			chatID:  chatID,
@@ -361,7 +398,8 @@ func TestAddMembers(t *testing.T) {
 			members: testMembers,
 			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface, userSvc *mock.MockUserInterface) {
 				userSvc.EXPECT().CheckUserExists(gomock.Any(), userID).Return(true)
-				chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), chatID, userID).Return(true, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return(dom.ChatRoleAdmin, nil)
+				chatRepo.EXPECT().IsRestricted(gomock.Any(), chatID, userID, dom.RestrictAddMembers).Return(false, nil)
 				userSvc.EXPECT().CheckUserExists(gomock.Any(), testMembers[0]).Return(false)
 			},
 			expectedError: customerrors.ErrUserNotFound,
@@ -374,7 +412,8 @@ func TestAddMembers(t *testing.T) {
 			members: testMembers,
 			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface, userSvc *mock.MockUserInterface) {
 				userSvc.EXPECT().CheckUserExists(gomock.Any(), userID).Return(true)
-				chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), chatID, userID).Return(true, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return(dom.ChatRoleAdmin, nil)
+				chatRepo.EXPECT().IsRestricted(gomock.Any(), chatID, userID, dom.RestrictAddMembers).Return(false, nil)
 				userSvc.EXPECT().CheckUserExists(gomock.Any(), testMembers[0]).Return(true)
 				chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), chatID, testMembers[0]).Return(true, nil)
 			},
@@ -390,7 +429,7 @@ func TestAddMembers(t *testing.T) {
 			if tt.mockBehavior != nil {
 				tt.mockBehavior(mockChatRepo, mockUserSvc)
 			}
-			ChatService := service.NewChatService(mockUserSvc, mockChatRepo, nil)
+			ChatService := service.NewChatService(mockUserSvc, mockChatRepo, nil, nil, nil, nil)
 			err := ChatService.AddMembers(context.Background(), tt.chatID, tt.userID, tt.members)
 			if tt.isErr {
 				if tt.expectedError != nil {
@@ -405,57 +444,109 @@ func TestAddMembers(t *testing.T) {
 
 }
 
-func TestRemoveMember(t *testing.T) {
+func TestKickAndLeave(t *testing.T) {
 	chatID := int64(1)
-	userID := int64(1)
+	actorID := int64(1)
+	userID := int64(2)
 	tests := []struct {
 		name          string
 		chatID        int64
+		actorID       int64
 		userID        int64
 		mockBehavior  func(chatRepo *mock.MockChatRepositoryInterface)
 		expectedError error
-		isErr         bool
 	}{
 		{
-			name:   "Successful remove member",
-			chatID: chatID,
-			userID: userID,
+			name:    "Admin removes member",
+			chatID:  chatID,
+			actorID: actorID,
+			userID:  userID,
 			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
-				chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), chatID, userID).Return(true, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return(dom.ChatRoleMember, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, actorID).Return(dom.ChatRoleAdmin, nil)
 				chatRepo.EXPECT().RemoveMember(gomock.Any(), chatID, userID).Return(nil)
 			},
-			expectedError: nil,
-			isErr:         false,
 		},
 		{
-			name:   "User is not member of chat",
-			chatID: chatID,
-			userID: userID,
+			name:    "Owner removes admin",
+			chatID:  chatID,
+			actorID: actorID,
+			userID:  userID,
 			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
-				chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), chatID, userID).Return(false, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return(dom.ChatRoleAdmin, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, actorID).Return(dom.ChatRoleOwner, nil)
+				chatRepo.EXPECT().RemoveMember(gomock.Any(), chatID, userID).Return(nil)
 			},
-			expectedError: customerrors.ErrUserNotMemberOfChat,
-			isErr:         true,
 		},
 		{
-			name:   "Invalid input",
-			chatID: -1,
-			userID: 0,
+			name:    "Admin cannot remove admin",
+			chatID:  chatID,
+			actorID: actorID,
+			userID:  userID,
 			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
-
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return(dom.ChatRoleAdmin, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, actorID).Return(dom.ChatRoleAdmin, nil)
 			},
+			expectedError: customerrors.ErrChatPermissionDenied,
+		},
+		{
+			name:    "Plain member cannot remove others",
+			chatID:  chatID,
+			actorID: actorID,
+			userID:  userID,
+			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return(dom.ChatRoleMember, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, actorID).Return(dom.ChatRoleMember, nil)
+			},
+			expectedError: customerrors.ErrChatPermissionDenied,
+		},
+		{
+			name:    "Member leaves",
+			chatID:  chatID,
+			actorID: userID,
+			userID:  userID,
+			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return(dom.ChatRoleMember, nil)
+				chatRepo.EXPECT().RemoveMember(gomock.Any(), chatID, userID).Return(nil)
+			},
+		},
+		{
+			name:    "Owner cannot leave",
+			chatID:  chatID,
+			actorID: actorID,
+			userID:  actorID,
+			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, actorID).Return(dom.ChatRoleOwner, nil)
+			},
+			expectedError: customerrors.ErrChatPermissionDenied,
+		},
+		{
+			name:    "User is not member of chat",
+			chatID:  chatID,
+			actorID: actorID,
+			userID:  userID,
+			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return("", customerrors.ErrUserNotMemberOfChat)
+			},
+			expectedError: customerrors.ErrUserNotMemberOfChat,
+		},
+		{
+			name:          "Invalid input",
+			chatID:        -1,
+			actorID:       actorID,
+			userID:        0,
+			mockBehavior:  func(chatRepo *mock.MockChatRepositoryInterface) {},
 			expectedError: customerrors.ErrInvalidInput,
-			isErr:         true,
 		},
 		{
-			name:   "Repository error during membership check",
-			chatID: chatID,
-			userID: userID,
+			name:    "Repository error during membership check",
+			chatID:  chatID,
+			actorID: actorID,
+			userID:  userID,
 			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
-				chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), chatID, userID).Return(false, customerrors.ErrFailedToCheck)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return("", customerrors.ErrDatabase)
 			},
 			expectedError: customerrors.ErrFailedToCheck,
-			isErr:         true,
 		},
 	}
 	for _, tt := range tests {
@@ -465,15 +556,174 @@ func TestRemoveMember(t *testing.T) {
 			if tt.mockBehavior != nil {
 				tt.mockBehavior(mockChatRepo)
 			}
-			ChatService := service.NewChatService(nil, mockChatRepo, nil)
-			err := ChatService.RemoveMember(context.Background(), tt.chatID, tt.userID)
-			if tt.isErr {
-				if tt.expectedError != nil {
-					assert.ErrorIs(t, err, tt.expectedError)
-				} else {
-					assert.NoError(t, err)
-				}
+			ChatService := service.NewChatService(nil, mockChatRepo, nil, nil, nil, nil)
+			var err error
+			if tt.actorID == tt.userID {
+				err = ChatService.LeaveChat(context.Background(), tt.chatID, tt.userID)
+			} else {
+				err = ChatService.KickMember(context.Background(), tt.chatID, tt.actorID, tt.userID)
+			}
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSetMemberRole(t *testing.T) {
+	chatID := int64(1)
+	ownerID := int64(1)
+	userID := int64(2)
+	tests := []struct {
+		name          string
+		actorID       int64
+		role          string
+		mockBehavior  func(chatRepo *mock.MockChatRepositoryInterface)
+		expectedError error
+	}{
+		{
+			name:    "Owner promotes member",
+			actorID: ownerID,
+			role:    dom.ChatRoleAdmin,
+			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, ownerID).Return(dom.ChatRoleOwner, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return(dom.ChatRoleMember, nil)
+				chatRepo.EXPECT().SetMemberRole(gomock.Any(), chatID, userID, dom.ChatRoleAdmin).Return(nil)
+			},
+		},
+		{
+			name:    "Admin cannot change roles",
+			actorID: 3,
+			role:    dom.ChatRoleAdmin,
+			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, int64(3)).Return(dom.ChatRoleAdmin, nil)
+			},
+			expectedError: customerrors.ErrChatPermissionDenied,
+		},
+		{
+			name:          "Owner role cannot be granted",
+			actorID:       ownerID,
+			role:          dom.ChatRoleOwner,
+			mockBehavior:  func(chatRepo *mock.MockChatRepositoryInterface) {},
+			expectedError: customerrors.ErrInvalidInput,
+		},
+		{
+			name:          "Own role cannot be changed",
+			actorID:       userID,
+			role:          dom.ChatRoleMember,
+			mockBehavior:  func(chatRepo *mock.MockChatRepositoryInterface) {},
+			expectedError: customerrors.ErrInvalidInput,
+		},
+		{
+			name:    "Target is not a member",
+			actorID: ownerID,
+			role:    dom.ChatRoleAdmin,
+			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, ownerID).Return(dom.ChatRoleOwner, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, userID).Return("", customerrors.ErrUserNotMemberOfChat)
+			},
+			expectedError: customerrors.ErrUserNotMemberOfChat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockChatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+			tt.mockBehavior(mockChatRepo)
+			ChatService := service.NewChatService(nil, mockChatRepo, nil, nil, nil, nil)
+			err := ChatService.SetMemberRole(context.Background(), chatID, tt.actorID, userID, tt.role)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTransferOwnership(t *testing.T) {
+	chatID := int64(1)
+	ownerID := int64(1)
+	newOwnerID := int64(2)
+	tests := []struct {
+		name          string
+		actorID       int64
+		mockBehavior  func(chatRepo *mock.MockChatRepositoryInterface)
+		expectedError error
+	}{
+		{
+			name:    "Owner transfers ownership",
+			actorID: ownerID,
+			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, ownerID).Return(dom.ChatRoleOwner, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, newOwnerID).Return(dom.ChatRoleMember, nil)
+				chatRepo.EXPECT().TransferOwnership(gomock.Any(), chatID, ownerID, newOwnerID).Return(nil)
+			},
+		},
+		{
+			name:    "Admin cannot transfer ownership",
+			actorID: 3,
+			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, int64(3)).Return(dom.ChatRoleAdmin, nil)
+			},
+			expectedError: customerrors.ErrChatPermissionDenied,
+		},
+		{
+			name:    "New owner must be a member",
+			actorID: ownerID,
+			mockBehavior: func(chatRepo *mock.MockChatRepositoryInterface) {
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, ownerID).Return(dom.ChatRoleOwner, nil)
+				chatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, newOwnerID).Return("", customerrors.ErrUserNotMemberOfChat)
+			},
+			expectedError: customerrors.ErrUserNotMemberOfChat,
+		},
+		{
+			name:          "Owner cannot transfer to themselves",
+			actorID:       newOwnerID,
+			mockBehavior:  func(chatRepo *mock.MockChatRepositoryInterface) {},
+			expectedError: customerrors.ErrInvalidInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockChatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+			tt.mockBehavior(mockChatRepo)
+			ChatService := service.NewChatService(nil, mockChatRepo, nil, nil, nil, nil)
+			err := ChatService.TransferOwnership(context.Background(), chatID, tt.actorID, newOwnerID)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
 			}
 		})
 	}
 }
+
+func TestPinMessage(t *testing.T) {
+	chatID := int64(1)
+	adminID := int64(1)
+	memberID := int64(2)
+	messageID := "651eb1234567890abcdef123"
+
+	ctrl := gomock.NewController(t)
+	mockChatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	mockMsgRepo := mock.NewMockMessageRepositoryInterface(ctrl)
+	ChatService := service.NewChatService(nil, mockChatRepo, mockMsgRepo, nil, nil, nil)
+	mockChatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, adminID).Return(dom.ChatRoleAdmin, nil).AnyTimes()
+	mockChatRepo.EXPECT().GetMemberRole(gomock.Any(), chatID, memberID).Return(dom.ChatRoleMember, nil).AnyTimes()
+
+	mockMsgRepo.EXPECT().GetMessage(gomock.Any(), chatID, messageID).Return(dom.Message{ChatID: chatID}, nil)
+	mockChatRepo.EXPECT().SetPinnedMessage(gomock.Any(), chatID, messageID).Return(nil)
+	assert.NoError(t, ChatService.PinMessage(context.Background(), chatID, adminID, messageID))
+	// Missing, or in another chat.
+	mockMsgRepo.EXPECT().GetMessage(gomock.Any(), chatID, "651eb1234567890abcdef999").Return(dom.Message{}, customerrors.ErrMessageDoesNotExists)
+	assert.ErrorIs(t, ChatService.PinMessage(context.Background(), chatID, adminID, "651eb1234567890abcdef999"), customerrors.ErrMessageDoesNotExists)
+	assert.ErrorIs(t, ChatService.PinMessage(context.Background(), chatID, memberID, messageID), customerrors.ErrChatPermissionDenied)
+	assert.ErrorIs(t, ChatService.PinMessage(context.Background(), chatID, adminID, "not-an-id"), customerrors.ErrInvalidInput)
+
+	mockChatRepo.EXPECT().SetPinnedMessage(gomock.Any(), chatID, "").Return(nil)
+	assert.NoError(t, ChatService.UnpinMessage(context.Background(), chatID, adminID))
+}
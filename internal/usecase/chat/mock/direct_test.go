@@ -0,0 +1,42 @@
+package mock_test
+
+import (
+	"context"
+	"testing"
+
+	dom "main/internal/domain/entity"
+	service "main/internal/usecase/chat"
+	"main/internal/usecase/chat/mock"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestOpenDirectChat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	dm := dom.Chat{ID: 5, Title: "bob", Type: dom.ChatTypeDirect}
+	chatRepo.EXPECT().GetOrCreateDirectChat(gomock.Any(), int64(1), int64(2)).Return(dm, true, nil)
+	chat, created, err := ChatService.OpenDirectChat(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, dm, chat)
+
+	chatRepo.EXPECT().GetOrCreateDirectChat(gomock.Any(), int64(1), int64(99)).Return(dom.Chat{}, false, customerrors.ErrUserNotFound)
+	_, _, err = ChatService.OpenDirectChat(ctx, 1, 99)
+	assert.ErrorIs(t, err, customerrors.ErrUserNotFound)
+
+	_, _, err = ChatService.OpenDirectChat(ctx, 1, 1)
+	assert.ErrorIs(t, err, customerrors.ErrInvalidInput)
+	_, err = ChatService.GetDirectChat(ctx, 1, 0)
+	assert.ErrorIs(t, err, customerrors.ErrInvalidInput)
+
+	chatRepo.EXPECT().FindDirectChat(gomock.Any(), int64(2), int64(3)).Return(dom.Chat{}, customerrors.ErrNotFound)
+	_, err = ChatService.GetDirectChat(ctx, 2, 3)
+	assert.ErrorIs(t, err, customerrors.ErrNotFound)
+}
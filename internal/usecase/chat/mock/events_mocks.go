@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: events.go
+//
+// Generated by this command:
+//
+//	mockgen -source=events.go -destination=mock/events_mocks.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	events "main/internal/domain/events"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMembershipPublisher is a mock of MembershipPublisher interface.
+type MockMembershipPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockMembershipPublisherMockRecorder
+	isgomock struct{}
+}
+
+// MockMembershipPublisherMockRecorder is the mock recorder for MockMembershipPublisher.
+type MockMembershipPublisherMockRecorder struct {
+	mock *MockMembershipPublisher
+}
+
+// NewMockMembershipPublisher creates a new mock instance.
+func NewMockMembershipPublisher(ctrl *gomock.Controller) *MockMembershipPublisher {
+	mock := &MockMembershipPublisher{ctrl: ctrl}
+	mock.recorder = &MockMembershipPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMembershipPublisher) EXPECT() *MockMembershipPublisherMockRecorder {
+	return m.recorder
+}
+
+// SendMembershipChanged mocks base method.
+func (m *MockMembershipPublisher) SendMembershipChanged(ctx context.Context, event events.MembershipChanged) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMembershipChanged", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMembershipChanged indicates an expected call of SendMembershipChanged.
+func (mr *MockMembershipPublisherMockRecorder) SendMembershipChanged(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMembershipChanged", reflect.TypeOf((*MockMembershipPublisher)(nil).SendMembershipChanged), ctx, event)
+}
+
+// MockMessagePublisher is a mock of MessagePublisher interface.
+type MockMessagePublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessagePublisherMockRecorder
+	isgomock struct{}
+}
+
+// MockMessagePublisherMockRecorder is the mock recorder for MockMessagePublisher.
+type MockMessagePublisherMockRecorder struct {
+	mock *MockMessagePublisher
+}
+
+// NewMockMessagePublisher creates a new mock instance.
+func NewMockMessagePublisher(ctrl *gomock.Controller) *MockMessagePublisher {
+	mock := &MockMessagePublisher{ctrl: ctrl}
+	mock.recorder = &MockMessagePublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessagePublisher) EXPECT() *MockMessagePublisherMockRecorder {
+	return m.recorder
+}
+
+// SendMessageCreated mocks base method.
+func (m *MockMessagePublisher) SendMessageCreated(ctx context.Context, event events.MessageCreated) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessageCreated", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMessageCreated indicates an expected call of SendMessageCreated.
+func (mr *MockMessagePublisherMockRecorder) SendMessageCreated(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessageCreated", reflect.TypeOf((*MockMessagePublisher)(nil).SendMessageCreated), ctx, event)
+}
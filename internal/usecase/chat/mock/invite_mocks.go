@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: invite.go
+//
+// Generated by this command:
+//
+//	mockgen -source=invite.go -destination=mock/invite_mocks.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	entity "main/internal/domain/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockInviteRepository is a mock of InviteRepository interface.
+type MockInviteRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockInviteRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockInviteRepositoryMockRecorder is the mock recorder for MockInviteRepository.
+type MockInviteRepositoryMockRecorder struct {
+	mock *MockInviteRepository
+}
+
+// NewMockInviteRepository creates a new mock instance.
+func NewMockInviteRepository(ctrl *gomock.Controller) *MockInviteRepository {
+	mock := &MockInviteRepository{ctrl: ctrl}
+	mock.recorder = &MockInviteRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInviteRepository) EXPECT() *MockInviteRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimInviteUse mocks base method.
+func (m *MockInviteRepository) ClaimInviteUse(ctx context.Context, inviteID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimInviteUse", ctx, inviteID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimInviteUse indicates an expected call of ClaimInviteUse.
+func (mr *MockInviteRepositoryMockRecorder) ClaimInviteUse(ctx, inviteID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimInviteUse", reflect.TypeOf((*MockInviteRepository)(nil).ClaimInviteUse), ctx, inviteID)
+}
+
+// CreateInvite mocks base method.
+func (m *MockInviteRepository) CreateInvite(ctx context.Context, invite entity.ChatInvite) (entity.ChatInvite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvite", ctx, invite)
+	ret0, _ := ret[0].(entity.ChatInvite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvite indicates an expected call of CreateInvite.
+func (mr *MockInviteRepositoryMockRecorder) CreateInvite(ctx, invite any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvite", reflect.TypeOf((*MockInviteRepository)(nil).CreateInvite), ctx, invite)
+}
+
+// GetInviteByCode mocks base method.
+func (m *MockInviteRepository) GetInviteByCode(ctx context.Context, code string) (entity.ChatInvite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInviteByCode", ctx, code)
+	ret0, _ := ret[0].(entity.ChatInvite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInviteByCode indicates an expected call of GetInviteByCode.
+func (mr *MockInviteRepositoryMockRecorder) GetInviteByCode(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInviteByCode", reflect.TypeOf((*MockInviteRepository)(nil).GetInviteByCode), ctx, code)
+}
+
+// ListInvites mocks base method.
+func (m *MockInviteRepository) ListInvites(ctx context.Context, chatID int64) ([]entity.ChatInvite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvites", ctx, chatID)
+	ret0, _ := ret[0].([]entity.ChatInvite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvites indicates an expected call of ListInvites.
+func (mr *MockInviteRepositoryMockRecorder) ListInvites(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvites", reflect.TypeOf((*MockInviteRepository)(nil).ListInvites), ctx, chatID)
+}
+
+// ReleaseInviteUse mocks base method.
+func (m *MockInviteRepository) ReleaseInviteUse(ctx context.Context, inviteID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseInviteUse", ctx, inviteID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseInviteUse indicates an expected call of ReleaseInviteUse.
+func (mr *MockInviteRepositoryMockRecorder) ReleaseInviteUse(ctx, inviteID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseInviteUse", reflect.TypeOf((*MockInviteRepository)(nil).ReleaseInviteUse), ctx, inviteID)
+}
+
+// RevokeInvite mocks base method.
+func (m *MockInviteRepository) RevokeInvite(ctx context.Context, chatID, inviteID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeInvite", ctx, chatID, inviteID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeInvite indicates an expected call of RevokeInvite.
+func (mr *MockInviteRepositoryMockRecorder) RevokeInvite(ctx, chatID, inviteID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeInvite", reflect.TypeOf((*MockInviteRepository)(nil).RevokeInvite), ctx, chatID, inviteID)
+}
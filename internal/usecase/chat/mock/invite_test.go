@@ -0,0 +1,206 @@
+package mock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/internal/domain/events"
+	service "main/internal/usecase/chat"
+	"main/internal/usecase/chat/mock"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+)
+
+type inviteMocks struct {
+	chats     *mock.MockChatRepositoryInterface
+	invites   *mock.MockInviteRepository
+	publisher *mock.MockMembershipPublisher
+}
+
+func newInviteService(t *testing.T) (*service.InviteService, inviteMocks) {
+	ctrl := gomock.NewController(t)
+	m := inviteMocks{
+		chats:     mock.NewMockChatRepositoryInterface(ctrl),
+		invites:   mock.NewMockInviteRepository(ctrl),
+		publisher: mock.NewMockMembershipPublisher(ctrl),
+	}
+	chats := service.NewChatService(nil, m.chats, nil, m.publisher, nil, nil)
+	return service.NewInviteService(chats, m.invites, nil), m
+}
+
+func TestCreateInvite(t *testing.T) {
+	service, m := newInviteService(t)
+	ctx := context.Background()
+	expires := time.Now().Add(time.Hour)
+	maxUses := 5
+
+	m.chats.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleAdmin, nil)
+	m.chats.EXPECT().IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictAddMembers).Return(false, nil)
+	m.invites.EXPECT().CreateInvite(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, inv dom.ChatInvite) (dom.ChatInvite, error) {
+			assert.Equal(t, int64(1), inv.ChatID)
+			assert.Equal(t, int64(10), inv.CreatedBy)
+			assert.Len(t, inv.Code, 16)
+			assert.Equal(t, &maxUses, inv.MaxUses)
+			assert.True(t, inv.RequiresApproval)
+			inv.ID = 7
+			return inv, nil
+		})
+
+	invite, err := service.CreateInvite(ctx, 1, 10, dom.InviteOptions{ExpiresAt: &expires, MaxUses: &maxUses, RequiresApproval: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), invite.ID)
+
+	m.chats.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(11)).Return(dom.ChatRoleMember, nil)
+	_, err = service.CreateInvite(ctx, 1, 11, dom.InviteOptions{})
+	assert.ErrorIs(t, err, customerrors.ErrChatPermissionDenied)
+
+	m.chats.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(12)).Return(dom.ChatRoleAdmin, nil)
+	m.chats.EXPECT().IsRestricted(gomock.Any(), int64(1), int64(12), dom.RestrictAddMembers).Return(true, nil)
+	_, err = service.CreateInvite(ctx, 1, 12, dom.InviteOptions{})
+	assert.ErrorIs(t, err, customerrors.ErrMemberRestricted)
+
+	zero := 0
+	_, err = service.CreateInvite(ctx, 1, 10, dom.InviteOptions{MaxUses: &zero})
+	assert.ErrorIs(t, err, customerrors.ErrInvalidInput)
+	past := time.Now().Add(-time.Minute)
+	_, err = service.CreateInvite(ctx, 1, 10, dom.InviteOptions{ExpiresAt: &past})
+	assert.ErrorIs(t, err, customerrors.ErrInvalidInput)
+}
+
+func TestRedeemInvite(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	one := 1
+
+	tests := []struct {
+		name        string
+		invite      dom.ChatInvite
+		setup       func(m inviteMocks)
+		wantPending bool
+		wantErr     error
+	}{
+		{
+			name:   "Joins the chat",
+			invite: dom.ChatInvite{ID: 7, ChatID: 1, Code: "code"},
+			setup: func(m inviteMocks) {
+				m.chats.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(20)).Return(false, nil)
+				m.chats.EXPECT().BannedUsers(gomock.Any(), int64(1), []int64{20}).Return([]int64{}, nil)
+				m.invites.EXPECT().ClaimInviteUse(gomock.Any(), int64(7)).Return(nil)
+				m.chats.EXPECT().AddMembers(gomock.Any(), int64(1), []int64{20}).Return(nil)
+				m.publisher.EXPECT().SendMembershipChanged(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, evt events.MembershipChanged) error {
+						assert.Equal(t, int64(1), evt.ChatID)
+						assert.Equal(t, []int64{20}, evt.UserIDs)
+						assert.Equal(t, events.MembershipJoined, evt.Change)
+						assert.Equal(t, "invite", evt.Via)
+						return nil
+					})
+			},
+		},
+		{
+			name:   "Queues the user when approval is needed",
+			invite: dom.ChatInvite{ID: 7, ChatID: 1, Code: "code", RequiresApproval: true},
+			setup: func(m inviteMocks) {
+				m.chats.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(20)).Return(false, nil)
+				m.chats.EXPECT().BannedUsers(gomock.Any(), int64(1), []int64{20}).Return([]int64{}, nil)
+				m.chats.EXPECT().CreateJoinRequest(gomock.Any(), int64(1), int64(20), int64(7), "").
+					Return(dom.JoinRequest{ID: 3, ChatID: 1, UserID: 20, InviteID: 7, Status: dom.JoinRequestPending}, nil)
+			},
+			wantPending: true,
+		},
+		{
+			name:    "Expired invite",
+			invite:  dom.ChatInvite{ID: 7, ChatID: 1, Code: "code", ExpiresAt: &past},
+			setup:   func(m inviteMocks) {},
+			wantErr: customerrors.ErrInvalidInvite,
+		},
+		{
+			name:    "Revoked invite",
+			invite:  dom.ChatInvite{ID: 7, ChatID: 1, Code: "code", RevokedAt: &past},
+			setup:   func(m inviteMocks) {},
+			wantErr: customerrors.ErrInvalidInvite,
+		},
+		{
+			name:    "Used up invite",
+			invite:  dom.ChatInvite{ID: 7, ChatID: 1, Code: "code", MaxUses: &one, Uses: 1},
+			setup:   func(m inviteMocks) {},
+			wantErr: customerrors.ErrInvalidInvite,
+		},
+		{
+			name:   "Creator may not add members",
+			invite: dom.ChatInvite{ID: 7, ChatID: 1, Code: "code", CreatedBy: 10},
+			setup: func(m inviteMocks) {
+				m.chats.EXPECT().IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictAddMembers).Return(true, nil)
+			},
+			wantErr: customerrors.ErrInvalidInvite,
+		},
+		{
+			name:   "Already a member",
+			invite: dom.ChatInvite{ID: 7, ChatID: 1, Code: "code"},
+			setup: func(m inviteMocks) {
+				m.chats.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(20)).Return(true, nil)
+			},
+			wantErr: customerrors.ErrUserAlreadyInChat,
+		},
+		{
+			name:   "Banned user",
+			invite: dom.ChatInvite{ID: 7, ChatID: 1, Code: "code"},
+			setup: func(m inviteMocks) {
+				m.chats.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(20)).Return(false, nil)
+				m.chats.EXPECT().BannedUsers(gomock.Any(), int64(1), []int64{20}).Return([]int64{20}, nil)
+			},
+			wantErr: customerrors.ErrUserBanned,
+		},
+		{
+			name:   "Use is given back when adding fails",
+			invite: dom.ChatInvite{ID: 7, ChatID: 1, Code: "code"},
+			setup: func(m inviteMocks) {
+				m.chats.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(20)).Return(false, nil)
+				m.chats.EXPECT().BannedUsers(gomock.Any(), int64(1), []int64{20}).Return([]int64{}, nil)
+				m.invites.EXPECT().ClaimInviteUse(gomock.Any(), int64(7)).Return(nil)
+				m.chats.EXPECT().AddMembers(gomock.Any(), int64(1), []int64{20}).Return(customerrors.ErrDatabase)
+				m.invites.EXPECT().ReleaseInviteUse(gomock.Any(), int64(7)).Return(nil)
+			},
+			wantErr: customerrors.ErrDatabase,
+		},
+		{
+			name:   "Lost the race for the last use",
+			invite: dom.ChatInvite{ID: 7, ChatID: 1, Code: "code"},
+			setup: func(m inviteMocks) {
+				m.chats.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(20)).Return(false, nil)
+				m.chats.EXPECT().BannedUsers(gomock.Any(), int64(1), []int64{20}).Return([]int64{}, nil)
+				m.invites.EXPECT().ClaimInviteUse(gomock.Any(), int64(7)).Return(customerrors.ErrInvalidInvite)
+			},
+			wantErr: customerrors.ErrInvalidInvite,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, m := newInviteService(t)
+			m.invites.EXPECT().GetInviteByCode(gomock.Any(), "code").Return(tt.invite, nil)
+			tt.setup(m)
+
+			chatID, req, err := service.RedeemInvite(context.Background(), 20, "code")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), chatID)
+			assert.Equal(t, tt.wantPending, req != nil)
+		})
+	}
+}
+
+func TestRedeemUnknownInvite(t *testing.T) {
+	service, m := newInviteService(t)
+	m.invites.EXPECT().GetInviteByCode(gomock.Any(), "nope").Return(dom.ChatInvite{}, customerrors.ErrNotFound)
+
+	_, _, err := service.RedeemInvite(context.Background(), 20, "nope")
+	assert.ErrorIs(t, err, customerrors.ErrInvalidInvite)
+}
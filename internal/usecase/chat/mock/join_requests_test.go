@@ -0,0 +1,167 @@
+package mock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	dom "main/internal/domain/entity"
+	"main/internal/domain/events"
+	service "main/internal/usecase/chat"
+	"main/internal/usecase/chat/mock"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestRequestToJoin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, nil, nil, nil, nil)
+	ctx := context.Background()
+	private := dom.Chat{ID: 1, Type: dom.ChatTypeGroup, IsPrivate: true}
+
+	chatRepo.EXPECT().GetChatSummary(gomock.Any(), int64(1)).Return(private, nil)
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(20)).Return(false, nil)
+	chatRepo.EXPECT().BannedUsers(gomock.Any(), int64(1), []int64{20}).Return(nil, nil)
+	chatRepo.EXPECT().CreateJoinRequest(gomock.Any(), int64(1), int64(20), int64(0), "hi").
+		Return(dom.JoinRequest{ID: 5, ChatID: 1, UserID: 20, Status: dom.JoinRequestPending}, nil)
+	req, err := ChatService.RequestToJoin(ctx, 1, 20, "hi")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), req.ID)
+
+	chatRepo.EXPECT().GetChatSummary(gomock.Any(), int64(2)).Return(dom.Chat{ID: 2, Type: dom.ChatTypeGroup, IsPublic: true}, nil)
+	_, err = ChatService.RequestToJoin(ctx, 2, 20, "")
+	assert.ErrorIs(t, err, customerrors.ErrInvalidInput)
+
+	chatRepo.EXPECT().GetChatSummary(gomock.Any(), int64(3)).Return(dom.Chat{ID: 3, Type: dom.ChatTypeDirect, IsPrivate: true}, nil)
+	_, err = ChatService.RequestToJoin(ctx, 3, 20, "")
+	assert.ErrorIs(t, err, customerrors.ErrInvalidInput)
+
+	chatRepo.EXPECT().GetChatSummary(gomock.Any(), int64(1)).Return(private, nil)
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(10)).Return(true, nil)
+	_, err = ChatService.RequestToJoin(ctx, 1, 10, "")
+	assert.ErrorIs(t, err, customerrors.ErrUserAlreadyInChat)
+
+	chatRepo.EXPECT().GetChatSummary(gomock.Any(), int64(1)).Return(private, nil)
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(30)).Return(false, nil)
+	chatRepo.EXPECT().BannedUsers(gomock.Any(), int64(1), []int64{30}).Return([]int64{30}, nil)
+	_, err = ChatService.RequestToJoin(ctx, 1, 30, "")
+	assert.ErrorIs(t, err, customerrors.ErrUserBanned)
+
+	chatRepo.EXPECT().GetChatSummary(gomock.Any(), int64(9)).Return(dom.Chat{}, customerrors.ErrNotFound)
+	_, err = ChatService.RequestToJoin(ctx, 9, 20, "")
+	assert.ErrorIs(t, err, customerrors.ErrNotFound)
+}
+
+func TestApproveJoinRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	publisher := mock.NewMockMembershipPublisher(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, nil, publisher, nil, nil)
+	ctx := context.Background()
+	approved := dom.JoinRequest{ID: 5, ChatID: 1, UserID: 20, Status: dom.JoinRequestApproved, DecidedBy: 10}
+
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleAdmin, nil)
+	chatRepo.EXPECT().IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictAddMembers).Return(false, nil)
+	chatRepo.EXPECT().DecideJoinRequest(gomock.Any(), int64(1), int64(5), dom.JoinRequestApproved, int64(10)).Return(approved, nil)
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(20)).Return(false, nil)
+	chatRepo.EXPECT().BannedUsers(gomock.Any(), int64(1), []int64{20}).Return(nil, nil)
+	chatRepo.EXPECT().AddMembers(gomock.Any(), int64(1), []int64{20}).Return(nil)
+	publisher.EXPECT().SendMembershipChanged(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, evt events.MembershipChanged) error {
+			assert.Equal(t, events.MembershipJoined, evt.Change)
+			assert.Equal(t, []int64{20}, evt.UserIDs)
+			assert.Equal(t, "request", evt.Via)
+			return nil
+		})
+	req, err := ChatService.ApproveJoinRequest(ctx, 1, 10, 5)
+	require.NoError(t, err)
+	assert.Equal(t, dom.JoinRequestApproved, req.Status)
+
+	// A request made through an invite uses it up.
+	viaInvite := approved
+	viaInvite.InviteID = 7
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleAdmin, nil)
+	chatRepo.EXPECT().IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictAddMembers).Return(false, nil)
+	chatRepo.EXPECT().DecideJoinRequest(gomock.Any(), int64(1), int64(5), dom.JoinRequestApproved, int64(10)).Return(viaInvite, nil)
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(20)).Return(false, nil)
+	chatRepo.EXPECT().BannedUsers(gomock.Any(), int64(1), []int64{20}).Return(nil, nil)
+	chatRepo.EXPECT().ClaimInviteUse(gomock.Any(), int64(7)).Return(nil)
+	chatRepo.EXPECT().AddMembers(gomock.Any(), int64(1), []int64{20}).Return(nil)
+	publisher.EXPECT().SendMembershipChanged(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, evt events.MembershipChanged) error {
+			assert.Equal(t, "invite", evt.Via)
+			return nil
+		})
+	_, err = ChatService.ApproveJoinRequest(ctx, 1, 10, 5)
+	require.NoError(t, err)
+
+	// The invite was revoked or used up while the request waited.
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleAdmin, nil)
+	chatRepo.EXPECT().IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictAddMembers).Return(false, nil)
+	chatRepo.EXPECT().DecideJoinRequest(gomock.Any(), int64(1), int64(5), dom.JoinRequestApproved, int64(10)).Return(viaInvite, nil)
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(20)).Return(false, nil)
+	chatRepo.EXPECT().BannedUsers(gomock.Any(), int64(1), []int64{20}).Return(nil, nil)
+	chatRepo.EXPECT().ClaimInviteUse(gomock.Any(), int64(7)).Return(customerrors.ErrInvalidInvite)
+	chatRepo.EXPECT().ReopenJoinRequest(gomock.Any(), int64(5)).Return(nil)
+	_, err = ChatService.ApproveJoinRequest(ctx, 1, 10, 5)
+	assert.ErrorIs(t, err, customerrors.ErrInvalidInvite)
+
+	// A failed insert puts the request back.
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleAdmin, nil)
+	chatRepo.EXPECT().IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictAddMembers).Return(false, nil)
+	chatRepo.EXPECT().DecideJoinRequest(gomock.Any(), int64(1), int64(5), dom.JoinRequestApproved, int64(10)).Return(approved, nil)
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(20)).Return(false, nil)
+	chatRepo.EXPECT().BannedUsers(gomock.Any(), int64(1), []int64{20}).Return(nil, nil)
+	chatRepo.EXPECT().AddMembers(gomock.Any(), int64(1), []int64{20}).Return(errors.New("db down"))
+	chatRepo.EXPECT().ReopenJoinRequest(gomock.Any(), int64(5)).Return(nil)
+	_, err = ChatService.ApproveJoinRequest(ctx, 1, 10, 5)
+	assert.Error(t, err)
+
+	// Banned while the request waited.
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleAdmin, nil)
+	chatRepo.EXPECT().IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictAddMembers).Return(false, nil)
+	chatRepo.EXPECT().DecideJoinRequest(gomock.Any(), int64(1), int64(5), dom.JoinRequestApproved, int64(10)).Return(approved, nil)
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(20)).Return(false, nil)
+	chatRepo.EXPECT().BannedUsers(gomock.Any(), int64(1), []int64{20}).Return([]int64{20}, nil)
+	chatRepo.EXPECT().ReopenJoinRequest(gomock.Any(), int64(5)).Return(nil)
+	_, err = ChatService.ApproveJoinRequest(ctx, 1, 10, 5)
+	assert.ErrorIs(t, err, customerrors.ErrUserBanned)
+
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleAdmin, nil)
+	chatRepo.EXPECT().IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictAddMembers).Return(false, nil)
+	chatRepo.EXPECT().DecideJoinRequest(gomock.Any(), int64(1), int64(6), dom.JoinRequestApproved, int64(10)).Return(dom.JoinRequest{}, customerrors.ErrNotFound)
+	_, err = ChatService.ApproveJoinRequest(ctx, 1, 10, 6)
+	assert.ErrorIs(t, err, customerrors.ErrNotFound)
+
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(11)).Return(dom.ChatRoleMember, nil)
+	_, err = ChatService.ApproveJoinRequest(ctx, 1, 11, 5)
+	assert.ErrorIs(t, err, customerrors.ErrChatPermissionDenied)
+
+	// An admin who may not add members cannot let anybody in either.
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(12)).Return(dom.ChatRoleAdmin, nil)
+	chatRepo.EXPECT().IsRestricted(gomock.Any(), int64(1), int64(12), dom.RestrictAddMembers).Return(true, nil)
+	_, err = ChatService.ApproveJoinRequest(ctx, 1, 12, 5)
+	assert.ErrorIs(t, err, customerrors.ErrMemberRestricted)
+}
+
+func TestRejectJoinRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleOwner, nil)
+	chatRepo.EXPECT().DecideJoinRequest(gomock.Any(), int64(1), int64(5), dom.JoinRequestRejected, int64(10)).
+		Return(dom.JoinRequest{ID: 5, ChatID: 1, UserID: 20, Status: dom.JoinRequestRejected}, nil)
+	req, err := ChatService.RejectJoinRequest(ctx, 1, 10, 5)
+	require.NoError(t, err)
+	assert.Equal(t, dom.JoinRequestRejected, req.Status)
+
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(20)).Return("", customerrors.ErrUserNotMemberOfChat)
+	_, err = ChatService.ListJoinRequests(ctx, 1, 20)
+	assert.ErrorIs(t, err, customerrors.ErrUserNotMemberOfChat)
+}
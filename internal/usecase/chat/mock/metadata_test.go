@@ -0,0 +1,81 @@
+package mock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	dom "main/internal/domain/entity"
+	"main/internal/domain/events"
+	service "main/internal/usecase/chat"
+	"main/internal/usecase/chat/mock"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUpdateChat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	msgRepo := mock.NewMockMessageRepositoryInterface(ctrl)
+	messages := mock.NewMockMessagePublisher(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, msgRepo, nil, nil, nil)
+	ChatService.Messages = messages
+	ctx := context.Background()
+
+	title, empty := "Renamed", ""
+	upd := dom.ChatUpdate{Title: &title, AvatarURL: &empty}
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleAdmin, nil)
+	chatRepo.EXPECT().UpdateChat(gomock.Any(), int64(1), upd).Return(dom.Chat{ID: 1, Title: title}, nil)
+	msgRepo.EXPECT().SaveMessage(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, msg interface{}) (string, error) {
+			m := msg.(dom.Message)
+			assert.Equal(t, dom.MessageTypeSystem, m.Type)
+			assert.Zero(t, m.SenderID)
+			assert.Equal(t, int64(10), m.ActorID)
+			assert.Equal(t, `changed the title to "Renamed", removed the avatar`, m.Text)
+			return "651eb1234567890abcdef123", nil
+		})
+	messages.EXPECT().SendMessageCreated(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, evt events.MessageCreated) error {
+			assert.Equal(t, "651eb1234567890abcdef123", evt.MessageID)
+			assert.Equal(t, int64(1), evt.ChatID)
+			assert.Zero(t, evt.SenderID)
+			return nil
+		})
+
+	chat, msg, err := ChatService.UpdateChat(ctx, 1, 10, upd)
+	require.NoError(t, err)
+	assert.Equal(t, title, chat.Title)
+	require.NotNil(t, msg)
+	assert.Equal(t, "651eb1234567890abcdef123", msg.ID.Hex())
+
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(11)).Return(dom.ChatRoleMember, nil)
+	_, _, err = ChatService.UpdateChat(ctx, 1, 11, upd)
+	assert.ErrorIs(t, err, customerrors.ErrChatPermissionDenied)
+
+	// The change stays even if the system message is lost.
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleOwner, nil)
+	chatRepo.EXPECT().UpdateChat(gomock.Any(), int64(1), upd).Return(dom.Chat{ID: 1, Title: title}, nil)
+	msgRepo.EXPECT().SaveMessage(gomock.Any(), gomock.Any()).Return("", errors.New("mongo down"))
+	_, msg, err = ChatService.UpdateChat(ctx, 1, 10, upd)
+	require.NoError(t, err)
+	assert.Nil(t, msg)
+}
+
+func TestUpdateChatValidation(t *testing.T) {
+	ChatService := service.NewChatService(nil, nil, nil, nil, nil, nil)
+	long := "this title is much too long"
+	avatar := "javascript:alert(1)"
+
+	for name, upd := range map[string]dom.ChatUpdate{
+		"empty update": {},
+		"long title":   {Title: &long},
+		"bad avatar":   {AvatarURL: &avatar},
+	} {
+		_, _, err := ChatService.UpdateChat(context.Background(), 1, 10, upd)
+		assert.ErrorIs(t, err, customerrors.ErrInvalidInput, name)
+	}
+}
@@ -0,0 +1,133 @@
+package mock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/internal/domain/events"
+	service "main/internal/usecase/chat"
+	"main/internal/usecase/chat/mock"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestBanMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	publisher := mock.NewMockMembershipPublisher(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, nil, publisher, nil, nil)
+	ctx := context.Background()
+	expires := time.Now().Add(time.Hour)
+
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(20)).Return(dom.ChatRoleMember, nil)
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleAdmin, nil)
+	chatRepo.EXPECT().BanMember(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, ban dom.ChatBan) (bool, error) {
+			assert.Equal(t, int64(20), ban.UserID)
+			assert.Equal(t, int64(10), ban.BannedBy)
+			assert.Equal(t, "spam", ban.Reason)
+			assert.Equal(t, &expires, ban.ExpiresAt)
+			return true, nil
+		})
+	publisher.EXPECT().SendMembershipChanged(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, evt events.MembershipChanged) error {
+			assert.Equal(t, events.MembershipBanned, evt.Change)
+			assert.Equal(t, []int64{20}, evt.UserIDs)
+			return nil
+		})
+	require.NoError(t, ChatService.BanMember(ctx, 1, 10, 20, "spam", &expires))
+
+	// Non-members can be banned ahead of time; nobody left, so no event.
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(30)).Return("", customerrors.ErrUserNotMemberOfChat)
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleAdmin, nil)
+	chatRepo.EXPECT().BanMember(gomock.Any(), gomock.Any()).Return(false, nil)
+	require.NoError(t, ChatService.BanMember(ctx, 1, 10, 30, "", nil))
+
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(11)).Return(dom.ChatRoleAdmin, nil)
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleAdmin, nil)
+	assert.ErrorIs(t, ChatService.BanMember(ctx, 1, 10, 11, "", nil), customerrors.ErrChatPermissionDenied)
+
+	past := time.Now().Add(-time.Minute)
+	assert.ErrorIs(t, ChatService.BanMember(ctx, 1, 10, 20, "", &past), customerrors.ErrInvalidInput)
+	assert.ErrorIs(t, ChatService.BanMember(ctx, 1, 10, 10, "", nil), customerrors.ErrInvalidInput)
+}
+
+func TestBannedUserCannotBeAdded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	userRepo := mock.NewMockUserInterface(ctrl)
+	ChatService := service.NewChatService(userRepo, chatRepo, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	userRepo.EXPECT().CheckUserExists(gomock.Any(), gomock.Any()).Return(true).Times(2)
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleAdmin, nil)
+	chatRepo.EXPECT().IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictAddMembers).Return(false, nil)
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(20)).Return(false, nil)
+	chatRepo.EXPECT().BannedUsers(gomock.Any(), int64(1), []int64{20}).Return([]int64{20}, nil)
+
+	assert.ErrorIs(t, ChatService.AddMembers(ctx, 1, 10, []int64{20}), customerrors.ErrUserBanned)
+
+	chatRepo.EXPECT().GetPublicChat(gomock.Any(), int64(1)).Return(dom.Chat{ID: 1, IsPublic: true}, nil)
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(20)).Return(false, nil)
+	chatRepo.EXPECT().BannedUsers(gomock.Any(), int64(1), []int64{20}).Return([]int64{20}, nil)
+	assert.ErrorIs(t, ChatService.JoinPublicChat(ctx, 1, 20), customerrors.ErrUserBanned)
+}
+
+func TestUnbanMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleOwner, nil).AnyTimes()
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(12)).Return(dom.ChatRoleAdmin, nil).AnyTimes()
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(13)).Return(dom.ChatRoleAdmin, nil).AnyTimes()
+
+	chatRepo.EXPECT().GetBan(gomock.Any(), int64(1), int64(20)).Return(dom.ChatBan{ChatID: 1, UserID: 20, BannedBy: 10}, nil)
+	chatRepo.EXPECT().UnbanMember(gomock.Any(), int64(1), int64(20)).Return(nil)
+	require.NoError(t, ChatService.UnbanMember(ctx, 1, 10, 20))
+	chatRepo.EXPECT().GetBan(gomock.Any(), int64(1), int64(21)).Return(dom.ChatBan{}, customerrors.ErrNotFound)
+	assert.ErrorIs(t, ChatService.UnbanMember(ctx, 1, 10, 21), customerrors.ErrNotFound)
+
+	t.Run("Admin cannot lift the owner's ban", func(t *testing.T) {
+		chatRepo.EXPECT().GetBan(gomock.Any(), int64(1), int64(22)).Return(dom.ChatBan{ChatID: 1, UserID: 22, BannedBy: 10}, nil)
+		assert.ErrorIs(t, ChatService.UnbanMember(ctx, 1, 12, 22), customerrors.ErrChatPermissionDenied)
+	})
+
+	t.Run("Admin lifts a peer admin's ban", func(t *testing.T) {
+		chatRepo.EXPECT().GetBan(gomock.Any(), int64(1), int64(23)).Return(dom.ChatBan{ChatID: 1, UserID: 23, BannedBy: 13}, nil)
+		chatRepo.EXPECT().UnbanMember(gomock.Any(), int64(1), int64(23)).Return(nil)
+		require.NoError(t, ChatService.UnbanMember(ctx, 1, 12, 23))
+	})
+
+	t.Run("Banner has left the chat", func(t *testing.T) {
+		chatRepo.EXPECT().GetBan(gomock.Any(), int64(1), int64(24)).Return(dom.ChatBan{ChatID: 1, UserID: 24, BannedBy: 14}, nil)
+		chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(14)).Return("", customerrors.ErrUserNotMemberOfChat)
+		chatRepo.EXPECT().UnbanMember(gomock.Any(), int64(1), int64(24)).Return(nil)
+		require.NoError(t, ChatService.UnbanMember(ctx, 1, 12, 24))
+	})
+
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(11)).Return(dom.ChatRoleMember, nil)
+	_, err := ChatService.ListBans(ctx, 1, 11)
+	assert.ErrorIs(t, err, customerrors.ErrChatPermissionDenied)
+}
+
+func TestMembershipPublishers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	first := mock.NewMockMembershipPublisher(ctrl)
+	second := mock.NewMockMembershipPublisher(ctrl)
+	evt := events.MembershipChanged{ChatID: 1, Change: events.MembershipLeft}
+
+	// A failing publisher does not stop the others.
+	first.EXPECT().SendMembershipChanged(gomock.Any(), evt).Return(errors.New("kafka down"))
+	second.EXPECT().SendMembershipChanged(gomock.Any(), evt).Return(nil)
+
+	err := service.MembershipPublishers{first, second}.SendMembershipChanged(context.Background(), evt)
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package mock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/internal/domain/events"
+	service "main/internal/usecase/chat"
+	"main/internal/usecase/chat/mock"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestCreatePublicChat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	chatRepo.EXPECT().CreatePublicChat(gomock.Any(), int64(1), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int64, chat dom.Chat) (int64, error) {
+			assert.Equal(t, "go_news", chat.Handle)
+			assert.True(t, chat.IsPublic)
+			return 9, nil
+		})
+	chat, err := ChatService.CreatePublicChat(ctx, 1, "Go news", "@Go_News", "Releases and talks")
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), chat.ID)
+	assert.Equal(t, []int64{1}, chat.MembersID)
+
+	for _, handle := range []string{"", "ab", "1abc", "with space", "way_too_long_handle_for_a_chat_123"} {
+		_, err = ChatService.CreatePublicChat(ctx, 1, "Go news", handle, "")
+		assert.ErrorIs(t, err, customerrors.ErrInvalidInput, handle)
+	}
+
+	chatRepo.EXPECT().CreatePublicChat(gomock.Any(), int64(1), gomock.Any()).Return(int64(0), customerrors.ErrHandleTaken)
+	_, err = ChatService.CreatePublicChat(ctx, 1, "Go news", "go_news", "")
+	assert.ErrorIs(t, err, customerrors.ErrHandleTaken)
+}
+
+func TestSearchPublicChats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	chatRepo.EXPECT().SearchPublicChats(gomock.Any(), "go", int64(0), 3).
+		Return([]dom.Chat{{ID: 9}, {ID: 7}, {ID: 4}}, nil)
+	chats, next, err := ChatService.SearchPublicChats(ctx, "@go", 0, 2)
+	require.NoError(t, err)
+	assert.Len(t, chats, 2)
+	assert.Equal(t, int64(7), next)
+
+	chatRepo.EXPECT().SearchPublicChats(gomock.Any(), "go", int64(7), 3).Return([]dom.Chat{{ID: 4}}, nil)
+	chats, next, err = ChatService.SearchPublicChats(ctx, "go", 7, 2)
+	require.NoError(t, err)
+	assert.Len(t, chats, 1)
+	assert.Zero(t, next)
+
+	_, _, err = ChatService.SearchPublicChats(ctx, "go", 0, 101)
+	assert.ErrorIs(t, err, customerrors.ErrInvalidInput)
+}
+
+func TestPreviewPublicChat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	msgRepo := mock.NewMockMessageRepositoryInterface(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, msgRepo, nil, nil, nil)
+	ctx := context.Background()
+
+	chatRepo.EXPECT().GetPublicChat(gomock.Any(), int64(9)).Return(dom.Chat{ID: 9, IsPublic: true}, nil)
+	msgRepo.EXPECT().GetMessages(gomock.Any(), int64(9), time.Time{}, "", int64(50)).
+		Return([]dom.Message{{ChatID: 9, Text: "hi"}}, nil)
+	chat, messages, err := ChatService.PreviewPublicChat(ctx, 9, 500)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), chat.ID)
+	assert.Len(t, messages, 1)
+
+	chatRepo.EXPECT().GetPublicChat(gomock.Any(), int64(3)).Return(dom.Chat{}, customerrors.ErrNotFound)
+	_, _, err = ChatService.PreviewPublicChat(ctx, 3, 0)
+	assert.ErrorIs(t, err, customerrors.ErrNotFound)
+}
+
+func TestJoinAndLeavePublicChat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	publisher := mock.NewMockMembershipPublisher(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, nil, publisher, nil, nil)
+	ctx := context.Background()
+
+	chatRepo.EXPECT().GetPublicChat(gomock.Any(), int64(9)).Return(dom.Chat{ID: 9, IsPublic: true}, nil)
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(9), int64(20)).Return(false, nil)
+	chatRepo.EXPECT().BannedUsers(gomock.Any(), int64(9), []int64{20}).Return([]int64{}, nil)
+	chatRepo.EXPECT().AddMembers(gomock.Any(), int64(9), []int64{20}).Return(nil)
+	publisher.EXPECT().SendMembershipChanged(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, evt events.MembershipChanged) error {
+			assert.Equal(t, events.MembershipJoined, evt.Change)
+			assert.Equal(t, "public", evt.Via)
+			return nil
+		})
+	require.NoError(t, ChatService.JoinPublicChat(ctx, 9, 20))
+
+	chatRepo.EXPECT().GetPublicChat(gomock.Any(), int64(9)).Return(dom.Chat{ID: 9, IsPublic: true}, nil)
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(9), int64(20)).Return(true, nil)
+	assert.ErrorIs(t, ChatService.JoinPublicChat(ctx, 9, 20), customerrors.ErrUserAlreadyInChat)
+
+	// Private chats cannot be joined this way.
+	chatRepo.EXPECT().GetPublicChat(gomock.Any(), int64(3)).Return(dom.Chat{}, customerrors.ErrNotFound)
+	assert.ErrorIs(t, ChatService.JoinPublicChat(ctx, 3, 20), customerrors.ErrNotFound)
+
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(9), int64(20)).Return(dom.ChatRoleMember, nil)
+	chatRepo.EXPECT().RemoveMember(gomock.Any(), int64(9), int64(20)).Return(nil)
+	publisher.EXPECT().SendMembershipChanged(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, evt events.MembershipChanged) error {
+			assert.Equal(t, events.MembershipLeft, evt.Change)
+			return nil
+		})
+	require.NoError(t, ChatService.LeaveChat(ctx, 9, 20))
+}
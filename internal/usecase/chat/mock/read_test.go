@@ -0,0 +1,85 @@
+package mock_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	dom "main/internal/domain/entity"
+	service "main/internal/usecase/chat"
+	"main/internal/usecase/chat/mock"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+)
+
+const readMsgID = "651eb1234567890abcdef123"
+
+func TestMarkRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	msgRepo := mock.NewMockMessageRepositoryInterface(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, msgRepo, nil, nil, nil)
+	ctx := context.Background()
+	state := dom.ReadState{ChatID: 1, UserID: 10, LastReadMessageID: readMsgID}
+
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(10)).Return(true, nil)
+	msgRepo.EXPECT().GetMessage(gomock.Any(), int64(1), readMsgID).Return(dom.Message{ChatID: 1}, nil)
+	chatRepo.EXPECT().MarkRead(gomock.Any(), int64(1), int64(10), readMsgID).Return(state, true, nil)
+	msgRepo.EXPECT().CountUnread(gomock.Any(), int64(10), []dom.ReadState{state}).Return(map[int64]int64{1: 3}, nil)
+	got, advanced, err := ChatService.MarkRead(ctx, 1, 10, readMsgID)
+	require.NoError(t, err)
+	assert.True(t, advanced)
+	assert.Equal(t, int64(3), got.UnreadCount)
+
+	_, _, err = ChatService.MarkRead(ctx, 1, 10, "not-an-id")
+	assert.ErrorIs(t, err, customerrors.ErrInvalidInput)
+
+	// Uppercase hex is stored in the lowercase form markers are compared in.
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(10)).Return(true, nil)
+	msgRepo.EXPECT().GetMessage(gomock.Any(), int64(1), readMsgID).Return(dom.Message{ChatID: 1}, nil)
+	chatRepo.EXPECT().MarkRead(gomock.Any(), int64(1), int64(10), readMsgID).Return(state, false, nil)
+	msgRepo.EXPECT().CountUnread(gomock.Any(), int64(10), []dom.ReadState{state}).Return(map[int64]int64{1: 3}, nil)
+	_, _, err = ChatService.MarkRead(ctx, 1, 10, strings.ToUpper(readMsgID))
+	require.NoError(t, err)
+
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(20)).Return(false, nil)
+	_, _, err = ChatService.MarkRead(ctx, 1, 20, readMsgID)
+	assert.ErrorIs(t, err, customerrors.ErrUserNotMemberOfChat)
+
+	// The message has to belong to the chat.
+	chatRepo.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(2), int64(10)).Return(true, nil)
+	msgRepo.EXPECT().GetMessage(gomock.Any(), int64(2), readMsgID).Return(dom.Message{}, customerrors.ErrMessageDoesNotExists)
+	_, _, err = ChatService.MarkRead(ctx, 2, 10, readMsgID)
+	assert.ErrorIs(t, err, customerrors.ErrMessageDoesNotExists)
+}
+
+func TestListOfChatsUnreadCounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	msgRepo := mock.NewMockMessageRepositoryInterface(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, msgRepo, nil, nil, nil)
+	ctx := context.Background()
+	states := []dom.ReadState{{ChatID: 1, UserID: 10}, {ChatID: 2, UserID: 10, LastReadMessageID: readMsgID}}
+
+	chatRepo.EXPECT().ListOfChats(gomock.Any(), int64(10), time.Time{}, int64(0), gomock.Any()).Return([]dom.Chat{{ID: 1}, {ID: 2}}, nil)
+	chatRepo.EXPECT().ListReadStates(gomock.Any(), int64(10)).Return(states, nil)
+	msgRepo.EXPECT().CountUnread(gomock.Any(), int64(10), states).Return(map[int64]int64{1: 4}, nil)
+	chats, _, err := ChatService.ListOfChats(ctx, 10, "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), chats[0].UnreadCount)
+	assert.Equal(t, int64(0), chats[1].UnreadCount)
+
+	// Only the chats on the page are counted, and counting failures still
+	// return the list.
+	chatRepo.EXPECT().ListOfChats(gomock.Any(), int64(10), time.Time{}, int64(0), gomock.Any()).Return([]dom.Chat{{ID: 1}}, nil)
+	chatRepo.EXPECT().ListReadStates(gomock.Any(), int64(10)).Return(states, nil)
+	msgRepo.EXPECT().CountUnread(gomock.Any(), int64(10), states[:1]).Return(nil, errors.New("mongo down"))
+	chats, _, err = ChatService.ListOfChats(ctx, 10, "", 0)
+	require.NoError(t, err)
+	assert.Len(t, chats, 1)
+}
@@ -0,0 +1,123 @@
+package mock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dom "main/internal/domain/entity"
+	service "main/internal/usecase/chat"
+	"main/internal/usecase/chat/mock"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestRestrictMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(20)).Return(dom.ChatRoleMember, nil)
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleAdmin, nil)
+	var got []string
+	chatRepo.EXPECT().RestrictMember(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, restrictions []dom.MemberRestriction) error {
+			for _, r := range restrictions {
+				assert.Equal(t, int64(20), r.UserID)
+				assert.WithinDuration(t, time.Now().Add(time.Hour), r.ExpiresAt, time.Minute)
+				got = append(got, r.Restriction)
+			}
+			return nil
+		})
+
+	restrictions, err := ChatService.RestrictMember(ctx, 1, 10, 20, []string{dom.RestrictSend, dom.RestrictEdit, dom.RestrictSend}, time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, restrictions, 2)
+	assert.Equal(t, []string{dom.RestrictSend, dom.RestrictEdit}, got)
+
+	// Admins cannot restrict each other.
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(11)).Return(dom.ChatRoleAdmin, nil)
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleAdmin, nil)
+	_, err = ChatService.RestrictMember(ctx, 1, 10, 11, []string{dom.RestrictSend}, time.Hour)
+	assert.ErrorIs(t, err, customerrors.ErrChatPermissionDenied)
+
+	for name, tc := range map[string]struct {
+		restrictions []string
+		duration     time.Duration
+	}{
+		"unknown kind": {[]string{"speak"}, time.Hour},
+		"none":         {nil, time.Hour},
+		"no duration":  {[]string{dom.RestrictSend}, 0},
+		"too long":     {[]string{dom.RestrictSend}, 365 * 24 * time.Hour},
+	} {
+		_, err := ChatService.RestrictMember(ctx, 1, 10, 20, tc.restrictions, tc.duration)
+		assert.ErrorIs(t, err, customerrors.ErrInvalidInput, name)
+	}
+}
+
+func TestLiftRestrictions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(20)).Return(dom.ChatRoleAdmin, nil).Times(2)
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleOwner, nil).Times(2)
+	chatRepo.EXPECT().LiftRestrictions(gomock.Any(), int64(1), int64(20), []string{}).Return(int64(2), nil)
+	require.NoError(t, ChatService.LiftRestrictions(ctx, 1, 10, 20, nil))
+
+	chatRepo.EXPECT().LiftRestrictions(gomock.Any(), int64(1), int64(20), []string{dom.RestrictSend}).Return(int64(0), nil)
+	assert.ErrorIs(t, ChatService.LiftRestrictions(ctx, 1, 10, 20, []string{dom.RestrictSend}), customerrors.ErrNotFound)
+
+	// A restricted admin cannot lift their own restrictions.
+	err := ChatService.LiftRestrictions(ctx, 1, 20, 20, []string{dom.RestrictAddMembers})
+	assert.ErrorIs(t, err, customerrors.ErrChatPermissionDenied)
+
+	// Nor can a peer admin lift them.
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(20)).Return(dom.ChatRoleAdmin, nil)
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(11)).Return(dom.ChatRoleAdmin, nil)
+	err = ChatService.LiftRestrictions(ctx, 1, 11, 20, nil)
+	assert.ErrorIs(t, err, customerrors.ErrChatPermissionDenied)
+}
+
+func TestRestrictedMemberCannotAddMembers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	userRepo := mock.NewMockUserInterface(ctrl)
+	ChatService := service.NewChatService(userRepo, chatRepo, nil, nil, nil, nil)
+
+	userRepo.EXPECT().CheckUserExists(gomock.Any(), int64(10)).Return(true)
+	chatRepo.EXPECT().GetMemberRole(gomock.Any(), int64(1), int64(10)).Return(dom.ChatRoleAdmin, nil)
+	chatRepo.EXPECT().IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictAddMembers).Return(true, nil)
+
+	err := ChatService.AddMembers(context.Background(), 1, 10, []int64{20})
+	assert.ErrorIs(t, err, customerrors.ErrMemberRestricted)
+}
+
+func TestRestrictionSweeper(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatRepositoryInterface(ctrl)
+	ChatService := service.NewChatService(nil, chatRepo, nil, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	chatRepo.EXPECT().DeleteExpiredRestrictions(gomock.Any()).
+		DoAndReturn(func(context.Context) (int64, error) {
+			cancel()
+			return 3, nil
+		})
+
+	done := make(chan struct{})
+	go func() {
+		ChatService.StartRestrictionSweeper(ctx, time.Millisecond)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sweeper did not stop")
+	}
+}
@@ -0,0 +1,214 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/internal/domain/events"
+	"main/pkg/customerrors"
+)
+
+const maxBanReasonLen = 512
+
+// LeaveChat removes the user from the chat. The owner has to transfer
+// ownership first.
+func (c *ChatService) LeaveChat(ctx context.Context, chatID, userID int64) error {
+	if chatID <= 0 || userID <= 0 {
+		return fmt.Errorf("chat service: invalid chatID or userID: %w", customerrors.ErrInvalidInput)
+	}
+
+	role, err := c.memberRole(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+	if role == dom.ChatRoleOwner {
+		return fmt.Errorf("chat service: owner must transfer ownership before leaving: %w", customerrors.ErrChatPermissionDenied)
+	}
+
+	if err := c.Chat.RemoveMember(ctx, chatID, userID); err != nil {
+		return err
+	}
+	c.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    userID,
+		Action:     dom.AuditMemberLeft,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+	})
+	c.publishMembership(ctx, events.MembershipChanged{
+		ChatID:  chatID,
+		UserIDs: []int64{userID},
+		Change:  events.MembershipLeft,
+		ActorID: userID,
+	})
+	return nil
+}
+
+// KickMember removes another member from the chat. They may join again.
+// It takes an admin, and admins can only kick plain members.
+func (c *ChatService) KickMember(ctx context.Context, chatID, actorID, userID int64) error {
+	if chatID <= 0 || actorID <= 0 || userID <= 0 {
+		return fmt.Errorf("chat service: invalid chatID or userID: %w", customerrors.ErrInvalidInput)
+	}
+	if actorID == userID {
+		return fmt.Errorf("chat service: cannot kick yourself, leave the chat instead: %w", customerrors.ErrInvalidInput)
+	}
+
+	targetRole, err := c.memberRole(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+	if err := c.authorizeOver(ctx, chatID, actorID, actionRemoveMember, targetRole); err != nil {
+		return err
+	}
+
+	if err := c.Chat.RemoveMember(ctx, chatID, userID); err != nil {
+		return err
+	}
+	c.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    actorID,
+		Action:     dom.AuditMemberRemoved,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+		Metadata:   map[string]interface{}{"user_id": userID},
+	})
+	c.publishMembership(ctx, events.MembershipChanged{
+		ChatID:  chatID,
+		UserIDs: []int64{userID},
+		Change:  events.MembershipRemoved,
+		ActorID: actorID,
+	})
+	return nil
+}
+
+// BanMember removes the user from the chat and keeps them out until
+// expiresAt, or for good if it is nil. Users who are not members can be
+// banned too. The same rank rules as for KickMember apply.
+func (c *ChatService) BanMember(ctx context.Context, chatID, actorID, userID int64, reason string, expiresAt *time.Time) error {
+	if chatID <= 0 || actorID <= 0 || userID <= 0 {
+		return fmt.Errorf("chat service: invalid chatID or userID: %w", customerrors.ErrInvalidInput)
+	}
+	if actorID == userID {
+		return fmt.Errorf("chat service: cannot ban yourself: %w", customerrors.ErrInvalidInput)
+	}
+	if len(reason) > maxBanReasonLen {
+		return fmt.Errorf("chat service: reason cannot be more than %d characters: %w", maxBanReasonLen, customerrors.ErrInvalidInput)
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return fmt.Errorf("chat service: ban expiry must be in the future: %w", customerrors.ErrInvalidInput)
+	}
+
+	targetRole, err := c.memberRole(ctx, chatID, userID)
+	if err != nil && !errors.Is(err, customerrors.ErrUserNotMemberOfChat) {
+		return err
+	}
+	if err := c.authorizeOver(ctx, chatID, actorID, actionBanMember, targetRole); err != nil {
+		return err
+	}
+
+	removed, err := c.Chat.BanMember(ctx, dom.ChatBan{
+		ChatID:    chatID,
+		UserID:    userID,
+		BannedBy:  actorID,
+		Reason:    reason,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	metadata := map[string]interface{}{"user_id": userID}
+	if expiresAt != nil {
+		metadata["expires_at"] = expiresAt.UTC().Format(time.RFC3339)
+	}
+	c.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    actorID,
+		Action:     dom.AuditMemberBanned,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+		Metadata:   metadata,
+	})
+	if removed {
+		c.publishMembership(ctx, events.MembershipChanged{
+			ChatID:  chatID,
+			UserIDs: []int64{userID},
+			Change:  events.MembershipBanned,
+			ActorID: actorID,
+		})
+	}
+	return nil
+}
+
+// UnbanMember lifts a ban. The user is not added back. A ban placed by a
+// member who ranks above the actor stays in force.
+func (c *ChatService) UnbanMember(ctx context.Context, chatID, actorID, userID int64) error {
+	if chatID <= 0 || actorID <= 0 || userID <= 0 {
+		return fmt.Errorf("chat service: invalid chatID or userID: %w", customerrors.ErrInvalidInput)
+	}
+	actorRole, err := c.authorize(ctx, chatID, actorID, actionBanMember)
+	if err != nil {
+		return err
+	}
+	ban, err := c.Chat.GetBan(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+	if ban.BannedBy != 0 && ban.BannedBy != actorID {
+		bannerRole, err := c.memberRole(ctx, chatID, ban.BannedBy)
+		if err != nil && !errors.Is(err, customerrors.ErrUserNotMemberOfChat) {
+			return err
+		}
+		if roleRank(bannerRole) > roleRank(actorRole) {
+			return fmt.Errorf("chat service: %s cannot lift a ban placed by %s: %w", actorRole, bannerRole, customerrors.ErrChatPermissionDenied)
+		}
+	}
+
+	if err := c.Chat.UnbanMember(ctx, chatID, userID); err != nil {
+		return err
+	}
+	c.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    actorID,
+		Action:     dom.AuditMemberUnbanned,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+		Metadata:   map[string]interface{}{"user_id": userID},
+	})
+	return nil
+}
+
+// ListBans returns the bans of the chat still in force.
+func (c *ChatService) ListBans(ctx context.Context, chatID, actorID int64) ([]dom.ChatBan, error) {
+	if _, err := c.authorize(ctx, chatID, actorID, actionBanMember); err != nil {
+		return nil, err
+	}
+	return c.Chat.ListBans(ctx, chatID)
+}
+
+// authorizeOver checks that actorID may perform action on a member with
+// targetRole, which has to rank below the actor's own. An empty targetRole
+// is a non-member.
+func (c *ChatService) authorizeOver(ctx context.Context, chatID, actorID int64, action, targetRole string) error {
+	actorRole, err := c.authorize(ctx, chatID, actorID, action)
+	if err != nil {
+		return err
+	}
+	if roleRank(targetRole) >= roleRank(actorRole) {
+		return fmt.Errorf("chat service: %s cannot %s %s: %w", actorRole, action, targetRole, customerrors.ErrChatPermissionDenied)
+	}
+	return nil
+}
+
+// checkNotBanned fails with ErrUserBanned if any of userIDs is banned from
+// the chat.
+func (c *ChatService) checkNotBanned(ctx context.Context, chatID int64, userIDs []int64) error {
+	banned, err := c.Chat.BannedUsers(ctx, chatID, userIDs)
+	if err != nil {
+		return fmt.Errorf("chat service: failed to check bans: %w", customerrors.ErrFailedToCheck)
+	}
+	if len(banned) > 0 {
+		return fmt.Errorf("chat service: user %d is banned from chat: %w", banned[0], customerrors.ErrUserBanned)
+	}
+	return nil
+}
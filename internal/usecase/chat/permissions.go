@@ -0,0 +1,89 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+)
+
+// Actions on a chat that need more than plain membership.
+const (
+	actionDeleteChat        = "delete_chat"
+	actionEditChat          = "edit_chat"
+	actionAddMembers        = "add_members"
+	actionRemoveMember      = "remove_member"
+	actionPinMessage        = "pin_message"
+	actionSetRole           = "set_role"
+	actionTransferOwnership = "transfer_ownership"
+	actionManageInvites     = "manage_invites"
+	actionBanMember         = "ban_member"
+	actionRestrictMember    = "restrict_member"
+	actionReviewJoinRequest = "review_join_request"
+)
+
+// minRole is the least privileged role allowed to perform each action.
+var minRole = map[string]string{
+	actionDeleteChat:        dom.ChatRoleOwner,
+	actionEditChat:          dom.ChatRoleAdmin,
+	actionAddMembers:        dom.ChatRoleAdmin,
+	actionRemoveMember:      dom.ChatRoleAdmin,
+	actionPinMessage:        dom.ChatRoleAdmin,
+	actionSetRole:           dom.ChatRoleOwner,
+	actionTransferOwnership: dom.ChatRoleOwner,
+	actionManageInvites:     dom.ChatRoleAdmin,
+	actionBanMember:         dom.ChatRoleAdmin,
+	actionRestrictMember:    dom.ChatRoleAdmin,
+	actionReviewJoinRequest: dom.ChatRoleAdmin,
+}
+
+// roleRank orders the chat roles; unknown roles rank below members.
+func roleRank(role string) int {
+	switch role {
+	case dom.ChatRoleOwner:
+		return 3
+	case dom.ChatRoleAdmin:
+		return 2
+	case dom.ChatRoleMember:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// can reports whether a member with role may perform action.
+func can(role, action string) bool {
+	required, ok := minRole[action]
+	if !ok {
+		return false
+	}
+	return roleRank(role) >= roleRank(required)
+}
+
+// authorize returns the role of userID in the chat if it allows action. It
+// fails with ErrUserNotMemberOfChat for non-members and
+// ErrChatPermissionDenied when the role is too low.
+func (c *ChatService) authorize(ctx context.Context, chatID, userID int64, action string) (string, error) {
+	role, err := c.memberRole(ctx, chatID, userID)
+	if err != nil {
+		return "", err
+	}
+	if !can(role, action) {
+		return role, fmt.Errorf("chat service: %s cannot %s: %w", role, action, customerrors.ErrChatPermissionDenied)
+	}
+	return role, nil
+}
+
+// memberRole returns the role of userID in the chat.
+func (c *ChatService) memberRole(ctx context.Context, chatID, userID int64) (string, error) {
+	role, err := c.Chat.GetMemberRole(ctx, chatID, userID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrUserNotMemberOfChat) {
+			return "", fmt.Errorf("chat service: user is not a member of chat: %w", customerrors.ErrUserNotMemberOfChat)
+		}
+		return "", fmt.Errorf("chat service: failed to get member role: %w", customerrors.ErrFailedToCheck)
+	}
+	return role, nil
+}
@@ -0,0 +1,158 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/internal/domain/events"
+	"main/pkg/customerrors"
+)
+
+const (
+	defaultSearchLimit  = 20
+	maxSearchLimit      = 100
+	defaultPreviewLimit = 20
+	maxPreviewLimit     = 50
+	maxDescriptionLen   = 512
+)
+
+var handlePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{2,31}$`)
+
+// normalizeHandle lowercases the handle and drops a leading @.
+func normalizeHandle(handle string) (string, error) {
+	handle = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+	if !handlePattern.MatchString(handle) {
+		return "", fmt.Errorf("chat service: handle must be 3-32 letters, digits or underscores starting with a letter: %w",
+			customerrors.ErrInvalidInput)
+	}
+	return handle, nil
+}
+
+// CreatePublicChat creates a chat anyone can find by title or handle and join
+// without an invite. ownerID is its first member.
+func (c *ChatService) CreatePublicChat(ctx context.Context, ownerID int64, title, handle, description string) (dom.Chat, error) {
+	if ownerID <= 0 {
+		return dom.Chat{}, fmt.Errorf("chat service: invalid ownerID: %w", customerrors.ErrInvalidInput)
+	}
+	if err := validateTitle(title); err != nil {
+		return dom.Chat{}, err
+	}
+	handle, err := normalizeHandle(handle)
+	if err != nil {
+		return dom.Chat{}, err
+	}
+	if len(description) > maxDescriptionLen {
+		return dom.Chat{}, fmt.Errorf("chat service: description cannot be more than %d characters: %w",
+			maxDescriptionLen, customerrors.ErrInvalidInput)
+	}
+
+	chat := dom.Chat{
+		Title:        title,
+		Type:         dom.ChatTypeGroup,
+		Handle:       handle,
+		Description:  description,
+		IsPublic:     true,
+		MembersID:    []int64{ownerID},
+		MembersCount: 1,
+	}
+	chat.ID, err = c.Chat.CreatePublicChat(ctx, ownerID, chat)
+	if err != nil {
+		return dom.Chat{}, err
+	}
+	return chat, nil
+}
+
+// SearchPublicChats returns a page of public chats matching query and the
+// cursor of the next page, 0 on the last one.
+func (c *ChatService) SearchPublicChats(ctx context.Context, query string, cursor int64, limit int) ([]dom.Chat, int64, error) {
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit < 0 || limit > maxSearchLimit {
+		return nil, 0, fmt.Errorf("%w: limit must be between 1 and %d", customerrors.ErrInvalidInput, maxSearchLimit)
+	}
+	if cursor < 0 {
+		return nil, 0, fmt.Errorf("chat service: invalid cursor: %w", customerrors.ErrInvalidInput)
+	}
+	query = strings.TrimPrefix(strings.TrimSpace(query), "@")
+
+	// One extra row tells whether another page follows.
+	chats, err := c.Chat.SearchPublicChats(ctx, query, cursor, limit+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(chats) <= limit {
+		return chats, 0, nil
+	}
+	chats = chats[:limit]
+	return chats, chats[limit-1].ID, nil
+}
+
+// PreviewPublicChat returns a public chat with its latest messages, for users
+// deciding whether to join. Membership is not required.
+func (c *ChatService) PreviewPublicChat(ctx context.Context, chatID int64, limit int64) (dom.Chat, []dom.Message, error) {
+	if chatID <= 0 {
+		return dom.Chat{}, nil, fmt.Errorf("chat service: invalid chatID: %w", customerrors.ErrInvalidInput)
+	}
+	if limit <= 0 {
+		limit = defaultPreviewLimit
+	}
+	if limit > maxPreviewLimit {
+		limit = maxPreviewLimit
+	}
+
+	chat, err := c.Chat.GetPublicChat(ctx, chatID)
+	if err != nil {
+		return dom.Chat{}, nil, err
+	}
+	messages, err := c.Msg.GetMessages(ctx, chatID, time.Time{}, "", limit)
+	if err != nil {
+		return dom.Chat{}, nil, fmt.Errorf("chat service: failed to get messages: %w", err)
+	}
+	return chat, messages, nil
+}
+
+// JoinPublicChat adds the user to a public chat.
+func (c *ChatService) JoinPublicChat(ctx context.Context, chatID, userID int64) error {
+	if chatID <= 0 || userID <= 0 {
+		return fmt.Errorf("chat service: invalid chatID or userID: %w", customerrors.ErrInvalidInput)
+	}
+
+	if _, err := c.Chat.GetPublicChat(ctx, chatID); err != nil {
+		return err
+	}
+	inChat, err := c.Chat.CheckIsMemberOfChat(ctx, chatID, userID)
+	if err != nil {
+		return fmt.Errorf("chat service: failed to check if user is member of chat: %w", customerrors.ErrFailedToCheck)
+	}
+	if inChat {
+		return customerrors.ErrUserAlreadyInChat
+	}
+	if err := c.checkNotBanned(ctx, chatID, []int64{userID}); err != nil {
+		return err
+	}
+
+	if err := c.Chat.AddMembers(ctx, chatID, []int64{userID}); err != nil {
+		return err
+	}
+	c.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    userID,
+		Action:     dom.AuditMemberAdded,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+		Metadata:   map[string]interface{}{"members": []int64{userID}, "public": true},
+	})
+	c.publishMembership(ctx, events.MembershipChanged{
+		ChatID:  chatID,
+		UserIDs: []int64{userID},
+		Change:  events.MembershipJoined,
+		ActorID: userID,
+		Via:     "public",
+	})
+	return nil
+}
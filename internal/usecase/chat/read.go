@@ -0,0 +1,78 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MarkRead moves the user's read marker in the chat up to messageID and
+// returns the new state with the unread count. Marking an older message
+// keeps the marker where it is; advanced reports whether it moved.
+func (c *ChatService) MarkRead(ctx context.Context, chatID, userID int64, messageID string) (dom.ReadState, bool, error) {
+	if chatID <= 0 || userID <= 0 {
+		return dom.ReadState{}, false, fmt.Errorf("chat service: invalid chatID or userID: %w", customerrors.ErrInvalidInput)
+	}
+	objectID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return dom.ReadState{}, false, fmt.Errorf("chat service: invalid message id: %w", customerrors.ErrInvalidInput)
+	}
+	// The repository compares markers as strings, which only orders them
+	// like the IDs in the canonical lowercase form.
+	messageID = objectID.Hex()
+
+	isMember, err := c.Chat.CheckIsMemberOfChat(ctx, chatID, userID)
+	if err != nil {
+		return dom.ReadState{}, false, fmt.Errorf("chat service: failed to check if user is member of chat: %w", customerrors.ErrFailedToCheck)
+	}
+	if !isMember {
+		return dom.ReadState{}, false, fmt.Errorf("chat service: user is not a member of chat: %w", customerrors.ErrUserNotMemberOfChat)
+	}
+	if _, err := c.Msg.GetMessage(ctx, chatID, messageID); err != nil {
+		return dom.ReadState{}, false, err
+	}
+
+	state, advanced, err := c.Chat.MarkRead(ctx, chatID, userID, messageID)
+	if err != nil {
+		return dom.ReadState{}, false, err
+	}
+	counts, err := c.Msg.CountUnread(ctx, userID, []dom.ReadState{state})
+	if err != nil {
+		return dom.ReadState{}, false, err
+	}
+	state.UnreadCount = counts[chatID]
+	return state, advanced, nil
+}
+
+// fillUnreadCounts sets the unread count of each chat in the page. The list is still
+// worth showing without them, so failures are only logged.
+func (c *ChatService) fillUnreadCounts(ctx context.Context, userID int64, chats []dom.Chat) {
+	if len(chats) == 0 {
+		return
+	}
+	states, err := c.Chat.ListReadStates(ctx, userID)
+	if err != nil {
+		c.Logger.Warn("failed to get read markers", slog.Int64("user_id", userID), slog.String("error", err.Error()))
+		return
+	}
+	listed := make(map[int64]bool, len(chats))
+	for _, chat := range chats {
+		listed[chat.ID] = true
+	}
+	states = slices.DeleteFunc(states, func(state dom.ReadState) bool { return !listed[state.ChatID] })
+
+	counts, err := c.Msg.CountUnread(ctx, userID, states)
+	if err != nil {
+		c.Logger.Warn("failed to count unread messages", slog.Int64("user_id", userID), slog.String("error", err.Error()))
+		return
+	}
+	for i := range chats {
+		chats[i].UnreadCount = counts[chats[i].ID]
+	}
+}
@@ -0,0 +1,174 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/pkg/customerrors"
+)
+
+const maxRestrictionDuration = 30 * 24 * time.Hour
+
+var knownRestrictions = []string{dom.RestrictSend, dom.RestrictEdit, dom.RestrictAddMembers}
+
+// RestrictMember stops a member from doing the given things in the chat for
+// duration. Restricting again replaces the expiry. The same rank rules as for
+// KickMember apply.
+func (c *ChatService) RestrictMember(ctx context.Context, chatID, actorID, userID int64, restrictions []string, duration time.Duration) ([]dom.MemberRestriction, error) {
+	if chatID <= 0 || actorID <= 0 || userID <= 0 {
+		return nil, fmt.Errorf("chat service: invalid chatID or userID: %w", customerrors.ErrInvalidInput)
+	}
+	if actorID == userID {
+		return nil, fmt.Errorf("chat service: cannot restrict yourself: %w", customerrors.ErrInvalidInput)
+	}
+	if duration <= 0 || duration > maxRestrictionDuration {
+		return nil, fmt.Errorf("chat service: restriction must last between 1s and %s: %w", maxRestrictionDuration, customerrors.ErrInvalidInput)
+	}
+	restrictions, err := normalizeRestrictions(restrictions)
+	if err != nil {
+		return nil, err
+	}
+	if len(restrictions) == 0 {
+		return nil, fmt.Errorf("chat service: no restrictions given: %w", customerrors.ErrInvalidInput)
+	}
+
+	targetRole, err := c.memberRole(ctx, chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.authorizeOver(ctx, chatID, actorID, actionRestrictMember, targetRole); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(duration)
+	created := make([]dom.MemberRestriction, 0, len(restrictions))
+	for _, restriction := range restrictions {
+		created = append(created, dom.MemberRestriction{
+			ChatID:       chatID,
+			UserID:       userID,
+			Restriction:  restriction,
+			RestrictedBy: actorID,
+			ExpiresAt:    expiresAt,
+		})
+	}
+	if err := c.Chat.RestrictMember(ctx, created); err != nil {
+		return nil, err
+	}
+
+	c.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    actorID,
+		Action:     dom.AuditMemberRestricted,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+		Metadata: map[string]interface{}{
+			"user_id":      userID,
+			"restrictions": restrictions,
+			"expires_at":   expiresAt.UTC().Format(time.RFC3339),
+		},
+	})
+	return created, nil
+}
+
+// LiftRestrictions ends the member's restrictions of the given kinds before
+// they expire, or all of them if none are given. The same rank rules as for
+// RestrictMember apply, so nobody lifts their own restrictions.
+func (c *ChatService) LiftRestrictions(ctx context.Context, chatID, actorID, userID int64, restrictions []string) error {
+	if chatID <= 0 || actorID <= 0 || userID <= 0 {
+		return fmt.Errorf("chat service: invalid chatID or userID: %w", customerrors.ErrInvalidInput)
+	}
+	if actorID == userID {
+		return fmt.Errorf("chat service: cannot lift your own restrictions: %w", customerrors.ErrChatPermissionDenied)
+	}
+	restrictions, err := normalizeRestrictions(restrictions)
+	if err != nil {
+		return err
+	}
+	targetRole, err := c.memberRole(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+	if err := c.authorizeOver(ctx, chatID, actorID, actionRestrictMember, targetRole); err != nil {
+		return err
+	}
+
+	n, err := c.Chat.LiftRestrictions(ctx, chatID, userID, restrictions)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return customerrors.ErrNotFound
+	}
+	c.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    actorID,
+		Action:     dom.AuditRestrictionLifted,
+		TargetType: "chat",
+		TargetID:   strconv.FormatInt(chatID, 10),
+		Metadata:   map[string]interface{}{"user_id": userID, "restrictions": restrictions},
+	})
+	return nil
+}
+
+// ListRestrictions returns the restrictions in force in the chat.
+func (c *ChatService) ListRestrictions(ctx context.Context, chatID, actorID int64) ([]dom.MemberRestriction, error) {
+	if _, err := c.authorize(ctx, chatID, actorID, actionRestrictMember); err != nil {
+		return nil, err
+	}
+	return c.Chat.ListRestrictions(ctx, chatID)
+}
+
+// StartRestrictionSweeper deletes expired restrictions every interval until
+// ctx is done.
+func (c *ChatService) StartRestrictionSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := c.Chat.DeleteExpiredRestrictions(ctx)
+			if err != nil {
+				c.Logger.Error("failed to delete expired restrictions", slog.String("error", err.Error()))
+			} else if n > 0 {
+				c.Logger.Info("expired restrictions deleted", slog.Int64("restrictions", n))
+			}
+		}
+	}
+}
+
+// checkNotRestricted fails with ErrMemberRestricted if the member currently
+// has the restriction.
+func (c *ChatService) checkNotRestricted(ctx context.Context, chatID, userID int64, restriction string) error {
+	restricted, err := c.Chat.IsRestricted(ctx, chatID, userID, restriction)
+	if err != nil {
+		return fmt.Errorf("chat service: failed to check restrictions: %w", customerrors.ErrFailedToCheck)
+	}
+	if restricted {
+		return fmt.Errorf("chat service: %w: %s", customerrors.ErrMemberRestricted, restriction)
+	}
+	return nil
+}
+
+// normalizeRestrictions rejects unknown kinds and drops duplicates.
+func normalizeRestrictions(restrictions []string) ([]string, error) {
+	out := make([]string, 0, len(restrictions))
+	for _, r := range restrictions {
+		if !slices.Contains(knownRestrictions, r) {
+			return nil, fmt.Errorf("chat service: unknown restriction %q: %w", r, customerrors.ErrInvalidInput)
+		}
+		if !slices.Contains(out, r) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
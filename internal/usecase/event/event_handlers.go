@@ -3,17 +3,22 @@ package event
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	dom "main/internal/domain/entity"
 	"main/internal/domain/events"
+	"main/pkg/customerrors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+//go:generate mockgen -source=event_handlers.go -destination=mock/event_mocks.go -package=mock
+
 type ChatUpdater interface {
 	//delete and update last message if needed
-	UpdateChatLastMessage(ctx context.Context, chatID int64, messageText string, createdAt time.Time) error
+	UpdateChatLastMessage(ctx context.Context, chatID, senderID int64, messageText string, createdAt time.Time) error
+	ClearChatLastMessage(ctx context.Context, chatID int64) error
 }
 
 type MongoMessage interface {
@@ -41,13 +46,18 @@ func (h *EventHandlers) HandleMessageDeleted(ctx context.Context, data []byte) e
 	}
 
 	message, err := h.msg.GetLatestMessage(ctx, evt.ChatID)
-	if err != nil {
+	if err != nil && !errors.Is(err, customerrors.ErrMessageDoesNotExists) {
 		return fmt.Errorf("failed to get latest message: %w", err)
 	}
-	if message.ID != primitive.NilObjectID {
-		if err := h.repo.UpdateChatLastMessage(ctx, evt.ChatID, message.Text, message.CreatedAt); err != nil {
-			return fmt.Errorf("failed to update chat last message: %w", err)
+	if err != nil || message.ID == primitive.NilObjectID {
+		// The chat list must not keep showing the text of a deleted message.
+		if err := h.repo.ClearChatLastMessage(ctx, evt.ChatID); err != nil {
+			return fmt.Errorf("failed to clear chat last message: %w", err)
 		}
+		return nil
+	}
+	if err := h.repo.UpdateChatLastMessage(ctx, evt.ChatID, message.SenderID, message.Text, message.CreatedAt); err != nil {
+		return fmt.Errorf("failed to update chat last message: %w", err)
 	}
 
 	return nil
@@ -65,7 +75,7 @@ func (h *EventHandlers) HandleMessageCreated(ctx context.Context, data []byte) e
 		return fmt.Errorf("failed to get latest message: %w", err)
 	}
 
-	if err := h.repo.UpdateChatLastMessage(ctx, message.ChatID, message.Text, message.CreatedAt); err != nil {
+	if err := h.repo.UpdateChatLastMessage(ctx, message.ChatID, message.SenderID, message.Text, message.CreatedAt); err != nil {
 		return fmt.Errorf("failed to update chat last message: %w", err)
 	}
 	return nil
@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: event_handlers.go
+//
+// Generated by this command:
+//
+//	mockgen -source=event_handlers.go -destination=mock/event_mocks.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	entity "main/internal/domain/entity"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockChatUpdater is a mock of ChatUpdater interface.
+type MockChatUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockChatUpdaterMockRecorder
+	isgomock struct{}
+}
+
+// MockChatUpdaterMockRecorder is the mock recorder for MockChatUpdater.
+type MockChatUpdaterMockRecorder struct {
+	mock *MockChatUpdater
+}
+
+// NewMockChatUpdater creates a new mock instance.
+func NewMockChatUpdater(ctrl *gomock.Controller) *MockChatUpdater {
+	mock := &MockChatUpdater{ctrl: ctrl}
+	mock.recorder = &MockChatUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChatUpdater) EXPECT() *MockChatUpdaterMockRecorder {
+	return m.recorder
+}
+
+// ClearChatLastMessage mocks base method.
+func (m *MockChatUpdater) ClearChatLastMessage(ctx context.Context, chatID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearChatLastMessage", ctx, chatID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearChatLastMessage indicates an expected call of ClearChatLastMessage.
+func (mr *MockChatUpdaterMockRecorder) ClearChatLastMessage(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearChatLastMessage", reflect.TypeOf((*MockChatUpdater)(nil).ClearChatLastMessage), ctx, chatID)
+}
+
+// UpdateChatLastMessage mocks base method.
+func (m *MockChatUpdater) UpdateChatLastMessage(ctx context.Context, chatID, senderID int64, messageText string, createdAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateChatLastMessage", ctx, chatID, senderID, messageText, createdAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateChatLastMessage indicates an expected call of UpdateChatLastMessage.
+func (mr *MockChatUpdaterMockRecorder) UpdateChatLastMessage(ctx, chatID, senderID, messageText, createdAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChatLastMessage", reflect.TypeOf((*MockChatUpdater)(nil).UpdateChatLastMessage), ctx, chatID, senderID, messageText, createdAt)
+}
+
+// MockMongoMessage is a mock of MongoMessage interface.
+type MockMongoMessage struct {
+	ctrl     *gomock.Controller
+	recorder *MockMongoMessageMockRecorder
+	isgomock struct{}
+}
+
+// MockMongoMessageMockRecorder is the mock recorder for MockMongoMessage.
+type MockMongoMessageMockRecorder struct {
+	mock *MockMongoMessage
+}
+
+// NewMockMongoMessage creates a new mock instance.
+func NewMockMongoMessage(ctrl *gomock.Controller) *MockMongoMessage {
+	mock := &MockMongoMessage{ctrl: ctrl}
+	mock.recorder = &MockMongoMessageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMongoMessage) EXPECT() *MockMongoMessageMockRecorder {
+	return m.recorder
+}
+
+// GetLatestMessage mocks base method.
+func (m *MockMongoMessage) GetLatestMessage(ctx context.Context, chatID int64) (entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestMessage", ctx, chatID)
+	ret0, _ := ret[0].(entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestMessage indicates an expected call of GetLatestMessage.
+func (mr *MockMongoMessageMockRecorder) GetLatestMessage(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestMessage", reflect.TypeOf((*MockMongoMessage)(nil).GetLatestMessage), ctx, chatID)
+}
+
+// SaveMessage mocks base method.
+func (m *MockMongoMessage) SaveMessage(ctx context.Context, msg any) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMessage", ctx, msg)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveMessage indicates an expected call of SaveMessage.
+func (mr *MockMongoMessageMockRecorder) SaveMessage(ctx, msg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMessage", reflect.TypeOf((*MockMongoMessage)(nil).SaveMessage), ctx, msg)
+}
@@ -0,0 +1,40 @@
+package mock_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	dom "main/internal/domain/entity"
+	"main/internal/domain/events"
+	"main/internal/usecase/event"
+	"main/internal/usecase/event/mock"
+	"main/pkg/customerrors"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestHandleMessageDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	chatRepo := mock.NewMockChatUpdater(ctrl)
+	msgRepo := mock.NewMockMongoMessage(ctrl)
+	handlers := event.NewEventHandlers(chatRepo, msgRepo)
+	ctx := context.Background()
+	data, err := json.Marshal(events.MessageDeleted{ChatID: 1, MessageIDs: []string{"651eb1234567890abcdef123"}})
+	require.NoError(t, err)
+
+	// An older message becomes the preview.
+	createdAt := time.Now()
+	msgRepo.EXPECT().GetLatestMessage(gomock.Any(), int64(1)).
+		Return(dom.Message{ID: primitive.NewObjectID(), ChatID: 1, SenderID: 2, Text: "older", CreatedAt: createdAt}, nil)
+	chatRepo.EXPECT().UpdateChatLastMessage(gomock.Any(), int64(1), int64(2), "older", createdAt).Return(nil)
+	require.NoError(t, handlers.HandleMessageDeleted(ctx, data))
+
+	// Without messages left the preview of the deleted one is cleared.
+	msgRepo.EXPECT().GetLatestMessage(gomock.Any(), int64(1)).Return(dom.Message{}, customerrors.ErrMessageDoesNotExists)
+	chatRepo.EXPECT().ClearChatLastMessage(gomock.Any(), int64(1)).Return(nil)
+	require.NoError(t, handlers.HandleMessageDeleted(ctx, data))
+}
@@ -12,6 +12,11 @@ import (
 
 type ChatInterface interface {
 	CheckIsMemberOfChat(ctx context.Context, chatID int64, userID int64) (bool, error)
+	IsRestricted(ctx context.Context, chatID, userID int64, restriction string) (bool, error)
+}
+
+type UserInterface interface {
+	IsEmailVerified(ctx context.Context, userID int64) (bool, error)
 }
 
 //go:generate mockgen -source=message_usecase.go -destination=mock/message_mocks.go -package=mock
@@ -39,14 +44,16 @@ type KafkaProducer interface {
 
 type MessageService struct {
 	Chat   ChatInterface
+	User   UserInterface
 	Msg    MessageRepository
 	Kafka  KafkaProducer
 	Logger *slog.Logger
 }
 
-func NewMessageService(chat ChatInterface, msg MessageRepository, kafka KafkaProducer, logger *slog.Logger) *MessageService {
+func NewMessageService(chat ChatInterface, user UserInterface, msg MessageRepository, kafka KafkaProducer, logger *slog.Logger) *MessageService {
 	return &MessageService{
 		Chat:   chat,
+		User:   user,
 		Msg:    msg,
 		Kafka:  kafka,
 		Logger: logger,
@@ -58,6 +65,14 @@ func (m *MessageService) SendMessage(ctx context.Context,
 	userID int64,
 	senderUsername string,
 	text string) (*dom.Message, error) {
+	verified, err := m.User.IsEmailVerified(ctx, userID)
+	if err != nil {
+		return nil, customerrors.ErrDatabase
+	}
+	if !verified {
+		return nil, customerrors.ErrEmailNotVerified
+	}
+
 	isMember, err := m.Chat.CheckIsMemberOfChat(ctx, chatID, userID)
 	if err != nil {
 		return nil, customerrors.ErrDatabase
@@ -65,6 +80,9 @@ func (m *MessageService) SendMessage(ctx context.Context,
 	if !isMember {
 		return nil, customerrors.ErrUserNotMemberOfChat
 	}
+	if err := m.checkNotRestricted(ctx, chatID, userID, dom.RestrictSend); err != nil {
+		return nil, err
+	}
 
 	msg := dom.Message{
 		ChatID:         chatID,
@@ -139,6 +157,9 @@ func (m *MessageService) EditMessage(ctx context.Context, senderID int64, chatID
 	if !isMember {
 		return customerrors.ErrUserNotMemberOfChat
 	}
+	if err := m.checkNotRestricted(ctx, chatID, senderID, dom.RestrictEdit); err != nil {
+		return err
+	}
 
 	updatedCount, err := m.Msg.EditMessage(ctx, senderID, chatID, msgID, newText)
 	if err != nil {
@@ -174,3 +195,16 @@ func (m *MessageService) GetMessages(ctx context.Context, userID, chatID int64,
 
 	return m.Msg.GetMessages(ctx, chatID, anchorTime, anchorID, limit)
 }
+
+// checkNotRestricted fails with ErrMemberRestricted while a moderator has
+// taken the action away from the member.
+func (m *MessageService) checkNotRestricted(ctx context.Context, chatID, userID int64, restriction string) error {
+	restricted, err := m.Chat.IsRestricted(ctx, chatID, userID, restriction)
+	if err != nil {
+		return customerrors.ErrDatabase
+	}
+	if restricted {
+		return customerrors.ErrMemberRestricted
+	}
+	return nil
+}
@@ -58,6 +58,60 @@ func (mr *MockChatInterfaceMockRecorder) CheckIsMemberOfChat(ctx, chatID, userID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckIsMemberOfChat", reflect.TypeOf((*MockChatInterface)(nil).CheckIsMemberOfChat), ctx, chatID, userID)
 }
 
+// IsRestricted mocks base method.
+func (m *MockChatInterface) IsRestricted(ctx context.Context, chatID, userID int64, restriction string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRestricted", ctx, chatID, userID, restriction)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRestricted indicates an expected call of IsRestricted.
+func (mr *MockChatInterfaceMockRecorder) IsRestricted(ctx, chatID, userID, restriction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRestricted", reflect.TypeOf((*MockChatInterface)(nil).IsRestricted), ctx, chatID, userID, restriction)
+}
+
+// MockUserInterface is a mock of UserInterface interface.
+type MockUserInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockUserInterfaceMockRecorder is the mock recorder for MockUserInterface.
+type MockUserInterfaceMockRecorder struct {
+	mock *MockUserInterface
+}
+
+// NewMockUserInterface creates a new mock instance.
+func NewMockUserInterface(ctrl *gomock.Controller) *MockUserInterface {
+	mock := &MockUserInterface{ctrl: ctrl}
+	mock.recorder = &MockUserInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserInterface) EXPECT() *MockUserInterfaceMockRecorder {
+	return m.recorder
+}
+
+// IsEmailVerified mocks base method.
+func (m *MockUserInterface) IsEmailVerified(ctx context.Context, userID int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEmailVerified", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsEmailVerified indicates an expected call of IsEmailVerified.
+func (mr *MockUserInterfaceMockRecorder) IsEmailVerified(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEmailVerified", reflect.TypeOf((*MockUserInterface)(nil).IsEmailVerified), ctx, userID)
+}
+
 // MockMessageRepository is a mock of MessageRepository interface.
 type MockMessageRepository struct {
 	ctrl     *gomock.Controller
@@ -22,6 +22,7 @@ func TestSendMessage(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockChat := mock.NewMockChatInterface(ctrl)
+	mockUser := mock.NewMockUserInterface(ctrl)
 	mockMsgRepo := mock.NewMockMessageRepository(ctrl)
 	mockKafka := mock.NewMockKafkaProducer(ctrl)
 
@@ -44,9 +45,15 @@ func TestSendMessage(t *testing.T) {
 			text:           "Hello, world!",
 			setup: func() {
 
+				mockUser.EXPECT().
+					IsEmailVerified(gomock.Any(), int64(10)).
+					Return(true, nil)
 				mockChat.EXPECT().
 					CheckIsMemberOfChat(gomock.Any(), int64(1), int64(10)).
 					Return(true, nil)
+				mockChat.EXPECT().
+					IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictSend).
+					Return(false, nil)
 				mockMsgRepo.EXPECT().
 					SaveMessage(gomock.Any(), gomock.AssignableToTypeOf(dom.Message{})).
 					Return("mongo_id_123", nil)
@@ -61,18 +68,41 @@ func TestSendMessage(t *testing.T) {
 			chatID: 1,
 			userID: 99,
 			setup: func() {
+				mockUser.EXPECT().IsEmailVerified(gomock.Any(), int64(99)).Return(true, nil)
 				mockChat.EXPECT().
 					CheckIsMemberOfChat(gomock.Any(), int64(1), int64(99)).
 					Return(false, nil)
 			},
 			wantErr: customerrors.ErrUserNotMemberOfChat,
 		},
+		{
+			name:   "Error: email is not verified",
+			chatID: 1,
+			userID: 11,
+			setup: func() {
+				mockUser.EXPECT().IsEmailVerified(gomock.Any(), int64(11)).Return(false, nil)
+			},
+			wantErr: customerrors.ErrEmailNotVerified,
+		},
+		{
+			name:   "Error: member is muted",
+			chatID: 1,
+			userID: 10,
+			setup: func() {
+				mockUser.EXPECT().IsEmailVerified(gomock.Any(), int64(10)).Return(true, nil)
+				mockChat.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(10)).Return(true, nil)
+				mockChat.EXPECT().IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictSend).Return(true, nil)
+			},
+			wantErr: customerrors.ErrMemberRestricted,
+		},
 		{
 			name:   "Error: database failed to save message",
 			chatID: 1,
 			userID: 10,
 			setup: func() {
+				mockUser.EXPECT().IsEmailVerified(gomock.Any(), int64(10)).Return(true, nil)
 				mockChat.EXPECT().CheckIsMemberOfChat(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+				mockChat.EXPECT().IsRestricted(gomock.Any(), gomock.Any(), gomock.Any(), dom.RestrictSend).Return(false, nil)
 				mockMsgRepo.EXPECT().SaveMessage(gomock.Any(), gomock.Any()).Return("", errors.New("mongo down"))
 			},
 			wantErr: customerrors.ErrDatabase,
@@ -82,7 +112,9 @@ func TestSendMessage(t *testing.T) {
 			chatID: 1,
 			userID: 10,
 			setup: func() {
+				mockUser.EXPECT().IsEmailVerified(gomock.Any(), int64(10)).Return(true, nil)
 				mockChat.EXPECT().CheckIsMemberOfChat(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+				mockChat.EXPECT().IsRestricted(gomock.Any(), gomock.Any(), gomock.Any(), dom.RestrictSend).Return(false, nil)
 				mockMsgRepo.EXPECT().SaveMessage(gomock.Any(), gomock.Any()).Return("id123", nil)
 				mockKafka.EXPECT().SendMessageCreated(gomock.Any(), gomock.Any()).Return(errors.New("kafka connection error"))
 			},
@@ -96,6 +128,7 @@ func TestSendMessage(t *testing.T) {
 
 			service := &service.MessageService{
 				Chat:   mockChat,
+				User:   mockUser,
 				Msg:    mockMsgRepo,
 				Kafka:  mockKafka,
 				Logger: logger,
@@ -253,6 +286,9 @@ func TestEditMessage(t *testing.T) {
 				mockChat.EXPECT().
 					CheckIsMemberOfChat(gomock.Any(), int64(1), int64(10)).
 					Return(true, nil)
+				mockChat.EXPECT().
+					IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictEdit).
+					Return(false, nil)
 				mockMsgRepo.EXPECT().
 					EditMessage(gomock.Any(), int64(10), int64(1), msgID, newText).
 					Return(int64(1), nil)
@@ -294,6 +330,18 @@ func TestEditMessage(t *testing.T) {
 			},
 			wantErr: customerrors.ErrUserNotMemberOfChat,
 		},
+		{
+			name:     "Member may not edit",
+			senderID: 10,
+			chatID:   1,
+			msgID:    msgID,
+			newText:  newText,
+			setup: func() {
+				mockChat.EXPECT().CheckIsMemberOfChat(gomock.Any(), int64(1), int64(10)).Return(true, nil)
+				mockChat.EXPECT().IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictEdit).Return(true, nil)
+			},
+			wantErr: customerrors.ErrMemberRestricted,
+		},
 		{
 			name:     "Message not found or not an author",
 			senderID: 10,
@@ -304,6 +352,30 @@ func TestEditMessage(t *testing.T) {
 				mockChat.EXPECT().
 					CheckIsMemberOfChat(gomock.Any(), int64(1), int64(10)).
 					Return(true, nil)
+				mockChat.EXPECT().
+					IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictEdit).
+					Return(false, nil)
+				mockMsgRepo.EXPECT().
+					EditMessage(gomock.Any(), int64(10), int64(1), msgID, newText).
+					Return(int64(0), nil)
+			},
+			wantErr: customerrors.ErrMessageDoesNotExists,
+		},
+		{
+			name:     "System message posted about the member's change",
+			senderID: 10,
+			chatID:   1,
+			msgID:    msgID,
+			newText:  newText,
+			setup: func() {
+				mockChat.EXPECT().
+					CheckIsMemberOfChat(gomock.Any(), int64(1), int64(10)).
+					Return(true, nil)
+				mockChat.EXPECT().
+					IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictEdit).
+					Return(false, nil)
+				// The message has no sender and the filter skips system
+				// messages, so nothing matches.
 				mockMsgRepo.EXPECT().
 					EditMessage(gomock.Any(), int64(10), int64(1), msgID, newText).
 					Return(int64(0), nil)
@@ -320,6 +392,9 @@ func TestEditMessage(t *testing.T) {
 				mockChat.EXPECT().
 					CheckIsMemberOfChat(gomock.Any(), int64(1), int64(10)).
 					Return(true, nil)
+				mockChat.EXPECT().
+					IsRestricted(gomock.Any(), int64(1), int64(10), dom.RestrictEdit).
+					Return(false, nil)
 				mockMsgRepo.EXPECT().
 					EditMessage(gomock.Any(), int64(10), int64(1), msgID, newText).
 					Return(int64(0), errors.New("mongo timeout"))
@@ -87,7 +87,7 @@ func TestRegisterUser(t *testing.T) {
 				tt.mockBehavior(mockUserInterface)
 			}
 
-			userService := service.NewUserService(mockUserInterface, sillentLogger)
+			userService := service.NewUserService(mockUserInterface, nil, sillentLogger)
 
 			user, err := userService.RegisterUser(nil, tt.inputUsername, tt.inputEmail, tt.inputPassword)
 
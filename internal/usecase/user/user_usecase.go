@@ -2,9 +2,13 @@ package user
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	dom "main/internal/domain/entity"
+	"main/internal/usecase/audit"
 	"main/pkg/customerrors"
+	"main/pkg/jwt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -12,21 +16,32 @@ import (
 //go:generate mockgen -source=user_service.go -destination=mock/user_mock.go -package=mock
 type UserInterface interface {
 	SearchUser(ctx context.Context, query string) ([]dom.User, error)
+	SetUserRole(ctx context.Context, userID int64, role string) error
+}
+
+// AuditRecorder stores events for the security audit trail.
+type AuditRecorder interface {
+	Record(ctx context.Context, event dom.AuditEvent)
 }
 
 type UserService struct {
 	Repo     UserInterface
+	Audit    AuditRecorder
 	Logger   *slog.Logger
 	Timeout  time.Duration
 	MaxLimit int64
 }
 
-func NewUserService(repo UserInterface, logger *slog.Logger) *UserService {
+func NewUserService(repo UserInterface, auditRecorder AuditRecorder, logger *slog.Logger) *UserService {
 	if logger == nil {
 		logger = slog.Default()
 	}
+	if auditRecorder == nil {
+		auditRecorder = audit.Discard{}
+	}
 	return &UserService{
 		Repo:     repo,
+		Audit:    auditRecorder,
 		Logger:   logger,
 		Timeout:  3 * time.Hour,
 		MaxLimit: 100,
@@ -47,3 +62,28 @@ func (s *UserService) SearchUser(ctx context.Context, message string) ([]dom.Use
 	return users, nil
 
 }
+
+// SetRole changes the global role of userID. Admins cannot change their own
+// role, so the last admin cannot demote themselves by accident. The new role
+// reaches the user's tokens with their next refresh.
+func (s *UserService) SetRole(ctx context.Context, actorID, userID int64, role string) error {
+	if userID <= 0 || !jwt.ValidRole(role) {
+		return customerrors.ErrInvalidInput
+	}
+	if actorID == userID {
+		return fmt.Errorf("%w: cannot change own role", customerrors.ErrInvalidInput)
+	}
+
+	if err := s.Repo.SetUserRole(ctx, userID, role); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, dom.AuditEvent{
+		ActorID:    actorID,
+		Action:     dom.AuditRoleChanged,
+		TargetType: "user",
+		TargetID:   strconv.FormatInt(userID, 10),
+		Metadata:   map[string]interface{}{"role": role},
+	})
+	s.Logger.Info("user role changed", "user_id", userID, "role", role, "actor_id", actorID)
+	return nil
+}
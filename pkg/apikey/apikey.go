@@ -0,0 +1,39 @@
+// Package apikey generates and hashes the long-lived API keys of bot users.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Prefix starts every key, which tells keys apart from JWTs and makes leaked
+// keys easy to find with secret scanners.
+const Prefix = "bot_"
+
+// displayLen is how much of a key is kept in clear text to recognise it in
+// listings.
+const displayLen = len(Prefix) + 8
+
+// Generate returns a new key, the hash to store and the prefix to display.
+func Generate() (key, hash, display string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	key = Prefix + hex.EncodeToString(b)
+	return key, Hash(key), key[:displayLen], nil
+}
+
+// Hash returns the stored form of key. Keys are random, so a plain SHA-256
+// is enough; there is nothing to brute force.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsKey reports whether s looks like an API key rather than a JWT.
+func IsKey(s string) bool {
+	return strings.HasPrefix(s, Prefix)
+}
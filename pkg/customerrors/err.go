@@ -1,6 +1,10 @@
 package customerrors
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	ErrDecodingRequestBody   = errors.New("failed to decode request body")
@@ -20,5 +24,49 @@ var (
 	ErrNotFound              = errors.New("not found")
 	ErrRefreshTokenNotFound  = errors.New("refresh token not found")
 	ErrRefreshTokenExpired   = errors.New("refresh token expired")
+	ErrRefreshTokenRevoked   = errors.New("refresh token revoked")
+	ErrRefreshTokenReused    = errors.New("refresh token reuse detected")
 	ErrMessageDoesNotExists  = errors.New("message does not exist")
+	ErrInvalidTOTPCode       = errors.New("invalid two-factor code")
+	ErrTOTPAlreadyEnabled    = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnrolled       = errors.New("two-factor authentication is not enrolled")
+	ErrChallengeNotFound     = errors.New("login challenge is invalid or expired")
+	ErrEmailNotVerified      = errors.New("email is not verified")
+	ErrEmailAlreadyVerified  = errors.New("email is already verified")
+	ErrInvalidEmailCode      = errors.New("invalid or expired verification code")
+	ErrInvalidResetToken     = errors.New("invalid or expired password reset token")
+	ErrWrongPassword         = errors.New("wrong password")
+	ErrTooManyLoginAttempts  = errors.New("too many failed login attempts")
+	ErrTooManyEmailCodes     = errors.New("too many verification emails requested")
+	ErrUnknownOIDCProvider   = errors.New("unknown identity provider")
+	ErrInvalidOIDCState      = errors.New("sign-in state is invalid or expired")
+	ErrOIDCLogin             = errors.New("identity provider rejected the sign-in")
+	ErrInvalidAPIKey         = errors.New("invalid or revoked api key")
+	ErrInsufficientScope     = errors.New("api key lacks the required scope")
+	ErrIdentityNotLinkable   = errors.New("email belongs to an account that cannot be linked automatically")
+	ErrInsufficientRole      = errors.New("insufficient role")
+	ErrExportNotReady        = errors.New("export is not ready for download")
+	ErrInvalidDownloadLink   = errors.New("download link is invalid or expired")
+	ErrChatPermissionDenied  = errors.New("chat role does not allow this action")
+	ErrInvalidInvite         = errors.New("invite is invalid, expired or used up")
+	ErrHandleTaken           = errors.New("chat handle is already taken")
+	ErrUserBanned            = errors.New("user is banned from the chat")
+	ErrMemberRestricted      = errors.New("member is restricted from this action")
+	// ErrWeakPassword is an ErrInvalidInput, so callers that only know the
+	// latter keep working.
+	ErrWeakPassword = fmt.Errorf("%w: password is too weak", ErrInvalidInput)
 )
+
+// RetryAfterError tells the caller when a rejected request may be retried.
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", e.Err, e.RetryAfter.Round(time.Second))
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,490 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+
+	rsaKeyBits = 2048
+)
+
+// KeyOptions configures a Manager that signs tokens with asymmetric keys.
+type KeyOptions struct {
+	// Algorithm is RS256 or EdDSA.
+	Algorithm string
+	// KeysDir holds one PKCS#8 PEM private key per file, named <kid>.pem,
+	// with its creation time in a Created-At header. Rotated keys are written
+	// there so they survive restarts, and replicas sharing the directory pick
+	// up each other's keys. When empty, keys live in memory only.
+	KeysDir string
+	// Retention is how long a key stays valid for verification after it
+	// stopped being the signing key. It must exceed the access token TTL.
+	Retention time.Duration
+	// RotationInterval is how often StartRotation replaces the signing key.
+	// Replicas sharing KeysDir may sign with a key for this long after its
+	// creation, so key files are deleted only once RotationInterval plus
+	// Retention have passed since then, and never when it is zero.
+	RotationInterval time.Duration
+	// LegacySecret, when set, keeps verifying HS256 tokens without a kid
+	// that were issued before the switch to asymmetric keys. It is retired
+	// when the first asymmetric key becomes active and dropped LegacyTTL
+	// later, which should be the access token TTL.
+	LegacySecret string
+	LegacyTTL    time.Duration
+}
+
+// legacyRetiredFile records in KeysDir when the legacy secret was retired,
+// so restarts do not extend its life.
+const legacyRetiredFile = "legacy_retired_at"
+
+// createdAtHeader is the PEM header holding the creation time of a key. File
+// modification times change on copies and restores, so they are only used
+// for keys written without it.
+const createdAtHeader = "Created-At"
+
+type keyEntry struct {
+	kid       string
+	method    jwt.SigningMethod
+	private   crypto.PrivateKey
+	public    crypto.PublicKey
+	createdAt time.Time
+	retiredAt time.Time
+}
+
+// JWK is the public part of a signing key in JSON Web Key format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func NewAsymmetricManager(opts KeyOptions) (*Manager, error) {
+	if opts.Algorithm != AlgRS256 && opts.Algorithm != AlgEdDSA {
+		return nil, fmt.Errorf("unsupported signing algorithm: %q", opts.Algorithm)
+	}
+
+	m := &Manager{
+		alg:       opts.Algorithm,
+		keys:      make(map[string]*keyEntry),
+		keysDir:   opts.KeysDir,
+		retention: opts.Retention,
+		interval:  opts.RotationInterval,
+		legacyTTL: opts.LegacyTTL,
+	}
+
+	if m.keysDir != "" {
+		if err := os.MkdirAll(m.keysDir, 0o700); err != nil {
+			return nil, fmt.Errorf("create keys dir: %w", err)
+		}
+		if err := m.loadKeys(); err != nil {
+			return nil, err
+		}
+	}
+	if m.activeKID == "" {
+		if err := m.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+	if opts.LegacySecret != "" {
+		retiredAt, err := m.legacyRetiredAt()
+		if err != nil {
+			return nil, err
+		}
+		m.mu.Lock()
+		m.keys[""] = &keyEntry{
+			method:    jwt.SigningMethodHS256,
+			private:   []byte(opts.LegacySecret),
+			public:    []byte(opts.LegacySecret),
+			retiredAt: retiredAt,
+		}
+		m.pruneLocked(time.Now())
+		m.mu.Unlock()
+	}
+	return m, nil
+}
+
+// legacyRetiredAt returns when the legacy secret stopped signing: the time
+// recorded in KeysDir, or else the creation of the oldest asymmetric key,
+// which is then recorded.
+func (m *Manager) legacyRetiredAt() (time.Time, error) {
+	m.mu.RLock()
+	retiredAt := m.keys[m.activeKID].createdAt
+	for _, k := range m.keys {
+		if k.createdAt.Before(retiredAt) {
+			retiredAt = k.createdAt
+		}
+	}
+	m.mu.RUnlock()
+	if m.keysDir == "" {
+		return retiredAt, nil
+	}
+
+	file := filepath.Join(m.keysDir, legacyRetiredFile)
+	raw, err := os.ReadFile(file)
+	if err == nil {
+		recorded, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(raw)))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parse %s: %w", file, err)
+		}
+		return recorded, nil
+	}
+	if !os.IsNotExist(err) {
+		return time.Time{}, fmt.Errorf("read %s: %w", file, err)
+	}
+	if err := os.WriteFile(file, []byte(retiredAt.UTC().Format(time.RFC3339Nano)), 0o600); err != nil {
+		return time.Time{}, fmt.Errorf("write %s: %w", file, err)
+	}
+	return retiredAt, nil
+}
+
+// loadKeys merges every key in KeysDir into the loaded ones. The newest key
+// becomes the signing key, so a key rotated in by another replica is adopted;
+// each older key counts as retired when its successor was created.
+func (m *Manager) loadKeys() error {
+	files, err := filepath.Glob(filepath.Join(m.keysDir, "*.pem"))
+	if err != nil {
+		return fmt.Errorf("list keys dir: %w", err)
+	}
+
+	var read []*keyEntry
+	for _, file := range files {
+		k, err := readKeyFile(file)
+		if err != nil {
+			return err
+		}
+		if k.method.Alg() == m.alg {
+			read = append(read, k)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, k := range read {
+		if _, ok := m.keys[k.kid]; ok {
+			continue
+		}
+		// Keys pruned earlier are left alone, not resurrected.
+		if m.interval > 0 && now.Sub(k.createdAt) > m.interval+m.retention {
+			continue
+		}
+		m.keys[k.kid] = k
+	}
+
+	var loaded []*keyEntry
+	for kid, k := range m.keys {
+		if kid != "" {
+			loaded = append(loaded, k)
+		}
+	}
+	sort.Slice(loaded, func(i, j int) bool {
+		return loaded[i].createdAt.Before(loaded[j].createdAt)
+	})
+	for i, k := range loaded {
+		k.retiredAt = time.Time{}
+		if i < len(loaded)-1 {
+			k.retiredAt = loaded[i+1].createdAt
+		}
+	}
+	if len(loaded) > 0 {
+		m.activeKID = loaded[len(loaded)-1].kid
+	}
+	m.pruneLocked(now)
+	return nil
+}
+
+// reloadForKID reloads KeysDir when kid names a key file this Manager has not
+// loaded yet, such as one just rotated in by another replica.
+func (m *Manager) reloadForKID(kid string) bool {
+	if m.keysDir == "" || !isKID(kid) {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(m.keysDir, kid+".pem")); err != nil {
+		return false
+	}
+	return m.loadKeys() == nil
+}
+
+// isKID reports whether kid looks like one made by generateKey, so that a
+// token header cannot point outside KeysDir.
+func isKID(kid string) bool {
+	if len(kid) != 16 {
+		return false
+	}
+	_, err := hex.DecodeString(kid)
+	return err == nil
+}
+
+func readKeyFile(file string) (*keyEntry, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read key %s: %w", file, err)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, fmt.Errorf("stat key %s: %w", file, err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("key %s is not PEM encoded", file)
+	}
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse key %s: %w", file, err)
+	}
+
+	k := &keyEntry{
+		kid:       strings.TrimSuffix(filepath.Base(file), ".pem"),
+		private:   private,
+		createdAt: info.ModTime(),
+	}
+	if createdAt, ok := block.Headers[createdAtHeader]; ok {
+		k.createdAt, err = time.Parse(time.RFC3339Nano, createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s of key %s: %w", createdAtHeader, file, err)
+		}
+	}
+	switch key := private.(type) {
+	case *rsa.PrivateKey:
+		k.method = jwt.SigningMethodRS256
+		k.public = &key.PublicKey
+	case ed25519.PrivateKey:
+		k.method = jwt.SigningMethodEdDSA
+		k.public = key.Public()
+	default:
+		return nil, fmt.Errorf("key %s has unsupported type %T", file, private)
+	}
+	return k, nil
+}
+
+// Rotate generates a new signing key and retires the current one. Retired
+// keys keep verifying tokens until the retention period has passed.
+func (m *Manager) Rotate() error {
+	if m.alg == AlgHS256 {
+		return fmt.Errorf("rotation is not supported for %s", AlgHS256)
+	}
+
+	k, err := generateKey(m.alg)
+	if err != nil {
+		return err
+	}
+	if m.keysDir != "" {
+		if err := writeKeyFile(m.keysDir, k); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if active, ok := m.keys[m.activeKID]; ok && active.createdAt.Before(k.createdAt) {
+		active.retiredAt = k.createdAt
+	}
+	m.keys[k.kid] = k
+	m.activeKID = k.kid
+	m.pruneLocked(k.createdAt)
+	return nil
+}
+
+// StartRotation rotates the signing key every RotationInterval until ctx is
+// done. A key that is already older than that is rotated right away. With a
+// shared KeysDir, a replica first reloads it and adopts a key another replica
+// rotated in recently instead of rotating again.
+func (m *Manager) StartRotation(ctx context.Context, logger *slog.Logger) {
+	if m.interval <= 0 || m.alg == AlgHS256 {
+		return
+	}
+
+	timer := time.NewTimer(m.untilRotation())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			if m.keysDir != "" {
+				if err := m.loadKeys(); err != nil {
+					logger.Error("failed to reload signing keys", slog.String("error", err.Error()))
+				}
+			}
+			if wait := m.untilRotation(); wait > 0 {
+				logger.Info("adopted signing key", slog.String("kid", m.activeKeyID()))
+				timer.Reset(wait)
+				continue
+			}
+			if err := m.Rotate(); err != nil {
+				logger.Error("failed to rotate signing key", slog.String("error", err.Error()))
+			} else {
+				logger.Info("signing key rotated", slog.String("kid", m.activeKeyID()))
+			}
+			timer.Reset(m.interval)
+		}
+	}
+}
+
+// untilRotation returns how long the signing key has left before it is due
+// for rotation.
+func (m *Manager) untilRotation() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return max(m.interval-time.Since(m.keys[m.activeKID].createdAt), 0)
+}
+
+func (m *Manager) activeKeyID() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.activeKID
+}
+
+func (m *Manager) pruneLocked(now time.Time) {
+	for kid, k := range m.keys {
+		if !m.expiredLocked(kid, k, now) {
+			continue
+		}
+		delete(m.keys, kid)
+		if m.keysDir != "" && kid != "" && m.interval > 0 && now.Sub(k.createdAt) > m.interval+m.retention {
+			_ = os.Remove(filepath.Join(m.keysDir, kid+".pem"))
+		}
+	}
+}
+
+// expiredLocked reports whether a retired key has outlived its retention.
+// Other replicas may sign with a key until it is RotationInterval old, so its
+// retention never starts before then. The legacy secret, kid "", only
+// outlives the access token TTL.
+func (m *Manager) expiredLocked(kid string, k *keyEntry, now time.Time) bool {
+	if kid == m.activeKID || k.retiredAt.IsZero() {
+		return false
+	}
+	if kid == "" {
+		return now.Sub(k.retiredAt) > m.legacyTTL
+	}
+	retiredAt := k.retiredAt
+	if m.interval > 0 {
+		retiredAt = later(retiredAt, k.createdAt.Add(m.interval))
+	}
+	return now.Sub(retiredAt) > m.retention
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func generateKey(alg string) (*keyEntry, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("generate kid: %w", err)
+	}
+	k := &keyEntry{
+		kid:       hex.EncodeToString(b),
+		createdAt: time.Now(),
+	}
+
+	switch alg {
+	case AlgRS256:
+		private, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, fmt.Errorf("generate rsa key: %w", err)
+		}
+		k.method = jwt.SigningMethodRS256
+		k.private = private
+		k.public = &private.PublicKey
+	case AlgEdDSA:
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("generate ed25519 key: %w", err)
+		}
+		k.method = jwt.SigningMethodEdDSA
+		k.private = private
+		k.public = public
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %q", alg)
+	}
+	return k, nil
+}
+
+func writeKeyFile(dir string, k *keyEntry) error {
+	der, err := x509.MarshalPKCS8PrivateKey(k.private)
+	if err != nil {
+		return fmt.Errorf("marshal key: %w", err)
+	}
+	file := filepath.Join(dir, k.kid+".pem")
+	data := pem.EncodeToMemory(&pem.Block{
+		Type:    "PRIVATE KEY",
+		Headers: map[string]string{createdAtHeader: k.createdAt.UTC().Format(time.RFC3339Nano)},
+		Bytes:   der,
+	})
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return fmt.Errorf("write key %s: %w", file, err)
+	}
+	return nil
+}
+
+// JWKS returns the public keys that currently verify tokens. Shared HMAC
+// secrets are never published.
+func (m *Manager) JWKS() JWKS {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := JWKS{Keys: []JWK{}}
+	for _, k := range m.keys {
+		switch public := k.public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: k.kid,
+				Use: "sig",
+				Alg: AlgRS256,
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: k.kid,
+				Use: "sig",
+				Alg: AlgEdDSA,
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
@@ -0,0 +1,53 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrTokenRevoked   = errors.New("token is revoked")
+	ErrSessionRevoked = errors.New("session is revoked")
+)
+
+// TokenParser verifies an access token and returns its claims.
+type TokenParser interface {
+	Parse(accessToken string) (*TokenClaims, error)
+}
+
+// Blacklist reports whether a key was put on the token blacklist, either an
+// access token revoked at logout or a RevokedSessionKey.
+type Blacklist interface {
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// Authenticate parses accessToken and rejects it when it was revoked at logout
+// or belongs to a revoked session. Parse failures wrap ErrInvalidToken; any
+// other error comes from the blacklist.
+func Authenticate(ctx context.Context, parser TokenParser, blacklist Blacklist, accessToken string) (*TokenClaims, error) {
+	isBanned, err := blacklist.Exists(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("check token blacklist: %w", err)
+	}
+	if isBanned {
+		return nil, ErrTokenRevoked
+	}
+
+	claims, err := parser.Parse(accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if claims.SessionID != "" {
+		isRevoked, err := blacklist.Exists(ctx, RevokedSessionKey(claims.SessionID))
+		if err != nil {
+			return nil, fmt.Errorf("check session blacklist: %w", err)
+		}
+		if isRevoked {
+			return nil, ErrSessionRevoked
+		}
+	}
+	return claims, nil
+}
@@ -0,0 +1,39 @@
+package jwt_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"main/pkg/jwt"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type blacklist map[string]bool
+
+func (b blacklist) Exists(_ context.Context, key string) (bool, error) {
+	return b[key], nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	manager, err := jwt.NewManager("secret")
+	require.NoError(t, err)
+
+	token, err := manager.NewAccessToken(42, "sid-1", jwt.RoleUser, time.Minute)
+	require.NoError(t, err)
+
+	claims, err := jwt.Authenticate(context.Background(), manager, blacklist{}, token)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), claims.UserID)
+
+	_, err = jwt.Authenticate(context.Background(), manager, blacklist{token: true}, token)
+	assert.ErrorIs(t, err, jwt.ErrTokenRevoked)
+
+	_, err = jwt.Authenticate(context.Background(), manager, blacklist{jwt.RevokedSessionKey("sid-1"): true}, token)
+	assert.ErrorIs(t, err, jwt.ErrSessionRevoked)
+
+	_, err = jwt.Authenticate(context.Background(), manager, blacklist{}, "garbage")
+	assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+}
@@ -0,0 +1,35 @@
+package jwt
+
+// Global roles of a user. Each role includes the permissions of the roles
+// below it.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+var roleRank = map[string]int{
+	RoleUser:      1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+}
+
+// ValidRole reports whether role is a known role.
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// HasRole reports whether the caller holds role or a higher one. API keys act
+// as plain users whatever the role of their bot.
+func (c *TokenClaims) HasRole(role string) bool {
+	required, ok := roleRank[role]
+	if !ok {
+		return false
+	}
+	held := c.Role
+	if c.APIKeyID != 0 || held == "" {
+		held = RoleUser
+	}
+	return roleRank[held] >= required
+}
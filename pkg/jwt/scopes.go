@@ -0,0 +1,59 @@
+package jwt
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Scopes an API key can be granted. A scope on its own covers every chat the
+// bot is a member of; "<scope>:<chat_id>" restricts it to one chat.
+const (
+	ScopeMessagesRead  = "messages:read"
+	ScopeMessagesWrite = "messages:write"
+	ScopeChatsRead     = "chats:read"
+	ScopeChatsWrite    = "chats:write"
+	ScopeUsersRead     = "users:read"
+)
+
+var knownScopes = map[string]struct{}{
+	ScopeMessagesRead:  {},
+	ScopeMessagesWrite: {},
+	ScopeChatsRead:     {},
+	ScopeChatsWrite:    {},
+	ScopeUsersRead:     {},
+}
+
+// ValidScope reports whether s is a known scope, optionally restricted to a
+// chat.
+func ValidScope(s string) bool {
+	if _, ok := knownScopes[s]; ok {
+		return true
+	}
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return false
+	}
+	if _, ok := knownScopes[s[:i]]; !ok {
+		return false
+	}
+	chatID, err := strconv.ParseInt(s[i+1:], 10, 64)
+	return err == nil && chatID > 0
+}
+
+// Allows reports whether the caller may use scope on chatID. A chatID of zero
+// asks for the scope across all chats. Access tokens of users are not
+// scoped and allow everything.
+func (c *TokenClaims) Allows(scope string, chatID int64) bool {
+	if c.APIKeyID == 0 {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+		if chatID > 0 && s == scope+":"+strconv.FormatInt(chatID, 10) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,36 +4,74 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// TokenClaims describes the authenticated caller. Callers holding an API key
+// instead of an access token have APIKeyID set and are limited to Scopes.
 type TokenClaims struct {
-	UserID int64
-	Exp    int64
-	
+	UserID    int64
+	SessionID string
+	Role      string
+	Exp       int64
+	APIKeyID  int64
+	Scopes    []string
+}
+
+// RevokedSessionKey is the blacklist key marking every access token issued
+// for the session as revoked.
+func RevokedSessionKey(sessionID string) string {
+	return "revoked_session:" + sessionID
 }
 
 type Manager struct {
-	signingKey []byte
+	mu        sync.RWMutex
+	alg       string
+	keys      map[string]*keyEntry
+	activeKID string
+	keysDir   string
+	retention time.Duration
+	interval  time.Duration
+	legacyTTL time.Duration
 }
 
+// NewManager returns a Manager that signs and verifies HS256 tokens with a
+// single shared secret.
 func NewManager(signingKey string) (*Manager, error) {
 	if signingKey == "" {
 		return nil, fmt.Errorf("empty signing key")
 	}
-	return &Manager{signingKey: []byte(signingKey)}, nil
+	return &Manager{
+		alg: AlgHS256,
+		keys: map[string]*keyEntry{
+			"": {
+				method:  jwt.SigningMethodHS256,
+				private: []byte(signingKey),
+				public:  []byte(signingKey),
+			},
+		},
+	}, nil
 }
 
-func (m *Manager) NewAccessToken(userID int64, ttl time.Duration) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": userID,
-		"exp": time.Now().Add(ttl).Unix(),
-		"iat": time.Now().Unix(),
-	})
+func (m *Manager) NewAccessToken(userID int64, sessionID, role string, ttl time.Duration) (string, error) {
+	m.mu.RLock()
+	key := m.keys[m.activeKID]
+	m.mu.RUnlock()
 
-	return token.SignedString(m.signingKey)
+	token := jwt.NewWithClaims(key.method, jwt.MapClaims{
+		"sub":  userID,
+		"sid":  sessionID,
+		"role": role,
+		"exp":  time.Now().Add(ttl).Unix(),
+		"iat":  time.Now().Unix(),
+	})
+	if key.kid != "" {
+		token.Header["kid"] = key.kid
+	}
+	return token.SignedString(key.private)
 }
 
 func (m *Manager) NewRefreshToken() (string, error) {
@@ -44,12 +82,32 @@ func (m *Manager) NewRefreshToken() (string, error) {
 	return hex.EncodeToString(b), nil
 }
 
+// verifyingKey returns the loaded key kid unless it has expired.
+func (m *Manager) verifyingKey(kid string) (*keyEntry, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	key, ok := m.keys[kid]
+	if !ok || m.expiredLocked(kid, key, time.Now()) {
+		return nil, false
+	}
+	return key, true
+}
+
 func (m *Manager) Parse(accessToken string) (*TokenClaims, error) {
 	token, err := jwt.Parse(accessToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+
+		key, ok := m.verifyingKey(kid)
+		if !ok && m.reloadForKID(kid) {
+			key, ok = m.verifyingKey(kid)
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %q", kid)
+		}
+		if token.Method.Alg() != key.method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return m.signingKey, nil
+		return key.public, nil
 	})
 
 	if err != nil {
@@ -71,8 +129,18 @@ func (m *Manager) Parse(accessToken string) (*TokenClaims, error) {
 		return nil, fmt.Errorf("token does not contain exp")
 	}
 
+	sessionID, _ := claims["sid"].(string)
+
+	// Tokens issued before roles existed belong to plain users.
+	role, _ := claims["role"].(string)
+	if role == "" {
+		role = RoleUser
+	}
+
 	return &TokenClaims{
-		UserID: int64(subFloat),
-		Exp:    int64(expFloat),
+		UserID:    int64(subFloat),
+		SessionID: sessionID,
+		Role:      role,
+		Exp:       int64(expFloat),
 	}, nil
 }
@@ -0,0 +1,282 @@
+package jwt_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"main/pkg/jwt"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsymmetricManager(t *testing.T) {
+	for _, alg := range []string{jwt.AlgRS256, jwt.AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			manager, err := jwt.NewAsymmetricManager(jwt.KeyOptions{
+				Algorithm: alg,
+				Retention: time.Hour,
+			})
+			require.NoError(t, err)
+
+			token, err := manager.NewAccessToken(42, "sid-1", jwt.RoleModerator, time.Minute)
+			require.NoError(t, err)
+
+			claims, err := manager.Parse(token)
+			require.NoError(t, err)
+			assert.Equal(t, int64(42), claims.UserID)
+			assert.Equal(t, "sid-1", claims.SessionID)
+			assert.Equal(t, jwt.RoleModerator, claims.Role)
+
+			keys := manager.JWKS().Keys
+			require.Len(t, keys, 1)
+			assert.Equal(t, alg, keys[0].Alg)
+			assert.Equal(t, "sig", keys[0].Use)
+		})
+	}
+}
+
+func TestRotationKeepsOldTokensValid(t *testing.T) {
+	manager, err := jwt.NewAsymmetricManager(jwt.KeyOptions{
+		Algorithm: jwt.AlgEdDSA,
+		Retention: time.Hour,
+	})
+	require.NoError(t, err)
+
+	oldToken, err := manager.NewAccessToken(1, "", jwt.RoleUser, time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, manager.Rotate())
+
+	_, err = manager.Parse(oldToken)
+	assert.NoError(t, err)
+	assert.Len(t, manager.JWKS().Keys, 2)
+}
+
+func TestRotationDropsExpiredKeys(t *testing.T) {
+	manager, err := jwt.NewAsymmetricManager(jwt.KeyOptions{
+		Algorithm: jwt.AlgEdDSA,
+	})
+	require.NoError(t, err)
+
+	oldToken, err := manager.NewAccessToken(1, "", jwt.RoleUser, time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, manager.Rotate())
+	time.Sleep(time.Millisecond)
+	require.NoError(t, manager.Rotate())
+
+	// Only the first key is past retention; the one retired just now stays.
+	_, err = manager.Parse(oldToken)
+	assert.Error(t, err)
+	assert.Len(t, manager.JWKS().Keys, 2)
+}
+
+func TestLegacySecretStillVerifies(t *testing.T) {
+	legacy, err := jwt.NewManager("secret")
+	require.NoError(t, err)
+	legacyToken, err := legacy.NewAccessToken(7, "", jwt.RoleUser, time.Minute)
+	require.NoError(t, err)
+
+	manager, err := jwt.NewAsymmetricManager(jwt.KeyOptions{
+		Algorithm:    jwt.AlgRS256,
+		Retention:    time.Hour,
+		LegacySecret: "secret",
+		LegacyTTL:    time.Minute,
+	})
+	require.NoError(t, err)
+
+	claims, err := manager.Parse(legacyToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), claims.UserID)
+
+	// The shared secret must never be published.
+	assert.Len(t, manager.JWKS().Keys, 1)
+}
+
+func TestLegacySecretExpires(t *testing.T) {
+	legacy, err := jwt.NewManager("secret")
+	require.NoError(t, err)
+	legacyToken, err := legacy.NewAccessToken(7, "", jwt.RoleUser, time.Hour)
+	require.NoError(t, err)
+
+	opts := jwt.KeyOptions{
+		Algorithm:    jwt.AlgEdDSA,
+		Retention:    time.Hour,
+		LegacySecret: "secret",
+		LegacyTTL:    time.Millisecond,
+	}
+	manager, err := jwt.NewAsymmetricManager(opts)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	_, err = manager.Parse(legacyToken)
+	assert.Error(t, err)
+
+	// Without the option the secret is not accepted at all.
+	opts.LegacySecret = ""
+	opts.LegacyTTL = time.Hour
+	manager, err = jwt.NewAsymmetricManager(opts)
+	require.NoError(t, err)
+	_, err = manager.Parse(legacyToken)
+	assert.Error(t, err)
+}
+
+func TestLegacySecretRetirementIsPersisted(t *testing.T) {
+	legacy, err := jwt.NewManager("secret")
+	require.NoError(t, err)
+	legacyToken, err := legacy.NewAccessToken(7, "", jwt.RoleUser, time.Hour)
+	require.NoError(t, err)
+
+	opts := jwt.KeyOptions{
+		Algorithm:    jwt.AlgEdDSA,
+		KeysDir:      t.TempDir(),
+		Retention:    time.Hour,
+		LegacySecret: "secret",
+		LegacyTTL:    20 * time.Millisecond,
+	}
+	first, err := jwt.NewAsymmetricManager(opts)
+	require.NoError(t, err)
+	_, err = first.Parse(legacyToken)
+	require.NoError(t, err)
+
+	// A restart, even after a rotation, does not restart the clock.
+	require.NoError(t, first.Rotate())
+	time.Sleep(30 * time.Millisecond)
+	second, err := jwt.NewAsymmetricManager(opts)
+	require.NoError(t, err)
+	_, err = second.Parse(legacyToken)
+	assert.Error(t, err)
+}
+
+func TestKeysArePersisted(t *testing.T) {
+	dir := t.TempDir()
+	opts := jwt.KeyOptions{
+		Algorithm: jwt.AlgEdDSA,
+		KeysDir:   dir,
+		Retention: time.Hour,
+	}
+
+	first, err := jwt.NewAsymmetricManager(opts)
+	require.NoError(t, err)
+	token, err := first.NewAccessToken(3, "", jwt.RoleUser, time.Minute)
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	info, err := os.Stat(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	second, err := jwt.NewAsymmetricManager(opts)
+	require.NoError(t, err)
+	_, err = second.Parse(token)
+	assert.NoError(t, err)
+	assert.Equal(t, first.JWKS(), second.JWKS())
+}
+
+func TestHasRole(t *testing.T) {
+	admin := &jwt.TokenClaims{UserID: 1, Role: jwt.RoleAdmin}
+	assert.True(t, admin.HasRole(jwt.RoleUser))
+	assert.True(t, admin.HasRole(jwt.RoleModerator))
+	assert.True(t, admin.HasRole(jwt.RoleAdmin))
+
+	moderator := &jwt.TokenClaims{UserID: 2, Role: jwt.RoleModerator}
+	assert.True(t, moderator.HasRole(jwt.RoleModerator))
+	assert.False(t, moderator.HasRole(jwt.RoleAdmin))
+	assert.False(t, moderator.HasRole("root"))
+
+	legacy := &jwt.TokenClaims{UserID: 3}
+	assert.True(t, legacy.HasRole(jwt.RoleUser))
+	assert.False(t, legacy.HasRole(jwt.RoleModerator))
+
+	apiKey := &jwt.TokenClaims{UserID: 4, Role: jwt.RoleAdmin, APIKeyID: 9}
+	assert.False(t, apiKey.HasRole(jwt.RoleModerator))
+}
+
+func TestKeyAgeSurvivesCopies(t *testing.T) {
+	dir := t.TempDir()
+	opts := jwt.KeyOptions{
+		Algorithm:        jwt.AlgEdDSA,
+		KeysDir:          dir,
+		Retention:        time.Hour,
+		RotationInterval: time.Hour,
+	}
+	first, err := jwt.NewAsymmetricManager(opts)
+	require.NoError(t, err)
+	token, err := first.NewAccessToken(3, "", jwt.RoleUser, time.Minute)
+	require.NoError(t, err)
+
+	// An old modification time, as left by a restore, does not age the key.
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	require.NoError(t, err)
+	old := time.Now().Add(-24 * time.Hour)
+	require.NoError(t, os.Chtimes(files[0], old, old))
+
+	second, err := jwt.NewAsymmetricManager(opts)
+	require.NoError(t, err)
+	_, err = second.Parse(token)
+	assert.NoError(t, err)
+	assert.Equal(t, first.JWKS(), second.JWKS())
+}
+
+func TestReplicaPicksUpRotatedKey(t *testing.T) {
+	opts := jwt.KeyOptions{
+		Algorithm:        jwt.AlgEdDSA,
+		KeysDir:          t.TempDir(),
+		Retention:        time.Hour,
+		RotationInterval: time.Hour,
+	}
+	first, err := jwt.NewAsymmetricManager(opts)
+	require.NoError(t, err)
+	second, err := jwt.NewAsymmetricManager(opts)
+	require.NoError(t, err)
+
+	require.NoError(t, first.Rotate())
+	token, err := first.NewAccessToken(3, "", jwt.RoleUser, time.Minute)
+	require.NoError(t, err)
+
+	_, err = second.Parse(token)
+	require.NoError(t, err)
+	// The replica now signs with the newest key as well.
+	assert.Equal(t, first.JWKS(), second.JWKS())
+	other, err := second.NewAccessToken(4, "", jwt.RoleUser, time.Minute)
+	require.NoError(t, err)
+	_, err = first.Parse(other)
+	assert.NoError(t, err)
+
+	_, err = second.Parse(tokenWithKID(t, "../../etc/passwd"))
+	assert.Error(t, err)
+}
+
+func TestRotationKeepsKeyFilesOtherReplicasMayUse(t *testing.T) {
+	dir := t.TempDir()
+	manager, err := jwt.NewAsymmetricManager(jwt.KeyOptions{
+		Algorithm:        jwt.AlgEdDSA,
+		KeysDir:          dir,
+		RotationInterval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, manager.Rotate())
+	require.NoError(t, manager.Rotate())
+
+	// The first key is less than one rotation interval old, so another
+	// replica may still sign with it.
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+	assert.Len(t, manager.JWKS().Keys, 3)
+}
+
+func tokenWithKID(t *testing.T, kid string) string {
+	t.Helper()
+	token := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{"sub": 1, "exp": time.Now().Add(time.Minute).Unix()})
+	token.Header["kid"] = kid
+	signed, err := token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+	return signed
+}
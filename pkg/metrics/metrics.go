@@ -35,4 +35,12 @@ var (
 			Help: "Total number of messages processed",
 		},
 	)
+
+	LoginFailuresTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "login_failures_total",
+			Help: "Total number of failed login attempts",
+		},
+		[]string{"reason"},
+	)
 )
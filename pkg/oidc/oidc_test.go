@@ -0,0 +1,214 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"main/pkg/oidc"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockIdP is a minimal OpenID provider. It issues one code per authorization
+// request and checks the PKCE verifier when the code is redeemed.
+type mockIdP struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu         sync.Mutex
+	challenges map[string]string // code -> code_challenge
+	nonces     map[string]string // code -> nonce
+	claims     jwt.MapClaims
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	idp := &mockIdP{
+		t:          t,
+		key:        key,
+		challenges: map[string]string{},
+		nonces:     map[string]string{},
+		claims: jwt.MapClaims{
+			"sub":            "subject-1",
+			"email":          "alice@example.com",
+			"email_verified": true,
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "k1",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", idp.token)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize plays the user signing in at the authorization URL and returns
+// the code the provider would redirect back with.
+func (idp *mockIdP) authorize(authURL string) string {
+	u, err := url.Parse(authURL)
+	require.NoError(idp.t, err)
+	q := u.Query()
+	require.Equal(idp.t, "S256", q.Get("code_challenge_method"))
+
+	code := "code-" + q.Get("state")
+	idp.mu.Lock()
+	idp.challenges[code] = q.Get("code_challenge")
+	idp.nonces[code] = q.Get("nonce")
+	idp.mu.Unlock()
+	return code
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, _ := r.BasicAuth()
+	if clientID != "client" || clientSecret != "secret" {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+
+	code := r.PostFormValue("code")
+	idp.mu.Lock()
+	challenge, ok := idp.challenges[code]
+	nonce := idp.nonces[code]
+	delete(idp.challenges, code)
+	idp.mu.Unlock()
+	if !ok || oidc.CodeChallenge(r.PostFormValue("code_verifier")) != challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"aud":   "client",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": nonce,
+	}
+	for k, v := range idp.claims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "k1"
+	signed, err := token.SignedString(idp.key)
+	require.NoError(idp.t, err)
+
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"access_token": "at",
+		"token_type":   "Bearer",
+		"id_token":     signed,
+	})
+}
+
+func (idp *mockIdP) provider() *oidc.Provider {
+	return oidc.NewProvider(oidc.Config{
+		IssuerURL:    idp.server.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/callback",
+	}, idp.server.Client())
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	idp := newMockIdP(t)
+	p := idp.provider()
+	ctx := context.Background()
+
+	verifier, err := oidc.RandomString()
+	require.NoError(t, err)
+
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	require.NoError(t, err)
+	code := idp.authorize(authURL)
+
+	identity, err := p.Authenticate(ctx, code, verifier, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, oidc.Identity{
+		Subject:       "subject-1",
+		Email:         "alice@example.com",
+		EmailVerified: true,
+	}, identity)
+
+	// Codes are single use.
+	_, err = p.Authenticate(ctx, code, verifier, "nonce-1")
+	assert.Error(t, err)
+}
+
+func TestAuthenticateRejects(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("wrong verifier", func(t *testing.T) {
+		idp := newMockIdP(t)
+		p := idp.provider()
+		authURL, err := p.AuthCodeURL(ctx, "s", "n", "verifier-1")
+		require.NoError(t, err)
+
+		_, err = p.Authenticate(ctx, idp.authorize(authURL), "verifier-2", "n")
+		assert.Error(t, err)
+	})
+
+	t.Run("nonce mismatch", func(t *testing.T) {
+		idp := newMockIdP(t)
+		p := idp.provider()
+		authURL, err := p.AuthCodeURL(ctx, "s", "n", "v")
+		require.NoError(t, err)
+
+		_, err = p.Authenticate(ctx, idp.authorize(authURL), "v", "other")
+		assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		idp := newMockIdP(t)
+		idp.claims["aud"] = "someone-else"
+		p := idp.provider()
+		authURL, err := p.AuthCodeURL(ctx, "s", "n", "v")
+		require.NoError(t, err)
+
+		_, err = p.Authenticate(ctx, idp.authorize(authURL), "v", "n")
+		assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		idp := newMockIdP(t)
+		idp.claims["exp"] = time.Now().Add(-time.Hour).Unix()
+		p := idp.provider()
+		authURL, err := p.AuthCodeURL(ctx, "s", "n", "v")
+		require.NoError(t, err)
+
+		_, err = p.Authenticate(ctx, idp.authorize(authURL), "v", "n")
+		assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+	})
+}
@@ -0,0 +1,119 @@
+package password_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"main/pkg/customerrors"
+	"main/pkg/password"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var fast = password.Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestHasher(t *testing.T) {
+	h := password.NewHasher(fast)
+
+	hash, err := h.Hash("Secret123")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	other, err := h.Hash("Secret123")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "every hash gets its own salt")
+
+	ok, rehash, err := h.Verify("Secret123", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, rehash)
+
+	ok, _, err = h.Verify("Secret124", hash)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	stronger := password.NewHasher(password.Params{Memory: 2048, Iterations: 1, Parallelism: 1})
+	ok, rehash, err = stronger.Verify("Secret123", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash, "hashes with other parameters are upgraded")
+}
+
+func TestHasherVerifiesBcrypt(t *testing.T) {
+	h := password.NewHasher(fast)
+	legacy, err := bcrypt.GenerateFromPassword([]byte("Secret123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	ok, rehash, err := h.Verify("Secret123", string(legacy))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash)
+
+	ok, _, err = h.Verify("wrong", string(legacy))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, _, err = h.Verify("", "")
+	assert.ErrorIs(t, err, password.ErrUnknownHash)
+	assert.False(t, ok)
+}
+
+func TestPolicy(t *testing.T) {
+	policy := password.Policy{
+		MinLength:     10,
+		MaxLength:     20,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		Breached:      password.NewBreachedList([]string{"Tr0ub4dor&3x"}),
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		ok       bool
+	}{
+		{name: "Valid", password: "c0rrect-Horse", ok: true},
+		{name: "Too short", password: "c0rrect-H"},
+		{name: "Too long", password: "c0rrect-Horse-battery-staple"},
+		{name: "No uppercase", password: "c0rrect-horse"},
+		{name: "No lowercase", password: "C0RRECT-HORSE"},
+		{name: "No digit", password: "correct-Horse"},
+		{name: "No symbol", password: "c0rrectHorse"},
+		{name: "Breached in any case", password: "TR0UB4DOR&3X"},
+		{name: "Same as username", password: "Ali.ce-2000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.password, "ali.ce-2000")
+			if tt.ok {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, customerrors.ErrWeakPassword)
+		})
+	}
+}
+
+func TestBreachedList(t *testing.T) {
+	assert.True(t, password.CommonPasswords().Contains("Password1"))
+	assert.False(t, password.CommonPasswords().Contains("CorrectHorse9"))
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# leaked\nHunter2Hunter2\n\n"), 0o600))
+
+	list, err := password.LoadBreachedList(path)
+	require.NoError(t, err)
+	assert.True(t, list.Contains("hunter2hunter2"))
+	assert.True(t, list.Contains("qwerty123"), "the built-in list is included")
+	assert.Equal(t, password.CommonPasswords().Len()+1, list.Len())
+
+	_, err = password.LoadBreachedList(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
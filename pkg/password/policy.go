@@ -0,0 +1,153 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"main/pkg/customerrors"
+)
+
+// Policy describes what a new password must look like. Violations are
+// reported as customerrors.ErrWeakPassword with the reason appended.
+type Policy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// Breached rejects passwords known from leaks; nil skips the check.
+	Breached *BreachedList
+}
+
+// DefaultPolicy keeps the historic rules (eight characters and an uppercase
+// letter) and rejects the most common leaked passwords.
+var DefaultPolicy = Policy{
+	MinLength:    8,
+	MaxLength:    128,
+	RequireUpper: true,
+	Breached:     CommonPasswords(),
+}
+
+// Check returns nil if password satisfies the policy. userInputs are values
+// such as the username or email the password must not repeat.
+func (p Policy) Check(password string, userInputs ...string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters", customerrors.ErrWeakPassword, p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return fmt.Errorf("%w: must be at most %d characters", customerrors.ErrWeakPassword, p.MaxLength)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r), unicode.IsSymbol(r), r == ' ':
+			symbol = true
+		}
+	}
+	switch {
+	case p.RequireUpper && !upper:
+		return fmt.Errorf("%w: must contain an uppercase letter", customerrors.ErrWeakPassword)
+	case p.RequireLower && !lower:
+		return fmt.Errorf("%w: must contain a lowercase letter", customerrors.ErrWeakPassword)
+	case p.RequireDigit && !digit:
+		return fmt.Errorf("%w: must contain a digit", customerrors.ErrWeakPassword)
+	case p.RequireSymbol && !symbol:
+		return fmt.Errorf("%w: must contain a symbol", customerrors.ErrWeakPassword)
+	}
+
+	for _, input := range userInputs {
+		if input != "" && strings.EqualFold(password, input) {
+			return fmt.Errorf("%w: must not match your username or email", customerrors.ErrWeakPassword)
+		}
+	}
+
+	if p.Breached.Contains(password) {
+		return fmt.Errorf("%w: appears in a list of leaked passwords", customerrors.ErrWeakPassword)
+	}
+	return nil
+}
+
+// BreachedList is a set of leaked passwords, compared case-insensitively.
+type BreachedList struct {
+	passwords map[string]struct{}
+}
+
+func NewBreachedList(passwords []string) *BreachedList {
+	l := &BreachedList{passwords: make(map[string]struct{}, len(passwords))}
+	l.add(passwords)
+	return l
+}
+
+//go:embed common.txt
+var commonPasswords string
+
+// CommonPasswords returns the built-in list of the most common leaked
+// passwords.
+func CommonPasswords() *BreachedList {
+	return NewBreachedList(strings.Split(commonPasswords, "\n"))
+}
+
+// LoadBreachedList extends the built-in list with the passwords in path, one
+// per line. Empty lines and lines starting with # are skipped.
+func LoadBreachedList(path string) (*BreachedList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open breached password list: %w", err)
+	}
+	defer f.Close()
+
+	l := CommonPasswords()
+	var batch []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		batch = append(batch, scanner.Text())
+		if len(batch) == 4096 {
+			l.add(batch)
+			batch = batch[:0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read breached password list: %w", err)
+	}
+	l.add(batch)
+	return l, nil
+}
+
+func (l *BreachedList) add(passwords []string) {
+	for _, p := range passwords {
+		p = strings.TrimSpace(p)
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+		l.passwords[strings.ToLower(p)] = struct{}{}
+	}
+}
+
+func (l *BreachedList) Contains(password string) bool {
+	if l == nil {
+		return false
+	}
+	_, ok := l.passwords[strings.ToLower(password)]
+	return ok
+}
+
+func (l *BreachedList) Len() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.passwords)
+}
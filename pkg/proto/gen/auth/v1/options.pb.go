@@ -0,0 +1,99 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.32.1
+// source: auth/v1/options.proto
+
+package authv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+var file_auth_v1_options_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         50001,
+		Name:          "auth.v1.public",
+		Tag:           "varint,50001,opt,name=public",
+		Filename:      "auth/v1/options.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         50002,
+		Name:          "auth.v1.api_key_scope",
+		Tag:           "bytes,50002,opt,name=api_key_scope",
+		Filename:      "auth/v1/options.proto",
+	},
+}
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// The method can be called without credentials.
+	//
+	// optional bool public = 50001;
+	E_Public = &file_auth_v1_options_proto_extTypes[0]
+	// The scope an API key needs to call the method. Methods without one
+	// reject API keys.
+	//
+	// optional string api_key_scope = 50002;
+	E_ApiKeyScope = &file_auth_v1_options_proto_extTypes[1]
+)
+
+var File_auth_v1_options_proto protoreflect.FileDescriptor
+
+const file_auth_v1_options_proto_rawDesc = "" +
+	"\n" +
+	"\x15auth/v1/options.proto\x12\aauth.v1\x1a google/protobuf/descriptor.proto:8\n" +
+	"\x06public\x12\x1e.google.protobuf.MethodOptions\x18ц\x03 \x01(\bR\x06public:D\n" +
+	"\rapi_key_scope\x12\x1e.google.protobuf.MethodOptions\x18҆\x03 \x01(\tR\vapiKeyScopeB#Z!main/pkg/proto/gen/auth/v1;authv1b\x06proto3"
+
+var file_auth_v1_options_proto_goTypes = []any{
+	(*descriptorpb.MethodOptions)(nil), // 0: google.protobuf.MethodOptions
+}
+var file_auth_v1_options_proto_depIdxs = []int32{
+	0, // 0: auth.v1.public:extendee -> google.protobuf.MethodOptions
+	0, // 1: auth.v1.api_key_scope:extendee -> google.protobuf.MethodOptions
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	0, // [0:2] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_auth_v1_options_proto_init() }
+func file_auth_v1_options_proto_init() {
+	if File_auth_v1_options_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_auth_v1_options_proto_rawDesc), len(file_auth_v1_options_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   0,
+			NumExtensions: 2,
+			NumServices:   0,
+		},
+		GoTypes:           file_auth_v1_options_proto_goTypes,
+		DependencyIndexes: file_auth_v1_options_proto_depIdxs,
+		ExtensionInfos:    file_auth_v1_options_proto_extTypes,
+	}.Build()
+	File_auth_v1_options_proto = out.File
+	file_auth_v1_options_proto_goTypes = nil
+	file_auth_v1_options_proto_depIdxs = nil
+}
@@ -0,0 +1,22 @@
+// Package requestinfo carries details about the client of a request through
+// the context, for the audit trail.
+package requestinfo
+
+import "context"
+
+type Info struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type contextKey struct{}
+
+func ToContext(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+func FromContext(ctx context.Context) (Info, bool) {
+	info, ok := ctx.Value(contextKey{}).(Info)
+	return info, ok
+}